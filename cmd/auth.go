@@ -4,13 +4,21 @@ Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
 	"strings"
 
+	"github.com/charmbracelet/x/term"
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/auth"
+	"github.com/jbrinkman/ghi/pkg/clients"
+	"github.com/jbrinkman/ghi/pkg/db"
+	"github.com/jbrinkman/ghi/pkg/logger"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var authCmd = &cobra.Command{
@@ -18,7 +26,12 @@ var authCmd = &cobra.Command{
 	Short: "Manage authentication settings",
 	Long: `The auth command allows you to manage authentication settings.
 For GitHub access, you'll need to set your token to avoid rate limiting.
-You can create a token at https://github.com/settings/tokens`,
+You can create a token at https://github.com/settings/tokens
+
+Settings can be kept in named profiles, such as work and personal, each with
+its own host, token, username and database. Create one with
+'ghi auth set --profile work' and use it with --profile work or by setting
+GHI_PROFILE=work.`,
 }
 
 var authSetCmd = &cobra.Command{
@@ -29,56 +42,131 @@ var authSetCmd = &cobra.Command{
 		token, _ := cmd.Flags().GetString("token")
 		dburl, _ := cmd.Flags().GetString("db-url")
 		dbtoken, _ := cmd.Flags().GetString("db-token")
+		dbmode, _ := cmd.Flags().GetString("db-mode")
+		slackWebhook, _ := cmd.Flags().GetString("slack-webhook")
+		host, _ := cmd.Flags().GetString("host")
+		stdin, _ := cmd.Flags().GetBool("stdin")
 
-		// Create config directory if it doesn't exist
-		configDir := filepath.Join(os.Getenv("HOME"), ".ghi")
-		if err := os.MkdirAll(configDir, 0755); err != nil {
-			log.Fatalf("Error creating config directory: %v", err)
+		// Reading the token from stdin keeps it out of the shell history
+		if stdin {
+			if token != "" {
+				log.Fatal("The --token and --stdin flags can't be used together")
+			}
+			var err error
+			if token, err = readTokenStdin(); err != nil {
+				log.Fatal(err)
+			}
 		}
 
-		// Update environment file
-		envFile := filepath.Join(configDir, "env")
-		env := make(map[string]string)
+		if dbmode != "" && dbmode != db.ModePersonal && dbmode != db.ModeShared {
+			log.Fatalf("Invalid database mode %q. Use '%s' or '%s'", dbmode, db.ModePersonal, db.ModeShared)
+		}
+		host = strings.TrimSuffix(strings.TrimPrefix(host, "https://"), "/")
+		if strings.ContainsAny(host, "/ ") {
+			log.Fatal("The host must be a host name, such as github.example.com")
+		}
+		if slackWebhook != "" && !strings.HasPrefix(slackWebhook, "https://") {
+			log.Fatal("The Slack webhook must be an https:// URL, such as https://hooks.slack.com/services/...")
+		}
 
-		// Read existing env file if it exists
-		if data, err := os.ReadFile(envFile); err == nil {
-			for _, line := range strings.Split(string(data), "\n") {
-				parts := strings.SplitN(line, "=", 2)
-				if len(parts) == 2 {
-					env[parts[0]] = parts[1]
-				}
+		err := updateEnvFile(func(env map[string]string) {
+			if username != "" {
+				env["GHI_USERNAME"] = username
+			}
+			if token != "" {
+				env["GHI_GITHUB_TOKEN"] = token
+			}
+			if dburl != "" {
+				env["GHI_DB_URL"] = dburl
+			}
+			if dbtoken != "" {
+				env["GHI_AUTH_TOKEN"] = dbtoken
 			}
+			if dbmode != "" {
+				env["GHI_DB_MODE"] = dbmode
+			}
+			if slackWebhook != "" {
+				env["GHI_SLACK_WEBHOOK"] = slackWebhook
+			}
+			if host != "" {
+				env["GHI_HOST"] = host
+			}
+		})
+		if err != nil {
+			log.Fatal(err)
 		}
 
-		// Update values
-		if username != "" {
-			env["GHI_USERNAME"] = username
+		if activeProfile != "" {
+			fmt.Printf("Authentication settings of profile %s updated successfully\n", activeProfile)
+		} else {
+			fmt.Println("Authentication settings updated successfully")
 		}
 		if token != "" {
-			env["GHI_GITHUB_TOKEN"] = token
+			fmt.Println("GitHub token set - API requests will now use authenticated rate limits (5000/hour)")
+		}
+	},
+}
+
+var authEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the stored settings with a passphrase",
+	Long: `The encrypt command encrypts the env file with a passphrase so your tokens
+aren't stored as plaintext. The passphrase is read from the GHI_PASSPHRASE
+environment variable, or asked for when ghi runs in a terminal.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		envFile := envFilePath()
+		env, encrypted, err := readEnvFile(envFile)
+		if err != nil {
+			log.Fatalf("Error reading env file: %v", err)
 		}
-		if dburl != "" {
-			env["GHI_DB_URL"] = dburl
+		if encrypted {
+			fmt.Println("Settings are already encrypted")
+			return
 		}
-		if dbtoken != "" {
-			env["GHI_AUTH_TOKEN"] = dbtoken
+
+		// Confirm a new passphrase when it is typed in
+		if os.Getenv("GHI_PASSPHRASE") == "" {
+			passphrase, err := envPassphrase("New passphrase: ")
+			if err != nil {
+				log.Fatal(err)
+			}
+			cachedPassphrase = ""
+			confirm, err := envPassphrase("Confirm passphrase: ")
+			if err != nil {
+				log.Fatal(err)
+			}
+			if confirm != passphrase {
+				log.Fatal("Passphrases don't match")
+			}
 		}
 
-		// Write back to file
-		f, err := os.Create(envFile)
-		if err != nil {
-			log.Fatalf("Error creating env file: %v", err)
+		logger.Debug("Encrypting %d settings in %s", len(env), envFile)
+		if err := writeEnvFile(envFile, env, true); err != nil {
+			log.Fatalf("Error writing env file: %v", err)
 		}
-		defer f.Close()
+		fmt.Println("Settings encrypted. Set GHI_PASSPHRASE to avoid being asked for the passphrase")
+	},
+}
 
-		for k, v := range env {
-			fmt.Fprintf(f, "%s=%s\n", k, v)
+var authDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Store the settings as plaintext again",
+	Run: func(cmd *cobra.Command, args []string) {
+		envFile := envFilePath()
+		env, encrypted, err := readEnvFile(envFile)
+		if err != nil {
+			log.Fatalf("Error reading env file: %v", err)
+		}
+		if !encrypted {
+			fmt.Println("Settings are not encrypted")
+			return
 		}
 
-		fmt.Println("Authentication settings updated successfully")
-		if token != "" {
-			fmt.Println("GitHub token set - API requests will now use authenticated rate limits (5000/hour)")
+		logger.Debug("Decrypting %d settings in %s", len(env), envFile)
+		if err := writeEnvFile(envFile, env, false); err != nil {
+			log.Fatalf("Error writing env file: %v", err)
 		}
+		fmt.Println("Settings decrypted")
 	},
 }
 
@@ -86,24 +174,149 @@ var authShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show current authentication settings",
 	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf("Profile: %s\n", profileName())
+		fmt.Printf("Host: %s\n", clients.Host())
 		fmt.Printf("Username: %s\n", os.Getenv("GHI_USERNAME"))
 
-		// Don't show the full token for security
-		token := os.Getenv("GHI_GITHUB_TOKEN")
-		if token != "" {
-			fmt.Printf("GitHub Token: %s...%s\n", token[:4], token[len(token)-4:])
+		// Don't show the full token for security, or run the token command to show it
+		if command := viper.GetString("token_command"); command != "" {
+			fmt.Printf("GitHub Token: printed by token_command (%s)\n", auth.CommandName(command))
+		} else if token, source, err := clients.GitHubTokenSource(); err != nil {
+			fmt.Printf("GitHub Token: %v\n", err)
+		} else if token != "" {
+			fmt.Printf("GitHub Token: %s...%s (from %s)\n", token[:4], token[len(token)-4:], source)
 		} else {
 			fmt.Println("GitHub Token: not set")
 		}
+		fmt.Printf("  The token is taken from the first of: token_command in the config file, a %s set with GHI_APP_ID, GHI_GITHUB_TOKEN, %s, %s\n",
+			clients.TokenSourceApp, clients.TokenSourceGHCLI, clients.TokenSourceGHHosts)
 
 		fmt.Printf("Database URL: %s\n", os.Getenv("GHI_DB_URL"))
 
 		dbToken := os.Getenv("GHI_AUTH_TOKEN")
-		if dbToken != "" {
+		if command := viper.GetString("db_token_command"); command != "" {
+			fmt.Printf("Database Token: printed by db_token_command (%s)\n", auth.CommandName(command))
+		} else if dbToken != "" {
 			fmt.Printf("Database Token: %s...%s\n", dbToken[:4], dbToken[len(dbToken)-4:])
 		} else {
 			fmt.Println("Database Token: not set")
 		}
+
+		dbMode := os.Getenv("GHI_DB_MODE")
+		if dbMode == "" {
+			dbMode = db.ModePersonal
+		}
+		fmt.Printf("Database Mode: %s\n", dbMode)
+
+		// The webhook URL lets anyone post to the channel, so it's masked like a token
+		webhook := os.Getenv("GHI_SLACK_WEBHOOK")
+		if webhook != "" {
+			fmt.Printf("Slack Webhook: %s...%s\n", webhook[:min(len(webhook), 24)], webhook[max(len(webhook)-4, 0):])
+		} else {
+			fmt.Println("Slack Webhook: not set")
+		}
+	},
+}
+
+// readTokenStdin reads a token from stdin: asked for without echoing it in a
+// terminal, or the first line of a pipe such as 'op read ... | ghi auth set --stdin'
+func readTokenStdin() (string, error) {
+	fd := os.Stdin.Fd()
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, "GitHub token: ")
+		input, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the token: %w", err)
+		}
+		return validateToken(string(input))
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read the token from stdin: %w", err)
+	}
+	return validateToken(line)
+}
+
+// validateToken trims a token that was read and checks it's a single word
+func validateToken(token string) (string, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("no token was given on stdin")
+	}
+	if strings.ContainsAny(token, " \t") {
+		return "", fmt.Errorf("the token on stdin contains spaces, it should only be the token")
+	}
+	return token, nil
+}
+
+var authHardenCmd = &cobra.Command{
+	Use:   "harden",
+	Short: "Make the settings files readable only by you",
+	Long: `The harden command restricts the permissions of the config directory, the
+env files holding your tokens and the config file, so other users on the machine can't read them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		failed := false
+		for _, p := range sensitivePaths() {
+			mode, insecure := insecurePermissions(p.path)
+			if !insecure {
+				logger.Debug("%s is already private", p.path)
+				continue
+			}
+
+			if err := os.Chmod(p.path, p.mode); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to change permissions of %s: %v\n", p.path, err)
+				failed = true
+				continue
+			}
+			fmt.Printf("Changed %s from %04o to %04o\n", p.path, mode, p.mode)
+		}
+
+		if failed {
+			os.Exit(1)
+		}
+		fmt.Println("Settings files are only accessible by you")
+	},
+}
+
+var authSSOCheckCmd = &cobra.Command{
+	Use:   "sso-check",
+	Short: "Check that your token is authorized for an organization's SAML SSO",
+	Long: `The sso-check command verifies that your GitHub token has been authorized
+for an organization that enforces SAML single sign-on. If it hasn't, the
+URL to authorize the token is shown.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		org, _ := cmd.Flags().GetString("org")
+		if org == "" {
+			log.Fatal("The --org flag is required")
+		}
+
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		// Listing the organization's repositories requires SSO authorization
+		ctx := cmd.Context()
+		logger.Debug("Checking SSO authorization for organization %s", org)
+		_, _, err = client.Repositories.ListByOrg(ctx, org, &github.RepositoryListByOrgOptions{
+			ListOptions: github.ListOptions{PerPage: 1},
+		})
+
+		var ssoErr *clients.SSORequiredError
+		if errors.As(err, &ssoErr) {
+			fmt.Printf("GitHub token is NOT authorized for SAML SSO in %s\n", org)
+			if ssoErr.AuthorizationURL != "" {
+				fmt.Printf("Authorize it at: %s\n", ssoErr.AuthorizationURL)
+			}
+			os.Exit(1)
+		}
+		if err != nil {
+			log.Fatalf("Error checking organization %s: %v", org, err)
+		}
+
+		fmt.Printf("GitHub token is authorized for %s\n", org)
 	},
 }
 
@@ -111,10 +324,21 @@ func init() {
 	rootCmd.AddCommand(authCmd)
 	authCmd.AddCommand(authSetCmd)
 	authCmd.AddCommand(authShowCmd)
+	authCmd.AddCommand(authEncryptCmd)
+	authCmd.AddCommand(authDecryptCmd)
+	authCmd.AddCommand(authHardenCmd)
+	authCmd.AddCommand(authSSOCheckCmd)
 
 	// Add flags for auth set command
 	authSetCmd.Flags().StringP("username", "u", "", "Your GitHub username")
 	authSetCmd.Flags().StringP("token", "t", "", "Your GitHub personal access token")
 	authSetCmd.Flags().String("db-url", "", "Database URL")
 	authSetCmd.Flags().String("db-token", "", "Database authentication token")
+	authSetCmd.Flags().String("db-mode", "", "Database mode (personal, shared)")
+	authSetCmd.Flags().String("slack-webhook", "", "Slack incoming webhook URL for reports and alerts")
+	authSetCmd.Flags().Bool("stdin", false, "Read the GitHub token from stdin, keeping it out of the shell history")
+	authSetCmd.Flags().String("host", "", "GitHub Enterprise Server host, such as github.example.com (default github.com)")
+
+	// Add flags for auth sso-check command
+	authSSOCheckCmd.Flags().StringP("org", "o", "", "The GitHub organization to check")
 }
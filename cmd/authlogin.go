@@ -0,0 +1,121 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/jbrinkman/ghi/pkg/auth"
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Sign in to GitHub in your browser instead of pasting a token",
+	Long: `The login command signs in with GitHub's device flow. It prints a one-time
+code and opens the verification page in your browser, where you enter the
+code and authorize ghi. The token GitHub issues is stored in the env file of
+the active profile, along with your username and the host.
+
+The device flow needs an OAuth App with device flow enabled, registered on
+github.com or your Enterprise server. Set its client ID with --client-id or
+oauth_client_id in the config file.`,
+	Example: `  ghi auth login
+  ghi auth login --scopes repo,read:org,workflow
+  ghi auth login --profile work --host github.example.com --client-id Iv1.0123456789abcdef`,
+	Run: func(cmd *cobra.Command, args []string) {
+		scopes, _ := cmd.Flags().GetStringSlice("scopes")
+		host, _ := cmd.Flags().GetString("host")
+		noBrowser, _ := cmd.Flags().GetBool("no-browser")
+
+		viper.BindPFlag("oauth_client_id", cmd.Flags().Lookup("client-id"))
+		clientID := viper.GetString("oauth_client_id")
+
+		// The host of the profile is used unless another is given
+		host = strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(host, "https://"), "/"))
+		if host == "" {
+			host = clients.Host()
+		}
+		if strings.ContainsAny(host, "/ ") {
+			log.Fatal("The host must be a host name, such as github.example.com")
+		}
+		if clientID == "" {
+			log.Fatalf("No OAuth App client ID is set. Register an OAuth App with device flow enabled at https://%s/settings/applications/new, "+
+				"then pass its client ID with --client-id or set oauth_client_id in the config file", host)
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Device login to %s for profile %s with scopes %v", host, profileName(), scopes)
+
+		ctx := cmd.Context()
+		openPage := !noBrowser && term.IsTerminal(os.Stdout.Fd())
+		token, err := auth.DeviceLogin(ctx, host, clientID, scopes, func(code auth.DeviceCode) {
+			fmt.Printf("First copy your one-time code: %s\n", code.UserCode)
+			fmt.Printf("Then enter it at %s\n", code.VerificationURI)
+			if openPage {
+				if err := ui.OpenURL(code.VerificationURI); err != nil {
+					logger.Debug("Failed to open browser: %v", err)
+				}
+			}
+			fmt.Println("Waiting for you to authorize ghi...")
+		})
+		if err != nil {
+			log.Fatalf("Login failed: %v", err)
+		}
+		logger.AddSecret(token)
+
+		// Check the token itself and find the login it belongs to, even when a
+		// token command or GitHub App takes precedence over it
+		os.Setenv("GHI_HOST", host)
+		client, err := clients.NewGitHubClientWithToken(token)
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+		login, err := gh.AuthenticatedLogin(ctx, client)
+		if err != nil {
+			log.Fatalf("The token GitHub issued doesn't work: %v", err)
+		}
+
+		err = updateEnvFile(func(env map[string]string) {
+			env["GHI_GITHUB_TOKEN"] = token
+			env["GHI_USERNAME"] = login
+			if host == clients.DefaultHost {
+				delete(env, "GHI_HOST")
+			} else {
+				env["GHI_HOST"] = host
+			}
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("✅ Logged in to %s as %s\n", host, login)
+		switch clients.TokenOverride() {
+		case clients.TokenSourceCommand:
+			fmt.Fprintf(os.Stderr, "Warning: token_command is set in the config file, so %s is used instead of the token from this login.\n",
+				auth.CommandName(viper.GetString("token_command")))
+		case clients.TokenSourceApp:
+			fmt.Fprintln(os.Stderr, "Warning: GHI_APP_ID is set, so ghi authenticates as the GitHub App instead of with the token from this login.")
+		}
+	},
+}
+
+func init() {
+	authCmd.AddCommand(authLoginCmd)
+
+	// Define flags
+	authLoginCmd.Flags().StringSlice("scopes", []string{"repo", "read:org"}, "OAuth scopes to request")
+	authLoginCmd.Flags().String("host", "", "GitHub Enterprise Server host to sign in to (default the profile's host or github.com)")
+	authLoginCmd.Flags().String("client-id", "", "Client ID of the OAuth App to sign in with (default from the config file)")
+	authLoginCmd.Flags().Bool("no-browser", false, "Only print the verification URL, don't open it")
+}
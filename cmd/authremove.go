@@ -0,0 +1,110 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// removableSettings maps the flags of auth remove to the env file settings they remove
+var removableSettings = []struct {
+	flag string
+	keys []string
+}{
+	{"token", []string{"GHI_GITHUB_TOKEN"}},
+	{"username", []string{"GHI_USERNAME"}},
+	{"host", []string{"GHI_HOST"}},
+	{"db-url", []string{"GHI_DB_URL"}},
+	{"db-token", []string{"GHI_AUTH_TOKEN"}},
+	{"db-mode", []string{"GHI_DB_MODE"}},
+	{"slack-webhook", []string{"GHI_SLACK_WEBHOOK"}},
+	{"app", []string{"GHI_APP_ID", "GHI_APP_INSTALLATION_ID", "GHI_APP_PRIVATE_KEY", "GHI_APP_PRIVATE_KEY_FILE"}},
+}
+
+var authRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove stored credentials",
+	Long: `The remove command deletes settings from the env file of the active profile.
+Choose the settings to remove with flags, or remove the whole profile with
+--all, which deletes its env file after asking for confirmation.
+
+Tokens printed by token_command and tokens of the gh CLI aren't stored by ghi,
+so they're left as they are.`,
+	Example: `  ghi auth remove --token
+  ghi auth remove --db-url --db-token
+  ghi auth remove --profile work --all`,
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		var keys []string
+		for _, setting := range removableSettings {
+			if remove, _ := cmd.Flags().GetBool(setting.flag); remove {
+				keys = append(keys, setting.keys...)
+			}
+		}
+		if all == (len(keys) > 0) {
+			log.Fatal("Choose the settings to remove, such as --token, or remove the whole profile with --all")
+		}
+
+		envFile := envFilePath()
+		if !fileExists(envFile) {
+			fmt.Printf("Profile %s has no stored settings\n", profileName())
+			return
+		}
+
+		if all {
+			if !yes && !confirm(cmd.Context(), fmt.Sprintf("Remove every setting of profile %s in %s?", profileName(), envFile)) {
+				fmt.Println("Nothing was removed")
+				return
+			}
+			logger.Debug("Removing %s", envFile)
+			if err := os.Remove(envFile); err != nil {
+				log.Fatalf("Error removing env file: %v", err)
+			}
+			fmt.Printf("✅ Removed profile %s\n", profileName())
+			return
+		}
+
+		var removed []string
+		err := updateEnvFile(func(env map[string]string) {
+			for _, key := range keys {
+				if _, ok := env[key]; ok {
+					delete(env, key)
+					removed = append(removed, key)
+				}
+			}
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(removed) == 0 {
+			fmt.Printf("Profile %s doesn't have those settings\n", profileName())
+			return
+		}
+		fmt.Printf("✅ Removed %s from profile %s\n", strings.Join(removed, ", "), profileName())
+	},
+}
+
+func init() {
+	authCmd.AddCommand(authRemoveCmd)
+
+	// Define flags
+	authRemoveCmd.Flags().Bool("token", false, "Remove the GitHub token")
+	authRemoveCmd.Flags().Bool("username", false, "Remove the username")
+	authRemoveCmd.Flags().Bool("host", false, "Remove the GitHub Enterprise Server host")
+	authRemoveCmd.Flags().Bool("db-url", false, "Remove the database URL")
+	authRemoveCmd.Flags().Bool("db-token", false, "Remove the database token")
+	authRemoveCmd.Flags().Bool("db-mode", false, "Remove the database mode")
+	authRemoveCmd.Flags().Bool("slack-webhook", false, "Remove the Slack webhook")
+	authRemoveCmd.Flags().Bool("app", false, "Remove the GitHub App settings")
+	authRemoveCmd.Flags().Bool("all", false, "Remove every setting of the profile")
+	authRemoveCmd.Flags().BoolP("yes", "y", false, "Remove the profile without asking for confirmation")
+}
@@ -0,0 +1,228 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/auth"
+	"github.com/jbrinkman/ghi/pkg/clients"
+	"github.com/jbrinkman/ghi/pkg/db"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// tokenExpiryWarning is how soon before a token expires the test warns about it
+const tokenExpiryWarning = 7 * 24 * time.Hour
+
+var authTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Check that your GitHub token and review database work",
+	Long: `The test command calls the GitHub API with your token and reports the login
+it belongs to, its scopes and expiration, and the remaining rate limit. When a
+review database is configured, it connects to it too.
+
+Each failed check is shown with how to fix it, and the command exits with
+status 1 when any check fails.`,
+	Example: `  ghi auth test`,
+	Run: func(cmd *cobra.Command, args []string) {
+		failed := false
+		check := func(passed bool, name, detail string, hints ...string) {
+			if passed {
+				fmt.Printf("✅ %s: %s\n", name, detail)
+			} else {
+				fmt.Printf("✗ %s: %s\n", name, detail)
+				failed = true
+			}
+			for _, hint := range hints {
+				fmt.Printf("   %s\n", hint)
+			}
+		}
+
+		if source, ok := testGitHubAuth(check); ok {
+			client, err := clients.NewGitHubClient()
+			switch {
+			case err != nil:
+				check(false, "GitHub client", logger.Redact(err.Error()))
+			case source == clients.TokenSourceApp:
+				testGitHubApp(cmd.Context(), client, check)
+			default:
+				testGitHubToken(cmd.Context(), client, check)
+			}
+		}
+		testDatabaseAuth(check)
+
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// authCheckFunc prints the outcome of a check, with any hints below it
+type authCheckFunc func(passed bool, name, detail string, hints ...string)
+
+// testGitHubAuth checks that a GitHub token is set, running the token command
+// if there is one, and returns where the token came from
+func testGitHubAuth(check authCheckFunc) (string, bool) {
+	command := viper.GetString("token_command")
+	token, source, err := clients.GitHubTokenSource()
+	switch {
+	case err != nil:
+		hint := "Check token_command in the config file. The command must print only the token."
+		if source == clients.TokenSourceApp {
+			hint = "Check GHI_APP_ID, GHI_APP_INSTALLATION_ID and the private key of the app."
+		}
+		check(false, "GitHub token", logger.Redact(err.Error()), hint)
+		return source, false
+	case token == "":
+		check(false, "GitHub token", "not set, requests are limited to 60 per hour",
+			"Sign in with 'ghi auth login' or 'gh auth login', or create a token at https://github.com/settings/tokens and run 'ghi auth set --token <token>'")
+		return source, false
+	case source == clients.TokenSourceCommand:
+		check(true, "GitHub token", fmt.Sprintf("printed by token_command (%s)", auth.CommandName(command)))
+	case source == clients.TokenSourceApp:
+		check(true, "GitHub token", fmt.Sprintf("installation %s of GitHub App %s, renewed before it expires",
+			os.Getenv("GHI_APP_INSTALLATION_ID"), os.Getenv("GHI_APP_ID")))
+	default:
+		check(true, "GitHub token", "from "+source)
+	}
+	return source, true
+}
+
+// testGitHubApp checks the installation token of a GitHub App, which can't
+// read a user, by listing the repositories the installation can access
+func testGitHubApp(ctx context.Context, client *github.Client, check authCheckFunc) {
+	repos, _, err := client.Apps.ListRepos(ctx, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		check(false, "Installation", logger.Redact(err.Error()), gitHubAuthHint(err))
+		return
+	}
+	check(true, "Installation", fmt.Sprintf("can access %d repositories", repos.GetTotalCount()))
+
+	core, _, err := gh.RateLimits(ctx, client)
+	if err != nil {
+		check(false, "Rate limit", logger.Redact(err.Error()), gitHubAuthHint(err))
+		return
+	}
+	check(core.Remaining > 0, "Rate limit", fmt.Sprintf("%d of %d remaining, resets at %s",
+		core.Remaining, core.Limit, core.Reset.Local().Format("15:04")))
+}
+
+// testGitHubToken checks the token against the GitHub API and reports its
+// login, scopes, expiration and rate limits
+func testGitHubToken(ctx context.Context, client *github.Client, check authCheckFunc) {
+	details, err := gh.InspectToken(ctx, client)
+	if err != nil {
+		check(false, "GitHub login", logger.Redact(err.Error()), gitHubAuthHint(err))
+		return
+	}
+	check(true, "GitHub login", details.Login)
+
+	switch {
+	case !details.ScopesKnown:
+		check(true, "Token scopes", "not reported, permissions of fine-grained and GitHub App tokens are set when they're created")
+	default:
+		scopes := "none"
+		if len(details.Scopes) > 0 {
+			scopes = strings.Join(details.Scopes, ", ")
+		}
+		var hints []string
+		if !details.HasScope("repo") {
+			hints = append(hints, "Without the repo scope, private repositories can't be read.")
+		}
+		if !details.HasScope("read:org") {
+			hints = append(hints, "Without the read:org scope, review requests for your teams aren't found.")
+		}
+		check(true, "Token scopes", scopes, hints...)
+	}
+
+	switch {
+	case details.Expires.IsZero():
+		check(true, "Token expiration", "none reported")
+	case time.Until(details.Expires) < tokenExpiryWarning:
+		check(true, "Token expiration", details.Expires.Local().Format("2006-01-02 15:04"),
+			"The token expires soon. Create a new one at https://github.com/settings/tokens and run 'ghi auth set --token <token>'")
+	default:
+		check(true, "Token expiration", details.Expires.Local().Format("2006-01-02 15:04"))
+	}
+
+	core, search, err := gh.RateLimits(ctx, client)
+	if err != nil {
+		check(false, "Rate limit", logger.Redact(err.Error()), gitHubAuthHint(err))
+		return
+	}
+	detail := fmt.Sprintf("%d of %d remaining, search %d of %d, resets at %s",
+		core.Remaining, core.Limit, search.Remaining, search.Limit, core.Reset.Local().Format("15:04"))
+	if core.Remaining == 0 {
+		check(false, "Rate limit", detail, "Requests fail until the quota resets.")
+		return
+	}
+	check(true, "Rate limit", detail)
+}
+
+// gitHubAuthHint returns how to fix a failed GitHub request
+func gitHubAuthHint(err error) string {
+	var ssoErr *clients.SSORequiredError
+	var rateErr *github.RateLimitError
+	var respErr *github.ErrorResponse
+	switch {
+	case errors.As(err, &ssoErr):
+		if ssoErr.AuthorizationURL != "" {
+			return "Authorize the token for the organization at " + ssoErr.AuthorizationURL
+		}
+		return "Authorize the token for the organization's SAML SSO at https://github.com/settings/tokens"
+	case errors.As(err, &rateErr):
+		return fmt.Sprintf("The rate limit is used up, try again after %s.", rateErr.Rate.Reset.Local().Format("15:04"))
+	case errors.As(err, &respErr) && respErr.Response.StatusCode == http.StatusUnauthorized:
+		return "GitHub rejected the token, it may have expired or been revoked. Create a new one at https://github.com/settings/tokens and run 'ghi auth set --token <token>'"
+	case errors.As(err, &respErr) && respErr.Response.StatusCode == http.StatusForbidden:
+		return "The token isn't allowed to read your user. Give a fine-grained token read access to your account, or check the organization's token policy."
+	}
+	return "Check your network connection and proxy settings."
+}
+
+// testDatabaseAuth connects to the review database, when one is configured
+func testDatabaseAuth(check authCheckFunc) {
+	dbURL := os.Getenv("GHI_DB_URL")
+	if dbURL == "" {
+		check(true, "Review database", "not configured",
+			"Reviews are only logged with a database, set one with 'ghi auth set --db-url <url> --db-token <token>'")
+		return
+	}
+
+	start := time.Now()
+	dbClient, err := db.NewClient()
+	if err != nil {
+		hint := "Check the database URL and token with 'ghi auth show'. A new token can be created with 'turso db tokens create <database>'."
+		switch {
+		case strings.Contains(err.Error(), "GHI_DB_MODE"):
+			hint = "Set the mode with 'ghi auth set --db-mode personal' or 'ghi auth set --db-mode shared'."
+		case viper.GetString("db_token_command") != "" && strings.Contains(err.Error(), "token command"):
+			hint = "Check db_token_command in the config file. The command must print only the token."
+		}
+		check(false, "Review database", logger.Redact(err.Error()), hint)
+		return
+	}
+	defer dbClient.Close()
+
+	mode := db.ModePersonal
+	if dbClient.Shared() {
+		mode = db.ModeShared
+	}
+	check(true, "Review database", fmt.Sprintf("connected to %s in %s (%s mode)",
+		logger.Redact(dbURL), time.Since(start).Round(time.Millisecond), mode))
+}
+
+func init() {
+	authCmd.AddCommand(authTestCmd)
+}
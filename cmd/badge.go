@@ -0,0 +1,228 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/clients"
+	"github.com/jbrinkman/ghi/pkg/db"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// badgeCmd represents the badge command
+var badgeCmd = &cobra.Command{
+	Use:   "badge",
+	Short: "Generate SVG status badges from pull request and review data",
+	Long: `The 'badge' command groups subcommands that generate shields-style SVG badges
+for embedding in dashboards and READMEs. Each badge is written to the file given
+with --out, or to standard output.
+
+The color shows how healthy the value is. Use --color to choose it instead, as
+a name such as green, yellow or red, or a hex color such as #1f6feb.`,
+}
+
+// badgeOpenPRsCmd represents the badge open-prs command
+var badgeOpenPRsCmd = &cobra.Command{
+	Use:   "open-prs",
+	Short: "Generate a badge with the number of open pull requests",
+	Long: `The 'badge open-prs' command generates a badge with the number of pull
+requests open in a repository, drafts included. It's green below --warn, yellow
+below --critical and red from there.`,
+	Example: `  ghi badge open-prs -r octocat/Hello-World --out badge.svg`,
+	Run: func(cmd *cobra.Command, args []string) {
+		warn, _ := cmd.Flags().GetInt("warn")
+		critical, _ := cmd.Flags().GetInt("critical")
+
+		repo, issues := fetchBadgeOpenPRs(cmd, args)
+		count := len(issues)
+		logger.Debug("%s has %d open pull requests", repo, count)
+
+		color := badgeColor(float64(count), float64(warn), float64(critical))
+		writeBadge(cmd, "open PRs", fmt.Sprintf("%d", count), color)
+	},
+}
+
+// badgeAvgAgeCmd represents the badge avg-age command
+var badgeAvgAgeCmd = &cobra.Command{
+	Use:   "avg-age",
+	Short: "Generate a badge with the average age of open pull requests",
+	Long: `The 'badge avg-age' command generates a badge with the average age in days of
+the pull requests open in a repository, drafts included. It's green below
+--warn, yellow below --critical and red from there.`,
+	Example: `  ghi badge avg-age -r octocat/Hello-World --out age.svg --warn 3d --critical 1w`,
+	Run: func(cmd *cobra.Command, args []string) {
+		warnFlag, _ := cmd.Flags().GetString("warn")
+		criticalFlag, _ := cmd.Flags().GetString("critical")
+
+		warn, err := parseAge(warnFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		critical, err := parseAge(criticalFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		repo, issues := fetchBadgeOpenPRs(cmd, args)
+		message, color := "none open", "brightgreen"
+		if len(issues) > 0 {
+			var total time.Duration
+			now := time.Now()
+			for _, issue := range issues {
+				total += now.Sub(issue.GetCreatedAt().Time)
+			}
+			average := total / time.Duration(len(issues))
+			logger.Debug("%s has %d open pull requests with an average age of %s", repo, len(issues), average)
+
+			message = formatDays(average)
+			color = badgeColor(average.Hours(), warn.Hours(), critical.Hours())
+		}
+		writeBadge(cmd, "avg PR age", message, color)
+	},
+}
+
+// badgeReviewCountCmd represents the badge review-count command
+var badgeReviewCountCmd = &cobra.Command{
+	Use:   "review-count",
+	Short: "Generate a badge with the number of reviews logged recently",
+	Long: `The 'badge review-count' command generates a badge with the number of reviews
+logged in the database within --since, in a repository or in all of them when
+--repo isn't given. With a shared database, everyone's reviews are counted.`,
+	Example: `  ghi badge review-count -r octocat/Hello-World --since 7d --out reviews.svg`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, _ := cmd.Flags().GetString("repo")
+		sinceFlag, _ := cmd.Flags().GetString("since")
+
+		if repo != "" {
+			repo = expandRepoAlias(repo)
+		}
+		age, err := parseAge(sinceFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		endDate := time.Now()
+		startDate := endDate.Add(-age)
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Counting reviews in %q since %s", repo, startDate.Format(time.DateOnly))
+
+		// Connect to database
+		dbClient, err := db.NewClient()
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer dbClient.Close()
+
+		// Initialize schema if needed
+		ctx := cmd.Context()
+		if err := dbClient.InitSchema(ctx); err != nil {
+			log.Fatalf("Failed to initialize database schema: %v", err)
+		}
+
+		reviews, err := dbClient.GetReviewsByDateRange(ctx, repo, startDate, endDate)
+		if err != nil {
+			log.Fatalf("Failed to fetch reviews: %v", err)
+		}
+		logger.Debug("Found %d reviews", len(reviews))
+
+		writeBadge(cmd, "reviews "+sinceFlag, fmt.Sprintf("%d", len(reviews)), "blue")
+	},
+}
+
+// fetchBadgeOpenPRs lists the open pull requests of the repository a badge is for
+func fetchBadgeOpenPRs(cmd *cobra.Command, args []string) (string, []*github.Issue) {
+	repoFlag, _ := cmd.Flags().GetString("repo")
+
+	repo, err := resolveRepo(repoFlag)
+	if err != nil {
+		log.Fatalf("The --repo flag is required when not in a GitHub repository clone: %v", err)
+	}
+	parts := strings.Split(repo, "/")
+	if len(parts) != 2 {
+		log.Fatal("Invalid repository format. Use 'owner/repo'")
+	}
+
+	logger.Debug("Command arguments: %v", args)
+	logger.Debug("Listing open pull requests in %s for a badge", repo)
+
+	ctx := cmd.Context()
+	client, err := clients.NewGitHubClient()
+	if err != nil {
+		log.Fatalf("Failed to create GitHub client: %v", err)
+	}
+
+	issues, err := ui.WithSpinner(ctx, "Fetching open pull requests", func() ([]*github.Issue, error) {
+		return gh.ListPullRequestIssues(ctx, client, parts[0], parts[1], "open", nil)
+	})
+	if err != nil {
+		log.Fatalf("Failed to list pull requests in %s: %v", repo, err)
+	}
+	return repo, issues
+}
+
+// badgeColor picks the color of a value that gets worse as it grows
+func badgeColor(value, warn, critical float64) string {
+	switch {
+	case value >= critical:
+		return "red"
+	case value >= warn:
+		return "yellow"
+	default:
+		return "brightgreen"
+	}
+}
+
+// writeBadge draws a badge, with the --label and --color flags overriding
+// the defaults, and writes it to the --out file or standard output
+func writeBadge(cmd *cobra.Command, label, message, color string) {
+	if cmd.Flags().Changed("label") {
+		label, _ = cmd.Flags().GetString("label")
+	}
+	if cmd.Flags().Changed("color") {
+		color, _ = cmd.Flags().GetString("color")
+		if _, ok := ui.BadgeColors[color]; !ok && !strings.HasPrefix(color, "#") {
+			log.Fatalf("Invalid color %q. Use a hex color or one of brightgreen, green, yellowgreen, yellow, orange, red, blue or lightgrey", color)
+		}
+	}
+
+	svg := ui.Badge(label, message, color)
+	out, _ := cmd.Flags().GetString("out")
+	if out == "" {
+		fmt.Println(svg)
+		return
+	}
+	if err := os.WriteFile(out, []byte(svg+"\n"), 0644); err != nil {
+		log.Fatalf("Failed to write badge: %v", err)
+	}
+	fmt.Printf("✅ Wrote %s: %s badge to %s\n", label, message, out)
+}
+
+func init() {
+	rootCmd.AddCommand(badgeCmd)
+	badgeCmd.AddCommand(badgeOpenPRsCmd)
+	badgeCmd.AddCommand(badgeAvgAgeCmd)
+	badgeCmd.AddCommand(badgeReviewCountCmd)
+
+	// Define flags
+	badgeCmd.PersistentFlags().StringP("out", "o", "", "File to write the badge to (default stdout)")
+	badgeCmd.PersistentFlags().String("label", "", "Text for the left side of the badge (default depends on the badge)")
+	badgeCmd.PersistentFlags().String("color", "", "Color of the badge: a name such as green or red, or a hex color (default depends on the value)")
+	badgeOpenPRsCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+	badgeOpenPRsCmd.Flags().Int("warn", 10, "Number of open pull requests from which the badge is yellow")
+	badgeOpenPRsCmd.Flags().Int("critical", 25, "Number of open pull requests from which the badge is red")
+	badgeAvgAgeCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+	badgeAvgAgeCmd.Flags().String("warn", "7d", "Average age from which the badge is yellow, such as 7d or 2w")
+	badgeAvgAgeCmd.Flags().String("critical", "14d", "Average age from which the badge is red, such as 14d or 4w")
+	badgeReviewCountCmd.Flags().StringP("repo", "r", "", "Count only the reviews of a repository (owner/repo)")
+	badgeReviewCountCmd.Flags().String("since", "30d", "How far back to count reviews, such as 30d or 4w")
+}
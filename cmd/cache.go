@@ -0,0 +1,46 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/jbrinkman/ghi/pkg/cache"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the GitHub response cache",
+	Long: `The cache command manages the response cache kept in ghi's local storage.
+Cached responses are revalidated with ETags, so unchanged data is served from
+disk without using any of your GitHub rate limit.`,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached GitHub responses",
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := cache.Open()
+		if err != nil {
+			log.Fatalf("Error opening cache: %v", err)
+		}
+
+		logger.Debug("Clearing cache")
+		removed, err := store.Clear()
+		if err != nil {
+			log.Fatalf("Error clearing cache: %v", err)
+		}
+
+		fmt.Printf("Removed %d cached responses\n", removed)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
@@ -0,0 +1,303 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	"github.com/jbrinkman/ghi/pkg/db"
+	"github.com/jbrinkman/ghi/pkg/export"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// Files of a compliance evidence bundle
+const (
+	complianceManifestFile  = "manifest.json"
+	complianceChecksumsFile = "SHA256SUMS"
+)
+
+// quarterPattern matches quarters such as 2024Q2 or 2024-q2
+var quarterPattern = regexp.MustCompile(`^(\d{4})-?[Qq]([1-4])$`)
+
+// complianceManifest describes an evidence bundle: what it covers, who made
+// it and the checksum of the evidence file
+type complianceManifest struct {
+	GeneratedAt  time.Time `json:"generated_at"`
+	GeneratedBy  string    `json:"generated_by"`
+	Version      string    `json:"ghi_version"`
+	Repos        []string  `json:"repos"`
+	Start        string    `json:"start"`
+	End          string    `json:"end"`
+	PullRequests int       `json:"pull_requests"`
+	Unreviewed   int       `json:"unreviewed"`
+	Unapproved   int       `json:"unapproved"`
+	// LocalLog is set when the reviews logged in the database are included
+	LocalLog bool `json:"local_log"`
+	// Incomplete lists the repositories whose merged pull requests passed the search limit
+	Incomplete     []string `json:"incomplete,omitempty"`
+	Evidence       string   `json:"evidence"`
+	EvidenceSHA256 string   `json:"evidence_sha256"`
+}
+
+// complianceCmd represents the compliance command
+var complianceCmd = &cobra.Command{
+	Use:   "compliance",
+	Short: "Produce review evidence for audits",
+}
+
+// complianceExportCmd represents the compliance export command
+var complianceExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export an evidence bundle of who reviewed the merged pull requests",
+	Long: `The 'compliance export' command writes an evidence bundle for audits: every pull
+request merged in a period, who reviewed it on GitHub and when, and the reviews
+logged for it in your database. Pull requests merged without a review, or
+without an approval from someone other than the author, are counted in the
+manifest so they can be accounted for.
+
+The bundle is a directory with:
+
+  evidence.csv or evidence.json  the pull requests and their reviews
+  manifest.json                  the period, repositories, counts, who made
+                                 the bundle and the evidence checksum
+  SHA256SUMS                     SHA-256 checksums of both files
+
+Check that the bundle hasn't changed with 'sha256sum -c SHA256SUMS' in its
+directory.
+
+The period is a --quarter, such as 2024Q2, or --start-date and --end-date. The
+local review log is left out with --no-log or when no database is configured.`,
+	Example: `  ghi compliance export --repo octocat/Hello-World --quarter 2024Q2
+  ghi compliance export --group backend --start-date 2024-01-01 --end-date 2024-06-30 --format json -o evidence-h1`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoFlags, _ := cmd.Flags().GetStringSlice("repo")
+		group, _ := cmd.Flags().GetString("group")
+		quarter, _ := cmd.Flags().GetString("quarter")
+		startFlag, _ := cmd.Flags().GetString("start-date")
+		endFlag, _ := cmd.Flags().GetString("end-date")
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		noLog, _ := cmd.Flags().GetBool("no-log")
+
+		if err := export.Validate(format); err != nil {
+			log.Fatal(err)
+		}
+		if concurrency < 1 {
+			log.Fatal("The --concurrency flag must be at least 1")
+		}
+		if quarter != "" && (startFlag != "" || endFlag != "") {
+			log.Fatal("Give either --quarter or --start-date and --end-date, not both")
+		}
+
+		var start, end time.Time
+		var err error
+		if quarter != "" {
+			start, end, err = parseQuarter(quarter)
+		} else {
+			start, end, err = parseDateRange(startFlag, endFlag)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		repos, err := resolvePRRepos(repoFlags, group)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if output == "" {
+			output = "compliance-" + start.Format(time.DateOnly) + "_" + end.Format(time.DateOnly)
+			if quarter != "" {
+				output = "compliance-" + strings.ToUpper(strings.ReplaceAll(quarter, "-", ""))
+			}
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Exporting compliance evidence for %v from %s to %s to %s", repos, start.Format(time.DateOnly), end.Format(time.DateOnly), output)
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		var records []gh.ComplianceRecord
+		var incomplete []string
+		_, err = ui.WithSpinner(ctx, "Fetching merged pull requests and their reviews", func() (struct{}, error) {
+			for _, repo := range repos {
+				found, complete, err := gh.CollectCompliance(ctx, client, repo, start, end, concurrency)
+				if err != nil {
+					return struct{}{}, err
+				}
+				if !complete {
+					incomplete = append(incomplete, repo)
+				}
+				records = append(records, found...)
+			}
+			return struct{}{}, nil
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, repo := range incomplete {
+			fmt.Fprintf(os.Stderr, "Warning: %s has more merged pull requests than GitHub search returns. Export shorter periods to cover them all.\n", repo)
+		}
+
+		localLog := false
+		if !noLog {
+			if err := addLoggedReviews(ctx, records); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: The local review log isn't included: %v\n", err)
+			} else {
+				localLog = true
+			}
+		}
+
+		manifest := complianceManifest{
+			GeneratedAt:  time.Now().UTC(),
+			GeneratedBy:  os.Getenv("GHI_USERNAME"),
+			Version:      version,
+			Repos:        repos,
+			Start:        start.Format(time.DateOnly),
+			End:          end.Format(time.DateOnly),
+			PullRequests: len(records),
+			LocalLog:     localLog,
+			Incomplete:   incomplete,
+			Evidence:     "evidence." + export.Extension(format),
+		}
+		for _, record := range records {
+			if len(record.Reviews) == 0 {
+				manifest.Unreviewed++
+			}
+			if !record.Approved {
+				manifest.Unapproved++
+			}
+		}
+
+		if err := writeComplianceBundle(output, format, records, manifest); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("✅ Exported review evidence for %d merged pull requests to %s\n", len(records), output)
+		if manifest.Unreviewed > 0 || manifest.Unapproved > 0 {
+			fmt.Printf("%d were merged without a review and %d without an approval from someone other than the author\n",
+				manifest.Unreviewed, manifest.Unapproved)
+		}
+	},
+}
+
+// parseQuarter parses a quarter such as 2024Q2 into its first and last days
+func parseQuarter(quarter string) (time.Time, time.Time, error) {
+	match := quarterPattern.FindStringSubmatch(strings.TrimSpace(quarter))
+	if match == nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid quarter %q, use YYYYQN such as 2024Q2", quarter)
+	}
+	year, _ := strconv.Atoi(match[1])
+	n, _ := strconv.Atoi(match[2])
+	start := time.Date(year, time.Month(3*(n-1)+1), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 3, -1), nil
+}
+
+// addLoggedReviews adds the reviews logged in the database to the records
+func addLoggedReviews(ctx context.Context, records []gh.ComplianceRecord) error {
+	dbClient, err := db.NewClient()
+	if err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	if err := dbClient.InitSchema(ctx); err != nil {
+		return fmt.Errorf("failed to initialize database schema: %w", err)
+	}
+	for i := range records {
+		reviews, err := dbClient.GetReviews(ctx, records[i].Repo, records[i].Number)
+		if err != nil {
+			return err
+		}
+		for _, review := range reviews {
+			records[i].AddReviews(gh.ComplianceReview{
+				Reviewer:   review.Reviewer,
+				Source:     gh.ReviewSourceLog,
+				State:      review.Verdict,
+				ReviewedAt: review.Timestamp,
+				URL:        review.GitHubReviewURL,
+			})
+		}
+	}
+	return nil
+}
+
+// writeComplianceBundle writes the evidence, the manifest and the checksums
+// of both to the output directory
+func writeComplianceBundle(dir, format string, records []gh.ComplianceRecord, manifest complianceManifest) error {
+	var evidence bytes.Buffer
+	if err := gh.WriteCompliance(&evidence, format, records); err != nil {
+		return fmt.Errorf("failed to write the evidence: %w", err)
+	}
+	manifest.EvidenceSHA256 = sha256Hex(evidence.Bytes())
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write the manifest: %w", err)
+	}
+	data = append(data, '\n')
+
+	// The checksums are in the format sha256sum -c reads
+	checksums := fmt.Sprintf("%s  %s\n%s  %s\n",
+		manifest.EvidenceSHA256, manifest.Evidence,
+		sha256Hex(data), complianceManifestFile)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{manifest.Evidence, evidence.Bytes()},
+		{complianceManifestFile, data},
+		{complianceChecksumsFile, []byte(checksums)},
+	}
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(dir, file.name), file.data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+	}
+	return nil
+}
+
+// sha256Hex returns the hex SHA-256 checksum of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func init() {
+	rootCmd.AddCommand(complianceCmd)
+	complianceCmd.AddCommand(complianceExportCmd)
+
+	// Define flags
+	complianceExportCmd.Flags().StringSliceP("repo", "r", []string{}, "The Github repositories to export (owner/repo, default from git remote)")
+	complianceExportCmd.Flags().StringP("group", "g", "", "Export every repository in a group defined in the config file")
+	complianceExportCmd.Flags().StringP("quarter", "q", "", "The quarter to export, such as 2024Q2")
+	complianceExportCmd.Flags().StringP("start-date", "s", "", "Start date in YYYY-MM-DD format (default 30 days ago)")
+	complianceExportCmd.Flags().StringP("end-date", "e", "", "End date in YYYY-MM-DD format (default today)")
+	complianceExportCmd.Flags().StringP("format", "f", export.CSV, "Format of the evidence (csv, json, tsv, plain)")
+	complianceExportCmd.Flags().StringP("output", "o", "", "Directory to write the bundle to (default compliance-PERIOD)")
+	complianceExportCmd.Flags().IntP("concurrency", "c", 4, "Number of concurrent GitHub requests")
+	complianceExportCmd.Flags().Bool("no-log", false, "Leave out the reviews logged in the database")
+}
@@ -0,0 +1,616 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/locale"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// configKind is the type of value a setting holds
+type configKind int
+
+const (
+	configString configKind = iota
+	configBool
+	configInt
+	// configList settings are lists, given to 'config set' separated by commas
+	configList
+	// configSection settings hold named entries, so they're changed with
+	// 'config edit' or the commands that manage them
+	configSection
+)
+
+// Where the effective value of a setting comes from, in order of precedence
+const (
+	sourceFlag    = "flag"
+	sourceEnv     = "env"
+	sourceFile    = "file"
+	sourceDefault = "default"
+)
+
+// configSetting is a setting the config file can hold
+type configSetting struct {
+	key         string
+	kind        configKind
+	description string
+	// def is the value used when the setting isn't set anywhere
+	def string
+	// validate checks a value, or each item of a list, beyond its kind
+	validate func(value string) error
+}
+
+// configSettings are the settings of the config file, in the order they're listed
+var configSettings = []configSetting{
+	// Defaults for ghi pr
+	{key: "repo", kind: configString, description: "Repository to query (owner/repo or an alias)"},
+	{key: "group", kind: configString, description: "Group of repositories to query"},
+	{key: "org", kind: configString, description: "Organization whose repositories are scanned"},
+	{key: "include", kind: configList, description: "Organization repositories to scan, as patterns"},
+	{key: "exclude", kind: configList, description: "Organization repositories to skip, as patterns"},
+	{key: "author", kind: configList, description: "Authors to show"},
+	{key: "association", kind: configList, description: "Author associations to show"},
+	{key: "reviewer", kind: configList, description: "Reviewers to highlight"},
+	{key: "state", kind: configString, description: "State of pull requests to show", def: "all", validate: oneOf("all", "open", "closed")},
+	{key: "draft", kind: configString, description: "Whether drafts are shown", def: "hide", validate: oneOf("show", "hide")},
+	{key: "label", kind: configList, description: "Labels pull requests must have"},
+	{key: "exclude-label", kind: configList, description: "Labels of pull requests to hide"},
+	{key: "mine", kind: configBool, description: "Show only your pull requests", def: "false"},
+	{key: "review-requested", kind: configBool, description: "Show only pull requests awaiting your review", def: "false"},
+	{key: "no-reviews", kind: configBool, description: "Show only pull requests nobody has reviewed", def: "false"},
+	{key: "needs-approval", kind: configInt, description: "Show only pull requests with fewer approvals", def: "0"},
+	{key: "ready-to-merge", kind: configBool, description: "Show only pull requests with the required approvals", def: "false"},
+	{key: "conflicts", kind: configBool, description: "Show only pull requests with merge conflicts", def: "false"},
+	{key: "blocked", kind: configBool, description: "Show only pull requests with open dependencies", def: "false"},
+	{key: "max-size", kind: configString, description: "Largest size of pull requests to show", validate: validateSize},
+	{key: "older-than", kind: configString, description: "Show only pull requests opened longer ago", validate: validateAge},
+	{key: "updated-before", kind: configString, description: "Show only pull requests last updated before", validate: validateBefore},
+	{key: "path", kind: configList, description: "Paths pull requests must change"},
+	{key: "path-mode", kind: configString, description: "How paths are applied", def: "filter", validate: oneOf("filter", "flag")},
+	{key: "columns", kind: configList, description: "Columns of the table, in order", validate: validateColumn},
+	{key: "full-titles", kind: configBool, description: "Show titles untruncated", def: "false"},
+	{key: "sort", kind: configString, description: "Order of pull requests", validate: gh.ValidateSort},
+	{key: "verify-commits", kind: configBool, description: "Check commit signatures", def: "false"},
+	{key: "unverified-only", kind: configBool, description: "Show only pull requests with unverified commits", def: "false"},
+	{key: "interval", kind: configString, description: "Refresh interval of watch mode", def: "1m0s", validate: validateDuration},
+	{key: "concurrency", kind: configInt, description: "Number of concurrent GitHub requests", def: "4", validate: validatePositive},
+	{key: "hints", kind: configBool, description: "Show the legend tip below the table", def: "true"},
+	{key: "sla.stale", kind: configString, description: "Age past the review SLA", def: "30d", validate: validateAge},
+	{key: "sla.fresh", kind: configString, description: "Age under which pull requests are new", def: "1d", validate: validateAge},
+	{key: "alerts.no-review", kind: configString, description: "Age without a review that sends an alert", validate: validateAge},
+	{key: "alerts.approved-unmerged", kind: configString, description: "Age after approval that sends an alert", validate: validateAge},
+	{key: "conflict-comment", kind: configString, description: "Comment posted by pr conflicts"},
+	{key: "prompt.format", kind: configString, description: "Template of ghi prompt"},
+
+	// Sections with named entries
+	{key: "repos", kind: configSection, description: "Repository aliases"},
+	{key: "groups", kind: configSection, description: "Groups of repositories"},
+	{key: "views", kind: configSection, description: "Saved views"},
+	{key: "teams", kind: configSection, description: "Teams of reviewers"},
+	{key: "column-widths", kind: configSection, description: "Widths of table columns"},
+	{key: "notifications", kind: configSection, description: "Notification destinations"},
+	{key: "alerts.repos", kind: configSection, description: "Alert thresholds of repositories"},
+
+	// Settings of every command
+	{key: "profile", kind: configString, description: "Authentication profile", def: defaultProfile, validate: validateProfileName},
+	{key: "locale", kind: configString, description: "Locale of dates and numbers", validate: validateLocale},
+	{key: "debug", kind: configBool, description: "Write a debug log", def: "false"},
+	{key: "log-level", kind: configString, description: "Lowest level logged to the console", def: "warn", validate: validateLogLevel},
+	{key: "log-format", kind: configString, description: "Format of the debug log", def: logger.FormatText, validate: oneOf(logger.FormatText, logger.FormatJSON)},
+	{key: "no-cache", kind: configBool, description: "Don't use the response cache", def: "false"},
+	{key: "no-color", kind: configBool, description: "Don't use colors", def: "false"},
+	{key: "storage.backend", kind: configString, description: "Backend of local storage", def: "filesystem", validate: oneOf("filesystem")},
+	{key: "token_command", kind: configString, description: "Command printing the GitHub token"},
+	{key: "db_token_command", kind: configString, description: "Command printing the database token"},
+	{key: "gh_credentials", kind: configBool, description: "Use the gh CLI's token when ghi has none", def: "true"},
+	{key: "oauth_client_id", kind: configString, description: "OAuth App client ID of auth login"},
+}
+
+// configValueWidth is the width values are truncated to by 'config list'.
+// 'config get' prints them in full.
+const configValueWidth = 50
+
+// configVersionKey records the layout of the settings files. It's managed by
+// ghi, so it isn't listed or set.
+const configVersionKey = "config-version"
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change the settings of the config file",
+	Long: `The config command shows the settings ghi uses and where each comes from,
+and changes them in the config file. Run 'ghi config list' to see every
+setting.`,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every setting with its value and source",
+	Long: `The list command prints every setting with its effective value and where the
+value comes from: a flag, a GHI_ environment variable, the config file or the
+default. Settings the config file holds that ghi doesn't know are reported
+after the table.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Setting\tValue\tSource\tDescription")
+		fmt.Fprintln(w, "-------\t-----\t------\t-----------")
+		for _, setting := range configSettings {
+			value, source := effectiveSetting(setting)
+			if source == sourceEnv {
+				source += " " + setting.envName()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", setting.key, gh.TruncateColumn(value, configValueWidth), source, setting.description)
+		}
+		w.Flush()
+
+		if path := viper.ConfigFileUsed(); path != "" && fileExists(path) {
+			problems, unknown, err := checkConfigFile(path)
+			if err != nil {
+				problems = []string{err.Error()}
+			}
+			reportConfigProblems(path, problems, unknown)
+		}
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get KEY",
+	Short: "Print the effective value of a setting",
+	Long: `The get command prints the value ghi uses for a setting, from a flag, an
+environment variable, the config file or the default. Lists are printed
+separated by commas and sections as YAML. It exits with status 1 when the
+setting has no value.`,
+	Example: `  ghi config get state
+  ghi config get sla.stale
+  ghi config get groups`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		key := strings.ToLower(args[0])
+		setting, ok := lookupSetting(key)
+		if !ok {
+			log.Fatalf("Unknown setting %q. Run 'ghi config list' to see the settings", key)
+		}
+
+		if setting.kind == configSection {
+			value := viper.Get(key)
+			if value == nil {
+				os.Exit(1)
+			}
+			data, err := yaml.Marshal(value)
+			if err != nil {
+				log.Fatalf("Failed to format %s: %v", key, err)
+			}
+			fmt.Print(string(data))
+			return
+		}
+
+		value, _ := effectiveSetting(setting)
+		if value == "" {
+			os.Exit(1)
+		}
+		fmt.Println(value)
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set KEY VALUE",
+	Short: "Set a setting in the config file",
+	Long: `The set command checks a value and stores it in the config file, creating the
+file if needed. Lists are given separated by commas. Sections, such as groups
+and views, hold named entries and are changed with 'ghi config edit' or the
+commands that manage them.
+
+Flags and environment variables still take precedence over the config file.`,
+	Example: `  ghi config set state open
+  ghi config set author alice,bob
+  ghi config set sla.stale 2w`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		key := strings.ToLower(args[0])
+		setting, ok := lookupSetting(key)
+		if !ok {
+			log.Fatalf("Unknown setting %q. Run 'ghi config list' to see the settings", key)
+		}
+		value, err := setting.parse(args[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		logger.Debug("Setting %s to %v", setting.key, value)
+		if err := updateConfigFile(setting.key, value); err != nil {
+			log.Fatal(err)
+		}
+
+		path, _ := configFilePath()
+		fmt.Printf("✅ Set %s to %s in %s\n", setting.key, formatSettingValue(value), path)
+		if _, source := effectiveSetting(setting); source == sourceFlag || source == sourceEnv {
+			fmt.Fprintf(os.Stderr, "Warning: %s is also set by %s, which takes precedence over the config file.\n", setting.key, sourceName(setting, source))
+		}
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the config file in your editor",
+	Long: `The edit command opens a copy of the config file in the editor set by VISUAL
+or EDITOR. When you close the editor, the settings are checked and the config
+file is replaced. If the YAML is invalid or a setting has an invalid value,
+you can edit it again or discard your changes. Settings ghi doesn't know are
+kept, with a warning.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := configFilePath()
+		if err != nil {
+			log.Fatal(err)
+		}
+		original, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			log.Fatalf("Failed to read %s: %v", path, err)
+		}
+		if len(original) == 0 {
+			original = []byte(fmt.Sprintf("%s: %d\n", configVersionKey, currentConfigVersion))
+		}
+
+		text := string(original)
+		for {
+			text, err = editTempFile(text, "ghi-config-*.yaml")
+			if err != nil {
+				log.Fatal(err)
+			}
+			if text == string(original) {
+				fmt.Println("The config file wasn't changed")
+				return
+			}
+
+			problems, unknown, err := checkConfig([]byte(text))
+			if err != nil {
+				problems = []string{err.Error()}
+			}
+			if len(problems) == 0 {
+				reportConfigProblems(path, nil, unknown)
+				break
+			}
+			for _, problem := range problems {
+				fmt.Fprintf(os.Stderr, "✗ %s\n", problem)
+			}
+			if !confirm(cmd.Context(), "Edit the config file again?") {
+				fmt.Println("Your changes were discarded")
+				os.Exit(1)
+			}
+		}
+
+		logger.Debug("Writing edited config file %s", path)
+		if err := os.MkdirAll(filepath.Dir(path), privateDirMode); err != nil {
+			log.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(text), privateFileMode); err != nil {
+			log.Fatalf("Failed to write %s: %v", path, err)
+		}
+		fmt.Printf("✅ Updated %s\n", path)
+	},
+}
+
+// lookupSetting returns the setting of a key. Keys inside a section, such as
+// groups.backend, are part of the section.
+func lookupSetting(key string) (configSetting, bool) {
+	for _, setting := range configSettings {
+		if setting.key == key {
+			return setting, true
+		}
+	}
+	for _, setting := range configSettings {
+		if setting.kind == configSection && strings.HasPrefix(key, setting.key+".") {
+			section := setting
+			section.key = key
+			return section, true
+		}
+	}
+	return configSetting{}, false
+}
+
+// hasChildSettings reports whether settings are nested under key, such as
+// sla.stale under sla
+func hasChildSettings(key string) bool {
+	for _, setting := range configSettings {
+		if strings.HasPrefix(setting.key, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// envName returns the environment variable that sets the setting, such as
+// GHI_SLA_STALE for sla.stale
+func (s configSetting) envName() string {
+	return "GHI_" + strings.ToUpper(envKeyReplacer.Replace(s.key))
+}
+
+// parse converts a value given on the command line to the type the config
+// file stores, checking it
+func (s configSetting) parse(value string) (interface{}, error) {
+	switch s.kind {
+	case configSection:
+		return nil, fmt.Errorf("%s is a section of the config file. Change it with 'ghi config edit'", s.key)
+	case configBool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q. Use true or false", s.key, value)
+		}
+		return parsed, nil
+	case configInt:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q. Use a whole number", s.key, value)
+		}
+		return parsed, s.check(value)
+	case configList:
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		for _, item := range items {
+			if err := s.check(item); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return value, s.check(value)
+	}
+}
+
+// check runs the setting's own validation of a value
+func (s configSetting) check(value string) error {
+	if s.validate == nil {
+		return nil
+	}
+	if err := s.validate(value); err != nil {
+		return fmt.Errorf("%s: %w", s.key, err)
+	}
+	return nil
+}
+
+// checkValue checks a value read from the config file
+func (s configSetting) checkValue(value interface{}) error {
+	_, isMap := value.(map[string]interface{})
+	_, isList := value.([]interface{})
+	switch s.kind {
+	case configSection:
+		if !isMap && !isList {
+			return fmt.Errorf("%s must be a section with entries", s.key)
+		}
+		return nil
+	case configList:
+		if isMap {
+			return fmt.Errorf("%s must be a list", s.key)
+		}
+		for _, item := range cast.ToStringSlice(value) {
+			if err := s.check(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if isMap || isList {
+		return fmt.Errorf("%s must be a single value", s.key)
+	}
+	switch s.kind {
+	case configBool:
+		if _, err := cast.ToBoolE(value); err != nil {
+			return fmt.Errorf("invalid %s %v. Use true or false", s.key, value)
+		}
+	case configInt:
+		if _, err := cast.ToIntE(value); err != nil {
+			return fmt.Errorf("invalid %s %v. Use a whole number", s.key, value)
+		}
+	}
+	return s.check(cast.ToString(value))
+}
+
+// effectiveSetting returns the value ghi uses for a setting and where it
+// comes from
+func effectiveSetting(setting configSetting) (string, string) {
+	if flag := rootCmd.PersistentFlags().Lookup(setting.key); flag != nil && flag.Changed {
+		return flag.Value.String(), sourceFlag
+	}
+	source := sourceDefault
+	if _, ok := os.LookupEnv(setting.envName()); ok {
+		source = sourceEnv
+	} else if viper.InConfig(setting.key) {
+		source = sourceFile
+	}
+
+	switch {
+	case setting.kind == configSection:
+		entries := len(cast.ToStringMap(viper.Get(setting.key))) + len(cast.ToSlice(viper.Get(setting.key)))
+		switch entries {
+		case 0:
+			return "", source
+		case 1:
+			return "1 entry", source
+		}
+		return fmt.Sprintf("%d entries", entries), source
+	case source == sourceDefault && viper.Get(setting.key) == nil:
+		return setting.def, source
+	default:
+		return formatSettingValue(viper.Get(setting.key)), source
+	}
+}
+
+// formatSettingValue formats a value the way 'config set' accepts it
+func formatSettingValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []string:
+		return strings.Join(v, ",")
+	case []interface{}:
+		return strings.Join(cast.ToStringSlice(v), ",")
+	case time.Duration:
+		return v.String()
+	default:
+		return cast.ToString(v)
+	}
+}
+
+// sourceName describes where a setting's value comes from in a sentence
+func sourceName(setting configSetting, source string) string {
+	if source == sourceEnv {
+		return "the " + setting.envName() + " environment variable"
+	}
+	return "the --" + setting.key + " flag"
+}
+
+// checkConfigFile checks the settings of a config file
+func checkConfigFile(path string) ([]string, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return checkConfig(data)
+}
+
+// checkConfig checks the settings of a config file's contents. It returns
+// the problems with known settings and the keys of unknown ones, or an error
+// when the YAML can't be read.
+func checkConfig(data []byte) ([]string, []string, error) {
+	var settings map[string]interface{}
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+
+	var problems, unknown []string
+	var walk func(settings map[string]interface{}, prefix string)
+	walk = func(settings map[string]interface{}, prefix string) {
+		for key, value := range settings {
+			key = prefix + strings.ToLower(key)
+			if key == configVersionKey {
+				continue
+			}
+			setting, known := lookupSetting(key)
+			if nested, ok := value.(map[string]interface{}); ok && (!known || setting.kind != configSection) && hasChildSettings(key) {
+				walk(nested, key+".")
+				continue
+			}
+			if !known {
+				unknown = append(unknown, key)
+				continue
+			}
+			if err := setting.checkValue(value); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+	walk(settings, "")
+
+	sort.Strings(problems)
+	sort.Strings(unknown)
+	return problems, unknown, nil
+}
+
+// reportConfigProblems warns about invalid and unknown settings of a config file
+func reportConfigProblems(path string, problems, unknown []string) {
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "Warning: in %s, %s\n", path, problem)
+	}
+	if len(unknown) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %s doesn't know the settings %s, check them for typos.\n", path, strings.Join(unknown, ", "))
+	}
+}
+
+// oneOf returns a validation accepting only the given values, ignoring case
+func oneOf(allowed ...string) func(string) error {
+	return func(value string) error {
+		for _, a := range allowed {
+			if strings.EqualFold(value, a) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q isn't one of %s", value, strings.Join(allowed, ", "))
+	}
+}
+
+// validateAge checks an age such as 14d or 2w
+func validateAge(value string) error {
+	_, err := parseAge(value)
+	return err
+}
+
+// validateBefore checks a date or an age
+func validateBefore(value string) error {
+	_, err := parseBefore(value)
+	return err
+}
+
+// validateSize checks a size bucket name
+func validateSize(value string) error {
+	_, err := gh.ParseSize(value)
+	return err
+}
+
+// validateColumn checks a column name
+func validateColumn(value string) error {
+	_, err := gh.LookupColumns([]string{value})
+	return err
+}
+
+// validateDuration checks a Go duration such as 30s or 5m
+func validateDuration(value string) error {
+	if _, err := time.ParseDuration(value); err != nil {
+		return fmt.Errorf("%q isn't a duration such as 30s or 5m", value)
+	}
+	return nil
+}
+
+// validatePositive checks that a number is at least 1
+func validatePositive(value string) error {
+	if n, err := strconv.Atoi(value); err != nil || n < 1 {
+		return fmt.Errorf("%q must be at least 1", value)
+	}
+	return nil
+}
+
+// validateLogLevel checks a console log level
+func validateLogLevel(value string) error {
+	_, err := logger.ParseLevel(value)
+	return err
+}
+
+// validateLocale checks a language tag or POSIX locale
+func validateLocale(value string) error {
+	_, err := locale.Parse(value)
+	return err
+}
+
+// validateProfileName checks that a profile name is safe as a file name
+func validateProfileName(value string) error {
+	if !profileNamePattern.MatchString(value) {
+		return fmt.Errorf("%q can only contain letters, digits, - and _", value)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configEditCmd)
+}
@@ -0,0 +1,350 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/paths"
+)
+
+// currentConfigVersion is the layout of the settings files this version of ghi
+// uses. It's stored under config-version in the config file, and older
+// layouts are migrated when a newer ghi first runs.
+const currentConfigVersion = 2
+
+// configMigration upgrades the settings files to a config version
+type configMigration struct {
+	version     int
+	description string
+	// migrate changes the files in the home directory and reports whether
+	// there was anything to change
+	migrate func(home string) (bool, error)
+}
+
+// configMigrations are the changes to the settings layout, in version order
+var configMigrations = []configMigration{
+	{1, "moved ~/.github-info.yaml into the config directory", migrateLegacyConfigFile},
+	{2, "moved ~/.ghi to the XDG base directories", migrateXDGDirs},
+}
+
+// configVersionPattern matches the config-version line of a config file
+var configVersionPattern = regexp.MustCompile(`(?m)^config-version:[ \t]*(\S*)[ \t]*\r?$`)
+
+// defaultConfigPath returns the config file used when --config isn't given
+func defaultConfigPath() string {
+	return paths.ConfigFile()
+}
+
+// legacyConfigPaths are where the config file was kept before config version 1
+func legacyConfigPaths(home string) []string {
+	return []string{
+		filepath.Join(home, ".github-info.yaml"),
+		filepath.Join(home, ".github-info.yml"),
+	}
+}
+
+// migrateConfig brings the settings files in the home directory up to the
+// current config version, backing up the previous files first. Problems are
+// warnings, ghi still runs with the files as they are.
+func migrateConfig(home string) {
+	defer warnUnusedLegacySettings()
+
+	version, err := readConfigVersion(defaultConfigPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: settings weren't migrated: %v\n", err)
+		return
+	}
+	if version >= currentConfigVersion {
+		return
+	}
+
+	backupDir, err := backupSettings(home, version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: settings weren't migrated, the backup failed: %v\n", err)
+		return
+	}
+
+	var changes []string
+	for _, migration := range configMigrations {
+		if migration.version <= version {
+			continue
+		}
+		changed, err := migration.migrate(home)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: settings migration to config version %d failed: %v\n", migration.version, err)
+			return
+		}
+		// A fresh install has no settings to version yet, and creating a config
+		// file just to hold it would make every command report using it
+		if !changed && backupDir == "" {
+			continue
+		}
+		// The config file may have been moved by the migration
+		if err := writeConfigVersion(defaultConfigPath(), migration.version); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record config version %d: %v\n", migration.version, err)
+			return
+		}
+		if changed {
+			changes = append(changes, migration.description)
+		}
+	}
+
+	if len(changes) > 0 {
+		fmt.Fprintf(os.Stderr, "Migrated settings to config version %d: %s. The previous files are backed up in %s\n",
+			currentConfigVersion, strings.Join(changes, ", "), backupDir)
+	}
+}
+
+// readConfigVersion returns the config version recorded in the config file.
+// Files from before versions were recorded, and missing files, are version 0.
+func readConfigVersion(configFile string) (int, error) {
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	match := configVersionPattern.FindSubmatch(data)
+	if match == nil {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(strings.Trim(string(match[1]), `"'`))
+	if err != nil {
+		return 0, fmt.Errorf("invalid config-version %q in %s", match[1], configFile)
+	}
+	return version, nil
+}
+
+// writeConfigVersion records the config version in the config file, creating
+// the file if needed. The line is edited in place so the comments and layout
+// of a hand-written file are kept.
+func writeConfigVersion(configFile string, version int) error {
+	data, err := os.ReadFile(configFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	line := fmt.Sprintf("config-version: %d", version)
+	if configVersionPattern.Match(data) {
+		data = configVersionPattern.ReplaceAll(data, []byte(line))
+	} else {
+		data = append([]byte(line+"\n"), data...)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configFile), privateDirMode); err != nil {
+		return err
+	}
+	return os.WriteFile(configFile, data, privateFileMode)
+}
+
+// backupSettings copies the config and env files, in every layout, to a new
+// directory under the backups directory and returns it. It returns an empty
+// directory name when there are no settings files to back up yet.
+func backupSettings(home string, version int) (string, error) {
+	files := append([]string{defaultConfigPath(), envFilePath()}, legacyConfigPaths(home)...)
+	var existing []string
+	for _, file := range files {
+		if fileExists(file) {
+			existing = append(existing, file)
+		}
+	}
+	if len(existing) == 0 {
+		return "", nil
+	}
+
+	name := fmt.Sprintf("config-v%d-%s", version, time.Now().Format("20060102-150405"))
+	dir := filepath.Join(paths.BackupsDir(), name)
+	if err := os.MkdirAll(dir, privateDirMode); err != nil {
+		return "", err
+	}
+	for _, file := range existing {
+		if err := copyFile(file, filepath.Join(dir, filepath.Base(file))); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// copyFile copies a file, making the copy readable only by the user since
+// settings files hold tokens
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, privateFileMode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// moveFile renames a file or directory. Rename can't cross file systems, such
+// as when the home directory and ~/.config are different mounts, so there it's
+// copied and the original removed instead.
+func moveFile(from, to string) error {
+	err := os.Rename(from, to)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := copyTree(from, to); err != nil {
+		os.RemoveAll(to)
+		return err
+	}
+	return os.RemoveAll(from)
+}
+
+// copyTree copies a file, or a directory and everything in it, with copyFile
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if entry.IsDir() {
+			return os.MkdirAll(target, privateDirMode)
+		}
+		return copyFile(path, target)
+	})
+}
+
+// migrateLegacyConfigFile moves the config file from the home directory into
+// the config directory, next to the env file. A config file already in the
+// config directory is kept and the legacy one left alone.
+func migrateLegacyConfigFile(home string) (bool, error) {
+	configFile := defaultConfigPath()
+	if fileExists(configFile) {
+		return false, nil
+	}
+	for _, legacy := range legacyConfigPaths(home) {
+		if !fileExists(legacy) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(configFile), privateDirMode); err != nil {
+			return false, err
+		}
+		return true, os.Rename(legacy, configFile)
+	}
+	return false, nil
+}
+
+// settingsMove is a file or directory of ~/.ghi and where the XDG layout keeps it
+type settingsMove struct {
+	from, to string
+}
+
+// legacySettings are the settings files of ~/.ghi, which must all move to
+// the config directory for ghi to keep using them
+var legacySettings = []string{"config.yaml", "env", "profiles"}
+
+// migrateXDGDirs moves the files in ~/.ghi to the XDG base directories: the
+// config file, env files and profiles to the config directory, logs and
+// backups to the state directory and the response cache to the cache
+// directory. The settings are moved together; when one can't be moved the
+// others are moved back and ~/.ghi stays in use. When the config directory
+// already exists, settings it doesn't have yet are merged into it and those
+// it has are left in ~/.ghi for warnUnusedLegacySettings to report.
+func migrateXDGDirs(home string) (bool, error) {
+	legacy := paths.LegacyDir()
+	if legacy == "" || !fileExists(legacy) {
+		return false, nil
+	}
+	configHome := paths.ConfigHome()
+	createdConfigHome := !fileExists(configHome)
+
+	if err := os.MkdirAll(configHome, privateDirMode); err != nil {
+		return false, err
+	}
+	var moved []settingsMove
+	for _, name := range legacySettings {
+		move := settingsMove{filepath.Join(legacy, name), filepath.Join(configHome, name)}
+		if !fileExists(move.from) || fileExists(move.to) {
+			continue
+		}
+		if err := moveFile(move.from, move.to); err != nil {
+			for i := len(moved) - 1; i >= 0; i-- {
+				moveFile(moved[i].to, moved[i].from)
+			}
+			if createdConfigHome {
+				os.Remove(configHome)
+			}
+			return false, err
+		}
+		moved = append(moved, move)
+	}
+
+	// Logs, backups and cached responses can be recreated, so they're moved
+	// when they can be and otherwise left behind
+	others := []settingsMove{
+		{filepath.Join(legacy, "logs"), filepath.Join(paths.StateHome(), "logs")},
+		{filepath.Join(legacy, "storage"), paths.CacheHome()},
+	}
+	backups, _ := os.ReadDir(filepath.Join(legacy, "backups"))
+	for _, backup := range backups {
+		others = append(others, settingsMove{
+			filepath.Join(legacy, "backups", backup.Name()),
+			filepath.Join(paths.BackupsDir(), backup.Name()),
+		})
+	}
+	for _, move := range others {
+		if !fileExists(move.from) || fileExists(move.to) {
+			continue
+		}
+		err := os.MkdirAll(filepath.Dir(move.to), privateDirMode)
+		if err == nil {
+			err = moveFile(move.from, move.to)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s wasn't moved to %s: %v\n", move.from, move.to, err)
+		}
+	}
+
+	// ~/.ghi is kept while it holds files ghi doesn't manage, such as export state
+	os.Remove(filepath.Join(legacy, "backups"))
+	os.Remove(legacy)
+	return true, nil
+}
+
+// warnUnusedLegacySettings warns about settings files left in ~/.ghi once the
+// config directory is in use, such as tokens an older ghi saved there, since
+// they're silently ignored otherwise
+func warnUnusedLegacySettings() {
+	if paths.Legacy() {
+		return
+	}
+	legacy := paths.LegacyDir()
+	if legacy == "" {
+		return
+	}
+	for _, name := range legacySettings {
+		file := filepath.Join(legacy, name)
+		if fileExists(file) {
+			fmt.Fprintf(os.Stderr, "Warning: %s isn't used, settings are read from %s. Move what you need there and remove it.\n",
+				file, paths.ConfigHome())
+		}
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// editText opens the user's editor on a temporary file containing initial and
+// returns the edited text
+func editText(initial string) (string, error) {
+	edited, err := editTempFile(initial, "ghi-*.md")
+	return strings.TrimSpace(edited), err
+}
+
+// editorCommand returns the user's editor and its arguments, such as
+// "code --wait". The editor is taken from VISUAL or EDITOR, skipping ones that
+// are empty or only whitespace, falling back to vi, or Notepad on Windows.
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// editTempFile opens the user's editor on a temporary file named by pattern,
+// containing initial, and returns the edited contents
+func editTempFile(initial, pattern string) (string, error) {
+	fields := editorCommand()
+	editor := strings.Join(fields, " ")
+
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(initial); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	logger.Debug("Launching editor %q on %s", editor, file.Name())
+	cmd := exec.Command(fields[0], append(fields[1:], file.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %s failed: %w", editor, err)
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited text: %w", err)
+	}
+	return string(data), nil
+}
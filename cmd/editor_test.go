@@ -0,0 +1,37 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestEditorCommand(t *testing.T) {
+	fallback := []string{"vi"}
+	if runtime.GOOS == "windows" {
+		fallback = []string{"notepad"}
+	}
+
+	tests := []struct {
+		name, visual, editor string
+		want                 []string
+	}{
+		{"visual first", "code --wait", "nano", []string{"code", "--wait"}},
+		{"editor", "", "nano", []string{"nano"}},
+		{"whitespace visual", "  ", "nano", []string{"nano"}},
+		{"whitespace only", " \t", "   ", fallback},
+		{"unset", "", "", fallback},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VISUAL", tt.visual)
+			t.Setenv("EDITOR", tt.editor)
+			if got := editorCommand(); !slices.Equal(got, tt.want) {
+				t.Errorf("editorCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/jbrinkman/ghi/pkg/envcrypt"
+	"github.com/jbrinkman/ghi/pkg/paths"
+)
+
+// cachedPassphrase holds the env file passphrase once it has been entered,
+// so it is only asked for once per command
+var cachedPassphrase string
+
+// envFilePath returns the path of the env file of the active profile, which
+// is the env file in the config directory for the default profile
+func envFilePath() string {
+	if activeProfile != "" {
+		return profileEnvPath(activeProfile)
+	}
+	return paths.EnvFile()
+}
+
+// envPassphrase returns the passphrase for the encrypted env file. It is read
+// from GHI_PASSPHRASE, or prompted for when running in a terminal.
+func envPassphrase(prompt string) (string, error) {
+	if cachedPassphrase != "" {
+		return cachedPassphrase, nil
+	}
+	if passphrase := os.Getenv("GHI_PASSPHRASE"); passphrase != "" {
+		cachedPassphrase = passphrase
+		return passphrase, nil
+	}
+
+	fd := os.Stdin.Fd()
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("the env file is encrypted. Set GHI_PASSPHRASE to decrypt it")
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	input, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if len(input) == 0 {
+		return "", fmt.Errorf("passphrase is empty")
+	}
+
+	cachedPassphrase = string(input)
+	return cachedPassphrase, nil
+}
+
+// readEnvFile reads the settings in the env file, decrypting it if needed. It
+// also reports whether the file was encrypted. A missing file has no settings.
+func readEnvFile(filename string) (map[string]string, bool, error) {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return map[string]string{}, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	encrypted := envcrypt.IsEncrypted(data)
+	if encrypted {
+		passphrase, err := envPassphrase(fmt.Sprintf("Passphrase for %s: ", filename))
+		if err != nil {
+			return nil, true, err
+		}
+		if data, err = envcrypt.Decrypt(data, passphrase); err != nil {
+			cachedPassphrase = ""
+			return nil, true, fmt.Errorf("failed to decrypt %s: %w", filename, err)
+		}
+	}
+
+	return parseEnv(string(data)), encrypted, nil
+}
+
+// writeEnvFile writes the settings to the env file, encrypting them with the
+// env file passphrase if encrypt is set
+func writeEnvFile(filename string, env map[string]string, encrypt bool) error {
+	data := []byte(formatEnv(env))
+	if encrypt {
+		passphrase, err := envPassphrase(fmt.Sprintf("Passphrase for %s: ", filename))
+		if err != nil {
+			return err
+		}
+		if data, err = envcrypt.Encrypt(data, passphrase); err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", filename, err)
+		}
+	}
+
+	// An env file that links to another, such as one kept in a dotfiles
+	// repository, is replaced where the link points so the link is kept
+	if target, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = target
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	// Write a private temporary file and move it into place, so the settings
+	// are never readable by others or left half written
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".env-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(privateFileMode); err != nil && runtime.GOOS != "windows" {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// updateEnvFile changes the settings in the env file of the active profile,
+// creating it and its directory if needed and keeping its encryption
+func updateEnvFile(update func(env map[string]string)) error {
+	envFile := envFilePath()
+	if err := os.MkdirAll(filepath.Dir(envFile), privateDirMode); err != nil {
+		return fmt.Errorf("error creating config directory: %w", err)
+	}
+
+	env, encrypted, err := readEnvFile(envFile)
+	if err != nil {
+		return fmt.Errorf("error reading env file: %w", err)
+	}
+	update(env)
+	if err := writeEnvFile(envFile, env, encrypted); err != nil {
+		return fmt.Errorf("error writing env file: %w", err)
+	}
+	return nil
+}
+
+// parseEnv parses KEY=VALUE lines, skipping empty lines and comments
+func parseEnv(data string) map[string]string {
+	env := make(map[string]string)
+	for _, line := range strings.Split(data, "\n") {
+		if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		env[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return env
+}
+
+// formatEnv writes the settings as KEY=VALUE lines in key order
+func formatEnv(env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s=%s\n", key, env[key])
+	}
+	return b.String()
+}
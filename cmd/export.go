@@ -0,0 +1,282 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	"github.com/jbrinkman/ghi/pkg/db"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// Entities that can be exported
+const (
+	exportPRs       = "prs"
+	exportReviews   = "reviews"
+	exportSnapshots = "snapshots"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export data for other tools",
+}
+
+// exportStreamCmd represents the export stream command
+var exportStreamCmd = &cobra.Command{
+	Use:   "stream",
+	Short: "Stream pull requests, reviews or backlog snapshots as JSON lines",
+	Long: `The 'stream' command writes one JSON object per line to stdout, ready to pipe
+into jq, a warehouse loader or a file.
+
+The entities are:
+  prs        pull requests, least recently updated first
+  reviews    reviews logged in your database, in the order they were logged
+  snapshots  the number of open pull requests and their median age at
+             midnight UTC of each day, reconstructed from when they were
+             opened and closed
+
+With --state, the position reached is saved in the given file and the next run
+only exports what's new since then, so scheduled exports are incremental.`,
+	Example: `  ghi export stream -r octocat/Hello-World --entity prs --state ~/.local/state/ghi/export.json >> prs.ndjson
+  ghi export stream --entity reviews | jq -r .note`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoFlag, _ := cmd.Flags().GetString("repo")
+		entity, _ := cmd.Flags().GetString("entity")
+		statePath, _ := cmd.Flags().GetString("state")
+		sinceFlag, _ := cmd.Flags().GetString("since")
+
+		if entity != exportPRs && entity != exportReviews && entity != exportSnapshots {
+			log.Fatalf("Invalid entity %q. Use %s, %s or %s", entity, exportPRs, exportReviews, exportSnapshots)
+		}
+
+		// Reviews can be exported for every repository, the others need one
+		repo, err := resolveRepo(repoFlag)
+		if err != nil {
+			if entity != exportReviews {
+				log.Fatalf("The --repo flag is required when not in a GitHub repository clone: %v", err)
+			}
+			logger.Debug("No repository filter: %v", err)
+		}
+		if repo != "" && len(strings.Split(repo, "/")) != 2 {
+			log.Fatal("Invalid repository format. Use 'owner/repo'")
+		}
+
+		var since time.Time
+		if sinceFlag != "" {
+			age, err := parseAge(sinceFlag)
+			if err != nil {
+				log.Fatal(err)
+			}
+			since = time.Now().Add(-age)
+		}
+
+		state := make(map[string]string)
+		if statePath != "" {
+			if state, err = loadExportState(statePath); err != nil {
+				log.Fatal(err)
+			}
+		}
+		key := entity
+		if repo != "" {
+			key += ":" + repo
+		}
+		cursor := state[key]
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Exporting %s of %q since %v from cursor %q", entity, repo, since, cursor)
+
+		ctx := cmd.Context()
+		enc := json.NewEncoder(os.Stdout)
+		var next string
+		switch entity {
+		case exportPRs:
+			next, err = streamPRs(ctx, enc, repo, cursor, since)
+		case exportReviews:
+			next, err = streamReviews(ctx, enc, repo, cursor, since)
+		case exportSnapshots:
+			next, err = streamSnapshots(ctx, enc, repo, cursor, since)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if statePath != "" && next != cursor {
+			state[key] = next
+			if err := saveExportState(statePath, state); err != nil {
+				log.Fatal(err)
+			}
+			logger.Debug("Saved cursor %q for %s in %s", next, key, statePath)
+		}
+	},
+}
+
+// streamPRs writes the pull requests updated after the cursor, an RFC 3339
+// time, or after since when there's no cursor. It returns the new cursor.
+func streamPRs(ctx context.Context, enc *json.Encoder, repo, cursor string, since time.Time) (string, error) {
+	if cursor != "" {
+		t, err := time.Parse(time.RFC3339, cursor)
+		if err != nil {
+			return "", fmt.Errorf("invalid pull request cursor %q in the state file: %w", cursor, err)
+		}
+		since = t
+	}
+
+	client, err := clients.NewGitHubClient()
+	if err != nil {
+		return "", fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	parts := strings.Split(repo, "/")
+	issues, complete, err := gh.SearchPRsUpdatedSince(ctx, client, parts[0], parts[1], since)
+	if err != nil {
+		return "", err
+	}
+	if !complete {
+		fmt.Fprintln(os.Stderr, "Warning: GitHub search returned only the first 1000 pull requests. Run the export again with --state to continue")
+	}
+
+	for _, issue := range issues {
+		if err := enc.Encode(gh.NewPRRecord(repo, issue)); err != nil {
+			return "", fmt.Errorf("failed to write pull request #%d: %w", issue.GetNumber(), err)
+		}
+		cursor = issue.GetUpdatedAt().UTC().Format(time.RFC3339)
+	}
+	return cursor, nil
+}
+
+// streamReviews writes the reviews logged after the cursor, a review ID, or
+// after since when there's no cursor. It returns the new cursor.
+func streamReviews(ctx context.Context, enc *json.Encoder, repo, cursor string, since time.Time) (string, error) {
+	var afterID int64
+	if cursor != "" {
+		id, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid review cursor %q in the state file: %w", cursor, err)
+		}
+		afterID = id
+	}
+
+	dbClient, err := db.NewClient()
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbClient.Close()
+
+	if err := dbClient.InitSchema(ctx); err != nil {
+		return "", fmt.Errorf("failed to initialize database schema: %w", err)
+	}
+
+	reviews, err := dbClient.GetReviewsAfter(ctx, repo, afterID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, review := range reviews {
+		cursor = strconv.FormatInt(review.ID, 10)
+		if afterID == 0 && review.Timestamp.Before(since) {
+			continue
+		}
+		if err := enc.Encode(review); err != nil {
+			return "", fmt.Errorf("failed to write review %d: %w", review.ID, err)
+		}
+	}
+	return cursor, nil
+}
+
+// streamSnapshots writes a backlog snapshot for each day after the cursor, a
+// date, or from since when there's no cursor, up to today. It returns the new cursor.
+func streamSnapshots(ctx context.Context, enc *json.Encoder, repo, cursor string, since time.Time) (string, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, -30)
+	if !since.IsZero() {
+		start = since.UTC().Truncate(24 * time.Hour)
+	}
+	if cursor != "" {
+		last, err := time.Parse(time.DateOnly, cursor)
+		if err != nil {
+			return "", fmt.Errorf("invalid snapshot cursor %q in the state file: %w", cursor, err)
+		}
+		start = last.AddDate(0, 0, 1)
+	}
+	if start.After(today) {
+		logger.Debug("No new snapshots since %s", cursor)
+		return cursor, nil
+	}
+
+	client, err := clients.NewGitHubClient()
+	if err != nil {
+		return "", fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	parts := strings.Split(repo, "/")
+	issues, complete, err := gh.SearchPRsOpenSince(ctx, client, parts[0], parts[1], start)
+	if err != nil {
+		return "", err
+	}
+	if !complete {
+		fmt.Fprintln(os.Stderr, "Warning: GitHub search returned only the 1000 newest pull requests, so earlier days may be undercounted")
+	}
+
+	for day := start; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if err := enc.Encode(gh.NewSnapshotRecord(repo, gh.BacklogAt(issues, day))); err != nil {
+			return "", fmt.Errorf("failed to write snapshot of %s: %w", day.Format(time.DateOnly), err)
+		}
+		cursor = day.Format(time.DateOnly)
+	}
+	return cursor, nil
+}
+
+// loadExportState reads the cursors saved by previous exports. A missing file
+// means nothing has been exported yet.
+func loadExportState(path string) (map[string]string, error) {
+	state := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse export state %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// saveExportState writes the cursors of the exports to the state file
+func saveExportState(path string, state map[string]string) error {
+	data, err := prettyPrint(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode export state: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(data+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write export state: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportStreamCmd)
+
+	// Define flags
+	exportStreamCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+	exportStreamCmd.Flags().StringP("entity", "e", "", "What to export: prs, reviews or snapshots")
+	exportStreamCmd.Flags().String("state", "", "File that keeps the export position, so each run only exports what's new")
+	exportStreamCmd.Flags().String("since", "", "Only export what changed in this period on the first run, such as 90d (snapshots default to 30d)")
+	exportStreamCmd.MarkFlagRequired("entity")
+}
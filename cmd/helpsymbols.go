@@ -0,0 +1,26 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// symbolsCmd is a help topic explaining the markers, colors and keys of the
+// pull request tables. It has no Run, so 'ghi help symbols' shows it.
+var symbolsCmd = &cobra.Command{
+	Use:   "symbols",
+	Short: "What the markers, colors and keys of the pull request tables mean",
+	Long: `The pull request tables mark pull requests with these symbols and colors. The
+same legend is shown by pressing ? in the interactive table.
+
+` + ui.LegendText() + `
+The tip about the legend below the interactive table is hidden by pressing x,
+or by setting hints: false in the config file.`,
+}
+
+func init() {
+	rootCmd.AddCommand(symbolsCmd)
+}
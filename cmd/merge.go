@@ -0,0 +1,138 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// mergeCmd represents the pr merge command
+var mergeCmd = &cobra.Command{
+	Use:   "merge",
+	Short: "Merge a pull request",
+	Long: `The 'merge' command merges a pull request using the chosen --method.
+
+Before merging it checks that the pull request is open, has no merge conflicts,
+has the approvals required by branch protection and no outstanding change
+requests. You're asked to confirm the merge unless --yes is given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoFlag, _ := cmd.Flags().GetString("repo")
+		number, _ := cmd.Flags().GetInt("number")
+		method, _ := cmd.Flags().GetString("method")
+		deleteBranch, _ := cmd.Flags().GetBool("delete-branch")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		if number <= 0 {
+			log.Fatal("The --number flag is required")
+		}
+		if method != gh.MergeMethodMerge && method != gh.MergeMethodSquash && method != gh.MergeMethodRebase {
+			log.Fatalf("Invalid merge method %q. Use 'merge', 'squash' or 'rebase'", method)
+		}
+
+		repo, err := resolveRepo(repoFlag)
+		if err != nil {
+			log.Fatalf("The --repo flag is required when not in a GitHub repository clone: %v", err)
+		}
+		parts := strings.Split(repo, "/")
+		if len(parts) != 2 {
+			log.Fatal("Invalid repository format. Use 'owner/repo'")
+		}
+		owner, repoName := parts[0], parts[1]
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Merging %s #%d with method %s, delete branch: %v", repo, number, method, deleteBranch)
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		check, err := gh.CheckMergeable(ctx, client, owner, repoName, number)
+		if err != nil {
+			log.Fatal(err)
+		}
+		pr := check.PullRequest
+
+		fmt.Printf("%s #%d: %s\n", repo, number, pr.GetTitle())
+		fmt.Printf("%s → %s\n", pr.GetHead().GetLabel(), pr.GetBase().GetRef())
+		if check.RequiredApprovals > 0 {
+			fmt.Printf("Approvals: %d of %d required\n", check.Approvals, check.RequiredApprovals)
+		} else {
+			fmt.Printf("Approvals: %d\n", check.Approvals)
+		}
+
+		if len(check.Problems) > 0 {
+			fmt.Fprintln(os.Stderr, "Can't merge:")
+			for _, problem := range check.Problems {
+				fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+			}
+			os.Exit(1)
+		}
+
+		if !yes && !confirm(ctx, fmt.Sprintf("Merge with %s?", method)) {
+			fmt.Println("Merge cancelled")
+			return
+		}
+
+		result, err := gh.MergePullRequest(ctx, client, owner, repoName, number, method, pr.GetHead().GetSHA())
+		if err != nil {
+			log.Fatal(err)
+		}
+		logger.Debug("Merged #%d as %s", number, result.GetSHA())
+		fmt.Printf("✅ Merged %s #%d (%s)\n", repo, number, shortSHA(result.GetSHA()))
+
+		if deleteBranch {
+			if err := gh.DeleteHeadBranch(ctx, client, pr); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				return
+			}
+			fmt.Printf("✅ Deleted branch %s\n", pr.GetHead().GetRef())
+		}
+	},
+}
+
+// confirm asks a yes/no question on the terminal, defaulting to no. Ctrl+C
+// answers no.
+func confirm(ctx context.Context, question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+
+	line, err := ui.ReadLine(ctx)
+	if err != nil && line == "" {
+		fmt.Println()
+		return false
+	}
+
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
+
+// shortSHA abbreviates a commit SHA for display
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func init() {
+	prCmd.AddCommand(mergeCmd)
+
+	// Define flags
+	mergeCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+	mergeCmd.Flags().IntP("number", "n", 0, "The number of the pull request")
+	mergeCmd.Flags().StringP("method", "m", gh.MergeMethodMerge, "Merge method (merge, squash, rebase)")
+	mergeCmd.Flags().Bool("delete-branch", false, "Delete the pull request's branch after merging")
+	mergeCmd.Flags().BoolP("yes", "y", false, "Merge without asking for confirmation")
+}
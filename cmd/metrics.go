@@ -0,0 +1,127 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// metricsCmd represents the metrics command
+var metricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Report metrics about a repository's pull requests",
+	Long:  `The 'metrics' command groups subcommands that report on how a repository's pull requests are handled.`,
+}
+
+// metricsLinkageCmd represents the metrics linkage command
+var metricsLinkageCmd = &cobra.Command{
+	Use:   "linkage",
+	Short: "Report how many merged pull requests reference an issue",
+	Long: `The 'linkage' command reports the percentage of pull requests merged in a
+repository that reference an issue or ticket, overall and by author.
+
+A pull request is linked when its title or description references an issue as
+#123, owner/repo#123 or a link to the issue, or mentions a ticket key such as
+PAY-123. Use --ticket-pattern to match the keys of your tracker instead.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoFlag, _ := cmd.Flags().GetString("repo")
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		patternFlag, _ := cmd.Flags().GetString("ticket-pattern")
+		unlinked, _ := cmd.Flags().GetBool("unlinked")
+
+		repo, err := resolveRepo(repoFlag)
+		if err != nil {
+			log.Fatalf("The --repo flag is required when not in a GitHub repository clone: %v", err)
+		}
+		parts := strings.Split(repo, "/")
+		if len(parts) != 2 {
+			log.Fatal("Invalid repository format. Use 'owner/repo'")
+		}
+		owner, repoName := parts[0], parts[1]
+
+		age, err := parseAge(sinceFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		since := time.Now().Add(-age)
+
+		ticketPattern := gh.DefaultTicketPattern
+		if patternFlag != "" {
+			if ticketPattern, err = regexp.Compile(patternFlag); err != nil {
+				log.Fatalf("Invalid ticket pattern: %v", err)
+			}
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Reporting issue linkage for %s since %s, ticket pattern: %s", repo, since.Format(time.DateOnly), ticketPattern)
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		var total int
+		issues, err := ui.WithSpinner(ctx, "Fetching merged pull requests", func() ([]*github.Issue, error) {
+			issues, t, err := gh.SearchMergedPRs(ctx, client, owner, repoName, since)
+			total = t
+			return issues, err
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(issues) < total {
+			fmt.Fprintf(os.Stderr, "Warning: only the %d most recent of %d merged pull requests were checked. Use a shorter --since to cover them all.\n", len(issues), total)
+		}
+		if len(issues) == 0 {
+			fmt.Printf("No pull requests were merged in %s since %s\n", repo, since.Format(time.DateOnly))
+			return
+		}
+
+		report := gh.BuildLinkageReport(issues, ticketPattern)
+		fmt.Printf("%s, merged since %s\n\n", repo, since.Format(time.DateOnly))
+		fmt.Printf("Linked: %d of %d merged pull requests (%.0f%%)\n\n", report.Total.Linked, report.Total.Merged, report.Total.Percent())
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Author\tMerged\tLinked\tLinked %")
+		fmt.Fprintln(w, "------\t------\t------\t--------")
+		for _, stats := range report.Authors {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%.0f%%\n", stats.Author, stats.Merged, stats.Linked, stats.Percent())
+		}
+		w.Flush()
+
+		if unlinked && len(report.Unlinked) > 0 {
+			fmt.Println("\nUnlinked pull requests:")
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, issue := range report.Unlinked {
+				fmt.Fprintf(w, "#%d\t%s\t%s\n", issue.GetNumber(), truncateTitle(issue.GetTitle(), 60), issue.GetUser().GetLogin())
+			}
+			w.Flush()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(metricsCmd)
+	metricsCmd.AddCommand(metricsLinkageCmd)
+
+	// Define flags
+	metricsLinkageCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+	metricsLinkageCmd.Flags().String("since", "90d", "How far back to look at merged pull requests, such as 90d or 12w")
+	metricsLinkageCmd.Flags().String("ticket-pattern", "", "A regular expression matching ticket keys (default keys such as PAY-123)")
+	metricsLinkageCmd.Flags().Bool("unlinked", false, "List the merged pull requests that don't reference an issue")
+}
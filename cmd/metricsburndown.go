@@ -0,0 +1,108 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// metricsBurndownCmd represents the metrics burndown command
+var metricsBurndownCmd = &cobra.Command{
+	Use:   "burndown",
+	Short: "Chart the open pull request backlog over time",
+	Long: `The 'burndown' command charts the number of open pull requests in a
+repository and their median age every week, to show whether the
+backlog is shrinking.
+
+The backlog is reconstructed from when each pull request was opened and closed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoFlag, _ := cmd.Flags().GetString("repo")
+		sinceFlag, _ := cmd.Flags().GetString("since")
+
+		repo, err := resolveRepo(repoFlag)
+		if err != nil {
+			log.Fatalf("The --repo flag is required when not in a GitHub repository clone: %v", err)
+		}
+		parts := strings.Split(repo, "/")
+		if len(parts) != 2 {
+			log.Fatal("Invalid repository format. Use 'owner/repo'")
+		}
+		owner, repoName := parts[0], parts[1]
+
+		age, err := parseAge(sinceFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		now := time.Now()
+		since := now.Add(-age)
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Charting the backlog of %s since %s", repo, since.Format(time.DateOnly))
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		var complete bool
+		issues, err := ui.WithSpinner(ctx, "Fetching pull requests", func() ([]*github.Issue, error) {
+			issues, c, err := gh.SearchPRsOpenSince(ctx, client, owner, repoName, since)
+			complete = c
+			return issues, err
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !complete {
+			fmt.Fprintln(os.Stderr, "Warning: GitHub search returned only the 1000 newest pull requests, so older weeks may be undercounted")
+		}
+
+		points := gh.Burndown(issues, since, now)
+		open := make([]float64, 0, len(points))
+		ages := make([]float64, 0, len(points))
+		for _, point := range points {
+			open = append(open, float64(point.Open))
+			ages = append(ages, point.MedianAge.Hours())
+		}
+
+		first, last := points[0], points[len(points)-1]
+		fmt.Printf("%s, weekly since %s\n\n", repo, since.Format(time.DateOnly))
+		fmt.Printf("Open PRs    %s  %d → %d (%+d)\n", ui.Sparkline(open), first.Open, last.Open, last.Open-first.Open)
+		fmt.Printf("Median age  %s  %s → %s\n\n", ui.Sparkline(ages), formatDays(first.MedianAge), formatDays(last.MedianAge))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Week\tOpen\tMedian Age")
+		fmt.Fprintln(w, "----\t----\t----------")
+		for _, point := range points {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", point.Time.Format(time.DateOnly), point.Open, formatDays(point.MedianAge))
+		}
+		w.Flush()
+	},
+}
+
+// formatDays formats a duration as a whole number of days, such as "12d"
+func formatDays(d time.Duration) string {
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+func init() {
+	metricsCmd.AddCommand(metricsBurndownCmd)
+
+	// Define flags
+	metricsBurndownCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+	metricsBurndownCmd.Flags().String("since", "180d", "How far back to chart the backlog, such as 180d or 26w")
+}
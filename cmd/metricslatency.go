@@ -0,0 +1,202 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// metricsLatencyCmd represents the metrics latency command
+var metricsLatencyCmd = &cobra.Command{
+	Use:   "latency",
+	Short: "Report how long pull requests wait for review and merge",
+	Long: `The 'latency' command reports how long the pull requests opened in a
+repository took from opening to the first review, from opening to merge and
+from the first review to merge, with the median, 90th percentile and mean of
+each, overall and by the week they were opened.
+
+Reviews by the author don't count as the first review. Pull requests that
+haven't been reviewed or merged yet are left out of that step.`,
+	Example: `  ghi metrics latency -r octocat/Hello-World --since 90d
+  ghi metrics latency -r octocat/Hello-World --format markdown >> report.md`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoFlag, _ := cmd.Flags().GetString("repo")
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		format, _ := cmd.Flags().GetString("format")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+		if format != "table" && format != "json" && format != "markdown" {
+			log.Fatalf("Invalid format %q. Use 'table', 'json' or 'markdown'", format)
+		}
+
+		repo, err := resolveRepo(repoFlag)
+		if err != nil {
+			log.Fatalf("The --repo flag is required when not in a GitHub repository clone: %v", err)
+		}
+		parts := strings.Split(repo, "/")
+		if len(parts) != 2 {
+			log.Fatal("Invalid repository format. Use 'owner/repo'")
+		}
+		owner, repoName := parts[0], parts[1]
+
+		age, err := parseAge(sinceFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		since := time.Now().Add(-age)
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Reporting review latency for %s since %s, format: %s", repo, since.Format(time.DateOnly), format)
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		complete := true
+		latencies, err := ui.WithSpinner(ctx, "Fetching pull requests and reviews", func() ([]gh.PRLatency, error) {
+			issues, c, err := gh.SearchPRsCreatedSince(ctx, client, owner, repoName, since)
+			if err != nil {
+				return nil, err
+			}
+			complete = c
+			return gh.FetchPRLatencies(ctx, client, owner, repoName, issues, concurrency)
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !complete {
+			fmt.Fprintf(os.Stderr, "Warning: GitHub search returned only the %d newest pull requests. Use a shorter --since to cover them all.\n", len(latencies))
+		}
+		logger.Debug("Fetched the reviews of %d pull requests", len(latencies))
+
+		report := gh.BuildLatencyReport(latencies, since)
+		switch format {
+		case "json":
+			out, err := prettyPrint(struct {
+				Repo string `json:"repo"`
+				*gh.LatencyReport
+			}{repo, report})
+			if err != nil {
+				log.Fatalf("Failed to format the latency report: %v", err)
+			}
+			fmt.Println(out)
+		case "markdown":
+			printLatencyMarkdown(os.Stdout, repo, report)
+		default:
+			printLatencyTable(os.Stdout, repo, report)
+		}
+	},
+}
+
+// latencySteps are the steps a latency report measures, with their names
+func latencySteps(report *gh.LatencyReport) []struct {
+	name  string
+	stats gh.LatencyStats
+} {
+	return []struct {
+		name  string
+		stats gh.LatencyStats
+	}{
+		{"Open → first review", report.FirstReview},
+		{"Open → merge", report.Merge},
+		{"First review → merge", report.ReviewToMerge},
+	}
+}
+
+// formatLatency formats a latency in hours up to two days and in days after
+func formatLatency(stats gh.LatencyStats, d time.Duration) string {
+	switch {
+	case stats.Count == 0:
+		return "-"
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%.1fh", d.Hours())
+	default:
+		return fmt.Sprintf("%.1fd", d.Hours()/24)
+	}
+}
+
+// printLatencyTable prints a latency report as tables
+func printLatencyTable(out io.Writer, repo string, report *gh.LatencyReport) {
+	fmt.Fprintf(out, "%s, %d pull requests opened since %s\n\n", repo, report.Opened, report.Since.Format(time.DateOnly))
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Step\tPRs\tMedian\tP90\tMean")
+	fmt.Fprintln(w, "----\t---\t------\t---\t----")
+	for _, step := range latencySteps(report) {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", step.name, step.stats.Count,
+			formatLatency(step.stats, step.stats.Median),
+			formatLatency(step.stats, step.stats.P90),
+			formatLatency(step.stats, step.stats.Mean))
+	}
+	w.Flush()
+
+	if len(report.Weeks) == 0 {
+		return
+	}
+	fmt.Fprintln(out, "\nMedians by the week pull requests were opened")
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Week\tOpened\tFirst Review\tMerge\tReview → Merge")
+	fmt.Fprintln(w, "----\t------\t------------\t-----\t--------------")
+	for _, week := range report.Weeks {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", week.Start.Format(time.DateOnly), week.Opened,
+			formatLatency(week.FirstReview, week.FirstReview.Median),
+			formatLatency(week.Merge, week.Merge.Median),
+			formatLatency(week.ReviewToMerge, week.ReviewToMerge.Median))
+	}
+	w.Flush()
+}
+
+// printLatencyMarkdown prints a latency report as Markdown tables, ready to
+// paste into a status report
+func printLatencyMarkdown(out io.Writer, repo string, report *gh.LatencyReport) {
+	fmt.Fprintf(out, "## Review latency of %s\n\n", repo)
+	fmt.Fprintf(out, "%d pull requests opened since %s.\n\n", report.Opened, report.Since.Format(time.DateOnly))
+
+	fmt.Fprintln(out, "| Step | PRs | Median | P90 | Mean |")
+	fmt.Fprintln(out, "| --- | ---: | ---: | ---: | ---: |")
+	for _, step := range latencySteps(report) {
+		fmt.Fprintf(out, "| %s | %d | %s | %s | %s |\n", step.name, step.stats.Count,
+			formatLatency(step.stats, step.stats.Median),
+			formatLatency(step.stats, step.stats.P90),
+			formatLatency(step.stats, step.stats.Mean))
+	}
+
+	if len(report.Weeks) == 0 {
+		return
+	}
+	fmt.Fprintln(out, "\n### Medians by week opened")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "| Week | Opened | First review | Merge | Review → merge |")
+	fmt.Fprintln(out, "| --- | ---: | ---: | ---: | ---: |")
+	for _, week := range report.Weeks {
+		fmt.Fprintf(out, "| %s | %d | %s | %s | %s |\n", week.Start.Format(time.DateOnly), week.Opened,
+			formatLatency(week.FirstReview, week.FirstReview.Median),
+			formatLatency(week.Merge, week.Merge.Median),
+			formatLatency(week.ReviewToMerge, week.ReviewToMerge.Median))
+	}
+}
+
+func init() {
+	metricsCmd.AddCommand(metricsLatencyCmd)
+
+	// Define flags
+	metricsLatencyCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+	metricsLatencyCmd.Flags().String("since", "90d", "How far back to look at opened pull requests, such as 90d or 12w")
+	metricsLatencyCmd.Flags().StringP("format", "f", "table", "Output format (table, json, markdown)")
+	metricsLatencyCmd.Flags().IntP("concurrency", "c", 4, "Number of concurrent GitHub requests")
+}
@@ -0,0 +1,172 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/notify"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// notifyCmd represents the notify command
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Manage notifications",
+	Long: `Notifications are sent to the destinations listed under notifications: in the
+config file, and to the Slack webhook stored with 'ghi auth set --slack-webhook'.
+Supported types are slack, webhook, desktop, email and stdout.`,
+}
+
+// notifyTestCmd represents the notify test command
+var notifyTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a test notification to every configured destination",
+	Run: func(cmd *cobra.Command, args []string) {
+		notifier, err := loadNotifier()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if notifier.Empty() {
+			log.Fatal("No notifications are configured. Add a notifications: section to the config file or use 'ghi auth set --slack-webhook URL'")
+		}
+
+		err = notifier.Notify(cmd.Context(), notify.Message{
+			Title: "ghi test notification",
+			Body:  "Notifications from ghi will be delivered here.",
+		})
+		if err != nil {
+			log.Fatalf("Failed to send test notification: %v", err)
+		}
+		fmt.Printf("Test notification sent to %s\n", notifier.Name())
+	},
+}
+
+// loadNotifier creates a notifier for the destinations in the config file,
+// and the Slack webhook stored with 'ghi auth set --slack-webhook'. It has no
+// destinations when none are configured.
+func loadNotifier() (*notify.Multi, error) {
+	var configs []notify.Config
+	if err := viper.UnmarshalKey("notifications", &configs); err != nil {
+		return nil, fmt.Errorf("invalid notifications config: %w", err)
+	}
+	if webhook := os.Getenv("GHI_SLACK_WEBHOOK"); webhook != "" {
+		configured := slices.ContainsFunc(configs, func(config notify.Config) bool {
+			return config.Type == notify.TypeSlack && config.URL == webhook
+		})
+		if !configured {
+			configs = append(configs, notify.Config{Type: notify.TypeSlack, URL: webhook})
+		}
+	}
+	logger.Debug("Loaded %d notification destinations", len(configs))
+	return notify.NewMulti(configs)
+}
+
+// selectNotifier loads the notifier, keeping only the destinations of the
+// types given with --notify. Every destination is kept when none are given.
+func selectNotifier(types []string) (*notify.Multi, error) {
+	notifier, err := loadNotifier()
+	if err != nil || len(types) == 0 {
+		return notifier, err
+	}
+	selected, err := notifier.Only(types)
+	if err != nil && slices.Contains(types, notify.TypeSlack) {
+		if _, slackErr := notifier.Only([]string{notify.TypeSlack}); slackErr != nil {
+			return nil, fmt.Errorf("no Slack webhook is configured. Use 'ghi auth set --slack-webhook URL' to store one")
+		}
+	}
+	return selected, err
+}
+
+// changedPRsMessage summarizes the pull requests that changed in watch mode
+func changedPRsMessage(changed []*gh.PullRequestData) notify.Message {
+	title := fmt.Sprintf("%d pull requests changed", len(changed))
+	if len(changed) == 1 {
+		title = "1 pull request changed"
+	}
+
+	var lines []string
+	for _, pr := range changed {
+		lines = append(lines, fmt.Sprintf("%s#%d %s (%s)",
+			pr.RepoFullName(), pr.Number(), pr.Title(), pr.State()))
+	}
+
+	msg := notify.Message{Title: title, Body: strings.Join(lines, "\n")}
+	if len(changed) == 1 {
+		msg.URL = changed[0].URL()
+	}
+	return msg
+}
+
+// loadAlertPolicy reads the review latency thresholds under alerts: in the
+// config file, with the overrides of repositories under alerts.repos
+func loadAlertPolicy() (gh.AlertPolicy, error) {
+	policy := gh.AlertPolicy{Repos: make(map[string]gh.AlertThresholds)}
+	var err error
+	if policy.Default, err = parseAlertThresholds("alerts"); err != nil {
+		return policy, err
+	}
+	for repo := range viper.GetStringMap("alerts.repos") {
+		thresholds, err := parseAlertThresholds("alerts.repos." + repo)
+		if err != nil {
+			return policy, err
+		}
+		policy.Repos[strings.ToLower(repo)] = thresholds
+	}
+	logger.Debug("Loaded alert policy: %+v", policy)
+	return policy, nil
+}
+
+// parseAlertThresholds reads the thresholds under a config key
+func parseAlertThresholds(key string) (gh.AlertThresholds, error) {
+	var thresholds gh.AlertThresholds
+	for name, threshold := range map[string]*time.Duration{
+		"no-review":         &thresholds.NoReview,
+		"approved-unmerged": &thresholds.ApprovedUnmerged,
+	} {
+		value := viper.GetString(key + "." + name)
+		if value == "" {
+			continue
+		}
+		age, err := parseAge(value)
+		if err != nil {
+			return thresholds, fmt.Errorf("invalid %s.%s in config: %w", key, name, err)
+		}
+		*threshold = age
+	}
+	return thresholds, nil
+}
+
+// alertsMessage lists the pull requests that have waited longer than the
+// alert thresholds allow
+func alertsMessage(alerts []gh.Alert) notify.Message {
+	title := fmt.Sprintf("%d pull requests are waiting too long", len(alerts))
+	if len(alerts) == 1 {
+		title = "1 pull request is waiting too long"
+	}
+
+	lines := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		lines = append(lines, alert.String())
+	}
+
+	msg := notify.Message{Title: title, Body: strings.Join(lines, "\n")}
+	if len(alerts) == 1 {
+		msg.URL = alerts[0].PR.URL()
+	}
+	return msg
+}
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+	notifyCmd.AddCommand(notifyTestCmd)
+}
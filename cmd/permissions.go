@@ -0,0 +1,79 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/paths"
+	"github.com/spf13/viper"
+)
+
+const (
+	// privateFileMode is the mode for files holding tokens and settings
+	privateFileMode os.FileMode = 0600
+	// privateDirMode is the mode for the directory holding the settings files
+	privateDirMode os.FileMode = 0700
+)
+
+// sensitivePath is a file or directory that shouldn't be readable by other users
+type sensitivePath struct {
+	path string
+	mode os.FileMode
+}
+
+// sensitivePaths returns the ghi settings directory, the env file of every
+// profile and the config file
+func sensitivePaths() []sensitivePath {
+	sensitive := []sensitivePath{
+		{paths.ConfigDir(), privateDirMode},
+		{paths.EnvFile(), privateFileMode},
+		{profilesDir(), privateDirMode},
+	}
+	if names, err := listProfiles(); err == nil {
+		for _, name := range names[1:] {
+			sensitive = append(sensitive, sensitivePath{profileEnvPath(name), privateFileMode})
+		}
+	}
+	if configFile := viper.ConfigFileUsed(); configFile != "" {
+		sensitive = append(sensitive, sensitivePath{configFile, privateFileMode})
+	}
+	return sensitive
+}
+
+// insecurePermissions returns the current mode of path if it can be accessed by
+// group or other users. Missing files and platforms without Unix permissions
+// are never reported.
+func insecurePermissions(path string) (os.FileMode, bool) {
+	if runtime.GOOS == "windows" {
+		return 0, false
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, false
+	}
+	mode := info.Mode().Perm()
+	return mode, mode&0077 != 0
+}
+
+// fixInsecurePermissions makes each sensitive file other users can read private,
+// warning about what it changed. Files it can't change are left for 'ghi auth harden'.
+func fixInsecurePermissions() {
+	for _, p := range sensitivePaths() {
+		mode, insecure := insecurePermissions(p.path)
+		if !insecure {
+			continue
+		}
+		if err := os.Chmod(p.path, p.mode); err != nil {
+			logger.Debug("Failed to change permissions of %s: %v", p.path, err)
+			fmt.Fprintf(os.Stderr, "Warning: %s is accessible by other users (mode %04o). Run 'ghi auth harden' to fix it.\n", p.path, mode)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %s was accessible by other users (mode %04o), changed it to %04o.\n", p.path, mode, p.mode)
+	}
+}
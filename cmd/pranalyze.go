@@ -0,0 +1,96 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/locale"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// analyzeCmd represents the pr analyze command
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Analyze the size of a pull request and suggest how to split it",
+	Long: `The 'analyze' command reports how big a pull request is and how its changes
+are spread out: the lines changed in each top-level directory, and how many are
+in binary, vendored and generated files such as lockfiles and generated code.
+The effective size leaves those files out. Files marked linguist-generated or
+linguist-vendored in .gitattributes are recognized too.
+
+For large pull requests, it suggests split points: the areas, such as
+directories and documentation, that could each be reviewed as their own pull
+request.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, owner, repoName, number := prTarget(cmd)
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Analyzing %s #%d", repo, number)
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		analysis, err := gh.AnalyzePR(ctx, client, owner, repoName, number)
+		if err != nil {
+			log.Fatal(err)
+		}
+		pr := analysis.PullRequest
+
+		fmt.Printf("%s #%d: %s\n", repo, number, pr.GetTitle())
+		fmt.Printf("Size: %s (+%s -%s in %s files)\n", gh.SizeOf(analysis.Lines()),
+			locale.Number(pr.GetAdditions()), locale.Number(pr.GetDeletions()), locale.Number(pr.GetChangedFiles()))
+		for _, group := range analysis.Excluded {
+			fmt.Printf("Excluded: %d %s, %d lines\n", group.Files, group.Name, group.Lines())
+		}
+		if len(analysis.Excluded) > 0 {
+			fmt.Printf("Effective size: %s (%d lines, %.0f%% of the changes are excluded)\n",
+				gh.SizeOf(analysis.EffectiveLines()), analysis.EffectiveLines(), analysis.ExcludedRatio()*100)
+		}
+		if len(analysis.Files) < pr.GetChangedFiles() {
+			fmt.Fprintf(os.Stderr, "Warning: only %d of %d files could be listed\n", len(analysis.Files), pr.GetChangedFiles())
+		}
+		fmt.Println()
+
+		fmt.Printf("Directories touched: %d\n\n", len(analysis.Directories))
+		printFileGroups(analysis.Directories)
+
+		if len(analysis.SplitPoints) == 0 {
+			fmt.Println("\nNo split needed: the pull request is small or focused on one area")
+			return
+		}
+		fmt.Println("\nSuggested split points:")
+		for i, group := range analysis.SplitPoints {
+			fmt.Printf("  %d. %s (%d files, %d lines)\n", i+1, group.Name, group.Files, group.Lines())
+		}
+	},
+}
+
+// printFileGroups prints the lines changed in each group of files
+func printFileGroups(groups []gh.FileGroup) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Directory\tFiles\tAdded\tDeleted")
+	fmt.Fprintln(w, "---------\t-----\t-----\t-------")
+	for _, group := range groups {
+		fmt.Fprintf(w, "%s\t%s\t+%s\t-%s\n", group.Name, locale.Number(group.Files), locale.Number(group.Additions), locale.Number(group.Deletions))
+	}
+	w.Flush()
+}
+
+func init() {
+	prCmd.AddCommand(analyzeCmd)
+
+	// Define flags
+	analyzeCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+	analyzeCmd.Flags().IntP("number", "n", 0, "The number of the pull request")
+}
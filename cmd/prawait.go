@@ -0,0 +1,219 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/notify"
+	"github.com/spf13/cobra"
+)
+
+// Exit statuses of the pr await command. Errors exit with status 1.
+const (
+	awaitReady    = 0
+	awaitMerged   = 2
+	awaitClosed   = 3
+	awaitTimedOut = 4
+)
+
+// awaitMinQuota is the core API quota under which await waits for the rate
+// limit to reset instead of polling. A poll takes about five requests.
+const awaitMinQuota = 25
+
+// awaitCmd represents the pr await command
+var awaitCmd = &cobra.Command{
+	Use:   "await",
+	Short: "Wait until a pull request can be merged and send a notification",
+	Long: `The 'await' command checks a pull request every --interval until it can be
+merged, using the same rules as 'ghi pr gate': not a draft, the required
+approvals, no requested changes and every check successful. It stops early
+when the pull request is merged or closed.
+
+A notification is then sent to the destinations under notifications: in the
+config file, or only those of the types given with --notify. When few API
+requests remain, polling waits for the rate limit to reset.
+
+The command exits with status 0 when the pull request can be merged, 2 when it
+was merged, 3 when it was closed and 4 when --timeout passed.`,
+	Example: `  ghi pr await -n 123
+  ghi pr await -n 123 --required-approvals 2 --notify slack --timeout 8h`,
+	Run: func(cmd *cobra.Command, args []string) {
+		requiredApprovals, _ := cmd.Flags().GetInt("required-approvals")
+		skipChecks, _ := cmd.Flags().GetBool("skip-checks")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		notifyTypes, _ := cmd.Flags().GetStringSlice("notify")
+
+		repo, owner, repoName, number := prTarget(cmd)
+		if interval < 10*time.Second {
+			log.Fatal("The --interval flag must be at least 10s")
+		}
+		if !cmd.Flags().Changed("required-approvals") {
+			requiredApprovals = -1
+		} else if requiredApprovals < 0 {
+			log.Fatal("The --required-approvals flag can't be negative")
+		}
+		policy := gh.GatePolicy{RequiredApprovals: requiredApprovals, SkipChecks: skipChecks}
+
+		notifier, err := selectNotifier(notifyTypes)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if notifier.Empty() {
+			fmt.Fprintln(os.Stderr, "Warning: no notifications are configured, so only the exit status reports the result")
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Awaiting %s #%d every %v, timeout %v, policy %+v", repo, number, interval, timeout, policy)
+
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		// The timeout ends every wait, including one for the rate limit to reset
+		ctx := cmd.Context()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		var result *gh.GateResult
+		status := ""
+		for polls := 0; ; polls++ {
+			if err := waitForQuota(ctx, client); err != nil {
+				stopAwait(ctx, repo, number, timeout)
+			}
+
+			result, err = gh.EvaluateGate(ctx, client, owner, repoName, number, policy)
+			switch {
+			case ctx.Err() != nil:
+				stopAwait(ctx, repo, number, timeout)
+			case err != nil && polls == 0:
+				log.Fatal(err)
+			case err != nil:
+				// The next poll may succeed, a long wait shouldn't end on a network error
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			case result.Passed || result.Merged || result.Closed:
+				// The notification is sent even when the timeout is about to pass
+				finishAwait(cmd.Context(), notifier, result)
+			default:
+				if waiting := awaitStatus(result); waiting != status {
+					fmt.Printf("%s Waiting for %s\n", time.Now().Format("15:04"), waiting)
+					status = waiting
+				}
+			}
+
+			if err := sleepAwait(ctx, interval); err != nil {
+				stopAwait(ctx, repo, number, timeout)
+			}
+		}
+	},
+}
+
+// awaitStatus describes the rules a pull request doesn't meet yet
+func awaitStatus(result *gh.GateResult) string {
+	var waiting []string
+	for _, rule := range result.Rules {
+		if !rule.Passed {
+			waiting = append(waiting, fmt.Sprintf("%s (%s)", rule.Name, rule.Reason))
+		}
+	}
+	return strings.Join(waiting, ", ")
+}
+
+// finishAwait reports why waiting ended, sends the notification and exits
+// with the matching status
+func finishAwait(ctx context.Context, notifier *notify.Multi, result *gh.GateResult) {
+	title := fmt.Sprintf("%s #%d can be merged", result.Repo, result.Number)
+	code := awaitReady
+	switch {
+	case result.Merged:
+		title = fmt.Sprintf("%s #%d was merged", result.Repo, result.Number)
+		code = awaitMerged
+	case result.Closed:
+		title = fmt.Sprintf("%s #%d was closed", result.Repo, result.Number)
+		code = awaitClosed
+	}
+
+	if code == awaitReady {
+		fmt.Printf("✅ %s\n", title)
+	} else {
+		fmt.Println(title)
+	}
+	if !notifier.Empty() {
+		err := notifier.Notify(ctx, notify.Message{Title: title, Body: result.Title, URL: result.URL})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to send the notification: %v\n", err)
+		}
+	}
+	os.Exit(code)
+}
+
+// waitForQuota sleeps until the core rate limit resets when too few requests
+// remain to poll, so waiting doesn't use up the quota other commands need. It
+// returns the context's error when the timeout or Ctrl+C ends the wait.
+func waitForQuota(ctx context.Context, client *github.Client) error {
+	remaining, reset, err := gh.CoreQuota(ctx, client)
+	if err != nil {
+		logger.Debug("Could not check the core rate limit: %v", err)
+		return nil
+	}
+	if remaining >= awaitMinQuota {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "Only %d GitHub API requests remain, waiting for the quota to reset at %s\n",
+		remaining, reset.Local().Format("15:04"))
+	return sleepAwait(ctx, time.Until(reset)+time.Second)
+}
+
+// sleepAwait waits for d between polls. It returns the context's error when
+// the timeout or Ctrl+C ends the wait first.
+func sleepAwait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// stopAwait exits when waiting ends before the pull request can be merged:
+// with the timed out status once --timeout passed, and otherwise because the
+// user stopped waiting with Ctrl+C
+func stopAwait(ctx context.Context, repo string, number int, timeout time.Duration) {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		fmt.Printf("%s #%d still can't be merged after %v\n", repo, number, timeout)
+		os.Exit(awaitTimedOut)
+	}
+	fmt.Fprintln(os.Stderr, "Stopped waiting")
+	os.Exit(1)
+}
+
+func init() {
+	prCmd.AddCommand(awaitCmd)
+
+	// Define flags
+	awaitCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+	awaitCmd.Flags().IntP("number", "n", 0, "The number of the pull request")
+	awaitCmd.Flags().Int("required-approvals", 0, "Minimum number of approvals (default from the base branch protection)")
+	awaitCmd.Flags().Bool("skip-checks", false, "Don't wait for check runs and commit statuses to succeed")
+	awaitCmd.Flags().Duration("interval", time.Minute, "How often to check the pull request")
+	awaitCmd.Flags().Duration("timeout", 0, "Stop waiting after this long, such as 8h (default no limit)")
+	awaitCmd.Flags().StringSlice("notify", []string{}, "Notify only the destinations of these types, such as slack")
+}
@@ -0,0 +1,197 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// compareMarker marks the pull requests that are in both queries
+const compareMarker = "="
+
+// prCompareCmd represents the pr compare command
+var prCompareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "Compare the pull requests of two saved views",
+	Long: `The 'compare' command runs two saved views from the views: section of the
+config file and lists their pull requests side by side, marking those in both
+with =. Use it to compare "needs my review" with "assigned to me", or the
+queues of two teams.
+
+With --diff, the pull requests are grouped instead: those only in the first
+view, those only in the second and those in both.`,
+	Example: `  ghi pr compare --query team-a --query team-b
+  ghi pr compare --query needs-my-review --query mine --diff`,
+	Run: func(cmd *cobra.Command, args []string) {
+		configFile, _ := cmd.Flags().GetString("config")
+		if configFile != "" {
+			viper.SetConfigFile(configFile)
+			if err := viper.ReadInConfig(); err != nil {
+				log.Fatalf("Error reading config file: %v", err)
+			}
+		}
+
+		queries, _ := cmd.Flags().GetStringArray("query")
+		diff, _ := cmd.Flags().GetBool("diff")
+		if len(queries) != 2 {
+			log.Fatal("Give exactly two views to compare with --query")
+		}
+
+		// The views hold the flags, the pr defaults apply to those they don't set
+		keys := append([]string{"include", "exclude", "concurrency", "verify-commits", "unverified-only"}, viewKeys...)
+		for _, key := range keys {
+			viper.BindPFlag(key, prCmd.Flags().Lookup(key))
+		}
+		base := currentViewSettings()
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Comparing views %v, diff: %v", queries, diff)
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		var results [2][]*gh.PullRequestData
+		for i, name := range queries {
+			restoreViewSettings(base)
+			if err := switchView(name); err != nil {
+				log.Fatal(err)
+			}
+
+			q := newPRQuery(ctx, prCmd)
+			q.client = client
+			if err := q.configure(); err != nil {
+				log.Fatalf("View %s: %v", name, err)
+			}
+			if err := q.resolveTargets(); err != nil {
+				log.Fatalf("View %s: %v", name, err)
+			}
+			items, err := ui.WithSpinner(ctx, "Fetching pull requests for "+name, q.load)
+			if err != nil {
+				log.Fatalf("View %s: %v", name, err)
+			}
+			if q.usedFallback {
+				fmt.Fprintln(os.Stderr, "Warning: GitHub search rate limit exceeded. Results were listed without search and filtered locally.")
+			}
+			logger.Debug("View %s has %d pull requests", name, len(items))
+			results[i] = items
+		}
+
+		if diff {
+			printPRDiff(queries, results)
+		} else {
+			printPRSideBySide(queries, results)
+		}
+	},
+}
+
+// comparedPRKeys returns the keys of the pull requests in a query result
+func comparedPRKeys(items []*gh.PullRequestData) map[string]bool {
+	keys := make(map[string]bool, len(items))
+	for _, pr := range items {
+		keys[comparedPRKey(pr)] = true
+	}
+	return keys
+}
+
+// comparedPRKey identifies a pull request across repositories
+func comparedPRKey(pr *gh.PullRequestData) string {
+	return fmt.Sprintf("%s#%d", pr.RepoFullName(), pr.Number())
+}
+
+// comparedPRLine describes a pull request in a comparison
+func comparedPRLine(pr *gh.PullRequestData) string {
+	return fmt.Sprintf("%s #%d %s", pr.RepoFullName(), pr.Number(), truncateTitle(pr.Title(), 40))
+}
+
+// printPRSideBySide lists the pull requests of each query in a column,
+// marking those that are in both
+func printPRSideBySide(names []string, results [2][]*gh.PullRequestData) {
+	inOther := [2]map[string]bool{comparedPRKeys(results[1]), comparedPRKeys(results[0])}
+	columns := [2][]string{}
+	for i, items := range results {
+		for _, pr := range items {
+			marker := " "
+			if inOther[i][comparedPRKey(pr)] {
+				marker = compareMarker
+			}
+			columns[i] = append(columns[i], marker+" "+comparedPRLine(pr))
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	left := fmt.Sprintf("%s (%d)", names[0], len(results[0]))
+	right := fmt.Sprintf("%s (%d)", names[1], len(results[1]))
+	fmt.Fprintf(w, "%s\t%s\n", left, right)
+	fmt.Fprintf(w, "%s\t%s\n", strings.Repeat("-", len(left)), strings.Repeat("-", len(right)))
+	for row := 0; row < max(len(columns[0]), len(columns[1])); row++ {
+		var cells [2]string
+		for i := range columns {
+			if row < len(columns[i]) {
+				cells[i] = columns[i][row]
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\n", cells[0], cells[1])
+	}
+	w.Flush()
+}
+
+// printPRDiff lists the pull requests only in the first query, only in the
+// second and in both
+func printPRDiff(names []string, results [2][]*gh.PullRequestData) {
+	first, second := comparedPRKeys(results[0]), comparedPRKeys(results[1])
+	var onlyFirst, onlySecond, both []*gh.PullRequestData
+	for _, pr := range results[0] {
+		if second[comparedPRKey(pr)] {
+			both = append(both, pr)
+		} else {
+			onlyFirst = append(onlyFirst, pr)
+		}
+	}
+	for _, pr := range results[1] {
+		if !first[comparedPRKey(pr)] {
+			onlySecond = append(onlySecond, pr)
+		}
+	}
+
+	sections := []struct {
+		title string
+		items []*gh.PullRequestData
+	}{
+		{"Only in " + names[0], onlyFirst},
+		{"Only in " + names[1], onlySecond},
+		{"In both", both},
+	}
+	for i, section := range sections {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (%d)\n", section.title, len(section.items))
+		for _, pr := range section.items {
+			fmt.Println("  " + comparedPRLine(pr))
+		}
+	}
+}
+
+func init() {
+	prCmd.AddCommand(prCompareCmd)
+
+	// Define flags
+	prCompareCmd.Flags().StringArray("query", []string{}, "A saved view to compare. Give it twice")
+	prCompareCmd.Flags().Bool("diff", false, "Group the pull requests by the views they're in instead of listing them side by side")
+	prCompareCmd.Flags().StringP("config", "c", "", "Path to the configuration file")
+}
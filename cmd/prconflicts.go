@@ -0,0 +1,197 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/locale"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// conflictsCmd represents the pr conflicts command
+var conflictsCmd = &cobra.Command{
+	Use:   "conflicts",
+	Short: "Triage the open pull requests that have merge conflicts",
+	Long: `The 'conflicts' command lists the open pull requests with merge conflicts,
+oldest first, so they can be chased in one pass. In the interactive table:
+
+  c  posts a comment asking the author to resolve the conflicts
+  u  asks GitHub to update the branch with its base branch
+  o  opens the pull request in your browser
+
+Comments and branch updates are confirmed before they're sent. GitHub can only
+update branches whose conflicts it can resolve, the others are left to the
+author.
+
+The comment is a Go template, set with --comment or conflict-comment in the
+config file. It can use {{.Author}}, {{.Repo}}, {{.Number}}, {{.Title}},
+{{.Base}}, {{.Head}}, {{.URL}} and {{.Age}}.
+
+When the output isn't a terminal, a plain table is printed instead.`,
+	Example: `  ghi pr conflicts
+  ghi pr conflicts --group backend
+  ghi pr conflicts -r octocat/Hello-World --comment "@{{.Author}} please rebase onto {{.Base}}"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoFlags, _ := cmd.Flags().GetStringSlice("repo")
+		group, _ := cmd.Flags().GetString("group")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+		viper.BindPFlag("conflict-comment", cmd.Flags().Lookup("comment"))
+		comment, err := gh.ParseConflictComment(viper.GetString("conflict-comment"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		if concurrency < 1 {
+			log.Fatal("The --concurrency flag must be at least 1")
+		}
+		repos, err := resolvePRRepos(repoFlags, group)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Listing conflicts in %v", repos)
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		prs, err := ui.WithSpinner(ctx, "Checking pull requests for conflicts", func() ([]*gh.PullRequestData, error) {
+			return gh.CollectConflicts(ctx, client, repos, concurrency, viper.GetBool("debug"))
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if !term.IsTerminal(os.Stdout.Fd()) {
+			printConflicts(prs)
+			return
+		}
+		if len(prs) == 0 {
+			fmt.Println("✅ No open pull requests have merge conflicts")
+			return
+		}
+
+		p := tea.NewProgram(newConflictsTable(ctx, client, comment, prs), tea.WithAltScreen())
+		if _, err := p.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running conflicts table: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// printConflicts prints the pull requests with conflicts as a plain table
+func printConflicts(prs []*gh.PullRequestData) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Repository\tPR\tAuthor\tOpened\tBase\tTitle\tURL")
+	fmt.Fprintln(w, "----------\t--\t------\t------\t----\t-----\t---")
+	for _, pr := range prs {
+		notice := gh.NewConflictNotice(pr)
+		fmt.Fprintf(w, "%s\t#%d\t%s\t%s\t%s\t%s\t%s\n",
+			notice.Repo,
+			notice.Number,
+			notice.Author,
+			locale.DaysAgo(pr.CreatedAt()),
+			notice.Base,
+			truncateTitle(notice.Title, 50),
+			notice.URL)
+	}
+	w.Flush()
+}
+
+// newConflictsTable creates the interactive table of pull requests with
+// conflicts, with keys to comment on them and update their branches
+func newConflictsTable(ctx context.Context, client *github.Client, comment *template.Template, prs []*gh.PullRequestData) *ui.ListTableModel {
+	columns := []ui.ListColumn{
+		{Title: "Repository", Width: 30},
+		{Title: "PR", Width: 8},
+		{Title: "Author", Width: 16},
+		{Title: "Opened", Width: 14},
+		{Title: "Base", Width: 16},
+		{Title: "Title", Width: 50},
+	}
+	rows := make([]ui.ListRow, 0, len(prs))
+	for _, pr := range prs {
+		notice := gh.NewConflictNotice(pr)
+		rows = append(rows, ui.ListRow{
+			Cells: []string{
+				notice.Repo,
+				fmt.Sprintf("#%d", notice.Number),
+				notice.Author,
+				locale.DaysAgo(pr.CreatedAt()),
+				notice.Base,
+				notice.Title,
+			},
+			URL: notice.URL,
+			// Relative dates don't sort as text
+			SortKeys: []string{3: pr.CreatedAt().UTC().Format(time.RFC3339)},
+			Item:     pr,
+		})
+	}
+
+	describe := func(row ui.ListRow) string {
+		pr := row.Item.(*gh.PullRequestData)
+		return fmt.Sprintf("%s#%d", pr.RepoFullName(), pr.Number())
+	}
+	return ui.NewListTable(columns, rows).WithActions(
+		ui.ListAction{
+			Key:  "c",
+			Help: "Comment",
+			Confirm: func(row ui.ListRow) string {
+				return fmt.Sprintf("Ask the author of %s to resolve the conflicts?", describe(row))
+			},
+			Run: func(row ui.ListRow) (string, error) {
+				pr := row.Item.(*gh.PullRequestData)
+				body, err := gh.RenderConflictComment(comment, pr)
+				if err != nil {
+					return "", err
+				}
+				if _, err := gh.AddComment(ctx, client, pr.Owner, pr.Repo, pr.Number(), body); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("✅ Commented on %s", describe(row)), nil
+			},
+		},
+		ui.ListAction{
+			Key:  "u",
+			Help: "Update branch",
+			Confirm: func(row ui.ListRow) string {
+				return fmt.Sprintf("Merge the base branch into %s?", describe(row))
+			},
+			Run: func(row ui.ListRow) (string, error) {
+				if err := gh.UpdateBranch(ctx, client, row.Item.(*gh.PullRequestData)); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("✅ Requested a branch update for %s", describe(row)), nil
+			},
+		},
+	)
+}
+
+func init() {
+	prCmd.AddCommand(conflictsCmd)
+
+	// Define flags
+	conflictsCmd.Flags().StringSliceP("repo", "r", []string{}, "The Github repositories to check (owner/repo, default from git remote)")
+	conflictsCmd.Flags().StringP("group", "g", "", "Check every repository in a group defined in the config file")
+	conflictsCmd.Flags().IntP("concurrency", "c", 4, "Number of concurrent GitHub requests")
+	conflictsCmd.Flags().String("comment", "", "Go template of the comment asking authors to resolve conflicts (default from the config file)")
+}
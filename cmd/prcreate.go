@@ -0,0 +1,142 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/gitutil"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// createCmd represents the pr create command
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a pull request",
+	Long: `The 'create' command opens a pull request from a branch that has been pushed
+to GitHub, by default the current branch, into the repository's default branch.
+
+The description is written in your editor, pre-filled with the repository's
+pull request template and a list of the commits on the branch. Every section of
+the template must have content before the pull request is created, except those
+whose heading is marked "(optional)". Use --no-validate to skip the check.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoFlag, _ := cmd.Flags().GetString("repo")
+		title, _ := cmd.Flags().GetString("title")
+		base, _ := cmd.Flags().GetString("base")
+		head, _ := cmd.Flags().GetString("head")
+		draft, _ := cmd.Flags().GetBool("draft")
+		noValidate, _ := cmd.Flags().GetBool("no-validate")
+
+		repo, err := resolveRepo(repoFlag)
+		if err != nil {
+			log.Fatalf("The --repo flag is required when not in a GitHub repository clone: %v", err)
+		}
+		parts := strings.Split(repo, "/")
+		if len(parts) != 2 {
+			log.Fatal("Invalid repository format. Use 'owner/repo'")
+		}
+		owner, repoName := parts[0], parts[1]
+
+		if head == "" {
+			dir, err := os.Getwd()
+			if err != nil {
+				log.Fatalf("Could not determine working directory: %v", err)
+			}
+			if head, err = gitutil.CurrentBranch(dir); err != nil {
+				log.Fatalf("The --head flag is required when the current branch can't be detected: %v", err)
+			}
+		}
+
+		logger.Debug("Command arguments: %v", args)
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		if base == "" {
+			repository, _, err := client.Repositories.Get(ctx, owner, repoName)
+			if err != nil {
+				log.Fatalf("Error fetching repository %s: %v", repo, err)
+			}
+			base = repository.GetDefaultBranch()
+		}
+		logger.Debug("Creating pull request in %s from %s into %s, draft: %v", repo, head, base, draft)
+
+		summaries, err := gh.CommitSummaries(ctx, client, owner, repoName, base, head)
+		if err != nil {
+			log.Fatalf("%v. Push the branch before creating a pull request", err)
+		}
+		if len(summaries) == 0 {
+			log.Fatalf("%s has no commits that aren't on %s", head, base)
+		}
+		template, err := gh.FetchPRTemplate(ctx, client, owner, repoName, base)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if title == "" {
+			title = summaries[0]
+			if len(summaries) > 1 {
+				title = head
+			}
+		}
+
+		body := gh.ScaffoldPRBody(template, summaries)
+		for {
+			if body, err = editText(body); err != nil {
+				log.Fatal(err)
+			}
+			if noValidate || template == "" {
+				break
+			}
+
+			missing := gh.MissingSections(template, body)
+			if len(missing) == 0 {
+				break
+			}
+			fmt.Fprintln(os.Stderr, "These sections of the pull request template need content:")
+			for _, section := range missing {
+				fmt.Fprintf(os.Stderr, "  - %s\n", section)
+			}
+			if !confirm(ctx, "Edit the description again?") {
+				log.Fatal("Pull request not created")
+			}
+		}
+
+		pr, err := gh.CreatePullRequest(ctx, client, owner, repoName, &github.NewPullRequest{
+			Title: github.Ptr(title),
+			Head:  github.Ptr(head),
+			Base:  github.Ptr(base),
+			Body:  github.Ptr(body),
+			Draft: github.Ptr(draft),
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		logger.Debug("Created pull request #%d", pr.GetNumber())
+		fmt.Printf("✅ Created %s #%d: %s\n%s\n", repo, pr.GetNumber(), pr.GetTitle(), pr.GetHTMLURL())
+	},
+}
+
+func init() {
+	prCmd.AddCommand(createCmd)
+
+	// Define flags
+	createCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+	createCmd.Flags().StringP("title", "t", "", "The title of the pull request (default the commit summary, or the branch name for several commits)")
+	createCmd.Flags().StringP("base", "B", "", "The branch to merge into (default the repository's default branch)")
+	createCmd.Flags().StringP("head", "H", "", "The branch with the changes, or owner:branch for a fork (default the current branch)")
+	createCmd.Flags().Bool("draft", false, "Create the pull request as a draft")
+	createCmd.Flags().Bool("no-validate", false, "Create the pull request even when sections of the template are empty")
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// dcoCmd represents the pr dco command
+var dcoCmd = &cobra.Command{
+	Use:   "dco",
+	Short: "Check the DCO sign-off of every commit in a pull request",
+	Long: `The 'dco' command checks that every commit of a pull request has a
+Signed-off-by trailer from its author, as required by the Developer Certificate
+of Origin. Merge commits are skipped.
+
+With --comment, a comment explaining how to sign off the commits is posted on
+the pull request when any are missing a sign-off. The command exits with status
+1 when the check fails, so it can be used in scripts.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		postComment, _ := cmd.Flags().GetBool("comment")
+		repo, owner, repoName, number := prTarget(cmd)
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Checking DCO sign-offs of %s #%d, comment: %v", repo, number, postComment)
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		commits, err := gh.ListPRCommits(ctx, client, owner, repoName, number)
+		if err != nil {
+			log.Fatal(err)
+		}
+		problems := gh.CheckDCO(commits)
+
+		failed := make(map[string]string)
+		for _, problem := range problems {
+			failed[problem.Commit.GetSHA()] = problem.Reason
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Commit\tAuthor\tSign-off\tMessage")
+		fmt.Fprintln(w, "------\t------\t--------\t-------")
+		for _, commit := range commits {
+			status := "✓"
+			switch {
+			case len(commit.Parents) > 1:
+				status = "merge, skipped"
+			case failed[commit.GetSHA()] != "":
+				status = "✗ " + failed[commit.GetSHA()]
+			}
+			subject, _, _ := strings.Cut(commit.GetCommit().GetMessage(), "\n")
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+				shortSHA(commit.GetSHA()),
+				commit.GetCommit().GetAuthor().GetName(),
+				status,
+				truncateTitle(subject, 50))
+		}
+		w.Flush()
+		fmt.Println()
+
+		if len(problems) == 0 {
+			fmt.Printf("✅ All %d commits of %s #%d are signed off\n", len(commits), repo, number)
+			return
+		}
+		fmt.Printf("%d of %d commits of %s #%d are not signed off\n", len(problems), len(commits), repo, number)
+
+		if postComment {
+			comment, err := gh.AddComment(ctx, client, owner, repoName, number, gh.DCORemediation(problems, len(commits)))
+			if err != nil {
+				log.Fatal(err)
+			}
+			logger.Debug("Posted DCO comment %d", comment.GetID())
+			fmt.Printf("✅ Posted instructions for signing off\n%s\n", comment.GetHTMLURL())
+		}
+		os.Exit(1)
+	},
+}
+
+func init() {
+	prCmd.AddCommand(dcoCmd)
+
+	// Define flags
+	dcoCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+	dcoCmd.Flags().IntP("number", "n", 0, "The number of the pull request")
+	dcoCmd.Flags().Bool("comment", false, "Post a comment explaining how to sign off the commits when the check fails")
+}
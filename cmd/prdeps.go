@@ -0,0 +1,102 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// depsCmd represents the pr deps command
+var depsCmd = &cobra.Command{
+	Use:   "deps",
+	Short: "Show the dependency tree of a pull request",
+	Long: `The 'deps' command prints the pull requests and issues a pull request depends
+on, with the state of each. Dependencies are references that follow "depends on"
+or "blocked by" in a pull request's description, such as "depends on #123" or
+"blocked by owner/repo#45". Dependencies of dependencies are followed up to --depth levels.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		depth, _ := cmd.Flags().GetInt("depth")
+		repo, owner, repoName, number := prTarget(cmd)
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Fetching dependency tree of %s #%d, depth %d", repo, number, depth)
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		root, err := gh.FetchDependencyTree(ctx, client, gh.PRRef{Owner: owner, Repo: repoName, Number: number}, depth)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(formatDependencyNode(root, owner, repoName))
+		if len(root.Dependencies) == 0 {
+			fmt.Println("No dependencies found")
+			return
+		}
+		printDependencyTree(root.Dependencies, "", owner, repoName)
+	},
+}
+
+// printDependencyTree prints dependency nodes as a tree below their parent
+func printDependencyTree(nodes []*gh.DependencyNode, indent, owner, repo string) {
+	for i, node := range nodes {
+		branch, childIndent := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, childIndent = "└── ", "    "
+		}
+		fmt.Println(indent + branch + formatDependencyNode(node, owner, repo))
+		printDependencyTree(node.Dependencies, indent+childIndent, owner, repo)
+	}
+}
+
+// formatDependencyNode describes a node, leaving out the repository when it's
+// the same as the pull request the tree starts from
+func formatDependencyNode(node *gh.DependencyNode, owner, repo string) string {
+	ref := node.Ref.String()
+	if node.Ref.Owner == owner && node.Ref.Repo == repo {
+		ref = fmt.Sprintf("#%d", node.Ref.Number)
+	}
+
+	switch {
+	case node.Cycle:
+		return ref + " (circular dependency)"
+	case node.Err != nil:
+		return fmt.Sprintf("%s (could not be fetched: %v)", ref, node.Err)
+	}
+
+	line := fmt.Sprintf("%s %s (%s)", ref, node.Title, node.State)
+	if node.Open() && hasOpenDependency(node) {
+		line += " BLOCKED"
+	}
+	return line
+}
+
+// hasOpenDependency reports whether any direct dependency of the node is still open
+func hasOpenDependency(node *gh.DependencyNode) bool {
+	for _, dep := range node.Dependencies {
+		if dep.Open() {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	prCmd.AddCommand(depsCmd)
+
+	// Define flags
+	depsCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+	depsCmd.Flags().IntP("number", "n", 0, "The number of the pull request")
+	depsCmd.Flags().Int("depth", 5, "How many levels of dependencies to follow")
+}
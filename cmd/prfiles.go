@@ -0,0 +1,89 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// filesCmd represents the pr files command
+var filesCmd = &cobra.Command{
+	Use:   "files",
+	Short: "List the files changed by a pull request",
+	Long: `The 'files' command lists the files a pull request changes with the lines
+added and deleted in each.
+
+Binary files, vendored code and generated files such as lockfiles are collapsed
+into a summary line per kind, and left out of the effective size, so the changes
+that need reviewing stand out. Files marked linguist-generated or
+linguist-vendored in .gitattributes are collapsed too. Use --all to list them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+		repo, owner, repoName, number := prTarget(cmd)
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Listing files of %s #%d, all: %v", repo, number, all)
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		analysis, err := gh.AnalyzePR(ctx, client, owner, repoName, number)
+		if err != nil {
+			log.Fatal(err)
+		}
+		pr := analysis.PullRequest
+
+		fmt.Printf("%s #%d: %s\n\n", repo, number, pr.GetTitle())
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "File\tStatus\tAdded\tDeleted")
+		fmt.Fprintln(w, "----\t------\t-----\t-------")
+		for _, file := range analysis.Files {
+			name := file.GetFilename()
+			kind := analysis.Classifier.Kind(file)
+			if kind != gh.FileKindSource {
+				if !all {
+					continue
+				}
+				name += " (" + kind + ")"
+			}
+			fmt.Fprintf(w, "%s\t%s\t+%d\t-%d\n", name, file.GetStatus(), file.GetAdditions(), file.GetDeletions())
+		}
+		if !all {
+			for _, group := range analysis.Excluded {
+				fmt.Fprintf(w, "%d %s collapsed\t\t+%d\t-%d\n", group.Files, group.Name, group.Additions, group.Deletions)
+			}
+		}
+		w.Flush()
+
+		fmt.Printf("\nSize: %s (%d lines)", gh.SizeOf(analysis.Lines()), analysis.Lines())
+		if len(analysis.Excluded) > 0 {
+			fmt.Printf(", effective size: %s (%d lines)", gh.SizeOf(analysis.EffectiveLines()), analysis.EffectiveLines())
+		}
+		fmt.Println()
+		if len(analysis.Files) < pr.GetChangedFiles() {
+			fmt.Fprintf(os.Stderr, "Warning: only %d of %d files could be listed\n", len(analysis.Files), pr.GetChangedFiles())
+		}
+	},
+}
+
+func init() {
+	prCmd.AddCommand(filesCmd)
+
+	// Define flags
+	filesCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+	filesCmd.Flags().IntP("number", "n", 0, "The number of the pull request")
+	filesCmd.Flags().Bool("all", false, "List binary, vendored and generated files instead of collapsing them")
+}
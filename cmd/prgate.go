@@ -0,0 +1,122 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// gateCmd represents the pr gate command
+var gateCmd = &cobra.Command{
+	Use:   "gate",
+	Short: "Check a pull request against a merge policy for CI pipelines",
+	Long: `The 'gate' command checks a pull request against a merge policy without any
+prompts, so pipelines can block merges that don't meet it:
+
+  open               the pull request isn't merged or closed
+  draft              the pull request isn't a draft
+  approvals          it has the required approvals
+  changes-requested  no reviewer's latest verdict requests changes
+  checks             every check run and commit status of the head commit succeeded
+
+Each rule is printed with PASS or FAIL and its reason, or as JSON with
+--format json. The command exits with status 0 when every rule passes and 1
+when any fails.
+
+Without --required-approvals, the approvals required by the branch protection
+of the base branch are used, which needs admin access to the repository.`,
+	Example: `  ghi pr gate --number 42 --required-approvals 2
+  ghi pr gate -r octocat/Hello-World -n 42 --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		requiredApprovals, _ := cmd.Flags().GetInt("required-approvals")
+		allowDraft, _ := cmd.Flags().GetBool("allow-draft")
+		skipChecks, _ := cmd.Flags().GetBool("skip-checks")
+		format, _ := cmd.Flags().GetString("format")
+
+		if format != "text" && format != "json" {
+			log.Fatalf("Invalid format %q. Use 'text' or 'json'", format)
+		}
+		repo, owner, repoName, number := prTarget(cmd)
+
+		// A negative value falls back to branch protection
+		if !cmd.Flags().Changed("required-approvals") {
+			requiredApprovals = -1
+		} else if requiredApprovals < 0 {
+			log.Fatal("The --required-approvals flag can't be negative")
+		}
+		policy := gh.GatePolicy{
+			RequiredApprovals: requiredApprovals,
+			AllowDraft:        allowDraft,
+			SkipChecks:        skipChecks,
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Checking %s #%d against the gate %+v", repo, number, policy)
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		result, err := gh.EvaluateGate(ctx, client, owner, repoName, number, policy)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if format == "json" {
+			out, err := prettyPrint(result)
+			if err != nil {
+				log.Fatalf("Failed to format the gate result: %v", err)
+			}
+			fmt.Println(out)
+		} else {
+			printGateResult(result)
+		}
+		if !result.Passed {
+			os.Exit(1)
+		}
+	},
+}
+
+// printGateResult prints each rule of the gate with its outcome and a summary
+func printGateResult(result *gh.GateResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, rule := range result.Rules {
+		outcome := "PASS"
+		if !rule.Passed {
+			outcome = "FAIL"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", outcome, rule.Name, rule.Reason)
+	}
+	w.Flush()
+	fmt.Println()
+
+	if result.Passed {
+		fmt.Printf("✅ %s #%d passes the gate\n", result.Repo, result.Number)
+		return
+	}
+	fmt.Printf("%s #%d fails the gate: %s\n", result.Repo, result.Number, strings.Join(result.Failed(), ", "))
+}
+
+func init() {
+	prCmd.AddCommand(gateCmd)
+
+	// Define flags
+	gateCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+	gateCmd.Flags().IntP("number", "n", 0, "The number of the pull request")
+	gateCmd.Flags().Int("required-approvals", 0, "Minimum number of approvals (default from the base branch protection)")
+	gateCmd.Flags().Bool("allow-draft", false, "Let draft pull requests pass the gate")
+	gateCmd.Flags().Bool("skip-checks", false, "Don't require check runs and commit statuses to succeed")
+	gateCmd.Flags().StringP("format", "f", "text", "Output format (text, json)")
+}
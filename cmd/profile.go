@@ -0,0 +1,113 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jbrinkman/ghi/pkg/paths"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// defaultProfile names the settings in the default env file, used when no profile is chosen
+const defaultProfile = "default"
+
+// profileEnvSuffix ends the name of each profile's env file
+const profileEnvSuffix = ".env"
+
+// profileNamePattern limits profile names to those that are safe as file names
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// activeProfile is the profile whose settings are used, or empty for the
+// default settings in the default env file
+var activeProfile string
+
+// profilesDir returns the directory holding the env file of each profile
+func profilesDir() string {
+	return paths.ProfilesDir()
+}
+
+// profileEnvPath returns the env file of a profile
+func profileEnvPath(name string) string {
+	return filepath.Join(profilesDir(), name+profileEnvSuffix)
+}
+
+// resolveProfile returns the profile chosen with --profile, the GHI_PROFILE
+// environment variable or profile in the config file, in that order. It
+// returns an empty name for the default profile.
+func resolveProfile(cmd *cobra.Command) (string, error) {
+	name, _ := cmd.Flags().GetString("profile")
+	if !cmd.Flags().Changed("profile") {
+		name = os.Getenv("GHI_PROFILE")
+	}
+	if name == "" {
+		name = viper.GetString("profile")
+	}
+	if name == "" || name == defaultProfile {
+		return "", nil
+	}
+	if !profileNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid profile name %q, use letters, digits, '-' and '_'", name)
+	}
+	return name, nil
+}
+
+// profileName returns the name of the active profile for display
+func profileName() string {
+	if activeProfile == "" {
+		return defaultProfile
+	}
+	return activeProfile
+}
+
+// listProfiles returns the names of the profiles with an env file, sorted,
+// after the default profile
+func listProfiles() ([]string, error) {
+	entries, err := os.ReadDir(profilesDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), profileEnvSuffix)
+		if ok && !entry.IsDir() && profileNamePattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{defaultProfile}, names...), nil
+}
+
+var authProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List the authentication profiles",
+	Long: `The profiles command lists the profiles created with 'ghi auth set --profile'.
+The active profile is marked with an asterisk. A profile is chosen with
+--profile, the GHI_PROFILE environment variable or profile in the config file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		names, err := listProfiles()
+		if err != nil {
+			log.Fatalf("Error listing profiles: %v", err)
+		}
+		for _, name := range names {
+			marker := " "
+			if name == profileName() {
+				marker = "*"
+			}
+			fmt.Printf("%s %s\n", marker, name)
+		}
+	},
+}
+
+func init() {
+	authCmd.AddCommand(authProfilesCmd)
+}
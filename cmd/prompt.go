@@ -0,0 +1,221 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/storage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// The prompt status is kept in its own storage bucket
+const (
+	promptBucket     = "prompt"
+	promptStatusKey  = "status"
+	promptRefreshKey = "refresh"
+)
+
+// promptKey returns the storage key of a prompt record for the active profile
+// and GitHub host, so switching accounts never shows another account's counts
+func promptKey(name string) string {
+	return name + ":" + profileName() + "@" + clients.Host()
+}
+
+// defaultPromptFormat shows only the counts that aren't zero, such as "👀 3 ✗ 1"
+const defaultPromptFormat = `{{if .ReviewRequests}}👀 {{.ReviewRequests}}{{end}}` +
+	`{{if and .ReviewRequests .FailingPRs}} {{end}}` +
+	`{{if .FailingPRs}}✗ {{.FailingPRs}}{{end}}`
+
+// promptRefreshTimeout bounds a background refresh, so a stuck one doesn't
+// stop the next
+const promptRefreshTimeout = 30 * time.Second
+
+// promptData is what the prompt format template is executed with
+type promptData struct {
+	gh.PromptStatus
+	// Stale is set when the counts are older than --max-age
+	Stale bool
+}
+
+// promptCmd represents the prompt command
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print a short status for shell prompts and status bars",
+	Long: `The 'prompt' command prints the number of pull requests waiting for your
+review and the number of your pull requests with failing checks, such as
+"👀 3 ✗ 1", for embedding in shell prompts and status bars like starship and
+tmux. Counts of zero are left out, so nothing is printed when there's nothing
+to do.
+
+The counts are read from local storage, so the prompt never waits for the
+network. When they're older than --max-age, ghi refreshes them in the
+background for the next prompt. Refreshes are started at most once per
+--max-age, so prompts drawn in quick succession don't use up the search rate
+limit. Until the first refresh finishes, nothing is printed.
+
+The output is a Go template, set with --format or prompt.format in the config
+file. It can use {{.ReviewRequests}}, {{.FailingPRs}}, {{.UpdatedAt}} and
+{{.Stale}}.`,
+	Example: `  ghi prompt
+  ghi prompt --format '{{.ReviewRequests}} reviews'
+
+  # starship.toml
+  [custom.ghi]
+  command = "ghi prompt"
+  when = true
+
+  # .tmux.conf
+  set -g status-right '#(ghi prompt)'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		maxAge, _ := cmd.Flags().GetDuration("max-age")
+		refresh, _ := cmd.Flags().GetBool("refresh")
+
+		viper.BindPFlag("prompt.format", cmd.Flags().Lookup("format"))
+		format := viper.GetString("prompt.format")
+		if format == "" {
+			format = defaultPromptFormat
+		}
+		tmpl, err := template.New("prompt").Parse(format)
+		if err != nil {
+			log.Fatalf("Invalid prompt format: %v", err)
+		}
+
+		logger.Debug("Command arguments: %v", args)
+
+		s, err := storage.Open()
+		if err != nil {
+			log.Fatalf("Error opening storage: %v", err)
+		}
+
+		if refresh {
+			if err := refreshPromptStatus(cmd.Context(), s); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		status, ok := loadPromptStatus(s)
+		stale := !ok || time.Since(status.UpdatedAt) > maxAge
+		if stale && !refresh && promptRefreshDue(s, maxAge) {
+			if err := startPromptRefresh(); err != nil {
+				logger.Debug("Failed to start the prompt refresh: %v", err)
+			}
+		}
+		if !ok {
+			return
+		}
+
+		var b strings.Builder
+		if err := tmpl.Execute(&b, promptData{PromptStatus: status, Stale: stale}); err != nil {
+			log.Fatalf("Error rendering the prompt: %v", err)
+		}
+		if text := b.String(); text != "" {
+			fmt.Println(text)
+		}
+	},
+}
+
+// loadPromptStatus reads the counts saved by the last refresh
+func loadPromptStatus(s storage.Storage) (gh.PromptStatus, bool) {
+	var status gh.PromptStatus
+	data, ok, err := s.Get(promptBucket, promptKey(promptStatusKey))
+	if err != nil || !ok {
+		return status, false
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		logger.Debug("Ignoring an unreadable prompt status: %v", err)
+		return status, false
+	}
+	return status, true
+}
+
+// promptRefreshDue reports whether no refresh was started within maxAge, and
+// records that one is starting now if so
+func promptRefreshDue(s storage.Storage, maxAge time.Duration) bool {
+	if data, ok, err := s.Get(promptBucket, promptKey(promptRefreshKey)); err == nil && ok {
+		started, err := time.Parse(time.RFC3339Nano, string(data))
+		if err == nil && time.Since(started) < maxAge {
+			logger.Debug("A prompt refresh was started at %s", started.Format(time.RFC3339))
+			return false
+		}
+	}
+	if err := s.Put(promptBucket, promptKey(promptRefreshKey), []byte(time.Now().Format(time.RFC3339Nano))); err != nil {
+		logger.Debug("Failed to record the prompt refresh: %v", err)
+		return false
+	}
+	return true
+}
+
+// startPromptRefresh runs 'ghi prompt --refresh' in the background. It
+// outlives this process and its output is discarded. It's given the profile
+// and config file of this process, so it refreshes the counts of the same
+// account, saved under the same promptKey, even when they were chosen with
+// flags.
+func startPromptRefresh() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := []string{"prompt", "--refresh", "--profile", profileName()}
+	if cfgFile != "" {
+		config, err := filepath.Abs(cfgFile)
+		if err != nil {
+			return err
+		}
+		args = append(args, "--config", config)
+	}
+	if viper.GetBool("debug") {
+		args = append(args, "--debug")
+	}
+	refresh := exec.Command(exe, args...)
+	if err := refresh.Start(); err != nil {
+		return err
+	}
+	logger.Debug("Started the prompt refresh as process %d", refresh.Process.Pid)
+	return refresh.Process.Release()
+}
+
+// refreshPromptStatus fetches the counts from GitHub and saves them
+func refreshPromptStatus(ctx context.Context, s storage.Storage) error {
+	ctx, cancel := context.WithTimeout(ctx, promptRefreshTimeout)
+	defer cancel()
+
+	client, err := clients.NewGitHubClient()
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+	status, err := gh.FetchPromptStatus(ctx, client)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	logger.Debug("Refreshed the prompt status: %d review requests, %d failing pull requests", status.ReviewRequests, status.FailingPRs)
+	return s.Put(promptBucket, promptKey(promptStatusKey), data)
+}
+
+func init() {
+	rootCmd.AddCommand(promptCmd)
+
+	// Define flags
+	promptCmd.Flags().String("format", "", "Go template of the output (default from the config file)")
+	promptCmd.Flags().Duration("max-age", 5*time.Minute, "Refresh the counts in the background when they're older than this")
+	promptCmd.Flags().Bool("refresh", false, "Fetch the counts now instead of reading them from storage")
+	promptCmd.Flags().MarkHidden("refresh")
+}
@@ -0,0 +1,49 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/storage"
+)
+
+func TestPromptStatusIsKeptPerProfile(t *testing.T) {
+	s, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := activeProfile
+	t.Cleanup(func() { activeProfile = previous })
+	t.Setenv("GHI_HOST", "")
+
+	activeProfile = "work"
+	data, err := json.Marshal(gh.PromptStatus{ReviewRequests: 3, UpdatedAt: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(promptBucket, promptKey(promptStatusKey), data); err != nil {
+		t.Fatal(err)
+	}
+	if status, ok := loadPromptStatus(s); !ok || status.ReviewRequests != 3 {
+		t.Fatalf("work status = %+v, %v, want 3 review requests", status, ok)
+	}
+
+	activeProfile = "personal"
+	if status, ok := loadPromptStatus(s); ok {
+		t.Errorf("personal profile read the work status %+v", status)
+	}
+	if !promptRefreshDue(s, time.Hour) {
+		t.Error("a refresh of the work profile stopped the personal profile's refresh")
+	}
+
+	activeProfile = "work"
+	t.Setenv("GHI_HOST", "github.example.com")
+	if status, ok := loadPromptStatus(s); ok {
+		t.Errorf("another host read the work status %+v", status)
+	}
+}
@@ -0,0 +1,514 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// repoTarget is a single repository queried by the pr command
+type repoTarget struct {
+	owner string
+	name  string
+}
+
+// prQuery searches and enriches the pull requests for the pr flags. The
+// settings are read from viper, so a query can be configured again after a
+// view changes them.
+type prQuery struct {
+	ctx    context.Context
+	client *github.Client
+	cmd    *cobra.Command
+
+	// The settings that apply to every query the command makes
+	debug       bool
+	watch       bool
+	concurrency int
+	// quiet stops spinners and messages, which would corrupt a table that's shown
+	quiet bool
+	// progress receives how far the enrichment has got, when it's shown
+	progress chan gh.Progress
+	// mergeability waits for the mergeable states GitHub is still computing,
+	// which costs a request per PR, so it's only set when they're shown
+	mergeability bool
+	// dependencies looks up the PRs and issues each PR depends on, a request
+	// per reference, so it's only set when they're shown
+	dependencies bool
+
+	// The settings read by configure
+	org, state, draftOption, maxSize, sortField, pathMode string
+	repos, authors, reviewers, labels, excludeLabels      []string
+	associations, paths                                   []string
+	mine, reviewRequested, noReviews, readyToMerge        bool
+	unverifiedOnly, conflicts, verifyCommits, blocked     bool
+	needsApproval, maxSizeRank                            int
+	createdBefore, updatedBefore, asOf                    time.Time
+
+	// The repositories found by resolveTargets
+	orgRepos  []string
+	targets   []repoTarget
+	username  string
+	teamSlugs map[string][]string
+
+	// usedFallback is set when the search quota ran out and pull requests were listed instead
+	usedFallback bool
+}
+
+// newPRQuery creates a query for the pr flags of a command. The settings are
+// read by configure.
+func newPRQuery(ctx context.Context, cmd *cobra.Command) *prQuery {
+	return &prQuery{
+		ctx:         ctx,
+		cmd:         cmd,
+		debug:       viper.GetBool("debug"),
+		watch:       viper.GetBool("watch"),
+		concurrency: viper.GetInt("concurrency"),
+		teamSlugs:   make(map[string][]string),
+	}
+}
+
+// configure reads the query settings from the pr flags, the config file and
+// any view applied to them
+func (q *prQuery) configure() error {
+	// In organization mode the repositories are listed once the client exists
+	q.org = viper.GetString("org")
+	q.repos = nil
+	if q.org == "" {
+		var err error
+		if q.repos, err = resolvePRRepos(viper.GetStringSlice("repo"), viper.GetString("group")); err != nil {
+			return err
+		}
+	}
+
+	q.authors = viper.GetStringSlice("author")
+	q.state = viper.GetString("state")
+	q.reviewers = viper.GetStringSlice("reviewer")
+	q.draftOption = viper.GetString("draft")
+	q.mine = viper.GetBool("mine")
+	q.reviewRequested = viper.GetBool("review-requested")
+	q.labels = viper.GetStringSlice("label")
+	q.excludeLabels = viper.GetStringSlice("exclude-label")
+	q.noReviews = viper.GetBool("no-reviews")
+	q.needsApproval = viper.GetInt("needs-approval")
+	q.readyToMerge = viper.GetBool("ready-to-merge")
+	q.unverifiedOnly = viper.GetBool("unverified-only")
+	q.conflicts = viper.GetBool("conflicts")
+	q.blocked = viper.GetBool("blocked")
+	q.maxSize = viper.GetString("max-size")
+	q.maxSizeRank = -1
+	if q.maxSize != "" {
+		rank, err := gh.ParseSize(q.maxSize)
+		if err != nil {
+			return err
+		}
+		q.maxSizeRank = rank
+	}
+	q.verifyCommits = viper.GetBool("verify-commits") || q.unverifiedOnly
+	q.sortField = viper.GetString("sort")
+	if q.sortField != "" {
+		if err := gh.ValidateSort(q.sortField); err != nil {
+			return err
+		}
+	}
+	q.createdBefore, q.updatedBefore = time.Time{}, time.Time{}
+	if olderThan := viper.GetString("older-than"); olderThan != "" {
+		age, err := parseAge(olderThan)
+		if err != nil {
+			return err
+		}
+		q.createdBefore = time.Now().Add(-age)
+	}
+	if before := viper.GetString("updated-before"); before != "" {
+		var err error
+		if q.updatedBefore, err = parseBefore(before); err != nil {
+			return err
+		}
+	}
+	q.associations = nil
+	for _, name := range viper.GetStringSlice("association") {
+		association, err := gh.NormalizeAssociation(name)
+		if err != nil {
+			return err
+		}
+		q.associations = append(q.associations, association)
+	}
+	q.paths = viper.GetStringSlice("path")
+	q.pathMode = viper.GetString("path-mode")
+	if q.pathMode != "filter" && q.pathMode != "flag" {
+		return fmt.Errorf("invalid path mode %q. Use 'filter' or 'flag'", q.pathMode)
+	}
+
+	// Organization mode surveys open pull requests unless a state was chosen
+	if q.org != "" && !q.cmd.Flags().Changed("state") && !viper.InConfig("state") {
+		q.state = "open"
+	}
+
+	// The pull requests open as of a past date are listed regardless of
+	// their state now, and filtered locally
+	q.asOf = time.Time{}
+	if asOfFlag, _ := q.cmd.Flags().GetString("as-of"); asOfFlag != "" {
+		switch {
+		case q.watch:
+			return fmt.Errorf("the --as-of flag can't be used with --watch")
+		case q.conflicts:
+			return fmt.Errorf("the --as-of flag can't be used with --conflicts, merge conflicts are only known for now")
+		case q.cmd.Flags().Changed("state"):
+			return fmt.Errorf("the --as-of flag lists the pull requests open at the time, it can't be used with --state")
+		}
+		var err error
+		if q.asOf, err = time.ParseInLocation("2006-01-02T15:04", asOfFlag, time.Local); err != nil {
+			if q.asOf, err = parseBefore(asOfFlag); err != nil {
+				return err
+			}
+		}
+		if q.asOf.After(time.Now()) {
+			return fmt.Errorf("the --as-of flag must be in the past")
+		}
+		q.state = "all"
+	}
+
+	// Convert authors and reviewers to lowercase for case-insensitive comparison
+	for i, author := range q.authors {
+		q.authors[i] = strings.ToLower(author)
+	}
+	for i, reviewer := range q.reviewers {
+		q.reviewers[i] = strings.ToLower(reviewer)
+	}
+	return nil
+}
+
+// spin shows a spinner while a function runs, unless the query is quiet
+func (q *prQuery) spin(message string, fn func() ([]string, error)) ([]string, error) {
+	if q.quiet {
+		return fn()
+	}
+	return ui.WithSpinner(q.ctx, message, fn)
+}
+
+// resolveTargets finds the repositories to query. Group and organization scans
+// skip archived repositories and those listed under exclude-repos in the config file.
+func (q *prQuery) resolveTargets() error {
+	q.orgRepos = nil
+	if q.org != "" {
+		include := viper.GetStringSlice("include")
+		exclude := viper.GetStringSlice("exclude")
+		logger.Debug("Listing repositories in %s, include: %v, exclude: %v", q.org, include, exclude)
+
+		var skips gh.RepoSkips
+		listed, err := q.spin("Listing repositories", func() ([]string, error) {
+			names, s, err := gh.ListOrgRepos(q.ctx, q.client, q.org, include, exclude)
+			skips = s
+			return names, err
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, name := range listed {
+			q.repos = append(q.repos, q.org+"/"+name)
+		}
+		var excluded []string
+		q.repos, excluded = excludeConfiguredRepos(q.repos)
+		for _, repo := range q.repos {
+			q.orgRepos = append(q.orgRepos, strings.TrimPrefix(repo, q.org+"/"))
+		}
+		if !q.quiet {
+			reportSkippedRepos(len(q.repos), skips.Archived, skips.Filtered+len(excluded))
+		}
+		if len(q.orgRepos) == 0 {
+			return fmt.Errorf("no repositories in %s are left to scan after skipping archived and excluded repositories", q.org)
+		}
+
+		// Each repository costs at least one request, and the transport only waits
+		// out short rate limit resets, so warn before starting a scan that can't finish
+		if remaining, reset, err := gh.CoreQuota(q.ctx, q.client); err != nil {
+			logger.Debug("Could not check the core rate limit: %v", err)
+		} else if remaining < len(q.orgRepos) && !q.quiet {
+			fmt.Fprintf(os.Stderr, "Warning: only %d GitHub API requests remain for %d repositories. The quota resets at %s.\n",
+				remaining, len(q.orgRepos), reset.Local().Format("15:04"))
+		}
+	} else if viper.GetString("group") != "" {
+		var excluded, archived []string
+		var err error
+		q.repos, excluded = excludeConfiguredRepos(q.repos)
+		q.repos, err = q.spin("Checking repositories", func() ([]string, error) {
+			var active []string
+			active, archived = gh.FilterArchived(q.ctx, q.client, q.repos, q.concurrency)
+			return active, nil
+		})
+		if err != nil {
+			return err
+		}
+		if !q.quiet {
+			reportSkippedRepos(len(q.repos), len(archived), len(excluded))
+		}
+		if len(q.repos) == 0 {
+			return fmt.Errorf("no repositories are left to scan after skipping archived and excluded repositories")
+		}
+	}
+
+	// Split each repo into owner and repo name
+	q.targets = make([]repoTarget, 0, len(q.repos))
+	for _, repo := range q.repos {
+		parts := strings.Split(repo, "/")
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid repository format %q. Use 'owner/repo'", repo)
+		}
+		q.targets = append(q.targets, repoTarget{owner: parts[0], name: parts[1]})
+	}
+
+	// Resolve the current user for the work queue filters
+	if q.mine || q.reviewRequested {
+		if q.username == "" {
+			q.username = resolveUsername(q.ctx, q.client)
+			logger.Debug("Resolved current user: %s", q.username)
+		}
+
+		// --mine adds you to the authors given with --author, so they can be
+		// combined, such as the pull requests of you and your pair
+		if me := strings.ToLower(q.username); q.mine && !slices.Contains(q.authors, me) {
+			q.authors = append(q.authors, me)
+		}
+		if q.reviewRequested {
+			// Team membership is optional, the token may lack the read:org scope
+			for _, target := range q.targets {
+				if _, ok := q.teamSlugs[target.owner]; ok {
+					continue
+				}
+				slugs, err := gh.UserTeamSlugs(q.ctx, q.client, target.owner)
+				if err != nil {
+					logger.Debug("Could not resolve team review requests in %s: %v", target.owner, err)
+				}
+				q.teamSlugs[target.owner] = slugs
+			}
+		}
+	}
+	return nil
+}
+
+// scan searches the pull requests of a repository, listing them instead when
+// search is rate limited. The client's transport returns search rate limits
+// without waiting, so the fallback isn't delayed.
+func (q *prQuery) scan(target repoTarget) ([]*github.Issue, error) {
+	// Construct the search query
+	query := fmt.Sprintf("repo:%s/%s", target.owner, target.name)
+	if q.state != "" && q.state != "all" {
+		query += fmt.Sprintf(" state:%s", q.state)
+	}
+	for _, author := range q.authors {
+		query += fmt.Sprintf(" author:%s", author)
+	}
+	if q.reviewRequested {
+		query += fmt.Sprintf(" review-requested:%s", q.username)
+	}
+	for _, label := range q.labels {
+		query += fmt.Sprintf(" label:%q", label)
+	}
+	for _, label := range q.excludeLabels {
+		query += fmt.Sprintf(" -label:%q", label)
+	}
+	// Search works in days, the exact times are filtered locally
+	if !q.createdBefore.IsZero() {
+		query += " created:<=" + q.createdBefore.Format(time.DateOnly)
+	}
+	if !q.updatedBefore.IsZero() {
+		query += " updated:<=" + q.updatedBefore.Format(time.DateOnly)
+	}
+	query += " type:pr" // Ensure only pull requests are returned
+
+	// Search can't combine open PRs with those closed since a date,
+	// so those open as of a past date take a query for each
+	queries := []string{query}
+	if !q.asOf.IsZero() {
+		day := q.asOf.Format(time.DateOnly)
+		queries = []string{
+			query + " is:open created:<=" + day,
+			query + " is:closed created:<=" + day + " closed:>=" + day,
+		}
+	}
+
+	var issues []*github.Issue
+	var err error
+	for _, search := range queries {
+		logger.Debug("Search query: %s", search)
+		var result *github.IssuesSearchResult
+		if result, _, err = q.client.Search.Issues(q.ctx, search, &github.SearchOptions{}); err != nil {
+			break
+		}
+		issues = append(issues, result.Issues...)
+	}
+	if err == nil {
+		return issues, nil
+	}
+
+	if !isRateLimit(err) {
+		return nil, fmt.Errorf("error searching pull requests in %s/%s: %w", target.owner, target.name, err)
+	}
+
+	// The search quota is tracked separately from the core quota,
+	// so fall back to listing pull requests if the core API is available
+	if !gh.CoreQuotaAvailable(q.ctx, q.client) {
+		return nil, fmt.Errorf("GitHub API rate limit exceeded. Try setting GHI_GITHUB_TOKEN environment variable")
+	}
+
+	logger.Debug("Search quota exhausted, falling back to listing pull requests")
+	issues, err = gh.ListPullRequestIssues(q.ctx, q.client, target.owner, target.name, q.state, q.authors)
+	if err != nil {
+		return nil, err
+	}
+	q.usedFallback = true
+	return issues, nil
+}
+
+// isRateLimit reports whether err is a primary or secondary GitHub rate limit
+func isRateLimit(err error) bool {
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	return errors.As(err, &rateErr) || errors.As(err, &abuseErr)
+}
+
+// scanAll searches the pull requests of every repository
+func (q *prQuery) scanAll() ([][]*github.Issue, error) {
+	// Listing every repository concurrently keeps the search quota
+	// for normal use, it's far too small to cover an organization
+	if q.org != "" {
+		return gh.ListOrgPullRequestIssues(q.ctx, q.client, q.org, q.orgRepos, q.state, q.authors, q.concurrency)
+	}
+
+	results := make([][]*github.Issue, len(q.targets))
+	for i, target := range q.targets {
+		issues, err := q.scan(target)
+		if err != nil && i > 0 && ui.Interrupted(q.ctx) {
+			// Keep the repositories searched before Ctrl+C
+			logger.Debug("Interrupted after searching %d of %d repositories", i, len(q.targets))
+			return results, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		results[i] = issues
+	}
+	return results, nil
+}
+
+// process enriches and filters the pull requests found by scanAll. Results from
+// every repository are merged into a single collection.
+func (q *prQuery) process(results [][]*github.Issue) ([]*gh.PullRequestData, error) {
+	logger.Debug("Creating new PR collection for %v", q.repos)
+	collection := gh.NewPRCollection(q.ctx, q.client, q.debug)
+	collection.WithDraftOption(q.draftOption).WithConcurrency(q.concurrency).WithAsOf(q.asOf).WithProgress(q.progress)
+
+	// Process the data in a pipeline
+	for i, target := range q.targets {
+		logger.Debug("Fetching issues from %s/%s (count: %d)", target.owner, target.name, len(results[i]))
+		collection.FetchIssues(target.owner, target.name, results[i])
+	}
+	collection.FilterOpenAsOf()
+	// The association comes with the issue, so filter before fetching more
+	collection.FilterAssociations(q.associations)
+	if !q.createdBefore.IsZero() {
+		collection.FilterCreatedBefore(q.createdBefore)
+	}
+	if !q.updatedBefore.IsZero() {
+		collection.FilterUpdatedBefore(q.updatedBefore)
+	}
+	logger.Debug("Enriching with pull requests")
+	collection.EnrichWithPullRequests()
+	collection.EnrichWithStacks()
+	// Sizes come with the pull request, so filter before fetching reviews
+	if q.maxSizeRank >= 0 {
+		collection.FilterMaxSize(q.maxSizeRank)
+	}
+	logger.Debug("Enriching with reviews for reviewers: %v", q.reviewers)
+	collection.EnrichWithReviews(q.reviewers)
+	logger.Debug("Enriching with required approvals")
+	collection.EnrichWithRequiredApprovals()
+	logger.Debug("Filtering drafts with option: %s", q.draftOption)
+	collection.FilterDrafts()
+	collection.FilterLabels(q.labels, q.excludeLabels)
+	if q.noReviews {
+		collection.FilterNoReviews()
+	}
+	if q.needsApproval > 0 {
+		collection.FilterNeedsApproval(q.needsApproval)
+	}
+	if q.readyToMerge {
+		collection.FilterReadyToMerge()
+	}
+	// Mergeability is only known for now
+	if q.asOf.IsZero() && (q.mergeability || q.conflicts) {
+		logger.Debug("Waiting for mergeability GitHub is still computing")
+		collection.EnrichWithMergeability()
+	}
+	if q.conflicts {
+		collection.FilterConflicts()
+	}
+	// Changed files are listed per PR, so paths are checked after the cheaper filters
+	if len(q.paths) > 0 {
+		logger.Debug("Checking changed files against paths: %v", q.paths)
+		collection.EnrichWithPaths(q.paths)
+		if q.pathMode == "filter" {
+			collection.FilterPaths()
+		}
+	}
+	// Commits are listed per PR, so signatures are checked after the cheaper filters
+	if q.verifyCommits {
+		logger.Debug("Checking commit signatures")
+		collection.EnrichWithVerification()
+		if q.unverifiedOnly {
+			collection.FilterUnverified()
+		}
+	}
+	if q.reviewRequested {
+		logger.Debug("Filtering to PRs with review requested from %s", q.username)
+		collection.FilterReviewRequested(q.username, q.teamSlugs)
+	}
+	// Each reference is looked up on its own, so dependencies come after every other filter
+	if q.dependencies || q.blocked {
+		logger.Debug("Enriching with dependencies")
+		collection.EnrichWithDependencies()
+		if q.blocked {
+			collection.FilterBlocked()
+		}
+	}
+
+	if q.sortField != "" {
+		collection.SortBy(q.sortField)
+	}
+
+	logger.Debug("Processing complete. Found %d PRs after filtering", len(collection.Items))
+	for i, item := range collection.Items {
+		if i >= 5 { // Only show first 5 items
+			logger.Debug("  ... and %d more items", len(collection.Items)-5)
+			break
+		}
+		if item.Valid() {
+			logger.Debug("  PR %s#%d: %s", item.RepoFullName(), item.Number(), item.Title())
+		}
+	}
+
+	return collection.Items, nil
+}
+
+// load searches, enriches and filters the pull requests of the query
+func (q *prQuery) load() ([]*gh.PullRequestData, error) {
+	results, err := q.scanAll()
+	if err != nil {
+		return nil, err
+	}
+	return q.process(results)
+}
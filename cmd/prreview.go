@@ -0,0 +1,239 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/clients"
+	"github.com/jbrinkman/ghi/pkg/db"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// approveCmd represents the pr approve command
+var approveCmd = &cobra.Command{
+	Use:   "approve",
+	Short: "Approve a pull request",
+	Long: `The 'approve' command submits an approving review on a pull request. An optional
+review body can be given with --body, or written in your editor with --editor.
+
+The review is logged to the review database once it has been submitted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		submitPRReview(cmd, gh.ReviewApprove)
+	},
+}
+
+// requestChangesCmd represents the pr request-changes command
+var requestChangesCmd = &cobra.Command{
+	Use:   "request-changes",
+	Short: "Request changes on a pull request",
+	Long: `The 'request-changes' command submits a review requesting changes on a pull
+request. A review body is required. Give it with --body, or write it in your
+editor, which is opened automatically when --body is missing.
+
+The review is logged to the review database once it has been submitted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		submitPRReview(cmd, gh.ReviewRequestChanges)
+	},
+}
+
+// commentCmd represents the pr comment command
+var commentCmd = &cobra.Command{
+	Use:   "comment",
+	Short: "Comment on a pull request",
+	Long: `The 'comment' command posts a comment on the conversation of a pull request.
+Give the comment with --body, read it from a file or stdin with --body-file,
+or write it in your editor, which is opened automatically when no body is given.
+
+Use --review to submit the comment as a review instead, without approving or
+requesting changes. Reviews are logged to the review database once they have
+been submitted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if review, _ := cmd.Flags().GetBool("review"); review {
+			submitPRReview(cmd, gh.ReviewComment)
+			return
+		}
+		postPRComment(cmd)
+	},
+}
+
+// postPRComment posts an issue comment on a pull request using the command's flags
+func postPRComment(cmd *cobra.Command) {
+	repo, owner, repoName, number := prTarget(cmd)
+
+	body, err := commentBody(cmd, true)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if body == "" {
+		log.Fatal("Comment is empty, nothing was posted")
+	}
+
+	logger.Debug("Posting comment on %s #%d", repo, number)
+
+	ctx := cmd.Context()
+	client, err := clients.NewGitHubClient()
+	if err != nil {
+		log.Fatalf("Failed to create GitHub client: %v", err)
+	}
+
+	comment, err := gh.AddComment(ctx, client, owner, repoName, number, body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger.Debug("Posted comment %d: %s", comment.GetID(), comment.GetHTMLURL())
+	fmt.Printf("✅ Commented on %s #%d\n%s\n", repo, number, comment.GetHTMLURL())
+}
+
+// prTarget returns the repository and pull request number from the --repo and
+// --number flags, exiting when they are missing or invalid
+func prTarget(cmd *cobra.Command) (repo, owner, repoName string, number int) {
+	repoFlag, _ := cmd.Flags().GetString("repo")
+	number, _ = cmd.Flags().GetInt("number")
+
+	if number <= 0 {
+		log.Fatal("The --number flag is required")
+	}
+
+	repo, err := resolveRepo(repoFlag)
+	if err != nil {
+		log.Fatalf("The --repo flag is required when not in a GitHub repository clone: %v", err)
+	}
+	parts := strings.Split(repo, "/")
+	if len(parts) != 2 {
+		log.Fatal("Invalid repository format. Use 'owner/repo'")
+	}
+	return repo, parts[0], parts[1], number
+}
+
+// commentBody returns the text given with --body or --body-file. The editor is
+// opened with --editor, or when required and no text was given.
+func commentBody(cmd *cobra.Command, required bool) (string, error) {
+	body, _ := cmd.Flags().GetString("body")
+	bodyFile, _ := cmd.Flags().GetString("body-file")
+	useEditor, _ := cmd.Flags().GetBool("editor")
+
+	if bodyFile != "" {
+		var data []byte
+		var err error
+		if bodyFile == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(bodyFile)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read body: %w", err)
+		}
+		body = strings.TrimSpace(string(data))
+	}
+
+	if useEditor || (required && body == "") {
+		return editText(body)
+	}
+	return body, nil
+}
+
+// submitPRReview submits a review with the given event using the command's
+// flags, then logs it to the review database
+func submitPRReview(cmd *cobra.Command, event string) {
+	noLog, _ := cmd.Flags().GetBool("no-log")
+	repo, owner, repoName, number := prTarget(cmd)
+
+	// Only approvals can be submitted without a body
+	body, err := commentBody(cmd, event != gh.ReviewApprove)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if body == "" && event != gh.ReviewApprove {
+		log.Fatal("Review body is empty, nothing was submitted")
+	}
+
+	logger.Debug("Submitting %s review on %s #%d", event, repo, number)
+
+	ctx := cmd.Context()
+	client, err := clients.NewGitHubClient()
+	if err != nil {
+		log.Fatalf("Failed to create GitHub client: %v", err)
+	}
+
+	review, err := gh.SubmitReview(ctx, client, owner, repoName, number, event, body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger.Debug("Submitted review %d: %s", review.GetID(), review.GetHTMLURL())
+
+	switch event {
+	case gh.ReviewApprove:
+		fmt.Printf("✅ Approved %s #%d\n", repo, number)
+	case gh.ReviewRequestChanges:
+		fmt.Printf("✅ Requested changes on %s #%d\n", repo, number)
+	default:
+		fmt.Printf("✅ Commented on %s #%d\n", repo, number)
+	}
+
+	if noLog {
+		return
+	}
+
+	// The review is already on GitHub, so a logging failure is only a warning
+	if err := logSubmittedReview(ctx, repo, number, review); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: review submitted but not logged: %v\n", err)
+		return
+	}
+	fmt.Printf("✅ Review logged for %s #%d\n", repo, number)
+}
+
+// logSubmittedReview records a review submitted from the CLI in the review database
+func logSubmittedReview(ctx context.Context, repo string, number int, submitted *github.PullRequestReview) error {
+	reviewer := submitted.GetUser().GetLogin()
+	if reviewer == "" {
+		reviewer = os.Getenv("GHI_USERNAME")
+	}
+	if reviewer == "" {
+		return fmt.Errorf("GHI_USERNAME environment variable not set. Use 'ghi auth set --username YOUR_USERNAME' to set it")
+	}
+
+	dbClient, err := db.NewClient()
+	if err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	if err := dbClient.InitSchema(ctx); err != nil {
+		return fmt.Errorf("failed to initialize database schema: %w", err)
+	}
+
+	return dbClient.LogReview(ctx, db.Review{
+		Repo:            repo,
+		PRNumber:        number,
+		Reviewer:        reviewer,
+		GitHubReviewID:  submitted.GetID(),
+		GitHubReviewURL: submitted.GetHTMLURL(),
+	})
+}
+
+func init() {
+	for _, c := range []*cobra.Command{approveCmd, requestChangesCmd, commentCmd} {
+		prCmd.AddCommand(c)
+
+		// Define flags
+		c.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+		c.Flags().IntP("number", "n", 0, "The number of the pull request")
+		c.Flags().StringP("body", "b", "", "The review body")
+		c.Flags().StringP("body-file", "F", "", "Read the body from a file, or stdin with '-'")
+		c.Flags().BoolP("editor", "e", false, "Write the body in your editor")
+		c.Flags().Bool("no-log", false, "Don't log the review to the review database")
+	}
+
+	commentCmd.Flags().Lookup("body").Usage = "The comment body"
+	commentCmd.Flags().Bool("review", false, "Submit the comment as a review instead of a conversation comment")
+}
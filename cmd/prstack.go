@@ -0,0 +1,95 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// stackCmd represents the pr stack command
+var stackCmd = &cobra.Command{
+	Use:   "stack",
+	Short: "Show the stack a pull request belongs to",
+	Long: `The 'stack' command prints the stack of pull requests a pull request belongs
+to. A pull request is stacked when its base branch is the head branch of another
+open pull request. The stack is printed from the bottom, the pull request that
+should be merged first, with each pull request followed by those stacked on it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, owner, repoName, number := prTarget(cmd)
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Finding the stack of %s #%d", repo, number)
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		pr, _, err := client.PullRequests.Get(ctx, owner, repoName, number)
+		if err != nil {
+			log.Fatalf("Error fetching pull request #%d: %v", number, err)
+		}
+		open, err := gh.ListOpenPullRequests(ctx, client, owner, repoName)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		stack := gh.StackOf(open, pr)
+		if stack == nil {
+			fmt.Printf("%s #%d isn't part of a stack\n", repo, number)
+			return
+		}
+
+		fmt.Printf("Stack for %s #%d (%d pull requests)\n\n", repo, number, len(stack))
+		printStack(stack, number)
+	},
+}
+
+// printStack prints a stack from the bottom, indenting pull requests by their
+// level and marking the current one
+func printStack(stack []gh.StackEntry, current int) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Pull Request\tBranch\tState")
+	fmt.Fprintln(w, "------------\t------\t-----")
+	for _, entry := range stack {
+		pr := entry.PullRequest
+
+		state := pr.GetState()
+		if pr.GetDraft() {
+			state = "draft"
+		}
+		marker := ""
+		if pr.GetNumber() == current {
+			marker = "  ← this pull request"
+		}
+
+		fmt.Fprintf(w, "%s#%d %s\t%s → %s\t%s%s\n",
+			strings.Repeat("  ", entry.Level),
+			pr.GetNumber(),
+			truncateTitle(pr.GetTitle(), 40),
+			pr.GetHead().GetRef(),
+			pr.GetBase().GetRef(),
+			state,
+			marker)
+	}
+	w.Flush()
+}
+
+func init() {
+	prCmd.AddCommand(stackCmd)
+
+	// Define flags
+	stackCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+	stackCmd.Flags().IntP("number", "n", 0, "The number of the pull request")
+}
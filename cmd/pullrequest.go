@@ -9,15 +9,22 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path"
+	"sort"
 	"strings"
+	"text/template"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
 	"github.com/google/go-github/v69/github"
 	"github.com/jbrinkman/ghi/pkg/clients"
+	"github.com/jbrinkman/ghi/pkg/colors"
 	gh "github.com/jbrinkman/ghi/pkg/github"
 	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/notify"
 	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -27,9 +34,17 @@ var prCmd = &cobra.Command{
 	Use:   "pr",
 	Short: "List pull requests from a specified GitHub repository",
 	Long: `The 'pr' command retrieves and lists pull requests from a specified GitHub repository.
+Use --repo more than once to list pull requests from several repositories in one table.
 You can filter the pull requests by author using the --author option. Multiple --author options
 can be used to provide a list of author filters. The command outputs the number, title, author,
-state, and URL of each pull request.`,
+state, and URL of each pull request.
+
+The --repo flag also accepts an alias from the repos: section of the config file.
+Use --group to query every repository in a group from the groups: section and
+merge the results into one table.
+
+Use --org to survey the open pull requests of every repository in an organization.
+Narrow the repositories with --include and --exclude glob patterns.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		configFile, _ := cmd.Flags().GetString("config")
 		if configFile != "" {
@@ -41,145 +56,311 @@ state, and URL of each pull request.`,
 
 		// Bind flags to viper
 		viper.BindPFlag("repo", cmd.Flags().Lookup("repo"))
+		viper.BindPFlag("group", cmd.Flags().Lookup("group"))
 		viper.BindPFlag("author", cmd.Flags().Lookup("author"))
 		viper.BindPFlag("state", cmd.Flags().Lookup("state"))
 		viper.BindPFlag("reviewer", cmd.Flags().Lookup("reviewer"))
 		viper.BindPFlag("debug", cmd.Flags().Lookup("debug"))
 		viper.BindPFlag("draft", cmd.Flags().Lookup("draft"))
+		viper.BindPFlag("mine", cmd.Flags().Lookup("mine"))
+		viper.BindPFlag("review-requested", cmd.Flags().Lookup("review-requested"))
+		viper.BindPFlag("watch", cmd.Flags().Lookup("watch"))
+		viper.BindPFlag("interval", cmd.Flags().Lookup("interval"))
+		viper.BindPFlag("org", cmd.Flags().Lookup("org"))
+		viper.BindPFlag("include", cmd.Flags().Lookup("include"))
+		viper.BindPFlag("exclude", cmd.Flags().Lookup("exclude"))
+		viper.BindPFlag("concurrency", cmd.Flags().Lookup("concurrency"))
+		viper.BindPFlag("label", cmd.Flags().Lookup("label"))
+		viper.BindPFlag("exclude-label", cmd.Flags().Lookup("exclude-label"))
+		viper.BindPFlag("no-reviews", cmd.Flags().Lookup("no-reviews"))
+		viper.BindPFlag("needs-approval", cmd.Flags().Lookup("needs-approval"))
+		viper.BindPFlag("ready-to-merge", cmd.Flags().Lookup("ready-to-merge"))
+		viper.BindPFlag("association", cmd.Flags().Lookup("association"))
+		viper.BindPFlag("verify-commits", cmd.Flags().Lookup("verify-commits"))
+		viper.BindPFlag("unverified-only", cmd.Flags().Lookup("unverified-only"))
+		viper.BindPFlag("conflicts", cmd.Flags().Lookup("conflicts"))
+		viper.BindPFlag("blocked", cmd.Flags().Lookup("blocked"))
+		viper.BindPFlag("max-size", cmd.Flags().Lookup("max-size"))
+		viper.BindPFlag("older-than", cmd.Flags().Lookup("older-than"))
+		viper.BindPFlag("updated-before", cmd.Flags().Lookup("updated-before"))
+		viper.BindPFlag("columns", cmd.Flags().Lookup("columns"))
+		viper.BindPFlag("full-titles", cmd.Flags().Lookup("full-titles"))
+		viper.BindPFlag("path", cmd.Flags().Lookup("path"))
+		viper.BindPFlag("path-mode", cmd.Flags().Lookup("path-mode"))
+		viper.BindPFlag("sort", cmd.Flags().Lookup("sort"))
+
+		// The table can switch to other sources, starting from the flags given
+		baseSettings := currentViewSettings()
 
-		repo := viper.GetString("repo")
-		if repo == "" {
-			log.Fatal("The --repo flag is required")
+		// A saved view fills in the flags that weren't given
+		view, _ := cmd.Flags().GetString("view")
+		if view != "" {
+			if err := applyView(cmd, view); err != nil {
+				log.Fatal(err)
+			}
 		}
 
-		debug := viper.GetBool("debug")
 		// Debug logging is handled by the root command's PersistentPreRun
+		interval := viper.GetDuration("interval")
 
-		authors := viper.GetStringSlice("author")
-		state := viper.GetString("state")
-		reviewers := viper.GetStringSlice("reviewer")
-		draftOption := viper.GetString("draft")
+		// The query settings are read again when the table switches to another source
+		ctx := cmd.Context()
+		q := newPRQuery(ctx, cmd)
+		if err := q.configure(); err != nil {
+			log.Fatal(err)
+		}
 
-		// Convert authors and reviewers to lowercase for case-insensitive comparison
-		for i, author := range authors {
-			authors[i] = strings.ToLower(author)
+		sla, err := loadSLA()
+		if err != nil {
+			log.Fatal(err)
 		}
-		for i, reviewer := range reviewers {
-			reviewers[i] = strings.ToLower(reviewer)
+		columnNames := viper.GetStringSlice("columns")
+		if len(columnNames) == 0 {
+			columnNames = gh.DefaultColumnNames(q.draftOption == "show", len(q.reviewers) > 0, q.verifyCommits, q.conflicts, q.blocked)
 		}
-
-		// Split the repo into owner and repo name
-		parts := strings.Split(repo, "/")
-		if len(parts) != 2 {
-			log.Fatal("Invalid repository format. Use 'owner/repo'")
+		columns, err := gh.LookupColumns(columnNames)
+		if err != nil {
+			log.Fatal(err)
 		}
-		owner, repoName := parts[0], parts[1]
-
-		if debug {
+		widths, err := loadColumnWidths()
+		if err != nil {
+			log.Fatal(err)
+		}
+		columns = gh.ApplyWidths(columns, widths)
+		// A format prints the pull requests for scripts instead of showing the table
+		var format *template.Template
+		formatFlag, _ := cmd.Flags().GetString("format")
+		if formatFlag != "" {
+			if q.watch {
+				log.Fatal("The --format flag can't be used with --watch")
+			}
+			if format, err = gh.ParseFormat(formatFlag, colors.Enabled(os.Stdout)); err != nil {
+				log.Fatal(err)
+			}
+		}
+		// Templates can show mergeability through .MergeStatus or the GitHub
+		// pull request, and dependencies through .Blocked or .Dependencies
+		q.mergeability = showsColumn(columns, "merge") || strings.Contains(formatFlag, "Merge")
+		q.dependencies = showsColumn(columns, "blocked") || strings.Contains(formatFlag, "Blocked") ||
+			strings.Contains(formatFlag, "Dependencies")
+		notifyTypes, _ := cmd.Flags().GetStringSlice("notify")
+		if len(notifyTypes) > 0 && !q.watch {
+			log.Fatal("The --notify flag requires --watch")
+		}
+		// The interactive table needs a terminal, pipes and redirects get plain output
+		noInteractive, _ := cmd.Flags().GetBool("no-interactive")
+		stdoutTerminal := term.IsTerminal(os.Stdout.Fd())
+		interactive := !noInteractive && stdoutTerminal
+		if q.watch && !interactive {
+			log.Fatal("The --watch flag needs an interactive terminal and can't be used with --no-interactive")
+		}
+		if q.debug {
 			logger.Debug("Command arguments: %v", args)
-			logger.Debug("Repository: %s/%s", owner, repoName)
-			logger.Debug("Authors filter: %v", authors)
-			logger.Debug("State filter: %s", state)
-			logger.Debug("Reviewers filter: %v", reviewers)
-			logger.Debug("Draft option: %s", draftOption)
+			logger.Debug("Repositories: %v, organization: %s", q.repos, q.org)
+			logger.Debug("Authors filter: %v, associations: %v", q.authors, q.associations)
+			logger.Debug("State filter: %s", q.state)
+			logger.Debug("Reviewers filter: %v", q.reviewers)
+			logger.Debug("Draft option: %s", q.draftOption)
+			logger.Debug("Mine: %v, Review requested: %v", q.mine, q.reviewRequested)
+			logger.Debug("Labels: %v, excluded labels: %v", q.labels, q.excludeLabels)
+			logger.Debug("No reviews: %v, needs approval: %d, ready to merge: %v", q.noReviews, q.needsApproval, q.readyToMerge)
+			logger.Debug("Paths: %v, path mode: %s", q.paths, q.pathMode)
+			logger.Debug("Verify commits: %v, unverified only: %v", q.verifyCommits, q.unverifiedOnly)
+			logger.Debug("Conflicts: %v, blocked: %v, max size: %s", q.conflicts, q.blocked, q.maxSize)
+			logger.Debug("Created before: %v, updated before: %v, SLA: %+v", q.createdBefore, q.updatedBefore, sla)
+			logger.Debug("Columns: %v, format: %v, sort: %s", columnNames, format != nil, q.sortField)
+			logger.Debug("As of: %v", q.asOf)
 		}
 
 		// Create a new Github client with cache control
-		ctx := context.Background()
 		client, err := clients.NewGitHubClient()
 		if err != nil {
 			log.Fatalf("Failed to create GitHub client: %v", err)
 		}
+		q.client = client
+		if err := q.resolveTargets(); err != nil {
+			log.Fatal(err)
+		}
+
+		// Show spinner while fetching PRs
+		logger.Debug("Starting to fetch pull requests from %d repositories", len(q.targets))
+		results, err := ui.WithSpinner(ctx, "Fetching pull requests", q.scanAll)
+		if err != nil {
+			logger.Debug("Error fetching pull requests: %v", err)
+			log.Fatal(err)
+		}
+		if q.usedFallback {
+			fmt.Fprintln(os.Stderr, "Warning: GitHub search rate limit exceeded. Results were listed without search and filtered locally.")
+		}
 
-		// Construct the search query
-		query := fmt.Sprintf("repo:%s/%s", owner, repoName)
-		if state != "" && state != "all" {
-			query += fmt.Sprintf(" state:%s", state)
+		if q.debug {
+			for i, target := range q.targets {
+				logger.Debug("Found %d pull requests in %s/%s", len(results[i]), target.owner, target.name)
+			}
 		}
-		for _, author := range authors {
-			query += fmt.Sprintf(" author:%s", author)
+
+		// Show how many PRs have been enriched while processing them. The
+		// channel is closed once processing is done, which ends the spinner's
+		// wait for the next update.
+		progress := make(chan gh.Progress, 1)
+		q.progress = progress
+		prItems, err := ui.WithProgress(ctx, "Processing pull requests", progress, func() ([]*gh.PullRequestData, error) {
+			defer close(progress)
+			return q.process(results)
+		})
+		q.progress = nil
+		if err != nil {
+			log.Fatal(err)
 		}
-		query += " type:pr" // Ensure only pull requests are returned
 
-		if debug {
-			logger.Debug("Search query: %s", query)
+		// What was fetched before Ctrl+C is printed, the user asked to stop
+		if warnIfInterrupted(ctx) {
+			interactive = false
 		}
 
-		// Search pull requests with retry on rate limit
-		var result *github.IssuesSearchResult
-		scanPRs := func() (*github.IssuesSearchResult, error) {
-			searchOpts := &github.SearchOptions{}
-			for attempts := 0; attempts < 3; attempts++ {
-				var err error
-				result, _, err = client.Search.Issues(ctx, query, searchOpts)
-				if err != nil {
-					if _, ok := err.(*github.RateLimitError); ok {
-						if attempts < 2 {
-							// On rate limit, wait and retry
-							logger.Debug("Hit rate limit, waiting 5 seconds before retry...")
-							time.Sleep(5 * time.Second)
-							continue
-						}
-						return nil, fmt.Errorf("GitHub API rate limit exceeded. Try setting GHI_GITHUB_TOKEN environment variable")
-					}
-					return nil, fmt.Errorf("error searching pull requests: %w", err)
+		if format != nil {
+			if err := gh.RenderFormat(os.Stdout, format, prItems); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		// A plain table in a terminal, tab-separated values for scripts
+		if !interactive {
+			collection := gh.NewPRCollection(ctx, client, q.debug).WithDraftOption(q.draftOption)
+			collection.Items = prItems
+			display := gh.NewPRDisplay(collection).WithColumns(columnNames).WithWidths(widths).WithSLA(sla)
+			display.Options.ShowDraft = q.draftOption == "show"
+			if !stdoutTerminal {
+				if err := display.RenderTSV(os.Stdout); err != nil {
+					log.Fatal(err)
 				}
-				return result, nil
+				return
 			}
-			return nil, fmt.Errorf("max retries exceeded")
+			display.WithColor(colors.Enabled(os.Stdout)).RenderTable()
+			return
 		}
 
-		// Show spinner while fetching PRs
-		logger.Debug("Starting to fetch pull requests with query: %s", query)
-		result, err = ui.WithSpinner(ctx, "Fetching pull requests", scanPRs)
-		if err != nil {
-			logger.Debug("Error fetching pull requests: %v", err)
-			log.Fatal(err)
+		// Create and show the interactive table
+		prTable := ui.NewPRTable(prItems).
+			WithColumns(columns, gh.ColumnOptions{SLA: sla, ShowDraft: q.draftOption == "show"}).
+			WithAsOf(q.asOf).
+			WithDetailLoader(func(pr *gh.PullRequestData) (*gh.PRDetail, error) {
+				return gh.FetchPRDetail(ctx, client, pr.Owner, pr.Repo, pr.Number())
+			})
+		// Pressing g in the table switches to a repository alias, group or
+		// view from the config file
+		if sources := prSources(); len(sources) > 0 {
+			prTable.WithSource(prSourceLabel(view, q.repos)).WithSourcePicker(sources, func(source ui.PRSource) ([]*gh.PullRequestData, error) {
+				previous := currentViewSettings()
+				load := func() ([]*gh.PullRequestData, error) {
+					restoreViewSettings(baseSettings)
+					switch source.Kind {
+					case ui.SourceRepo:
+						viper.Set("repo", []string{source.Name})
+						viper.Set("group", "")
+						viper.Set("org", "")
+					case ui.SourceGroup:
+						viper.Set("repo", []string{})
+						viper.Set("group", source.Name)
+						viper.Set("org", "")
+					case ui.SourceView:
+						if err := switchView(source.Name); err != nil {
+							return nil, err
+						}
+					}
+					if err := q.configure(); err != nil {
+						return nil, err
+					}
+					if err := q.resolveTargets(); err != nil {
+						return nil, err
+					}
+					return q.load()
+				}
+
+				items, err := load()
+				if err != nil {
+					// Go back to the source that's shown, so watch mode keeps refreshing it
+					restoreViewSettings(previous)
+					if err := q.configure(); err != nil {
+						logger.Debug("Failed to restore the pull request settings: %v", err)
+					} else if err := q.resolveTargets(); err != nil {
+						logger.Debug("Failed to restore the repositories: %v", err)
+					}
+				}
+				return items, err
+			})
 		}
-		logger.Debug("Found %d issues from search", len(result.Issues))
-
-		if debug {
-			logger.Debug("Found %d pull requests", len(result.Issues))
-		}
-
-		// Process PRs with a spinner
-		processPRs := func() ([]*gh.PullRequestData, error) {
-			logger.Debug("Creating new PR collection for %s/%s", owner, repoName)
-			collection := gh.NewPRCollection(ctx, client, owner, repoName, debug)
-			collection.WithDraftOption(draftOption)
-
-			// Process the data in a pipeline
-			logger.Debug("Fetching issues (count: %d)", len(result.Issues))
-			collection.FetchIssues(result.Issues)
-			logger.Debug("Enriching with pull requests")
-			collection.EnrichWithPullRequests()
-			logger.Debug("Enriching with reviews for reviewers: %v", reviewers)
-			collection.EnrichWithReviews(reviewers)
-			logger.Debug("Filtering drafts with option: %s", draftOption)
-			collection.FilterDrafts()
-
-			logger.Debug("Processing complete. Found %d PRs after filtering", len(collection.Items))
-			for i, item := range collection.Items {
-				if i >= 5 { // Only show first 5 items
-					logger.Debug("  ... and %d more items", len(collection.Items)-5)
-					break
+		if q.watch {
+			if interval <= 0 {
+				log.Fatal("The --interval flag must be greater than zero")
+			}
+
+			notifier, err := selectNotifier(notifyTypes)
+			if err != nil {
+				log.Fatal(err)
+			}
+			// Text written to stdout would corrupt the table
+			notifiers := notifier.Notifiers[:0]
+			for _, n := range notifier.Notifiers {
+				if n.Name() != notify.TypeStdout {
+					notifiers = append(notifiers, n)
 				}
-				if item != nil && item.Issue != nil && item.Issue.Number != nil {
-					logger.Debug("  PR #%d: %s", *item.Issue.Number, *item.Issue.Title)
+			}
+			notifier.Notifiers = notifiers
+
+			if !notifier.Empty() {
+				prTable.WithChangeHandler(func(changed []*gh.PullRequestData) {
+					if err := notifier.Notify(ctx, changedPRsMessage(changed)); err != nil {
+						logger.Debug("Failed to send change notification: %v", err)
+					}
+				})
+			}
+
+			// Pull requests waiting past the alert thresholds are notified once
+			// when they're first seen past them
+			policy, err := loadAlertPolicy()
+			if err != nil {
+				log.Fatal(err)
+			}
+			checkAlerts := func(items []*gh.PullRequestData) {}
+			if !policy.Empty() {
+				if notifier.Empty() {
+					fmt.Fprintln(os.Stderr, "Warning: alerts are configured but there are no notifications to send them to")
 				}
+				tracker := gh.NewAlertTracker()
+				checkAlerts = func(items []*gh.PullRequestData) {
+					alerts := tracker.New(policy.CheckAlerts(items))
+					logger.Debug("%d new review latency alerts", len(alerts))
+					if len(alerts) == 0 || notifier.Empty() {
+						return
+					}
+					if err := notifier.Notify(ctx, alertsMessage(alerts)); err != nil {
+						logger.Debug("Failed to send alert notification: %v", err)
+					}
+				}
+				go checkAlerts(prItems)
 			}
 
-			return collection.Items, nil
+			// Re-run the search and enrichment pipeline on every refresh
+			logger.Debug("Watching pull requests, refreshing every %v", interval)
+			prTable.WithRefresh(interval, func() ([]*gh.PullRequestData, error) {
+				items, err := q.load()
+				if err == nil {
+					checkAlerts(items)
+				}
+				return items, err
+			})
 		}
-
-		// Show spinner while processing PRs
-		prItems, err := ui.WithSpinner(ctx, "Processing pull requests", processPRs)
-		if err != nil {
-			log.Fatal(err)
+		// New users get a tip about the legend until they hide it with x
+		if !viper.IsSet("hints") || viper.GetBool("hints") {
+			prTable.WithHints(func() {
+				if err := updateConfigFile("hints", false); err != nil {
+					logger.Debug("Failed to hide hints in the config file: %v", err)
+				}
+			})
 		}
-
-		// Create and show the interactive table
-		prTable := ui.NewPRTable(prItems)
+		q.quiet = true
 		p := tea.NewProgram(prTable, tea.WithAltScreen())
 		if _, err := p.Run(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error running PR table: %v\n", err)
@@ -192,12 +373,253 @@ func init() {
 	rootCmd.AddCommand(prCmd)
 
 	// Define flags
-	prCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo)")
+	prCmd.Flags().StringArrayP("repo", "r", []string{}, "The name of a Github repository (owner/repo, default from git remote). Can be repeated")
+	prCmd.Flags().StringP("group", "g", "", "Query every repository in a group defined in the config file")
 	prCmd.Flags().StringArrayP("author", "A", []string{}, "Filter pull requests by author")
+	prCmd.Flags().StringArray("association", []string{}, "Show only pull requests whose author has this association with the repository, such as first-time or member. Can be repeated")
 	prCmd.Flags().StringP("state", "s", "all", "Filter pull requests by state (ALL, OPEN, CLOSED)")
 	prCmd.Flags().StringArrayP("reviewer", "R", []string{}, "Highlight pull requests by reviewer")
 	prCmd.Flags().StringP("config", "c", "", "Path to the configuration file")
+	prCmd.Flags().String("view", "", "Use the flags saved in a view from the views: section of the config file")
+	prCmd.Flags().String("as-of", "", "Show the pull requests that were open at a past date (YYYY-MM-DD or YYYY-MM-DDTHH:MM) with the approvals they had then")
+	prCmd.Flags().String("sort", "", "Sort pull requests by created, updated, number or size, newest or largest first")
 	prCmd.Flags().StringP("draft", "D", "hide", "Control draft PR display (show, hide)")
+	prCmd.Flags().Bool("mine", false, "Show only pull requests you authored, along with those of --author")
+	prCmd.Flags().Bool("review-requested", false, "Show only pull requests where your review is requested")
+	prCmd.Flags().BoolP("watch", "w", false, "Keep the table open and refresh it periodically")
+	prCmd.Flags().Duration("interval", 60*time.Second, "Refresh interval for --watch")
+	prCmd.Flags().StringSlice("notify", []string{}, "Send watch notifications and alerts only to destinations of these types, such as slack")
+	prCmd.Flags().StringP("org", "o", "", "Scan every repository in a GitHub organization")
+	prCmd.Flags().StringArray("include", []string{}, "Only scan organization repositories matching a pattern, such as 'api-*'")
+	prCmd.Flags().StringArray("exclude", []string{}, "Skip organization repositories matching a pattern")
+	prCmd.Flags().Int("concurrency", 4, "Number of concurrent GitHub requests")
+	prCmd.Flags().StringArrayP("label", "l", []string{}, "Show only pull requests with this label. Can be repeated")
+	prCmd.Flags().StringArray("exclude-label", []string{}, "Hide pull requests with this label. Can be repeated")
+	prCmd.Flags().Bool("no-reviews", false, "Show only pull requests nobody has reviewed yet")
+	prCmd.Flags().Int("needs-approval", 0, "Show only pull requests with fewer than this many approvals")
+	prCmd.Flags().Bool("ready-to-merge", false, "Show only pull requests with the approvals their base branch requires")
+	prCmd.Flags().StringSlice("columns", []string{}, "Columns to show, in order, such as number,title,author,age,approvals")
+	prCmd.Flags().Bool("full-titles", false, "Show titles in full instead of truncating them, widening the title column as far as the terminal allows")
+	prCmd.Flags().String("format", "", "Print each pull request with a Go template, such as '{{.Number}} {{.Title}}', instead of showing the table")
+	prCmd.Flags().Bool("no-interactive", false, "Print a plain table instead of the interactive one. Output that isn't a terminal is always tab-separated")
+	prCmd.Flags().String("older-than", "", "Show only pull requests opened longer ago than this, such as 14d or 2w")
+	prCmd.Flags().String("updated-before", "", "Show only pull requests last updated before a date (YYYY-MM-DD) or longer ago than an age, such as 7d")
+	prCmd.Flags().String("max-size", "", "Show only pull requests no larger than this size (XS, S, M, L, XL)")
+	prCmd.Flags().Bool("conflicts", false, "Show only pull requests with merge conflicts")
+	prCmd.Flags().Bool("blocked", false, "Show only pull requests with a dependency that is still open")
+	prCmd.Flags().Bool("verify-commits", false, "Check whether every commit of each pull request has a verified signature")
+	prCmd.Flags().Bool("unverified-only", false, "Show only pull requests with commits that aren't verified. Implies --verify-commits")
+	prCmd.Flags().StringArray("path", []string{}, "Show only pull requests changing files under this path, such as 'services/payments/**'. Can be repeated")
+	prCmd.Flags().String("path-mode", "filter", "How --path is applied: filter hides other pull requests, flag marks matching ones with ★")
+}
+
+// parseBefore parses a date in YYYY-MM-DD format, or an age such as 7d that is
+// counted back from now
+func parseBefore(s string) (time.Time, error) {
+	if date, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return date, nil
+	}
+	age, err := parseAge(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q. Use YYYY-MM-DD or an age such as '7d'", s)
+	}
+	return time.Now().Add(-age), nil
+}
+
+// loadSLA reads the review SLA thresholds from the sla: section of the config
+// file, using the defaults for thresholds that aren't set
+func loadSLA() (gh.SLA, error) {
+	sla := gh.DefaultSLA
+	for key, threshold := range map[string]*time.Duration{"sla.stale": &sla.Stale, "sla.fresh": &sla.Fresh} {
+		value := viper.GetString(key)
+		if value == "" {
+			continue
+		}
+		age, err := parseAge(value)
+		if err != nil {
+			return sla, fmt.Errorf("invalid %s in config: %w", key, err)
+		}
+		*threshold = age
+	}
+	return sla, nil
+}
+
+// loadColumnWidths reads the column widths set under column-widths: in the
+// config file. With --full-titles the title column isn't truncated.
+func loadColumnWidths() (map[string]int, error) {
+	widths := make(map[string]int)
+	for name, value := range viper.GetStringMap("column-widths") {
+		width, err := cast.ToIntE(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid width %v for column %q in column-widths", value, name)
+		}
+		widths[strings.ToLower(name)] = width
+	}
+	if err := gh.ValidateWidths(widths); err != nil {
+		return nil, fmt.Errorf("invalid column-widths in config: %w", err)
+	}
+	if viper.GetBool("full-titles") {
+		widths["title"] = 0
+	}
+	return widths, nil
+}
+
+// showsColumn reports whether the column with the given name is shown
+func showsColumn(columns []gh.Column, name string) bool {
+	for _, column := range columns {
+		if column.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePRRepos combines the repositories given with --repo and the members of
+// the --group, without duplicates. With neither, the repository is detected from
+// the git remote of the current directory.
+func resolvePRRepos(repoFlags []string, group string) ([]string, error) {
+	var candidates []string
+	for _, repo := range repoFlags {
+		if repo = strings.TrimSpace(repo); repo != "" {
+			candidates = append(candidates, expandRepoAlias(repo))
+		}
+	}
+	if group != "" {
+		members, err := resolveGroup(group)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, members...)
+	}
+
+	if len(candidates) == 0 {
+		repo, err := resolveRepo("")
+		if err != nil {
+			return nil, fmt.Errorf("the --repo flag is required when not in a GitHub repository clone: %w", err)
+		}
+		return []string{repo}, nil
+	}
+
+	var repos []string
+	seen := make(map[string]bool)
+	for _, repo := range candidates {
+		key := strings.ToLower(repo)
+		if !seen[key] {
+			seen[key] = true
+			repos = append(repos, repo)
+		}
+	}
+	return repos, nil
+}
+
+// excludeConfiguredRepos removes the repositories listed under exclude-repos
+// in the config file. Entries may be repositories, aliases or glob patterns
+// such as "myorg/sandbox-*".
+func excludeConfiguredRepos(repos []string) (kept, excluded []string) {
+	var patterns []string
+	for _, entry := range viper.GetStringSlice("exclude-repos") {
+		patterns = append(patterns, strings.ToLower(expandRepoAlias(entry)))
+	}
+	if len(patterns) == 0 {
+		return repos, nil
+	}
+
+	for _, repo := range repos {
+		if matchesRepoPattern(patterns, strings.ToLower(repo)) {
+			logger.Debug("Skipping excluded repository %s", repo)
+			excluded = append(excluded, repo)
+		} else {
+			kept = append(kept, repo)
+		}
+	}
+	return kept, excluded
+}
+
+// matchesRepoPattern reports whether a lowercase owner/repo name matches any of the patterns
+func matchesRepoPattern(patterns []string, repo string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, repo); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// reportSkippedRepos prints how many repositories a group or organization scan covers
+func reportSkippedRepos(scanned, archived, excluded int) {
+	if archived+excluded == 0 {
+		fmt.Fprintf(os.Stderr, "Scanning %d repositories\n", scanned)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Scanning %d repositories, skipped %d (%d archived, %d excluded)\n",
+		scanned, archived+excluded, archived, excluded)
+}
+
+// prSources lists the repository aliases, groups and saved views in the config
+// file that the table can switch to, each sorted by name
+func prSources() []ui.PRSource {
+	var sources []ui.PRSource
+	aliases := viper.GetStringMap("repos")
+	for _, name := range sortedNames(aliases) {
+		// Repositories can also hold settings such as alert thresholds
+		if target, ok := aliases[name].(string); ok {
+			sources = append(sources, ui.PRSource{Kind: ui.SourceRepo, Name: name, Description: target})
+		}
+	}
+	for _, name := range sortedNames(viper.GetStringMap("groups")) {
+		members := viper.GetStringSlice("groups." + name)
+		sources = append(sources, ui.PRSource{Kind: ui.SourceGroup, Name: name, Description: strings.Join(members, ", ")})
+	}
+	for _, name := range sortedNames(viper.GetStringMap("views")) {
+		settings := viper.GetStringMap("views." + name)
+		var flags []string
+		for _, key := range viewKeys {
+			if value, ok := settings[key]; ok {
+				flags = append(flags, formatViewFlag(key, value))
+			}
+		}
+		sources = append(sources, ui.PRSource{Kind: ui.SourceView, Name: name, Description: strings.Join(flags, " ")})
+	}
+	return sources
+}
+
+// prSourceLabel names the pull requests the pr command started with
+func prSourceLabel(view string, repos []string) string {
+	switch {
+	case view != "":
+		return ui.SourceView + " " + view
+	case viper.GetString("org") != "":
+		return "org " + viper.GetString("org")
+	case viper.GetString("group") != "":
+		return ui.SourceGroup + " " + viper.GetString("group")
+	}
+	return strings.Join(repos, ", ")
+}
+
+// sortedNames returns the keys of a config section in order
+func sortedNames(section map[string]interface{}) []string {
+	names := make([]string, 0, len(section))
+	for name := range section {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveUsername returns the login of the authenticated GitHub user, falling back
+// to the GHI_USERNAME environment variable when the token can't be used to look it up
+func resolveUsername(ctx context.Context, client *github.Client) string {
+	username, err := gh.AuthenticatedLogin(ctx, client)
+	if err == nil && username != "" {
+		return username
+	}
+	logger.Debug("Could not determine authenticated user: %v", err)
+
+	username = os.Getenv("GHI_USERNAME")
+	if username == "" {
+		log.Fatal("Could not determine your GitHub username. Set GHI_GITHUB_TOKEN or use 'ghi auth set --username YOUR_USERNAME'")
+	}
+	return username
 }
 
 func prettyPrint(v interface{}) (string, error) {
@@ -0,0 +1,171 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// repoCmd represents the repo command
+var repoCmd = &cobra.Command{
+	Use:   "repo",
+	Short: "Work with repositories",
+	Long:  `The 'repo' command groups subcommands for finding and organizing repositories.`,
+}
+
+// repoDiscoverCmd represents the repo discover command
+var repoDiscoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Find repositories in an organization",
+	Long: `The 'discover' command lists the repositories in an organization that match
+the given criteria, most recently pushed first. Archived repositories and
+those listed under exclude-repos in the config file are always skipped.
+
+Use --group to append the repositories found to a repository group in the
+config file, so 'ghi pr --group' covers them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		org, _ := cmd.Flags().GetString("org")
+		activeSince, _ := cmd.Flags().GetString("active-since")
+		language, _ := cmd.Flags().GetString("language")
+		include, _ := cmd.Flags().GetStringArray("include")
+		exclude, _ := cmd.Flags().GetStringArray("exclude")
+		group, _ := cmd.Flags().GetString("group")
+
+		if org == "" {
+			log.Fatal("The --org flag is required")
+		}
+
+		filter := gh.RepoFilter{Include: include, Exclude: exclude, Language: language}
+		if activeSince != "" {
+			age, err := parseAge(activeSince)
+			if err != nil {
+				log.Fatal(err)
+			}
+			filter.ActiveSince = time.Now().Add(-age)
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Discovering repositories in %s with filter %+v", org, filter)
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		discovered, skips, err := gh.DiscoverOrgRepos(ctx, client, org, filter)
+		if err != nil {
+			log.Fatal(err)
+		}
+		logger.Debug("Skipped %d archived and %d filtered repositories", skips.Archived, skips.Filtered)
+
+		// Leave out the repositories excluded in the config file
+		var repos []*github.Repository
+		for _, repo := range discovered {
+			if kept, _ := excludeConfiguredRepos([]string{repo.GetFullName()}); len(kept) > 0 {
+				repos = append(repos, repo)
+			}
+		}
+		if len(repos) == 0 {
+			fmt.Printf("No matching repositories found in %s\n", org)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Repository\tLanguage\tLast Push\tOpen Issues")
+		fmt.Fprintln(w, "----------\t--------\t---------\t-----------")
+		names := make([]string, 0, len(repos))
+		for _, repo := range repos {
+			names = append(names, repo.GetFullName())
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\n",
+				repo.GetFullName(),
+				repo.GetLanguage(),
+				repo.GetPushedAt().Format("2006-01-02"),
+				repo.GetOpenIssuesCount())
+		}
+		w.Flush()
+
+		if group == "" {
+			return
+		}
+
+		added, err := addToGroup(group, names)
+		if err != nil {
+			log.Fatalf("Failed to update group %s: %v", group, err)
+		}
+		fmt.Printf("\n✅ Added %d repositories to group %s (%d already present)\n", added, group, len(names)-added)
+	},
+}
+
+// addToGroup appends repositories to a group in the config file, skipping
+// those already in it directly or through an alias. It returns how many were added.
+func addToGroup(group string, repos []string) (int, error) {
+	members := viper.GetStringSlice("groups." + group)
+
+	present := make(map[string]bool, len(members))
+	for _, member := range members {
+		present[strings.ToLower(expandRepoAlias(member))] = true
+	}
+
+	added := 0
+	for _, repo := range repos {
+		if present[strings.ToLower(repo)] {
+			continue
+		}
+		present[strings.ToLower(repo)] = true
+		members = append(members, repo)
+		added++
+	}
+
+	if added == 0 {
+		return 0, nil
+	}
+	return added, updateConfigFile("groups."+group, members)
+}
+
+// parseAge parses an age such as "30d", "2w" or any Go duration like "36h"
+func parseAge(s string) (time.Duration, error) {
+	day := 24 * time.Hour
+	units := map[string]time.Duration{"d": day, "w": 7 * day}
+	for suffix, unit := range units {
+		if n, err := strconv.Atoi(strings.TrimSuffix(s, suffix)); err == nil && strings.HasSuffix(s, suffix) {
+			if n < 0 {
+				break
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	age, err := time.ParseDuration(s)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age %q. Use a number of days or weeks such as '30d' or '2w'", s)
+	}
+	return age, nil
+}
+
+func init() {
+	rootCmd.AddCommand(repoCmd)
+	repoCmd.AddCommand(repoDiscoverCmd)
+
+	// Define flags
+	repoDiscoverCmd.Flags().StringP("org", "o", "", "The GitHub organization to search")
+	repoDiscoverCmd.Flags().String("active-since", "", "Only repositories pushed to within this age, such as 30d or 2w")
+	repoDiscoverCmd.Flags().StringP("language", "l", "", "Only repositories with this primary language")
+	repoDiscoverCmd.Flags().StringArray("include", []string{}, "Only repositories whose names match this glob (can be repeated)")
+	repoDiscoverCmd.Flags().StringArray("exclude", []string{}, "Skip repositories whose names match this glob (can be repeated)")
+	repoDiscoverCmd.Flags().StringP("group", "g", "", "Append the repositories found to this repository group in the config file")
+}
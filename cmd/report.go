@@ -0,0 +1,226 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	"github.com/jbrinkman/ghi/pkg/db"
+	"github.com/jbrinkman/ghi/pkg/locale"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/notify"
+	"github.com/jbrinkman/ghi/pkg/report"
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Write a Markdown or HTML digest of recent pull request activity",
+	Long: `The 'report' command writes a digest of what happened in your repositories,
+ready to paste into a standup document or a chat message:
+
+  - the pull requests opened and merged in the period
+  - the open pull requests still waiting for a first review
+  - the longest open pull requests
+  - the reviews you logged in the period
+
+Pull requests come from GitHub and reviews from your database. When the
+database isn't set up, the digest leaves out your reviews.
+
+Use --template to render the digest with your own Go template instead of the
+built-in one.
+
+Use --notify to post the digest to notification destinations, such as the
+Slack webhook stored with 'ghi auth set --slack-webhook', instead of writing
+it to stdout.`,
+	Example: `  ghi report --since 7d
+  ghi report --group backend --format html -o digest.html
+  ghi report --group backend --notify slack`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoFlags, _ := cmd.Flags().GetStringSlice("repo")
+		group, _ := cmd.Flags().GetString("group")
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		format, _ := cmd.Flags().GetString("format")
+		templatePath, _ := cmd.Flags().GetString("template")
+		oldest, _ := cmd.Flags().GetInt("oldest")
+		output, _ := cmd.Flags().GetString("output")
+		notifyTypes, _ := cmd.Flags().GetStringSlice("notify")
+
+		if !slices.Contains(report.Formats, format) {
+			log.Fatalf("Invalid format %q. Use 'markdown', 'html' or 'slack'", format)
+		}
+		if oldest < 0 || oldest > 100 {
+			log.Fatal("The --oldest flag must be between 0 and 100")
+		}
+
+		repos, err := resolvePRRepos(repoFlags, group)
+		if err != nil {
+			log.Fatal(err)
+		}
+		age, err := parseAge(sinceFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		until := time.Now()
+		opts := report.Options{
+			Repos:    repos,
+			Since:    until.Add(-age),
+			Until:    until,
+			Oldest:   oldest,
+			Username: os.Getenv("GHI_USERNAME"),
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Reporting on %v since %s, format: %s, template: %q", repos, opts.Since.Format(time.DateOnly), format, templatePath)
+
+		// Check the destinations before spending the time to collect the digest
+		var notifier *notify.Multi
+		if len(notifyTypes) > 0 {
+			if notifier, err = selectNotifier(notifyTypes); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		digest := &report.Digest{Since: opts.Since, Until: opts.Until, Username: opts.Username}
+		digest.Repos, err = ui.WithSpinner(ctx, "Fetching pull requests", func() ([]report.RepoDigest, error) {
+			var digests []report.RepoDigest
+			for _, repo := range repos {
+				repoDigest, err := report.CollectRepo(ctx, client, repo, opts)
+				if err != nil && len(digests) > 0 && ui.Interrupted(ctx) {
+					// Report on the repositories collected before Ctrl+C
+					return digests, nil
+				}
+				if err != nil {
+					return nil, err
+				}
+				digests = append(digests, repoDigest)
+			}
+			return digests, nil
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		warnIfInterrupted(ctx)
+
+		digest.Reviews, digest.ReviewsIncluded = collectReportReviews(ctx, opts)
+
+		if notifier != nil {
+			if err := notifyDigest(ctx, notifier, digest); err != nil {
+				log.Fatalf("Failed to post the digest: %v", err)
+			}
+			fmt.Fprintf(os.Stderr, "✅ Posted the digest to %s\n", strings.Join(notifyTypes, ", "))
+			if output == "" {
+				return
+			}
+		}
+
+		var w io.Writer = os.Stdout
+		if output != "" {
+			file, err := os.Create(output)
+			if err != nil {
+				log.Fatalf("Failed to create %s: %v", output, err)
+			}
+			defer file.Close()
+			w = file
+		}
+
+		if err := report.Render(w, format, templatePath, digest); err != nil {
+			log.Fatal(err)
+		}
+		if output != "" {
+			fmt.Fprintf(os.Stderr, "✅ Wrote the digest to %s\n", output)
+		}
+	},
+}
+
+// collectReportReviews returns the reviews the user logged in the period, and
+// whether the database could be read. A digest is still useful without them,
+// so problems are warnings.
+func collectReportReviews(ctx context.Context, opts report.Options) ([]db.Review, bool) {
+	if opts.Username == "" {
+		fmt.Fprintln(os.Stderr, "Warning: GHI_USERNAME isn't set, so your reviews aren't included. Use 'ghi auth set --username YOUR_USERNAME' to set it")
+		return nil, false
+	}
+
+	dbClient, err := db.NewClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: your reviews aren't included: %v\n", err)
+		return nil, false
+	}
+	defer dbClient.Close()
+
+	if err := dbClient.InitSchema(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: your reviews aren't included: %v\n", err)
+		return nil, false
+	}
+	reviews, err := report.CollectReviews(ctx, dbClient, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: your reviews aren't included: %v\n", err)
+		return nil, false
+	}
+	logger.Debug("Found %d reviews by %s", len(reviews), opts.Username)
+	return reviews, true
+}
+
+// notifyDigest posts the digest with the built-in templates, as Slack mrkdwn
+// to Slack and as Markdown to the other destinations
+func notifyDigest(ctx context.Context, notifier *notify.Multi, digest *report.Digest) error {
+	title := fmt.Sprintf("Digest for %s to %s", locale.Date(digest.Since), locale.Date(digest.Until))
+	var errs []error
+	for _, n := range notifier.Notifiers {
+		format := report.FormatMarkdown
+		if n.Name() == notify.TypeSlack {
+			format = report.FormatSlack
+		}
+		var body strings.Builder
+		if err := report.Render(&body, format, "", digest); err != nil {
+			return err
+		}
+		// The built-in Markdown template starts with the title, which the
+		// destination shows already
+		text := strings.TrimSpace(body.String())
+		if format == report.FormatMarkdown {
+			_, text, _ = strings.Cut(text, "\n")
+			text = strings.TrimSpace(text)
+		}
+
+		msg := notify.Message{Title: title, Body: text, Time: digest.Until}
+		if err := n.Notify(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	// Define flags
+	reportCmd.Flags().StringSliceP("repo", "r", []string{}, "The Github repositories to report on (owner/repo, default from git remote)")
+	reportCmd.Flags().StringP("group", "g", "", "Report on every repository in a group defined in the config file")
+	reportCmd.Flags().String("since", "7d", "The period to report on, such as 7d or 2w")
+	reportCmd.Flags().StringP("format", "f", report.FormatMarkdown, "Output format (markdown, html, slack)")
+	reportCmd.Flags().String("template", "", "A Go template file to render the digest with instead of the built-in one")
+	reportCmd.Flags().Int("oldest", 5, "Number of the longest open pull requests to list per repository (0 for none)")
+	reportCmd.Flags().StringP("output", "o", "", "File to write the digest to (default stdout)")
+	reportCmd.Flags().StringSlice("notify", []string{}, "Post the digest to notification destinations of these types, such as slack, instead of writing it to stdout")
+}
@@ -4,7 +4,6 @@ Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"log"
 	"os"
@@ -12,9 +11,13 @@ import (
 	"text/tabwriter"
 	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
 	"github.com/jbrinkman/ghi/pkg/clients"
 	"github.com/jbrinkman/ghi/pkg/db"
+	"github.com/jbrinkman/ghi/pkg/locale"
 	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -24,7 +27,17 @@ var reviewCmd = &cobra.Command{
 	Use:   "review",
 	Short: "List pull requests you have reviewed",
 	Long: `The 'review' command shows a list of pull requests you have reviewed.
-You can filter by repository using the --repo flag.`,
+You can filter by repository using the --repo flag.
+
+With a shared database, use --reviewer to show someone else's review history.
+
+Open pull requests with new commits since your last review of them are marked
+STALE. Use 'ghi review pending' to list only those.
+
+The reviews are shown in an interactive table. Press s to sort by the next
+column and S to reverse the order, / to filter the rows by text, and o to open
+the selected pull request in your browser. When the output isn't a terminal, a
+plain table is printed instead.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		configFile, _ := cmd.Flags().GetString("config")
 		if configFile != "" {
@@ -38,16 +51,23 @@ You can filter by repository using the --repo flag.`,
 		viper.BindPFlag("repo", cmd.Flags().Lookup("repo"))
 		viper.BindPFlag("all", cmd.Flags().Lookup("all"))
 
-		repo := viper.GetString("repo")
 		all := viper.GetBool("all")
+		reviewer, _ := cmd.Flags().GetString("reviewer")
+
+		// The repository filter is optional, so detection failures just mean no filter
+		repo, err := resolveRepo(viper.GetString("repo"))
+		if err != nil {
+			logger.Debug("No repository filter: %v", err)
+		}
 
 		logger.Debug("Command arguments: %v", args)
 		logger.Debug("Repository filter: %s", repo)
 		logger.Debug("Show all flag: %v", all)
+		logger.Debug("Reviewer: %s", reviewer)
 
 		// Check for username
 		username := os.Getenv("GHI_USERNAME")
-		if username == "" {
+		if username == "" && reviewer == "" {
 			log.Fatal("GHI_USERNAME environment variable not set. Use 'ghi auth set --username YOUR_USERNAME' to set it")
 		}
 
@@ -58,8 +78,14 @@ You can filter by repository using the --repo flag.`,
 		}
 		defer dbClient.Close()
 
+		// Other people's reviews are only available in a shared database
+		if reviewer != "" && !strings.EqualFold(reviewer, username) {
+			requireSharedDatabase(dbClient, "ghi review --reviewer")
+			username = reviewer
+		}
+
 		// Initialize schema if needed
-		ctx := context.Background()
+		ctx := cmd.Context()
 		if err := dbClient.InitSchema(ctx); err != nil {
 			log.Fatalf("Failed to initialize database schema: %v", err)
 		}
@@ -76,58 +102,129 @@ You can filter by repository using the --repo flag.`,
 			log.Fatalf("Failed to create GitHub client: %v", err)
 		}
 
-		// Print reviews in a table format
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "Repository\tPR Number\tStatus\tReviewed At")
-		fmt.Fprintln(w, "----------\t---------\t------\t-----------")
-
-		for _, review := range reviews {
-			// Parse repository to get owner and repo name
-			parts := strings.Split(review.Repo, "/")
-			if len(parts) != 2 {
-				logger.Debug("Invalid repository format: %s", review.Repo)
-				continue
+		// Look up the state of each reviewed PR
+		type reviewRow struct {
+			review db.Review
+			state  string
+			url    string
+		}
+		rows, err := ui.WithSpinner(ctx, "Fetching pull request states", func() ([]reviewRow, error) {
+			var rows []reviewRow
+			latest := make(map[int64]bool)
+			for _, review := range latestReviews(reviews) {
+				latest[review.ID] = true
 			}
-			owner, repoName := parts[0], parts[1]
-
-			// Get PR status
-			pr, _, err := client.PullRequests.Get(ctx, owner, repoName, review.PRNumber)
-			if err != nil {
-				logger.Debug("Failed to fetch PR status for %s #%d: %v",
-					review.Repo, review.PRNumber, err)
-				// If we can't fetch the PR and --all is not set, skip it
-				if !all {
+			for _, review := range reviews {
+				// Parse repository to get owner and repo name
+				parts := strings.Split(review.Repo, "/")
+				if len(parts) != 2 {
+					logger.Debug("Invalid repository format: %s", review.Repo)
 					continue
 				}
-				fmt.Fprintf(w, "%s\t#%d\t%s\t%s\n",
-					review.Repo,
-					review.PRNumber,
-					"unknown",
-					review.Timestamp.Format(time.RFC822))
-				continue
+				owner, repoName := parts[0], parts[1]
+
+				row := reviewRow{
+					review: review,
+					state:  "unknown",
+					url:    fmt.Sprintf("https://github.com/%s/pull/%d", review.Repo, review.PRNumber),
+				}
+				pr, _, err := client.PullRequests.Get(ctx, owner, repoName, review.PRNumber)
+				if err != nil {
+					logger.Debug("Failed to fetch PR status for %s #%d: %v",
+						review.Repo, review.PRNumber, err)
+					// If we can't fetch the PR and --all is not set, skip it
+					if !all {
+						continue
+					}
+				} else {
+					// Skip closed PRs unless --all is set
+					if !all && pr.GetState() == "closed" {
+						continue
+					}
+					row.state = pr.GetState()
+					row.url = pr.GetHTMLURL()
+					// Only the latest review of a PR can be out of date
+					if latest[review.ID] {
+						if _, stale := changedSinceReview(ctx, client, pr, review); stale {
+							row.state += " STALE"
+						}
+					}
+				}
+				rows = append(rows, row)
 			}
+			return rows, nil
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
 
-			// Skip closed PRs unless --all is set
-			if !all && *pr.State == "closed" {
-				continue
+		// Output that isn't going to a terminal is printed as a plain table
+		if !term.IsTerminal(os.Stdout.Fd()) {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Repository\tPR Number\tStatus\tReviewed At\tVerdict\tNote\tGitHub Review")
+			fmt.Fprintln(w, "----------\t---------\t------\t-----------\t-------\t----\t-------------")
+			for _, row := range rows {
+				fmt.Fprintf(w, "%s\t#%d\t%s\t%s\t%s\t%s\t%s\n",
+					row.review.Repo,
+					row.review.PRNumber,
+					row.state,
+					locale.DateTime(row.review.Timestamp),
+					row.review.Verdict,
+					truncateTitle(firstLine(row.review.Note), 50),
+					row.review.GitHubReviewURL)
 			}
+			w.Flush()
+			return
+		}
+		if len(rows) == 0 {
+			fmt.Println("No reviews found")
+			return
+		}
 
-			fmt.Fprintf(w, "%s\t#%d\t%s\t%s\n",
-				review.Repo,
-				review.PRNumber,
-				*pr.State,
-				review.Timestamp.Format(time.RFC822))
+		columns := []ui.ListColumn{
+			{Title: "Repository", Width: 30},
+			{Title: "PR", Width: 8},
+			{Title: "Status", Width: 11},
+			{Title: "Reviewed At", Width: 30},
+			{Title: "Verdict", Width: 17},
+			{Title: "Note", Width: 40},
+		}
+		listRows := make([]ui.ListRow, 0, len(rows))
+		for _, row := range rows {
+			listRows = append(listRows, ui.ListRow{
+				Cells: []string{
+					row.review.Repo,
+					fmt.Sprintf("#%d", row.review.PRNumber),
+					row.state,
+					locale.DateTime(row.review.Timestamp.Local()),
+					row.review.Verdict,
+					firstLine(row.review.Note),
+				},
+				URL: row.url,
+				// Localized dates don't sort as text
+				SortKeys: []string{3: row.review.Timestamp.UTC().Format(time.RFC3339)},
+			})
 		}
 
-		w.Flush()
+		p := tea.NewProgram(ui.NewListTable(columns, listRows), tea.WithAltScreen())
+		if _, err := p.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running review table: %v\n", err)
+			os.Exit(1)
+		}
 	},
 }
 
+// firstLine returns the first line of a possibly multi-line text
+func firstLine(text string) string {
+	return strings.SplitN(text, "\n", 2)[0]
+}
+
 func init() {
 	rootCmd.AddCommand(reviewCmd)
 
 	// Define flags
-	reviewCmd.Flags().StringP("repo", "r", "", "Filter reviews by repository (owner/repo)")
+	reviewCmd.Flags().StringP("repo", "r", "", "Filter reviews by repository (owner/repo, default from git remote)")
 	reviewCmd.Flags().BoolP("all", "a", false, "Show all reviews, including closed PRs")
+	reviewCmd.Flags().String("reviewer", "", "Show the reviews of another reviewer (requires a shared database)")
 	reviewCmd.Flags().StringP("config", "c", "", "Path to the configuration file")
 }
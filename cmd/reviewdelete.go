@@ -0,0 +1,156 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jbrinkman/ghi/pkg/db"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// reviewDeleteCmd represents the review delete command
+var reviewDeleteCmd = &cobra.Command{
+	Use:   "delete [ID...]",
+	Short: "Delete logged reviews",
+	Long: `The 'review delete' command removes reviews you logged by mistake. Give the
+IDs shown by 'ghi review list', or --number to delete every review you logged
+for a pull request.
+
+The reviews are listed and you're asked to confirm unless --yes is given. Only
+your own reviews can be deleted, even in a shared database.`,
+	Example: `  ghi review delete 42
+  ghi review delete -r octocat/Hello-World -n 123`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoFlag, _ := cmd.Flags().GetString("repo")
+		number, _ := cmd.Flags().GetInt("number")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		if len(args) == 0 && number == 0 {
+			log.Fatal("Give the IDs of the reviews to delete or the --number of a pull request")
+		}
+		if len(args) > 0 && number != 0 {
+			log.Fatal("Give either review IDs or --number, not both")
+		}
+		ids, err := parseReviewIDs(args)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var repo string
+		if number != 0 {
+			if repo, err = resolveRepo(repoFlag); err != nil {
+				log.Fatalf("The --repo flag is required when not in a GitHub repository clone: %v", err)
+			}
+			if len(strings.Split(repo, "/")) != 2 {
+				log.Fatal("Invalid repository format. Use 'owner/repo'")
+			}
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Deleting reviews %v of %s #%d", ids, repo, number)
+
+		// Check for username
+		username := os.Getenv("GHI_USERNAME")
+		if username == "" {
+			log.Fatal("GHI_USERNAME environment variable not set. Use 'ghi auth set --username YOUR_USERNAME' to set it")
+		}
+
+		// Connect to database
+		dbClient, err := db.NewClient()
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer dbClient.Close()
+
+		// Initialize schema if needed
+		ctx := cmd.Context()
+		if err := dbClient.InitSchema(ctx); err != nil {
+			log.Fatalf("Failed to initialize database schema: %v", err)
+		}
+
+		var reviews []db.Review
+		if number != 0 {
+			all, err := dbClient.GetReviews(ctx, repo, number)
+			if err != nil {
+				log.Fatalf("Failed to fetch reviews: %v", err)
+			}
+			for _, review := range all {
+				if strings.EqualFold(review.Reviewer, username) {
+					reviews = append(reviews, review)
+				}
+			}
+			if len(reviews) == 0 {
+				fmt.Printf("You haven't logged any reviews of %s #%d\n", repo, number)
+				return
+			}
+		} else {
+			for _, id := range ids {
+				review, err := getOwnReview(ctx, dbClient, id, username)
+				if err != nil {
+					log.Fatal(err)
+				}
+				reviews = append(reviews, *review)
+			}
+		}
+
+		printReviewRows(reviews)
+		fmt.Println()
+		if !yes && !confirm(ctx, "Delete these reviews?") {
+			fmt.Println("Cancelled")
+			return
+		}
+
+		for _, review := range reviews {
+			if err := dbClient.DeleteReview(ctx, review.ID); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("✅ Deleted review %d of %s #%d\n", review.ID, review.Repo, review.PRNumber)
+		}
+	},
+}
+
+// parseReviewIDs parses review IDs given as arguments
+func parseReviewIDs(args []string) ([]int64, error) {
+	ids := make([]int64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid review ID %q. Use the IDs shown by 'ghi review list'", arg)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// getOwnReview fetches a review by ID, checking it was logged by the user
+func getOwnReview(ctx context.Context, dbClient *db.Client, id int64, username string) (*db.Review, error) {
+	review, err := dbClient.GetReview(ctx, id)
+	if errors.Is(err, db.ErrReviewNotFound) {
+		return nil, fmt.Errorf("there's no review with ID %d. Use 'ghi review list' to see the IDs", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(review.Reviewer, username) {
+		return nil, fmt.Errorf("review %d was logged by %s, you can only change your own reviews", id, review.Reviewer)
+	}
+	return review, nil
+}
+
+func init() {
+	reviewCmd.AddCommand(reviewDeleteCmd)
+
+	// Define flags
+	reviewDeleteCmd.Flags().StringP("repo", "r", "", "The repository of --number (owner/repo, default from git remote)")
+	reviewDeleteCmd.Flags().IntP("number", "n", 0, "Delete every review you logged for this pull request")
+	reviewDeleteCmd.Flags().BoolP("yes", "y", false, "Delete without asking for confirmation")
+}
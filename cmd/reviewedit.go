@@ -0,0 +1,121 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/db"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// reviewEditCmd represents the review edit command
+var reviewEditCmd = &cobra.Command{
+	Use:   "edit ID",
+	Short: "Correct the time, note or verdict of a logged review",
+	Long: `The 'review edit' command corrects a review you logged. Give the ID shown by
+'ghi review list' and the fields to change. An empty --note or --verdict clears it.
+
+The review is shown before and after the change and you're asked to confirm
+unless --yes is given. Only your own reviews can be edited, even in a shared database.`,
+	Example: `  ghi review edit 42 --at 2025-03-14T09:30
+  ghi review edit 42 --note "Looked at the migration only" --verdict commented`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		at, _ := cmd.Flags().GetString("at")
+		note, _ := cmd.Flags().GetString("note")
+		verdict, _ := cmd.Flags().GetString("verdict")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		ids, err := parseReviewIDs(args)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !cmd.Flags().Changed("at") && !cmd.Flags().Changed("note") && !cmd.Flags().Changed("verdict") {
+			log.Fatal("Nothing to change. Use --at, --note or --verdict")
+		}
+		if err := db.ValidateVerdict(verdict); err != nil {
+			log.Fatal(err)
+		}
+
+		var timestamp time.Time
+		if at != "" {
+			if timestamp, err = time.ParseInLocation("2006-01-02T15:04", at, time.Local); err != nil {
+				if timestamp, err = time.ParseInLocation(time.DateOnly, at, time.Local); err != nil {
+					log.Fatalf("Invalid time %q. Use YYYY-MM-DD or YYYY-MM-DDTHH:MM", at)
+				}
+			}
+			if timestamp.After(time.Now()) {
+				log.Fatal("The --at flag must be in the past")
+			}
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Editing review %d, at: %q, note: %q, verdict: %q", ids[0], at, note, verdict)
+
+		// Check for username
+		username := os.Getenv("GHI_USERNAME")
+		if username == "" {
+			log.Fatal("GHI_USERNAME environment variable not set. Use 'ghi auth set --username YOUR_USERNAME' to set it")
+		}
+
+		// Connect to database
+		dbClient, err := db.NewClient()
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer dbClient.Close()
+
+		// Initialize schema if needed
+		ctx := cmd.Context()
+		if err := dbClient.InitSchema(ctx); err != nil {
+			log.Fatalf("Failed to initialize database schema: %v", err)
+		}
+
+		review, err := getOwnReview(ctx, dbClient, ids[0], username)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		edited := *review
+		if cmd.Flags().Changed("at") {
+			edited.Timestamp = timestamp
+		}
+		if cmd.Flags().Changed("note") {
+			edited.Note = note
+		}
+		if cmd.Flags().Changed("verdict") {
+			edited.Verdict = verdict
+		}
+
+		fmt.Println("Before:")
+		printReviewRows([]db.Review{*review})
+		fmt.Println("\nAfter:")
+		printReviewRows([]db.Review{edited})
+		fmt.Println()
+		if !yes && !confirm(ctx, "Save the changes?") {
+			fmt.Println("Cancelled")
+			return
+		}
+
+		if err := dbClient.UpdateReview(ctx, edited); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("✅ Updated review %d of %s #%d\n", edited.ID, edited.Repo, edited.PRNumber)
+	},
+}
+
+func init() {
+	reviewCmd.AddCommand(reviewEditCmd)
+
+	// Define flags
+	reviewEditCmd.Flags().String("at", "", "When the review was done, in local time (YYYY-MM-DD or YYYY-MM-DDTHH:MM)")
+	reviewEditCmd.Flags().String("note", "", "The note to store with the review")
+	reviewEditCmd.Flags().String("verdict", "", "The outcome of the review: approved, changes-requested or commented")
+	reviewEditCmd.Flags().BoolP("yes", "y", false, "Save without asking for confirmation")
+}
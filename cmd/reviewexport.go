@@ -0,0 +1,163 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/clients"
+	"github.com/jbrinkman/ghi/pkg/db"
+	"github.com/jbrinkman/ghi/pkg/export"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// reviewExportCmd represents the review export command
+var reviewExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export logged reviews as CSV, JSON, TSV or plain text",
+	Long: `The 'review export' command writes the reviews logged in the database to a
+CSV or JSON file, for performance reviews and team reporting.
+
+For awk and cut pipelines, --format tsv writes tab-separated values and
+--format plain writes fixed-width columns separated by spaces, without a
+header, borders or colors.
+
+By default the last 30 days are exported. Use --start-date and --end-date to
+choose a different range. Each review includes the current state and title of
+its pull request, looked up on GitHub unless --no-pr-state is given.`,
+	Example: `  ghi review export --format csv --start-date 2024-01-01 --end-date 2024-12-31 -o reviews-2024.csv
+  ghi review export --format plain --no-pr-state | awk '$6 == "approved" { print $2 }'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, _ := cmd.Flags().GetString("repo")
+		startFlag, _ := cmd.Flags().GetString("start-date")
+		endFlag, _ := cmd.Flags().GetString("end-date")
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		noPRState, _ := cmd.Flags().GetBool("no-pr-state")
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Repository filter: %s", repo)
+		logger.Debug("Date range: %s - %s, format: %s, output: %s", startFlag, endFlag, format, output)
+
+		if err := export.Validate(format); err != nil {
+			log.Fatal(err)
+		}
+		if repo != "" {
+			repo = expandRepoAlias(repo)
+		}
+
+		startDate, endDate, err := parseDateRange(startFlag, endFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Connect to database
+		dbClient, err := db.NewClient()
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer dbClient.Close()
+
+		// Initialize schema if needed
+		ctx := cmd.Context()
+		if err := dbClient.InitSchema(ctx); err != nil {
+			log.Fatalf("Failed to initialize database schema: %v", err)
+		}
+
+		reviews, err := dbClient.GetReviewsByDateRange(ctx, repo, startDate, endDate)
+		if err != nil {
+			log.Fatalf("Failed to fetch reviews: %v", err)
+		}
+		logger.Debug("Exporting %d reviews", len(reviews))
+
+		exported := make([]db.ExportedReview, 0, len(reviews))
+		for _, review := range reviews {
+			exported = append(exported, db.ExportedReview{Review: review})
+		}
+
+		if !noPRState && len(exported) > 0 {
+			client, err := clients.NewGitHubClient()
+			if err != nil {
+				log.Fatalf("Failed to create GitHub client: %v", err)
+			}
+			_, err = ui.WithSpinner(ctx, "Fetching pull request states", func() (struct{}, error) {
+				addPRStates(ctx, client, exported)
+				return struct{}{}, nil
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		var w io.Writer = os.Stdout
+		if output != "" {
+			file, err := os.Create(output)
+			if err != nil {
+				log.Fatalf("Failed to create %s: %v", output, err)
+			}
+			defer file.Close()
+			w = file
+		}
+
+		if err := db.WriteReviews(w, format, exported); err != nil {
+			log.Fatal(err)
+		}
+		if output != "" {
+			fmt.Fprintf(os.Stderr, "✅ Exported %d reviews to %s\n", len(exported), output)
+		}
+	},
+}
+
+// addPRStates sets the current state and title of each review's pull request,
+// looking each pull request up once. Pull requests that can't be fetched are
+// left without a state.
+func addPRStates(ctx context.Context, client *github.Client, reviews []db.ExportedReview) {
+	type prInfo struct{ state, title string }
+	fetched := make(map[string]*prInfo)
+
+	for i := range reviews {
+		review := &reviews[i]
+		key := fmt.Sprintf("%s#%d", strings.ToLower(review.Repo), review.PRNumber)
+		info, ok := fetched[key]
+		if !ok {
+			parts := strings.Split(review.Repo, "/")
+			if len(parts) == 2 {
+				pr, _, err := client.PullRequests.Get(ctx, parts[0], parts[1], review.PRNumber)
+				if err != nil {
+					logger.Debug("Failed to fetch %s #%d: %v", review.Repo, review.PRNumber, err)
+				} else {
+					info = &prInfo{state: pr.GetState(), title: pr.GetTitle()}
+					if pr.GetMerged() || pr.MergedAt != nil {
+						info.state = "merged"
+					}
+				}
+			}
+			fetched[key] = info
+		}
+		if info != nil {
+			review.PRState = info.state
+			review.PRTitle = info.title
+		}
+	}
+}
+
+func init() {
+	reviewCmd.AddCommand(reviewExportCmd)
+
+	// Define flags
+	reviewExportCmd.Flags().StringP("repo", "r", "", "Filter reviews by repository (owner/repo)")
+	reviewExportCmd.Flags().StringP("start-date", "s", "", "Start date in YYYY-MM-DD format (default 30 days ago)")
+	reviewExportCmd.Flags().StringP("end-date", "e", "", "End date in YYYY-MM-DD format (default today)")
+	reviewExportCmd.Flags().StringP("format", "f", export.CSV, "Output format (csv, json, tsv, plain)")
+	reviewExportCmd.Flags().StringP("output", "o", "", "File to write the export to (default stdout)")
+	reviewExportCmd.Flags().Bool("no-pr-state", false, "Don't look up the state of each pull request on GitHub")
+}
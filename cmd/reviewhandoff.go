@@ -0,0 +1,168 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	"github.com/jbrinkman/ghi/pkg/db"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/locale"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// reviewHandoffCmd represents the review handoff command
+var reviewHandoffCmd = &cobra.Command{
+	Use:   "handoff",
+	Short: "Hand the rest of a review on to another reviewer",
+	Long: `The 'review handoff' command records that you reviewed part of a pull request
+and passed the rest on to someone else, with a note of what you covered, so
+partial reviews are picked up instead of done twice. Use --comment to also post
+the handoff on the pull request, mentioning the reviewer it's passed to.
+
+Without --to, the handoffs of the pull request given with --number are listed,
+or the handoffs passed to you when --number isn't given.
+
+Handoffs are stored in a shared database, so the reviewer they're passed to
+can see them.`,
+	Example: `  ghi review handoff -n 123 --to bob --note "checked DB layer, please look at API"
+  ghi review handoff -r octocat/Hello-World -n 123 --to bob --note "API left" --comment
+  ghi review handoff -n 123
+  ghi review handoff`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoFlag, _ := cmd.Flags().GetString("repo")
+		number, _ := cmd.Flags().GetInt("number")
+		to, _ := cmd.Flags().GetString("to")
+		note, _ := cmd.Flags().GetString("note")
+		comment, _ := cmd.Flags().GetBool("comment")
+
+		to = strings.TrimPrefix(strings.TrimSpace(to), "@")
+		if to == "" && (note != "" || comment) {
+			log.Fatal("The --note and --comment flags need --to, the reviewer to hand the review to")
+		}
+		if to != "" && number == 0 {
+			log.Fatal("The --number flag is required to hand off a review")
+		}
+
+		// Listing the handoffs to you isn't limited to a repository unless asked
+		repo, err := resolveRepo(repoFlag)
+		if err != nil && (number != 0 || repoFlag != "") {
+			log.Fatalf("The --repo flag is required when not in a GitHub repository clone: %v", err)
+		}
+		if repo != "" && len(strings.Split(repo, "/")) != 2 {
+			log.Fatal("Invalid repository format. Use 'owner/repo'")
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Handoff of %s #%d to %q", repo, number, to)
+
+		// Check for username
+		username := os.Getenv("GHI_USERNAME")
+		if username == "" {
+			log.Fatal("GHI_USERNAME environment variable not set. Use 'ghi auth set --username YOUR_USERNAME' to set it")
+		}
+		if strings.EqualFold(to, username) {
+			log.Fatal("You can't hand a review off to yourself")
+		}
+
+		// Connect to database
+		dbClient, err := db.NewClient()
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer dbClient.Close()
+		requireSharedDatabase(dbClient, "ghi review handoff")
+
+		// Initialize schema if needed
+		ctx := cmd.Context()
+		if err := dbClient.InitSchema(ctx); err != nil {
+			log.Fatalf("Failed to initialize database schema: %v", err)
+		}
+
+		if to == "" {
+			var handoffs []db.Handoff
+			if number != 0 {
+				handoffs, err = dbClient.GetHandoffs(ctx, repo, number)
+			} else {
+				handoffs, err = dbClient.GetHandoffsTo(ctx, username, repo)
+			}
+			if err != nil {
+				log.Fatalf("Failed to fetch handoffs: %v", err)
+			}
+			if len(handoffs) == 0 {
+				fmt.Println("No handoffs found")
+				return
+			}
+			printHandoffs(handoffs)
+			return
+		}
+
+		handoff := db.Handoff{Repo: repo, PRNumber: number, From: username, To: to, Note: note}
+		if comment {
+			client, err := clients.NewGitHubClient()
+			if err != nil {
+				log.Fatalf("Failed to create GitHub client: %v", err)
+			}
+			owner, name, _ := strings.Cut(repo, "/")
+			posted, err := gh.AddComment(ctx, client, owner, name, number, handoffComment(handoff))
+			if err != nil {
+				log.Fatal(err)
+			}
+			handoff.CommentURL = posted.GetHTMLURL()
+		}
+
+		id, err := dbClient.LogHandoff(ctx, handoff)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("✅ Handed off the review of %s #%d to %s (handoff %d)\n", repo, number, to, id)
+		if handoff.CommentURL != "" {
+			fmt.Printf("Comment: %s\n", handoff.CommentURL)
+		}
+	},
+}
+
+// handoffComment renders the comment posted on a pull request for a handoff
+func handoffComment(handoff db.Handoff) string {
+	body := fmt.Sprintf("@%s I've reviewed part of this pull request and handed the rest of the review to you.", handoff.To)
+	if handoff.Note != "" {
+		body += "\n\n> " + strings.ReplaceAll(strings.TrimSpace(handoff.Note), "\n", "\n> ")
+	}
+	return body
+}
+
+// printHandoffs prints handoffs as a plain table
+func printHandoffs(handoffs []db.Handoff) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tRepository\tPR Number\tFrom\tTo\tHanded Off At\tNote")
+	fmt.Fprintln(w, "--\t----------\t---------\t----\t--\t-------------\t----")
+	for _, handoff := range handoffs {
+		fmt.Fprintf(w, "%d\t%s\t#%d\t%s\t%s\t%s\t%s\n",
+			handoff.ID,
+			handoff.Repo,
+			handoff.PRNumber,
+			handoff.From,
+			handoff.To,
+			locale.DateTime(handoff.Timestamp.Local()),
+			truncateTitle(firstLine(handoff.Note), 50))
+	}
+	w.Flush()
+}
+
+func init() {
+	reviewCmd.AddCommand(reviewHandoffCmd)
+
+	// Define flags
+	reviewHandoffCmd.Flags().StringP("repo", "r", "", "The repository of the pull request (owner/repo, default from git remote)")
+	reviewHandoffCmd.Flags().IntP("number", "n", 0, "The pull request number")
+	reviewHandoffCmd.Flags().String("to", "", "The GitHub username of the reviewer to hand the review to")
+	reviewHandoffCmd.Flags().String("note", "", "What you reviewed and what's left")
+	reviewHandoffCmd.Flags().Bool("comment", false, "Also post the handoff as a comment on the pull request")
+}
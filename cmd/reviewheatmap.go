@@ -0,0 +1,93 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/db"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// reviewHeatmapCmd represents the review heatmap command
+var reviewHeatmapCmd = &cobra.Command{
+	Use:   "heatmap",
+	Short: "Show a heatmap of your logged reviews",
+	Long: `The 'review heatmap' command renders a GitHub-style contribution heatmap of the
+reviews you logged during a year. Use --format svg to produce an SVG image that
+can be embedded in documentation.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		year, _ := cmd.Flags().GetInt("year")
+		format, _ := cmd.Flags().GetString("format")
+		reviewer, _ := cmd.Flags().GetString("reviewer")
+		repo, _ := cmd.Flags().GetString("repo")
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Year: %d, format: %s, reviewer: %s, repo: %s", year, format, reviewer, repo)
+
+		if format != "terminal" && format != "svg" {
+			log.Fatalf("Invalid format %q. Use 'terminal' or 'svg'", format)
+		}
+
+		// Default to your own reviews
+		username := os.Getenv("GHI_USERNAME")
+		if reviewer == "" {
+			reviewer = username
+			if reviewer == "" {
+				log.Fatal("GHI_USERNAME environment variable not set. Use 'ghi auth set --username YOUR_USERNAME' to set it")
+			}
+		}
+
+		// Connect to database
+		dbClient, err := db.NewClient()
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer dbClient.Close()
+
+		// Other people's reviews are only available in a shared database
+		if !strings.EqualFold(reviewer, username) {
+			requireSharedDatabase(dbClient, "ghi review heatmap --reviewer")
+		}
+
+		// Initialize schema if needed
+		ctx := cmd.Context()
+		if err := dbClient.InitSchema(ctx); err != nil {
+			log.Fatalf("Failed to initialize database schema: %v", err)
+		}
+
+		startDate := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		endDate := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+		counts, err := dbClient.GetDailyReviewCounts(ctx, reviewer, repo, startDate, endDate)
+		if err != nil {
+			log.Fatalf("Failed to fetch reviews: %v", err)
+		}
+		logger.Debug("Found reviews on %d days", len(counts))
+
+		heatmap := ui.NewHeatmap(year, counts)
+		if format == "svg" {
+			fmt.Print(heatmap.SVG())
+			return
+		}
+
+		fmt.Printf("%d reviews by %s in %d\n\n", heatmap.Total(), reviewer, year)
+		fmt.Print(heatmap.Render())
+	},
+}
+
+func init() {
+	reviewCmd.AddCommand(reviewHeatmapCmd)
+
+	// Define flags
+	reviewHeatmapCmd.Flags().IntP("year", "y", time.Now().Year(), "The year to show")
+	reviewHeatmapCmd.Flags().StringP("format", "f", "terminal", "Output format (terminal, svg)")
+	reviewHeatmapCmd.Flags().String("reviewer", "", "Show reviews by this reviewer (default GHI_USERNAME)")
+	reviewHeatmapCmd.Flags().StringP("repo", "r", "", "Filter reviews by repository (owner/repo)")
+}
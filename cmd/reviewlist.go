@@ -0,0 +1,93 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/jbrinkman/ghi/pkg/db"
+	"github.com/jbrinkman/ghi/pkg/locale"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// reviewListCmd represents the review list command
+var reviewListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List your logged reviews with their IDs",
+	Long: `The 'review list' command lists the reviews you logged, newest first, with
+the ID of each. Use the IDs with 'ghi review edit' and 'ghi review delete'.
+
+Unlike 'ghi review', it only reads the database, so it lists every logged
+review quickly, including those of closed pull requests.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoFlag, _ := cmd.Flags().GetString("repo")
+
+		// The repository filter is optional, so detection failures just mean no filter
+		repo, err := resolveRepo(repoFlag)
+		if err != nil {
+			logger.Debug("No repository filter: %v", err)
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Repository filter: %s", repo)
+
+		// Check for username
+		username := os.Getenv("GHI_USERNAME")
+		if username == "" {
+			log.Fatal("GHI_USERNAME environment variable not set. Use 'ghi auth set --username YOUR_USERNAME' to set it")
+		}
+
+		// Connect to database
+		dbClient, err := db.NewClient()
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer dbClient.Close()
+
+		// Initialize schema if needed
+		ctx := cmd.Context()
+		if err := dbClient.InitSchema(ctx); err != nil {
+			log.Fatalf("Failed to initialize database schema: %v", err)
+		}
+
+		reviews, err := dbClient.GetReviewsByReviewer(ctx, username, repo)
+		if err != nil {
+			log.Fatalf("Failed to fetch reviews: %v", err)
+		}
+		if len(reviews) == 0 {
+			fmt.Println("No reviews found")
+			return
+		}
+
+		printReviewRows(reviews)
+	},
+}
+
+// printReviewRows prints logged reviews with their IDs as a table
+func printReviewRows(reviews []db.Review) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tRepository\tPR Number\tReviewed At\tVerdict\tNote")
+	fmt.Fprintln(w, "--\t----------\t---------\t-----------\t-------\t----")
+	for _, review := range reviews {
+		fmt.Fprintf(w, "%d\t%s\t#%d\t%s\t%s\t%s\n",
+			review.ID,
+			review.Repo,
+			review.PRNumber,
+			locale.DateTime(review.Timestamp.Local()),
+			review.Verdict,
+			truncateTitle(firstLine(review.Note), 40))
+	}
+	w.Flush()
+}
+
+func init() {
+	reviewCmd.AddCommand(reviewListCmd)
+
+	// Define flags
+	reviewListCmd.Flags().StringP("repo", "r", "", "Filter reviews by repository (owner/repo, default from git remote)")
+}
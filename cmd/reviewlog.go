@@ -0,0 +1,124 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	"github.com/jbrinkman/ghi/pkg/db"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// reviewLogCmd represents the review log command
+var reviewLogCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Log reviews for one or more pull requests",
+	Long: `The 'review log' command records that you reviewed one or more pull requests
+without viewing them first. This is useful for batches of related small PRs,
+such as dependency updates, that you review together.
+
+Repeat --number, pass a comma separated list or a range such as 10-20 to log
+several pull requests at once. An optional --note and --verdict are stored with
+each review. When no verdict is given, it's taken from your submitted GitHub review.
+
+Use --paths to record which files or areas you reviewed, or --select-paths to
+pick them from each pull request's changed files.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, _ := cmd.Flags().GetString("repo")
+		numberArgs, _ := cmd.Flags().GetStringSlice("number")
+		note, _ := cmd.Flags().GetString("note")
+		verdict, _ := cmd.Flags().GetString("verdict")
+		paths, _ := cmd.Flags().GetStringArray("paths")
+		selectPaths, _ := cmd.Flags().GetBool("select-paths")
+
+		if repo == "" {
+			log.Fatal("The --repo flag is required")
+		}
+		if len(strings.Split(repo, "/")) != 2 {
+			log.Fatal("Invalid repository format. Use 'owner/repo'")
+		}
+
+		if err := db.ValidateVerdict(verdict); err != nil {
+			log.Fatal(err)
+		}
+
+		numbers, err := parsePRNumbers(numberArgs)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(numbers) == 0 {
+			log.Fatal("The --number flag is required")
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Repository: %s, PR Numbers: %v, Note: %q", repo, numbers, note)
+
+		// Check for username
+		username := os.Getenv("GHI_USERNAME")
+		if username == "" {
+			log.Fatal("GHI_USERNAME environment variable not set. Use 'ghi auth set --username YOUR_USERNAME' to set it")
+		}
+
+		// Connect to database
+		dbClient, err := db.NewClient()
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer dbClient.Close()
+
+		// Initialize schema if needed
+		ctx := cmd.Context()
+		if err := dbClient.InitSchema(ctx); err != nil {
+			log.Fatalf("Failed to initialize database schema: %v", err)
+		}
+
+		// A GitHub client is only needed to link the submitted GitHub reviews
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			logger.Debug("Failed to create GitHub client, reviews won't be linked: %v", err)
+		}
+
+		failed := 0
+		for _, number := range numbers {
+			review := db.Review{
+				Repo:     repo,
+				PRNumber: number,
+				Reviewer: username,
+				Note:     note,
+				Verdict:  verdict,
+				Paths:    reviewPaths(ctx, client, repo, number, paths, selectPaths),
+			}
+			linkGitHubReview(ctx, client, dbClient, &review)
+
+			if err := dbClient.LogReview(ctx, review); err != nil {
+				logger.Debug("Failed to log review for #%d: %v", number, err)
+				fmt.Fprintf(os.Stderr, "Failed to log review for %s #%d: %v\n", repo, number, err)
+				failed++
+				continue
+			}
+			fmt.Printf("✅ Review logged for %s #%d\n", repo, number)
+		}
+
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	reviewCmd.AddCommand(reviewLogCmd)
+
+	// Define flags
+	reviewLogCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo)")
+	reviewLogCmd.Flags().StringSliceP("number", "n", []string{}, "The number of the pull request (repeatable, comma separated or a range like 10-20)")
+	reviewLogCmd.Flags().String("note", "", "A note to store with each review")
+	reviewLogCmd.Flags().String("verdict", "", "The outcome of the reviews: approved, changes-requested or commented")
+	reviewLogCmd.Flags().StringArray("paths", []string{}, "Files or areas reviewed, e.g. 'pkg/db/**' (repeatable)")
+	reviewLogCmd.Flags().Bool("select-paths", false, "Choose the reviewed files from each PR's changed files")
+}
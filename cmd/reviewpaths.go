@@ -0,0 +1,87 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
+)
+
+// reviewPaths returns the paths to record with a review of the given PR. The
+// paths given on the command line are always included, and when interactive
+// is set the user is prompted to pick from the PR's changed files as well.
+func reviewPaths(ctx context.Context, client *github.Client, repo string, number int, paths []string, interactive bool) []string {
+	selected := append([]string{}, paths...)
+	if !interactive {
+		return selected
+	}
+
+	parts := strings.Split(repo, "/")
+	if client == nil || len(parts) != 2 {
+		return selected
+	}
+
+	chosen, err := selectChangedPaths(ctx, client, parts[0], parts[1], number)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not select changed files for #%d: %v\n", number, err)
+		return selected
+	}
+	return append(selected, chosen...)
+}
+
+// selectChangedPaths lists the files changed by a pull request and prompts
+// the user to choose the ones they reviewed
+func selectChangedPaths(ctx context.Context, client *github.Client, owner, repo string, number int) ([]string, error) {
+	files, err := gh.ChangedFiles(ctx, client, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	fmt.Printf("Files changed in %s/%s #%d:\n", owner, repo, number)
+	for i, file := range files {
+		fmt.Printf("  %3d) %s\n", i+1, file)
+	}
+	fmt.Print("Which files did you review? (e.g. 1,3-5, 'all', or blank for none): ")
+
+	line, err := ui.ReadLine(ctx)
+	if err != nil && line == "" {
+		return nil, fmt.Errorf("error reading selection: %w", err)
+	}
+
+	line = strings.TrimSpace(line)
+	switch line {
+	case "":
+		return nil, nil
+	case "all":
+		return files, nil
+	}
+
+	// The selection uses the same list and range syntax as PR numbers
+	indexes, err := parsePRNumbers([]string{line})
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []string
+	for _, index := range indexes {
+		if index < 1 || index > len(files) {
+			return nil, fmt.Errorf("file number %d is out of range", index)
+		}
+		selected = append(selected, files[index-1])
+	}
+
+	logger.Debug("Selected %d of %d changed files for #%d", len(selected), len(files), number)
+	return selected, nil
+}
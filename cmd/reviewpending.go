@@ -0,0 +1,160 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/clients"
+	"github.com/jbrinkman/ghi/pkg/db"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/locale"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// reviewPendingCmd represents the review pending command
+var reviewPendingCmd = &cobra.Command{
+	Use:   "pending",
+	Short: "List pull requests that changed since you reviewed them",
+	Long: `The 'review pending' command lists the open pull requests you reviewed that
+have new commits since your last logged review, so you know which ones need
+another look. The same pull requests are marked STALE in 'ghi review'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoFlag, _ := cmd.Flags().GetString("repo")
+
+		// The repository filter is optional, so detection failures just mean no filter
+		repo, err := resolveRepo(repoFlag)
+		if err != nil {
+			logger.Debug("No repository filter: %v", err)
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Repository filter: %s", repo)
+
+		// Check for username
+		username := os.Getenv("GHI_USERNAME")
+		if username == "" {
+			log.Fatal("GHI_USERNAME environment variable not set. Use 'ghi auth set --username YOUR_USERNAME' to set it")
+		}
+
+		// Connect to database
+		dbClient, err := db.NewClient()
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer dbClient.Close()
+
+		// Initialize schema if needed
+		ctx := cmd.Context()
+		if err := dbClient.InitSchema(ctx); err != nil {
+			log.Fatalf("Failed to initialize database schema: %v", err)
+		}
+
+		reviews, err := dbClient.GetReviewsByReviewer(ctx, username, repo)
+		if err != nil {
+			log.Fatalf("Failed to fetch reviews: %v", err)
+		}
+
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		type pendingRow struct {
+			review     db.Review
+			title      string
+			lastCommit time.Time
+		}
+		rows, err := ui.WithSpinner(ctx, "Checking reviewed pull requests for changes", func() ([]pendingRow, error) {
+			var rows []pendingRow
+			for _, review := range latestReviews(reviews) {
+				parts := strings.Split(review.Repo, "/")
+				if len(parts) != 2 {
+					logger.Debug("Invalid repository format: %s", review.Repo)
+					continue
+				}
+
+				pr, _, err := client.PullRequests.Get(ctx, parts[0], parts[1], review.PRNumber)
+				if err != nil {
+					logger.Debug("Failed to fetch %s #%d: %v", review.Repo, review.PRNumber, err)
+					continue
+				}
+				if lastCommit, changed := changedSinceReview(ctx, client, pr, review); changed {
+					rows = append(rows, pendingRow{review: review, title: pr.GetTitle(), lastCommit: lastCommit})
+				}
+			}
+			return rows, nil
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(rows) == 0 {
+			fmt.Println("No reviewed pull requests have changed since your last review")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Repository\tPR Number\tTitle\tReviewed At\tLast Commit")
+		fmt.Fprintln(w, "----------\t---------\t-----\t-----------\t-----------")
+		for _, row := range rows {
+			fmt.Fprintf(w, "%s\t#%d\t%s\t%s\t%s\n",
+				row.review.Repo,
+				row.review.PRNumber,
+				truncateTitle(row.title, 50),
+				locale.DateTime(row.review.Timestamp.Local()),
+				locale.DateTime(row.lastCommit.Local()))
+		}
+		w.Flush()
+	},
+}
+
+// latestReviews returns the most recent review of each pull request. The
+// reviews must be ordered newest first, as the database returns them.
+func latestReviews(reviews []db.Review) []db.Review {
+	seen := make(map[string]bool)
+	var latest []db.Review
+	for _, review := range reviews {
+		key := fmt.Sprintf("%s#%d", strings.ToLower(review.Repo), review.PRNumber)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		latest = append(latest, review)
+	}
+	return latest
+}
+
+// changedSinceReview reports whether an open pull request has commits made
+// after the review, and when the latest was made. The commits are only listed
+// when the pull request was updated after the review.
+func changedSinceReview(ctx context.Context, client *github.Client, pr *github.PullRequest, review db.Review) (time.Time, bool) {
+	if pr.GetState() != "open" || !pr.GetUpdatedAt().After(review.Timestamp) {
+		return time.Time{}, false
+	}
+
+	parts := strings.Split(review.Repo, "/")
+	lastCommit, err := gh.LatestCommitTime(ctx, client, parts[0], parts[1], review.PRNumber)
+	if err != nil {
+		logger.Debug("Failed to check commits of %s #%d: %v", review.Repo, review.PRNumber, err)
+		return time.Time{}, false
+	}
+	return lastCommit, lastCommit.After(review.Timestamp)
+}
+
+func init() {
+	reviewCmd.AddCommand(reviewPendingCmd)
+
+	// Define flags
+	reviewPendingCmd.Flags().StringP("repo", "r", "", "Filter reviews by repository (owner/repo, default from git remote)")
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jbrinkman/ghi/pkg/db"
+	"github.com/jbrinkman/ghi/pkg/locale"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// reviewSearchCmd represents the review search command
+var reviewSearchCmd = &cobra.Command{
+	Use:   "search QUERY",
+	Short: "Search the notes of logged reviews",
+	Long: `The 'review search' command searches the notes recorded with logged reviews
+using a full-text index, so you can find past reviews about a topic.
+
+Every word in the query must appear in the note. End a word with * to match
+words starting with it, for example 'retr*' matches 'retry' and 'retrying'.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, _ := cmd.Flags().GetString("repo")
+		limit, _ := cmd.Flags().GetInt("limit")
+		query := strings.Join(args, " ")
+
+		if repo != "" {
+			repo = expandRepoAlias(repo)
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Searching review notes for %q, repository filter: %s, limit: %d", query, repo, limit)
+
+		// Connect to database
+		dbClient, err := db.NewClient()
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer dbClient.Close()
+
+		// Initialize schema if needed
+		ctx := cmd.Context()
+		if err := dbClient.InitSchema(ctx); err != nil {
+			log.Fatalf("Failed to initialize database schema: %v", err)
+		}
+		// The index is created by the first search, so other commands work
+		// with databases that don't support full-text search
+		if err := dbClient.InitSearchIndex(ctx); err != nil {
+			log.Fatalf("Full-text search isn't available in this database: %v", err)
+		}
+
+		reviews, err := dbClient.SearchReviews(ctx, query, repo, limit)
+		if err != nil {
+			log.Fatalf("Failed to search reviews: %v", err)
+		}
+		logger.Debug("Found %d matching reviews", len(reviews))
+
+		if len(reviews) == 0 {
+			fmt.Printf("No reviews found with notes matching %q\n", query)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Repository\tPR Number\tReviewer\tReviewed At\tNote")
+		fmt.Fprintln(w, "----------\t---------\t--------\t-----------\t----")
+		for _, review := range reviews {
+			fmt.Fprintf(w, "%s\t#%d\t%s\t%s\t%s\n",
+				review.Repo,
+				review.PRNumber,
+				review.Reviewer,
+				locale.Date(review.Timestamp),
+				strings.Join(strings.Fields(review.Note), " "))
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	reviewCmd.AddCommand(reviewSearchCmd)
+
+	// Define flags
+	reviewSearchCmd.Flags().StringP("repo", "r", "", "Filter reviews by repository (owner/repo)")
+	reviewSearchCmd.Flags().IntP("limit", "l", 20, "Maximum number of reviews to show (0 for all)")
+}
@@ -0,0 +1,148 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/db"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// reviewStatsCmd represents the review stats command
+var reviewStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show statistics about logged reviews",
+	Long: `The 'review stats' command aggregates the reviews logged in the database
+by reviewer, repository, week and month. It shows review counts, the average
+number of reviews per day and the busiest repositories.
+
+By default the last 30 days are included. Use --start-date and --end-date to
+choose a different range, and --format json for machine-readable output.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, _ := cmd.Flags().GetString("repo")
+		startFlag, _ := cmd.Flags().GetString("start-date")
+		endFlag, _ := cmd.Flags().GetString("end-date")
+		format, _ := cmd.Flags().GetString("format")
+		top, _ := cmd.Flags().GetInt("top")
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Repository filter: %s", repo)
+		logger.Debug("Date range: %s - %s, format: %s", startFlag, endFlag, format)
+
+		if format != "table" && format != "json" {
+			log.Fatalf("Invalid format %q. Use 'table' or 'json'", format)
+		}
+
+		startDate, endDate, err := parseDateRange(startFlag, endFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Connect to database
+		dbClient, err := db.NewClient()
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer dbClient.Close()
+
+		// Initialize schema if needed
+		ctx := cmd.Context()
+		if err := dbClient.InitSchema(ctx); err != nil {
+			log.Fatalf("Failed to initialize database schema: %v", err)
+		}
+
+		stats, err := dbClient.GetReviewStats(ctx, repo, startDate, endDate)
+		if err != nil {
+			log.Fatalf("Failed to fetch review statistics: %v", err)
+		}
+		logger.Debug("Aggregated %d reviews", stats.Total)
+
+		if format == "json" {
+			out, err := prettyPrint(stats)
+			if err != nil {
+				log.Fatalf("Failed to format review statistics: %v", err)
+			}
+			fmt.Println(out)
+			return
+		}
+
+		printReviewStats(stats, top)
+	},
+}
+
+// printReviewStats writes the review statistics as a set of tables
+func printReviewStats(stats *db.ReviewStats, top int) {
+	fmt.Printf("Reviews from %s to %s\n",
+		stats.StartDate.Format("2006-01-02"), stats.EndDate.Format("2006-01-02"))
+	fmt.Printf("Total: %d (%.2f per day)\n", stats.Total, stats.PerDayAverage)
+
+	busiest := stats.ByRepo
+	if top > 0 && len(busiest) > top {
+		busiest = busiest[:top]
+	}
+
+	printReviewCounts("Reviewer", stats.ByReviewer)
+	printReviewCounts("Busiest Repositories", busiest)
+	printReviewCounts("Week", stats.ByWeek)
+	printReviewCounts("Month", stats.ByMonth)
+	if len(stats.ByPath) > 0 {
+		printReviewCounts("Path", stats.ByPath)
+	}
+}
+
+// printReviewCounts writes a two column table of review counts
+func printReviewCounts(title string, counts []db.ReviewCount) {
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tReviews\n", title)
+	fmt.Fprintln(w, "----------\t-------")
+	for _, count := range counts {
+		fmt.Fprintf(w, "%s\t%d\n", count.Key, count.Count)
+	}
+	w.Flush()
+}
+
+// parseDateRange parses YYYY-MM-DD start and end dates. The start date defaults
+// to 30 days ago and the end date defaults to today.
+func parseDateRange(start, end string) (time.Time, time.Time, error) {
+	now := time.Now()
+	startDate := now.AddDate(0, 0, -30)
+	endDate := now
+
+	var err error
+	if start != "" {
+		if startDate, err = time.Parse("2006-01-02", start); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start date %q, use YYYY-MM-DD: %w", start, err)
+		}
+	}
+	if end != "" {
+		if endDate, err = time.Parse("2006-01-02", end); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end date %q, use YYYY-MM-DD: %w", end, err)
+		}
+	}
+
+	if endDate.Before(startDate) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end date %s is before start date %s",
+			endDate.Format("2006-01-02"), startDate.Format("2006-01-02"))
+	}
+
+	return startDate, endDate, nil
+}
+
+func init() {
+	reviewCmd.AddCommand(reviewStatsCmd)
+
+	// Define flags
+	reviewStatsCmd.Flags().StringP("repo", "r", "", "Filter reviews by repository (owner/repo)")
+	reviewStatsCmd.Flags().StringP("start-date", "s", "", "Start date in YYYY-MM-DD format (default 30 days ago)")
+	reviewStatsCmd.Flags().StringP("end-date", "e", "", "End date in YYYY-MM-DD format (default today)")
+	reviewStatsCmd.Flags().StringP("format", "f", "table", "Output format (table, json)")
+	reviewStatsCmd.Flags().IntP("top", "t", 5, "Number of busiest repositories to show in table output (0 for all)")
+}
@@ -0,0 +1,217 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	"github.com/jbrinkman/ghi/pkg/db"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// reviewerCandidate is a possible secondary reviewer and their review history
+type reviewerCandidate struct {
+	Login        string
+	AreaReviews  int
+	TotalReviews int
+	LastArea     time.Time
+}
+
+// reviewSuggestCmd represents the review suggest command
+var reviewSuggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Suggest a secondary reviewer for a pull request",
+	Long: `The 'review suggest' command recommends a secondary reviewer for a pull request
+based on the reviews logged in the database and the files the pull request changes.
+
+In 'spread' mode (the default) reviewers who haven't reviewed the changed areas
+recently are preferred, to spread knowledge around the team. In 'expertise' mode
+reviewers who have reviewed those areas the most are preferred.
+
+Reviews only count towards an area when their paths were recorded with --paths
+or --select-paths.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoFlag, _ := cmd.Flags().GetString("repo")
+		number, _ := cmd.Flags().GetInt("pr")
+		mode, _ := cmd.Flags().GetString("mode")
+		days, _ := cmd.Flags().GetInt("days")
+		top, _ := cmd.Flags().GetInt("top")
+
+		if number <= 0 {
+			log.Fatal("The --pr flag is required")
+		}
+		if mode != "spread" && mode != "expertise" {
+			log.Fatalf("Invalid mode %q. Use 'spread' or 'expertise'", mode)
+		}
+
+		repo, err := resolveRepo(repoFlag)
+		if err != nil {
+			log.Fatalf("The --repo flag is required when not in a GitHub repository clone: %v", err)
+		}
+		parts := strings.Split(repo, "/")
+		if len(parts) != 2 {
+			log.Fatal("Invalid repository format. Use 'owner/repo'")
+		}
+		owner, repoName := parts[0], parts[1]
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Suggesting reviewers for %s #%d, mode: %s, days: %d", repo, number, mode, days)
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		pr, _, err := client.PullRequests.Get(ctx, owner, repoName, number)
+		if err != nil {
+			log.Fatalf("Error fetching pull request #%d: %v", number, err)
+		}
+
+		files, err := gh.ChangedFiles(ctx, client, owner, repoName, number)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// People already involved with the PR aren't useful suggestions
+		exclude := map[string]bool{strings.ToLower(pr.GetUser().GetLogin()): true}
+		if username := os.Getenv("GHI_USERNAME"); username != "" {
+			exclude[strings.ToLower(username)] = true
+		}
+		for _, user := range pr.RequestedReviewers {
+			exclude[strings.ToLower(user.GetLogin())] = true
+		}
+		if reviews, err := gh.ListAllReviews(ctx, client, owner, repoName, number); err == nil {
+			for _, review := range reviews {
+				exclude[strings.ToLower(review.GetUser().GetLogin())] = true
+			}
+		} else {
+			logger.Debug("Failed to fetch reviews for #%d: %v", number, err)
+		}
+
+		// Connect to database
+		dbClient, err := db.NewClient()
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer dbClient.Close()
+
+		if err := dbClient.InitSchema(ctx); err != nil {
+			log.Fatalf("Failed to initialize database schema: %v", err)
+		}
+
+		endDate := time.Now()
+		startDate := endDate.AddDate(0, 0, -days)
+		history, err := dbClient.GetReviewsByDateRange(ctx, repo, startDate, endDate)
+		if err != nil {
+			log.Fatalf("Failed to fetch review history: %v", err)
+		}
+		logger.Debug("Scoring %d logged reviews against %d changed files", len(history), len(files))
+
+		candidates := scoreCandidates(history, files, exclude)
+		if len(candidates) == 0 {
+			fmt.Printf("No candidate reviewers found in the last %d days of review history for %s\n", days, repo)
+			return
+		}
+
+		rankCandidates(candidates, mode)
+		if top > 0 && len(candidates) > top {
+			candidates = candidates[:top]
+		}
+
+		fmt.Printf("Suggested reviewers for %s #%d (%s mode, last %d days)\n\n", repo, number, mode, days)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Reviewer\tArea Reviews\tTotal Reviews\tLast Area Review")
+		fmt.Fprintln(w, "--------\t------------\t-------------\t----------------")
+		for _, c := range candidates {
+			last := "never"
+			if !c.LastArea.IsZero() {
+				last = c.LastArea.Format("2006-01-02")
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", c.Login, c.AreaReviews, c.TotalReviews, last)
+		}
+		w.Flush()
+	},
+}
+
+// scoreCandidates counts each reviewer's reviews overall and of the changed files
+func scoreCandidates(history []db.Review, files []string, exclude map[string]bool) []*reviewerCandidate {
+	byLogin := make(map[string]*reviewerCandidate)
+	for _, review := range history {
+		login := strings.ToLower(review.Reviewer)
+		if exclude[login] {
+			continue
+		}
+
+		candidate, ok := byLogin[login]
+		if !ok {
+			candidate = &reviewerCandidate{Login: review.Reviewer}
+			byLogin[login] = candidate
+		}
+		candidate.TotalReviews++
+
+		if coversAnyFile(review.Paths, files) {
+			candidate.AreaReviews++
+			if review.Timestamp.After(candidate.LastArea) {
+				candidate.LastArea = review.Timestamp
+			}
+		}
+	}
+
+	candidates := make([]*reviewerCandidate, 0, len(byLogin))
+	for _, candidate := range byLogin {
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// rankCandidates sorts candidates for the given mode. Spread mode prefers the
+// fewest area reviews, expertise mode the most. Ties go to the more active reviewer.
+func rankCandidates(candidates []*reviewerCandidate, mode string) {
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.AreaReviews != b.AreaReviews {
+			if mode == "expertise" {
+				return a.AreaReviews > b.AreaReviews
+			}
+			return a.AreaReviews < b.AreaReviews
+		}
+		if a.TotalReviews != b.TotalReviews {
+			return a.TotalReviews > b.TotalReviews
+		}
+		return a.Login < b.Login
+	})
+}
+
+// coversAnyFile reports whether any reviewed path covers one of the files
+func coversAnyFile(paths, files []string) bool {
+	for _, p := range paths {
+		for _, file := range files {
+			if gh.PathCovers(p, file) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func init() {
+	reviewCmd.AddCommand(reviewSuggestCmd)
+
+	// Define flags
+	reviewSuggestCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
+	reviewSuggestCmd.Flags().IntP("pr", "n", 0, "The number of the pull request")
+	reviewSuggestCmd.Flags().StringP("mode", "m", "spread", "Suggestion mode (spread, expertise)")
+	reviewSuggestCmd.Flags().Int("days", 90, "Number of days of review history to consider")
+	reviewSuggestCmd.Flags().IntP("top", "t", 3, "Number of suggestions to show (0 for all)")
+}
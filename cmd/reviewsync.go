@@ -0,0 +1,350 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/clients"
+	"github.com/jbrinkman/ghi/pkg/db"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// reviewSyncCmd represents the review sync command
+var reviewSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Import the reviews you submitted on GitHub",
+	Long: `The 'review sync' command finds the pull requests you reviewed on GitHub and
+adds each review you submitted to your database with the time it was submitted,
+so your review history is complete even when you forgot --log.
+
+Reviews that are already logged, with --log or an earlier sync, aren't added
+again. Replies to review comments aren't counted as reviews.
+
+With --org, every repository of an organization is synced at once, fetching
+reviews concurrently, and the reviews found in each repository are listed.
+Archived repositories and those under exclude-repos in the config file are
+skipped. The sync spends about one core API request per pull request and
+stops at --budget, so it doesn't use up the quota other commands need.`,
+	Example: `  ghi review sync --since 30d
+  ghi review sync --org myorg --since 30d --exclude 'sandbox-*'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoFlag, _ := cmd.Flags().GetString("repo")
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		org, _ := cmd.Flags().GetString("org")
+
+		if org != "" && repoFlag != "" {
+			log.Fatal("The --repo and --org flags can't be used together")
+		}
+
+		// The repository filter is optional, so detection failures just mean no filter
+		var repo string
+		var err error
+		if org == "" {
+			if repo, err = resolveRepo(repoFlag); err != nil {
+				logger.Debug("No repository filter: %v", err)
+			}
+		}
+		if repo != "" && len(strings.Split(repo, "/")) != 2 {
+			log.Fatal("Invalid repository format. Use 'owner/repo'")
+		}
+
+		age, err := parseAge(sinceFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		since := time.Now().Add(-age)
+
+		// Check for username
+		username := os.Getenv("GHI_USERNAME")
+		if username == "" {
+			log.Fatal("GHI_USERNAME environment variable not set. Use 'ghi auth set --username YOUR_USERNAME' to set it")
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Syncing reviews by %s in %q since %s", username, repo, since.Format(time.DateOnly))
+
+		// Connect to database
+		dbClient, err := db.NewClient()
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer dbClient.Close()
+
+		// Initialize schema if needed
+		ctx := cmd.Context()
+		if err := dbClient.InitSchema(ctx); err != nil {
+			log.Fatalf("Failed to initialize database schema: %v", err)
+		}
+
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		if org != "" {
+			syncOrgReviews(ctx, cmd, client, dbClient, username, org, since)
+			return
+		}
+
+		reviews, err := ui.WithSpinner(ctx, "Fetching your GitHub reviews", func() ([]db.Review, error) {
+			return fetchSubmittedReviews(ctx, client, username, repo, since)
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		imported := 0
+		for _, review := range reviews {
+			added, err := dbClient.ImportReview(ctx, review)
+			if err != nil {
+				log.Fatalf("Failed to import review of %s #%d: %v", review.Repo, review.PRNumber, err)
+			}
+			if added {
+				imported++
+				logger.Debug("Imported review %d of %s #%d", review.GitHubReviewID, review.Repo, review.PRNumber)
+			}
+		}
+
+		fmt.Printf("✅ Imported %d of %d reviews from GitHub (%d already logged)\n",
+			imported, len(reviews), len(reviews)-imported)
+	},
+}
+
+// fetchSubmittedReviews returns the reviews the user submitted on pull requests
+// updated since the given time, as review records
+func fetchSubmittedReviews(ctx context.Context, client *github.Client, username, repo string, since time.Time) ([]db.Review, error) {
+	issues, complete, err := gh.SearchPRsReviewedBy(ctx, client, username, repo, since)
+	if err != nil {
+		return nil, err
+	}
+	if !complete {
+		fmt.Fprintln(os.Stderr, "Warning: GitHub search returned only the 1000 most recent pull requests. Use a shorter --since to sync older reviews")
+	}
+
+	var records []db.Review
+	for _, issue := range issues {
+		owner, repoName, err := gh.IssueRepo(issue)
+		if err != nil {
+			logger.Debug("Skipping search result: %v", err)
+			continue
+		}
+
+		reviews, err := gh.ListAllReviews(ctx, client, owner, repoName, issue.GetNumber())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Could not fetch reviews of %s/%s #%d: %v\n", owner, repoName, issue.GetNumber(), err)
+			continue
+		}
+		records = append(records, reviewRecords(reviews, owner+"/"+repoName, issue.GetNumber(), username)...)
+	}
+	return records, nil
+}
+
+// reviewRecords converts the reviews the user submitted on a pull request to
+// review records
+func reviewRecords(reviews []*github.PullRequestReview, repo string, number int, username string) []db.Review {
+	var records []db.Review
+	for _, review := range gh.SubmittedReviewsBy(reviews, username) {
+		records = append(records, db.Review{
+			Repo:            repo,
+			PRNumber:        number,
+			Reviewer:        username,
+			Timestamp:       review.GetSubmittedAt().Time,
+			GitHubReviewID:  review.GetID(),
+			GitHubReviewURL: review.GetHTMLURL(),
+			Verdict:         verdictFromState(review.GetState()),
+		})
+	}
+	return records
+}
+
+// orgRepoSync is what an organization sync found in one repository
+type orgRepoSync struct {
+	Repo    string
+	PRs     int
+	Reviews []db.Review
+	// Skipped counts the pull requests left out when the request budget ran
+	// out, and Failed those whose reviews couldn't be fetched
+	Skipped  int
+	Failed   int
+	Imported int
+}
+
+// syncOrgReviews imports the reviews the user submitted in every repository of
+// an organization, fetching them concurrently within the request budget
+func syncOrgReviews(ctx context.Context, cmd *cobra.Command, client *github.Client, dbClient *db.Client, username, org string, since time.Time) {
+	include, _ := cmd.Flags().GetStringArray("include")
+	exclude, _ := cmd.Flags().GetStringArray("exclude")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	budgetFlag, _ := cmd.Flags().GetInt("budget")
+
+	if concurrency < 1 {
+		log.Fatal("The --concurrency flag must be at least 1")
+	}
+
+	var skips gh.RepoSkips
+	listed, err := ui.WithSpinner(ctx, "Listing repositories", func() ([]string, error) {
+		names, s, err := gh.ListOrgRepos(ctx, client, org, include, exclude)
+		skips = s
+		return names, err
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	var repos []string
+	for _, name := range listed {
+		repos = append(repos, org+"/"+name)
+	}
+	repos, excluded := excludeConfiguredRepos(repos)
+	for i, repo := range repos {
+		repos[i] = strings.TrimPrefix(repo, org+"/")
+	}
+	reportSkippedRepos(len(repos), skips.Archived, skips.Filtered+len(excluded))
+	if len(repos) == 0 {
+		log.Fatalf("No repositories in %s are left to sync after skipping archived and excluded repositories", org)
+	}
+
+	// Keep a tenth of the quota for other commands unless a budget is given
+	remaining, reset, err := gh.CoreQuota(ctx, client)
+	if err != nil {
+		log.Fatal(err)
+	}
+	budget := remaining - remaining/10
+	if budgetFlag > 0 {
+		budget = min(budgetFlag, remaining)
+	}
+	logger.Debug("Syncing %d repositories in %s with a budget of %d of %d requests", len(repos), org, budget, remaining)
+	if budget <= 0 {
+		log.Fatalf("No GitHub API requests remain. The quota resets at %s", reset.Local().Format("15:04"))
+	}
+
+	var incomplete []string
+	prsByRepo, err := ui.WithSpinner(ctx, "Searching your reviews in "+org, func() (map[string][]*github.Issue, error) {
+		byRepo, cut, err := gh.SearchOrgPRsReviewedBy(ctx, client, username, org, repos, since, concurrency)
+		incomplete = cut
+		return byRepo, err
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	requests := gh.NewRequestBudget(budget)
+	results, _ := ui.WithSpinner(ctx, "Fetching your GitHub reviews", func() ([]*orgRepoSync, error) {
+		return fetchOrgReviews(ctx, client, username, org, prsByRepo, concurrency, requests), nil
+	})
+	logger.Debug("%d requests of the budget are left", requests.Remaining())
+
+	total, imported, skipped, failed := 0, 0, 0, 0
+	for _, result := range results {
+		for _, review := range result.Reviews {
+			added, err := dbClient.ImportReview(ctx, review)
+			if err != nil {
+				log.Fatalf("Failed to import review of %s #%d: %v", review.Repo, review.PRNumber, err)
+			}
+			if added {
+				result.Imported++
+			}
+		}
+		total += len(result.Reviews)
+		imported += result.Imported
+		skipped += result.Skipped
+		failed += result.Failed
+	}
+
+	if len(results) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Repository\tPRs\tReviews\tImported\tSkipped")
+		fmt.Fprintln(w, "----------\t---\t-------\t--------\t-------")
+		for _, result := range results {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", result.Repo, result.PRs, len(result.Reviews), result.Imported, result.Skipped+result.Failed)
+		}
+		w.Flush()
+		fmt.Println()
+	}
+
+	if len(incomplete) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: GitHub search returned only the 1000 most recent pull requests in %s. Use a shorter --since to sync older reviews\n",
+			strings.Join(incomplete, ", "))
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: the reviews of %d pull requests couldn't be fetched. Use --debug for details\n", failed)
+	}
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: the request budget ran out before %d pull requests were synced. Run the sync again after the quota resets at %s\n",
+			skipped, reset.Local().Format("15:04"))
+	}
+	fmt.Printf("✅ Imported %d of %d reviews from %d of %d repositories in %s (%d already logged)\n",
+		imported, total, len(results), len(repos), org, total-imported)
+}
+
+// fetchOrgReviews fetches the user's reviews of the pull requests in each
+// repository with up to workers concurrent requests, sorted by repository.
+// Pull requests are skipped once the budget is spent.
+func fetchOrgReviews(ctx context.Context, client *github.Client, username, org string, prsByRepo map[string][]*github.Issue, workers int, budget *gh.RequestBudget) []*orgRepoSync {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+
+	results := make([]*orgRepoSync, 0, len(prsByRepo))
+	for repo, issues := range prsByRepo {
+		result := &orgRepoSync{Repo: repo, PRs: len(issues)}
+		results = append(results, result)
+
+		for _, issue := range issues {
+			wg.Add(1)
+			go func(result *orgRepoSync, number int) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				// Listing the reviews of a pull request takes one request per 100 reviews
+				if !budget.Take(1) {
+					mu.Lock()
+					result.Skipped++
+					mu.Unlock()
+					return
+				}
+				reviews, err := gh.ListAllReviews(ctx, client, org, result.Repo, number)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					logger.Debug("Could not fetch reviews of %s/%s #%d: %v", org, result.Repo, number, err)
+					result.Failed++
+					return
+				}
+				result.Reviews = append(result.Reviews, reviewRecords(reviews, org+"/"+result.Repo, number, username)...)
+			}(result, issue.GetNumber())
+		}
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Repo < results[j].Repo })
+	return results
+}
+
+func init() {
+	reviewCmd.AddCommand(reviewSyncCmd)
+
+	// Define flags
+	reviewSyncCmd.Flags().StringP("repo", "r", "", "Only sync reviews in this repository (owner/repo, default from git remote)")
+	reviewSyncCmd.Flags().String("since", "90d", "Sync reviews of pull requests updated in this period, such as 30d or 12w")
+	reviewSyncCmd.Flags().StringP("org", "o", "", "Sync reviews in every repository of a GitHub organization")
+	reviewSyncCmd.Flags().StringArray("include", []string{}, "Only sync organization repositories matching a pattern, such as 'api-*'")
+	reviewSyncCmd.Flags().StringArray("exclude", []string{}, "Skip organization repositories matching a pattern")
+	reviewSyncCmd.Flags().IntP("concurrency", "c", 4, "Number of concurrent GitHub requests with --org")
+	reviewSyncCmd.Flags().Int("budget", 0, "Maximum GitHub API requests to spend with --org (default 90% of the remaining quota)")
+}
@@ -0,0 +1,357 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/clients"
+	"github.com/jbrinkman/ghi/pkg/db"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/locale"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// reviewTeamCmd represents the review team command
+var reviewTeamCmd = &cobra.Command{
+	Use:   "team",
+	Short: "Show a dashboard of the reviews logged by everyone in a shared database",
+	Long: `The 'review team' command shows a dashboard of the reviews logged in a shared
+database:
+
+  Reviewers      how many reviews each reviewer logged, how many pull requests
+                 and repositories they covered and when they last reviewed
+  Coverage       the reviews logged in each repository, and how many of the
+                 pull requests opened in the period were reviewed
+  Unreviewed     the pull requests opened in the period that nobody reviewed
+
+Use --team to include only the members of a team from the teams: section of
+the config file. Members who logged no reviews are listed too.
+
+The pull requests opened in the period are looked up on GitHub for the
+repository given with --repo, the repositories of --group, or otherwise those
+with reviews in the period. Use --no-gaps to skip the lookup.
+
+It requires a shared database. Use 'ghi auth set --db-mode shared' when the
+database is used by a whole team. By default the last 30 days are included.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, _ := cmd.Flags().GetString("repo")
+		group, _ := cmd.Flags().GetString("group")
+		team, _ := cmd.Flags().GetString("team")
+		fromFlag, _ := cmd.Flags().GetString("from")
+		toFlag, _ := cmd.Flags().GetString("to")
+		format, _ := cmd.Flags().GetString("format")
+		noGaps, _ := cmd.Flags().GetBool("no-gaps")
+
+		if repo != "" {
+			repo = expandRepoAlias(repo)
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Repository filter: %s, group: %s, team: %s, date range: %s - %s, format: %s",
+			repo, group, team, fromFlag, toFlag, format)
+
+		if format != "table" && format != "json" {
+			log.Fatalf("Invalid format %q. Use 'table' or 'json'", format)
+		}
+		if repo != "" && group != "" {
+			log.Fatal("Use either --repo or --group, not both")
+		}
+
+		startDate, endDate, err := parseDateRange(fromFlag, toFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var members []string
+		if team != "" {
+			if members, err = resolveTeam(team); err != nil {
+				log.Fatal(err)
+			}
+		}
+		var groupRepos []string
+		if group != "" {
+			if groupRepos, err = resolveGroup(group); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		// Connect to database
+		dbClient, err := db.NewClient()
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer dbClient.Close()
+		requireSharedDatabase(dbClient, "ghi review team")
+
+		// Initialize schema if needed
+		ctx := cmd.Context()
+		if err := dbClient.InitSchema(ctx); err != nil {
+			log.Fatalf("Failed to initialize database schema: %v", err)
+		}
+
+		summaries, err := dbClient.GetReviewerSummaries(ctx, repo, members, startDate, endDate)
+		if err != nil {
+			log.Fatalf("Failed to fetch reviewer summaries: %v", err)
+		}
+		summaries = addIdleMembers(summaries, members)
+		logger.Debug("Summarized %d reviewers", len(summaries))
+
+		dbCoverage, err := dbClient.GetRepoCoverage(ctx, repo, members, startDate, endDate)
+		if err != nil {
+			log.Fatalf("Failed to fetch repository coverage: %v", err)
+		}
+		coverage := teamCoverage(dbCoverage, repo, groupRepos)
+
+		var unreviewed []teamUnreviewedPR
+		if !noGaps {
+			client, err := clients.NewGitHubClient()
+			if err != nil {
+				log.Fatalf("Failed to create GitHub client: %v", err)
+			}
+			unreviewed, err = ui.WithSpinner(ctx, "Checking pull requests for reviews", func() ([]teamUnreviewedPR, error) {
+				return findReviewGaps(ctx, client, dbClient, coverage, members, startDate, endDate)
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if format == "json" {
+			out, err := prettyPrint(teamDashboard{Reviewers: summaries, Repositories: coverage, Unreviewed: unreviewed})
+			if err != nil {
+				log.Fatalf("Failed to format the team dashboard: %v", err)
+			}
+			fmt.Println(out)
+			return
+		}
+
+		title := "Team reviews"
+		if team != "" {
+			title = fmt.Sprintf("Reviews by team %s", team)
+		}
+		fmt.Printf("%s from %s to %s\n\n", title, locale.Date(startDate), locale.Date(endDate))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Reviewer\tReviews\tPull Requests\tRepositories\tLast Review")
+		fmt.Fprintln(w, "--------\t-------\t-------------\t------------\t-----------")
+		for _, summary := range summaries {
+			lastReview := "never"
+			if !summary.LastReview.IsZero() {
+				lastReview = locale.Date(summary.LastReview)
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n",
+				summary.Reviewer,
+				summary.Reviews,
+				summary.PullRequests,
+				summary.Repos,
+				lastReview)
+		}
+		w.Flush()
+
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Repository\tReviews\tReviewers\tPull Requests\tOpened\tCoverage")
+		fmt.Fprintln(w, "----------\t-------\t---------\t-------------\t------\t--------")
+		for _, row := range coverage {
+			opened, percent := "-", "-"
+			if !noGaps {
+				opened = locale.Number(row.Opened)
+				if row.Opened > 0 {
+					percent = fmt.Sprintf("%.0f%%", float64(row.OpenedReviewed)/float64(row.Opened)*100)
+				}
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%s\n",
+				row.Repo,
+				row.Reviews,
+				row.Reviewers,
+				row.PullRequests,
+				opened,
+				percent)
+		}
+		w.Flush()
+
+		if noGaps {
+			return
+		}
+		fmt.Println()
+		if len(unreviewed) == 0 {
+			fmt.Println("Every pull request opened in the period was reviewed")
+			return
+		}
+		fmt.Printf("Unreviewed pull requests (%d)\n\n", len(unreviewed))
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Repository\tPR Number\tTitle\tAuthor\tState\tOpened")
+		fmt.Fprintln(w, "----------\t---------\t-----\t------\t-----\t------")
+		for _, pr := range unreviewed {
+			fmt.Fprintf(w, "%s\t#%d\t%s\t%s\t%s\t%s\n",
+				pr.Repo,
+				pr.Number,
+				truncateTitle(pr.Title, 50),
+				pr.Author,
+				pr.State,
+				locale.Date(pr.CreatedAt.Local()))
+		}
+		w.Flush()
+	},
+}
+
+// teamDashboard is the team dashboard written as JSON
+type teamDashboard struct {
+	Reviewers    []db.ReviewerSummary `json:"reviewers"`
+	Repositories []teamRepoCoverage   `json:"repositories"`
+	Unreviewed   []teamUnreviewedPR   `json:"unreviewed,omitempty"`
+}
+
+// teamRepoCoverage is the reviews logged in a repository, with how many of the
+// pull requests opened in the period were reviewed. Opened is only known when
+// the pull requests are looked up on GitHub.
+type teamRepoCoverage struct {
+	db.RepoCoverage
+	Opened         int `json:"opened"`
+	OpenedReviewed int `json:"opened_reviewed"`
+}
+
+// teamUnreviewedPR is a pull request opened in the period that nobody reviewed
+type teamUnreviewedPR struct {
+	Repo      string    `json:"repo"`
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	State     string    `json:"state"`
+	CreatedAt time.Time `json:"created_at"`
+	URL       string    `json:"url"`
+}
+
+// resolveTeam returns the members of a named team from the teams: config section
+func resolveTeam(name string) ([]string, error) {
+	members := viper.GetStringSlice("teams." + name)
+	if len(members) == 0 {
+		return nil, fmt.Errorf("team %q is not defined in the config file", name)
+	}
+	logger.Debug("Resolved team %s to %v", name, members)
+	return members, nil
+}
+
+// addIdleMembers adds the team members who logged no reviews to the summaries
+func addIdleMembers(summaries []db.ReviewerSummary, members []string) []db.ReviewerSummary {
+	active := make(map[string]bool, len(summaries))
+	for _, summary := range summaries {
+		active[strings.ToLower(summary.Reviewer)] = true
+	}
+	for _, member := range members {
+		if !active[strings.ToLower(member)] {
+			summaries = append(summaries, db.ReviewerSummary{Reviewer: member})
+		}
+	}
+	return summaries
+}
+
+// teamCoverage lists the repositories to show coverage for: the repository
+// or group given, or otherwise those with reviews in the period
+func teamCoverage(dbCoverage []db.RepoCoverage, repo string, groupRepos []string) []teamRepoCoverage {
+	byRepo := make(map[string]db.RepoCoverage, len(dbCoverage))
+	for _, row := range dbCoverage {
+		byRepo[strings.ToLower(row.Repo)] = row
+	}
+
+	repos := groupRepos
+	if repo != "" {
+		repos = []string{repo}
+	}
+	if len(repos) == 0 {
+		coverage := make([]teamRepoCoverage, 0, len(dbCoverage))
+		for _, row := range dbCoverage {
+			coverage = append(coverage, teamRepoCoverage{RepoCoverage: row})
+		}
+		return coverage
+	}
+
+	coverage := make([]teamRepoCoverage, 0, len(repos))
+	for _, name := range repos {
+		row, ok := byRepo[strings.ToLower(name)]
+		if !ok {
+			row = db.RepoCoverage{Repo: name}
+		}
+		coverage = append(coverage, teamRepoCoverage{RepoCoverage: row})
+	}
+	return coverage
+}
+
+// findReviewGaps looks up the pull requests opened in each repository in the
+// period, counting those with a logged review in the coverage, and returns
+// those without one. Drafts aren't expected to be reviewed, so they're skipped.
+func findReviewGaps(ctx context.Context, client *github.Client, dbClient *db.Client, coverage []teamRepoCoverage, members []string, startDate, endDate time.Time) ([]teamUnreviewedPR, error) {
+	var unreviewed []teamUnreviewedPR
+	for i, row := range coverage {
+		parts := strings.Split(row.Repo, "/")
+		if len(parts) != 2 {
+			logger.Debug("Invalid repository format: %s", row.Repo)
+			continue
+		}
+
+		query := fmt.Sprintf("repo:%s is:pr draft:false created:%s..%s",
+			row.Repo, startDate.Format(time.DateOnly), endDate.Format(time.DateOnly))
+		issues, total, err := gh.SearchAllIssues(ctx, client, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search pull requests in %s: %w", row.Repo, err)
+		}
+		if len(issues) < total {
+			fmt.Fprintf(os.Stderr, "Warning: GitHub search returned only %d of the %d pull requests opened in %s\n", len(issues), total, row.Repo)
+		}
+
+		reviewed, err := dbClient.GetReviewedPRNumbers(ctx, row.Repo, members)
+		if err != nil {
+			return nil, err
+		}
+
+		coverage[i].Opened = len(issues)
+		for _, issue := range issues {
+			if reviewed[issue.GetNumber()] {
+				coverage[i].OpenedReviewed++
+				continue
+			}
+			unreviewed = append(unreviewed, teamUnreviewedPR{
+				Repo:      row.Repo,
+				Number:    issue.GetNumber(),
+				Title:     issue.GetTitle(),
+				Author:    issue.GetUser().GetLogin(),
+				State:     issue.GetState(),
+				CreatedAt: issue.GetCreatedAt().Time,
+				URL:       issue.GetHTMLURL(),
+			})
+		}
+	}
+	return unreviewed, nil
+}
+
+// requireSharedDatabase exits with an explanation when a command that looks at
+// other people's reviews is used with a personal database
+func requireSharedDatabase(dbClient *db.Client, command string) {
+	if !dbClient.Shared() {
+		log.Fatalf("'%s' requires a shared database. Use 'ghi auth set --db-mode shared' if this database is used by your team", command)
+	}
+}
+
+func init() {
+	reviewCmd.AddCommand(reviewTeamCmd)
+
+	// Define flags
+	reviewTeamCmd.Flags().StringP("repo", "r", "", "Filter reviews by repository (owner/repo)")
+	reviewTeamCmd.Flags().StringP("group", "g", "", "Show the coverage of every repository in a group defined in the config file")
+	reviewTeamCmd.Flags().StringP("team", "t", "", "Only include the members of a team defined in the teams: section of the config file")
+	reviewTeamCmd.Flags().String("from", "", "Start date in YYYY-MM-DD format (default 30 days ago)")
+	reviewTeamCmd.Flags().String("to", "", "End date in YYYY-MM-DD format (default today)")
+	reviewTeamCmd.Flags().StringP("format", "f", "table", "Output format (table, json)")
+	reviewTeamCmd.Flags().Bool("no-gaps", false, "Don't look up the pull requests opened on GitHub, so coverage and unreviewed pull requests aren't shown")
+}
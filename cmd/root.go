@@ -4,12 +4,22 @@ Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"log/slog"
 	"os"
-	"path/filepath"
 	"strings"
 
+	"github.com/jbrinkman/ghi/pkg/clients"
+	"github.com/jbrinkman/ghi/pkg/colors"
+	"github.com/jbrinkman/ghi/pkg/db"
+	"github.com/jbrinkman/ghi/pkg/gitutil"
+	"github.com/jbrinkman/ghi/pkg/locale"
 	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/paths"
+	"github.com/jbrinkman/ghi/pkg/storage"
+	"github.com/jbrinkman/ghi/pkg/ui"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -37,17 +47,85 @@ Before using commands that interact with GitHub APIs, make sure to:
 You can create a GitHub token at https://github.com/settings/tokens
 Using a token increases your API rate limit from 60 to 5000 requests per hour.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		// Enable debug logging if flag is set
+		// Log to the console from the configured level, and to a file with --debug
+		level, err := logger.ParseLevel(viper.GetString("log-level"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			level = slog.LevelWarn
+		}
+		format := viper.GetString("log-format")
+		if format != logger.FormatText && format != logger.FormatJSON {
+			fmt.Fprintf(os.Stderr, "Warning: invalid log format %q, use %s or %s\n", format, logger.FormatText, logger.FormatJSON)
+			format = logger.FormatText
+		}
 		debug := viper.GetBool("debug")
+		options := logger.Options{
+			Level:   level,
+			Debug:   debug,
+			Format:  format,
+			Command: cmd.CommandPath(),
+		}
+		if err := logger.Setup(options); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
 		if debug {
-			logger.SetDebug(true)
 			logger.Debug("Debug logging enabled")
 		}
 
-		// Load environment variables from .ghi/env file
-		if envFile := filepath.Join(os.Getenv("HOME"), ".ghi", "env"); fileExists(envFile) {
-			loadEnvFile(envFile)
+		// Leave colors out of tables and interactive views
+		if viper.GetBool("no-color") || os.Getenv("NO_COLOR") != "" {
+			logger.Debug("Colors disabled")
+			colors.Disable()
+		}
+
+		// Disable the ETag response cache if requested
+		if viper.GetBool("no-cache") {
+			logger.Debug("Response cache disabled")
+			clients.SetCacheEnabled(false)
+		}
+
+		// Format dates and numbers for the configured or detected locale
+		if err := locale.Set(viper.GetString("locale")); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 		}
+		logger.Debug("Locale: %s", locale.Tag())
+
+		// Select where local state such as the response cache is kept
+		if backend := viper.GetString("storage.backend"); backend != "" {
+			logger.Debug("Storage backend: %s", backend)
+			storage.SetBackend(backend)
+		}
+
+		// Each profile keeps its own credentials, in its own env file
+		profile, err := resolveProfile(cmd)
+		if err != nil {
+			log.Fatal(err)
+		}
+		activeProfile = profile
+		if activeProfile != "" {
+			logger.Debug("Using profile %s", activeProfile)
+			if !fileExists(envFilePath()) && cmd != authSetCmd {
+				fmt.Fprintf(os.Stderr, "Warning: profile %q has no settings. Create it with 'ghi auth set --profile %s'.\n", activeProfile, activeProfile)
+			}
+		}
+
+		// Tokens shouldn't be readable by other users
+		if cmd != authHardenCmd {
+			fixInsecurePermissions()
+		}
+
+		// Load environment variables from the profile's env file
+		if envFile := envFilePath(); fileExists(envFile) {
+			if err := loadEnvFile(envFile); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not load %s: %v\n", envFile, err)
+			}
+		}
+
+		// Tokens can come from a password manager or vault instead of the env file
+		clients.SetTokenCommand(viper.GetString("token_command"))
+		// The gh CLI's token is used when ghi has none, unless turned off
+		clients.SetGHCredentials(!viper.IsSet("gh_credentials") || viper.GetBool("gh_credentials"))
+		db.SetTokenCommand(viper.GetString("db_token_command"))
 	},
 }
 
@@ -58,7 +136,11 @@ func Execute(ver, comm, dt string) {
 	commit = comm
 	date = dt
 
-	err := rootCmd.Execute()
+	// Ctrl+C cancels the GitHub requests in flight so commands can show what
+	// they fetched so far and leave the terminal as it was
+	ctx, stop := ui.WithInterrupt(context.Background())
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
 	if err != nil {
 		os.Exit(1)
 	}
@@ -70,7 +152,7 @@ func init() {
 	rootCmd.Version = fmt.Sprintf("%s (Built: %s, Commit: %s)", version, date, commit)
 
 	// Here you will define your flags and configuration settings.
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.github-info.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $XDG_CONFIG_HOME/ghi/config.yaml, or ~/.config/ghi/config.yaml)")
 
 	// Define debug flag with both long and short forms in a single call
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "Enable debug logging to file")
@@ -78,11 +160,34 @@ func init() {
 	// Bind debug flag to viper
 	viper.BindPFlag("debug", rootCmd.PersistentFlags().Lookup("debug"))
 
+	// Define flag to choose the lowest level of messages shown on the console
+	rootCmd.PersistentFlags().String("log-level", "warn", "Lowest level of log messages shown on the console: debug, info, warn or error")
+	viper.BindPFlag("log-level", rootCmd.PersistentFlags().Lookup("log-level"))
+
+	// Define flag to write the debug log as JSON for log tooling
+	rootCmd.PersistentFlags().String("log-format", logger.FormatText, "Format of the debug log file: text or json")
+	viper.BindPFlag("log-format", rootCmd.PersistentFlags().Lookup("log-format"))
+
+	// Define flag to choose the profile whose credentials are used
+	rootCmd.PersistentFlags().String("profile", "", "Authentication profile to use (default from GHI_PROFILE or the config file)")
+
+	// Define flag to bypass the ETag response cache
+	rootCmd.PersistentFlags().Bool("no-cache", false, "Don't use the GitHub response cache")
+	viper.BindPFlag("no-cache", rootCmd.PersistentFlags().Lookup("no-cache"))
+
+	// Define flag to turn off terminal colors, as the NO_COLOR environment variable does
+	rootCmd.PersistentFlags().Bool("no-color", false, "Don't use colors in output")
+	viper.BindPFlag("no-color", rootCmd.PersistentFlags().Lookup("no-color"))
+
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
+// envKeyReplacer turns a setting's key into the name of its environment
+// variable, after the GHI_ prefix
+var envKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
 	if cfgFile != "" {
@@ -90,15 +195,29 @@ func initConfig() {
 		viper.SetConfigFile(cfgFile)
 	} else {
 		// Find home directory.
-		home, err := os.UserHomeDir()
+		home, err := paths.Home()
 		cobra.CheckErr(err)
 
-		// Search config in home directory with name ".github-info" (without extension).
-		viper.AddConfigPath(home)
+		// Upgrade the settings files of older versions before reading them
+		migrateConfig(home)
+
+		// Use config.yaml in the config directory, or the config file of
+		// older versions when it couldn't be migrated
+		configFile := defaultConfigPath()
+		for _, legacy := range legacyConfigPaths(home) {
+			if !fileExists(configFile) && fileExists(legacy) {
+				configFile = legacy
+			}
+		}
 		viper.SetConfigType("yaml")
-		viper.SetConfigName(".github-info")
+		viper.SetConfigFile(configFile)
 	}
 
+	// Read settings from GHI_ environment variables, such as GHI_LOG_LEVEL for
+	// log-level and GHI_SLA_STALE for sla.stale. The prefix keeps settings
+	// like path from picking up PATH and other variables of the OS.
+	viper.SetEnvPrefix("GHI")
+	viper.SetEnvKeyReplacer(envKeyReplacer)
 	viper.AutomaticEnv() // read in environment variables that match
 
 	// If a config file is found, read it in.
@@ -107,31 +226,74 @@ func initConfig() {
 	}
 }
 
-// loadEnvFile loads environment variables from the specified file
+// loadEnvFile loads environment variables from the specified file,
+// decrypting it first if it has been encrypted
 func loadEnvFile(filename string) error {
-	data, err := os.ReadFile(filename)
+	env, _, err := readEnvFile(filename)
 	if err != nil {
 		return err
 	}
 
-	for _, line := range strings.Split(string(data), "\n") {
-		// Skip empty lines and comments
-		if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
+	for key, value := range env {
+		os.Setenv(key, value)
+	}
 
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
+	return nil
+}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+// resolveRepo returns repo if it is set, otherwise the owner/repo detected
+// from the git remote of the current directory. Aliases defined under repos:
+// in the config file are expanded to the repository they name.
+func resolveRepo(repo string) (string, error) {
+	if repo != "" {
+		return expandRepoAlias(repo), nil
+	}
 
-		os.Setenv(key, value)
+	detected, err := gitutil.DetectRepo()
+	if err != nil {
+		return "", err
 	}
+	logger.Debug("Detected repository %s from git remote (host %s)", detected.FullName(), detected.Host)
+	return detected.FullName(), nil
+}
 
-	return nil
+// expandRepoAlias returns the repository for an alias from the repos: config
+// section, or repo unchanged if it isn't an alias
+func expandRepoAlias(repo string) string {
+	if strings.Contains(repo, "/") {
+		return repo
+	}
+	if target := viper.GetString("repos." + repo); target != "" {
+		logger.Debug("Expanded repository alias %s to %s", repo, target)
+		return target
+	}
+	return repo
+}
+
+// resolveGroup returns the repositories in a named group from the groups:
+// config section. Group entries may be repositories or aliases.
+func resolveGroup(name string) ([]string, error) {
+	members := viper.GetStringSlice("groups." + name)
+	if len(members) == 0 {
+		return nil, fmt.Errorf("repository group %q is not defined in the config file", name)
+	}
+
+	repos := make([]string, 0, len(members))
+	for _, member := range members {
+		repos = append(repos, expandRepoAlias(member))
+	}
+	logger.Debug("Resolved repository group %s to %v", name, repos)
+	return repos, nil
+}
+
+// warnIfInterrupted warns that the results are incomplete when Ctrl+C stopped
+// fetching them, and reports whether it did
+func warnIfInterrupted(ctx context.Context) bool {
+	if !ui.Interrupted(ctx) {
+		return false
+	}
+	fmt.Fprintln(os.Stderr, "Warning: interrupted, showing the results fetched so far")
+	return true
 }
 
 // fileExists checks if a file exists
@@ -139,3 +301,41 @@ func fileExists(filename string) bool {
 	_, err := os.Stat(filename)
 	return err == nil
 }
+
+// configFilePath returns the config file in use, or the default location in
+// the home directory when there isn't one yet
+func configFilePath() (string, error) {
+	if path := viper.ConfigFileUsed(); path != "" && fileExists(path) {
+		return path, nil
+	}
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	return defaultConfigPath(), nil
+}
+
+// updateConfigFile sets a key in the config file, creating the file if needed.
+// Only the file's own settings are written, not flags or environment variables.
+func updateConfigFile(key string, value interface{}) error {
+	path, err := configFilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate config file: %w", err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigPermissions(privateFileMode)
+	if fileExists(path) {
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+
+	v.Set(key, value)
+	if err := v.WriteConfigAs(path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	viper.Set(key, value)
+	logger.Debug("Updated %s in %s", key, path)
+	return nil
+}
@@ -0,0 +1,36 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestUpdateConfigFileCreatesPrivateFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes aren't enforced on Windows")
+	}
+	previous := cfgFile
+	cfgFile = filepath.Join(t.TempDir(), "config.yaml")
+	t.Cleanup(func() {
+		cfgFile = previous
+		viper.Set("repo", nil)
+	})
+
+	if err := updateConfigFile("repo", "octo/repo"); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(cfgFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != privateFileMode {
+		t.Errorf("mode = %#o, want %#o", mode, privateFileMode)
+	}
+}
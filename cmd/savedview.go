@@ -0,0 +1,237 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// viewKeys are the pr flags a saved view can hold, in the order they're listed
+var viewKeys = []string{
+	"repo", "group", "org", "author", "association", "reviewer", "state", "draft",
+	"label", "exclude-label", "mine", "review-requested", "no-reviews", "needs-approval",
+	"ready-to-merge", "conflicts", "blocked", "max-size", "older-than", "updated-before",
+	"path", "path-mode", "columns", "full-titles", "sort",
+}
+
+// savedViewCmd represents the view command
+var savedViewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Manage saved pull request views",
+	Long: `The 'view' command manages saved views: named combinations of 'ghi pr' flags
+stored under views: in the config file. Use a view with 'ghi pr --view NAME'.`,
+}
+
+// savedViewSaveCmd represents the view save command
+var savedViewSaveCmd = &cobra.Command{
+	Use:   "save NAME",
+	Short: "Save a combination of pr flags as a view",
+	Long: `The 'save' command stores the given 'ghi pr' flags in the config file as a
+named view, replacing any view with the same name. Only the flags given are
+saved, so the others keep their defaults when the view is used.`,
+	Example: `  ghi view save backend-open -r myorg/api -r myorg/worker -s open --label backend --sort updated`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if strings.ContainsAny(name, ". ") {
+			log.Fatalf("Invalid view name %q. Names can't contain dots or spaces", name)
+		}
+
+		settings := make(map[string]interface{})
+		cmd.Flags().Visit(func(flag *pflag.Flag) {
+			if value, ok := flag.Value.(pflag.SliceValue); ok {
+				settings[flag.Name] = value.GetSlice()
+			} else if flag.Value.Type() == "bool" {
+				settings[flag.Name], _ = strconv.ParseBool(flag.Value.String())
+			} else {
+				settings[flag.Name] = flag.Value.String()
+			}
+		})
+		if len(settings) == 0 {
+			log.Fatal("No pr flags given, there is nothing to save")
+		}
+		if sortField, ok := settings["sort"].(string); ok {
+			if err := gh.ValidateSort(sortField); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		existed := viper.IsSet("views." + name)
+		logger.Debug("Saving view %s: %v", name, settings)
+		if err := updateConfigFile("views."+name, settings); err != nil {
+			log.Fatal(err)
+		}
+
+		path, _ := configFilePath()
+		if existed {
+			fmt.Printf("✅ Updated view %s in %s\n", name, path)
+		} else {
+			fmt.Printf("✅ Saved view %s in %s\n", name, path)
+		}
+	},
+}
+
+// savedViewListCmd represents the view list command
+var savedViewListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved views",
+	Run: func(cmd *cobra.Command, args []string) {
+		views := viper.GetStringMap("views")
+		if len(views) == 0 {
+			fmt.Println("No views are saved. Use 'ghi view save NAME' to save one.")
+			return
+		}
+
+		names := make([]string, 0, len(views))
+		for name := range views {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "View\tFlags")
+		fmt.Fprintln(w, "----\t-----")
+		for _, name := range names {
+			settings := viper.GetStringMap("views." + name)
+			var flags []string
+			for _, key := range viewKeys {
+				if value, ok := settings[key]; ok {
+					flags = append(flags, formatViewFlag(key, value))
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\n", name, strings.Join(flags, " "))
+		}
+		w.Flush()
+	},
+}
+
+// applyView sets the pr flags saved in a view. Flags given on the command line
+// take precedence over the view.
+func applyView(cmd *cobra.Command, name string) error {
+	settings, err := viewSettings(name)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range settings {
+		if cmd.Flags().Changed(key) {
+			logger.Debug("View %s setting %s overridden by the command line", name, key)
+			continue
+		}
+		viper.Set(key, value)
+	}
+	logger.Debug("Applied view %s: %v", name, settings)
+	return nil
+}
+
+// switchView sets the pr flags saved in a view when it's chosen in the table.
+// The view was picked over the command line, so its settings take precedence,
+// and a view that names repositories replaces those being shown.
+func switchView(name string) error {
+	settings, err := viewSettings(name)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range []string{"repo", "group", "org"} {
+		if _, ok := settings[key]; ok {
+			viper.Set("repo", []string{})
+			viper.Set("group", "")
+			viper.Set("org", "")
+			break
+		}
+	}
+	for key, value := range settings {
+		viper.Set(key, value)
+	}
+	logger.Debug("Switched to view %s: %v", name, settings)
+	return nil
+}
+
+// viewSettings returns the settings saved in a view, checking that each is a
+// flag views can hold
+func viewSettings(name string) (map[string]interface{}, error) {
+	if !viper.IsSet("views." + name) {
+		return nil, fmt.Errorf("view %q is not defined in the config file", name)
+	}
+
+	settings := viper.GetStringMap("views." + name)
+	for key := range settings {
+		if !isViewKey(key) {
+			return nil, fmt.Errorf("view %q has unknown setting %q. Views can hold %s", name, key, strings.Join(viewKeys, ", "))
+		}
+	}
+	return settings, nil
+}
+
+// currentViewSettings returns the current value of every setting a view can
+// hold, so they can be restored with restoreViewSettings
+func currentViewSettings() map[string]interface{} {
+	settings := make(map[string]interface{}, len(viewKeys))
+	for _, key := range viewKeys {
+		settings[key] = viper.Get(key)
+	}
+	return settings
+}
+
+// restoreViewSettings sets the settings a view can hold back to saved values
+func restoreViewSettings(settings map[string]interface{}) {
+	for key, value := range settings {
+		viper.Set(key, value)
+	}
+}
+
+// isViewKey reports whether a saved view can hold the setting
+func isViewKey(key string) bool {
+	for _, k := range viewKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// formatViewFlag formats a saved view setting as the flags that set it
+func formatViewFlag(key string, value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		flags := make([]string, 0, len(v))
+		for _, item := range v {
+			flags = append(flags, fmt.Sprintf("--%s %v", key, item))
+		}
+		return strings.Join(flags, " ")
+	case bool:
+		if v {
+			return "--" + key
+		}
+		return "--" + key + "=false"
+	default:
+		return fmt.Sprintf("--%s %v", key, v)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(savedViewCmd)
+	savedViewCmd.AddCommand(savedViewSaveCmd)
+	savedViewCmd.AddCommand(savedViewListCmd)
+
+	// Define flags. They're copies of the pr flags, which are defined first
+	// because init functions run in file name order.
+	for _, key := range viewKeys {
+		flag := *prCmd.Flags().Lookup(key)
+		savedViewSaveCmd.Flags().AddFlag(&flag)
+	}
+}
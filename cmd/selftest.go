@@ -0,0 +1,125 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/clients"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// selftestCmd represents the selftest command
+var selftestCmd = &cobra.Command{
+	Use:    "selftest",
+	Hidden: true,
+	Short:  "Check that ghi works end to end against a sandbox repository",
+	Long: `The 'selftest' command runs the pipeline behind 'ghi pr' against a repository
+and reports each step with PASS or FAIL: signing in, reading the repository,
+searching its pull requests, enriching them with details, reviews, required
+approvals, mergeability and dependencies, and rendering them as JSON.
+
+With --write it also comments on a pull request, edits the comment and
+deletes it. Use a sandbox repository for this, the comment is briefly
+visible and notifies the pull request's subscribers.
+
+It's meant for checking ghi against a GitHub Enterprise server or a new
+token, and for maintainers testing a release. The command exits with status
+0 when every check passes and 1 when any fails.`,
+	Example: `  ghi selftest --repo sandbox-org/ghi-test
+  ghi selftest --repo sandbox-org/ghi-test --write --number 1 --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, _ := cmd.Flags().GetString("repo")
+		write, _ := cmd.Flags().GetBool("write")
+		number, _ := cmd.Flags().GetInt("number")
+		limit, _ := cmd.Flags().GetInt("limit")
+		format, _ := cmd.Flags().GetString("format")
+
+		if format != "text" && format != "json" {
+			log.Fatalf("Invalid format %q. Use 'text' or 'json'", format)
+		}
+		if limit < 1 || limit > 100 {
+			log.Fatal("The --limit flag must be between 1 and 100")
+		}
+		// The repository isn't detected from the git remote, so the write
+		// checks never run against a real project by accident
+		repo = expandRepoAlias(repo)
+		parts := strings.Split(repo, "/")
+		if len(parts) != 2 {
+			log.Fatal("Invalid repository format. Use 'owner/repo'")
+		}
+
+		logger.Debug("Command arguments: %v", args)
+		logger.Debug("Running the self test against %s, write: %v, number: %d, limit: %d", repo, write, number, limit)
+
+		ctx := cmd.Context()
+		client, err := clients.NewGitHubClient()
+		if err != nil {
+			log.Fatalf("Failed to create GitHub client: %v", err)
+		}
+
+		result := gh.RunSelftest(ctx, client, parts[0], parts[1], gh.SelftestOptions{
+			Limit:       limit,
+			Concurrency: viper.GetInt("concurrency"),
+			Write:       write,
+			Number:      number,
+		})
+
+		if format == "json" {
+			out, err := prettyPrint(result)
+			if err != nil {
+				log.Fatalf("Failed to format the self test result: %v", err)
+			}
+			fmt.Println(out)
+		} else {
+			printSelftestResult(result)
+		}
+		if !result.Passed {
+			os.Exit(1)
+		}
+	},
+}
+
+// printSelftestResult prints each check of the self test with its outcome and a summary
+func printSelftestResult(result *gh.SelftestResult) {
+	fmt.Printf("ghi %s against %s (%s)\n\n", version, result.Repo, result.APIURL)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, check := range result.Checks {
+		outcome := "PASS"
+		if !check.Passed {
+			outcome = "FAIL"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", outcome, check.Name, check.Duration.Round(time.Millisecond), check.Detail)
+	}
+	w.Flush()
+	fmt.Println()
+
+	if result.Passed {
+		fmt.Printf("✅ All %d checks passed\n", len(result.Checks))
+		return
+	}
+	failed := result.Failed()
+	fmt.Printf("%d of %d checks failed: %s\n", len(failed), len(result.Checks), strings.Join(failed, ", "))
+}
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+
+	// Define flags
+	selftestCmd.Flags().StringP("repo", "r", "", "The sandbox repository to test against (owner/repo)")
+	selftestCmd.Flags().Bool("write", false, "Also comment on a pull request, edit the comment and delete it")
+	selftestCmd.Flags().IntP("number", "n", 0, "The pull request --write comments on (default the first open one found)")
+	selftestCmd.Flags().Int("limit", 10, "The most pull requests to search and enrich")
+	selftestCmd.Flags().StringP("format", "f", "text", "Output format (text, json)")
+	selftestCmd.MarkFlagRequired("repo")
+}
@@ -0,0 +1,46 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/storage"
+	"github.com/spf13/cobra"
+)
+
+// storageCmd represents the storage command
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Maintain ghi's local storage",
+	Long: `The storage command maintains the local storage that holds ghi's state, such
+as the response cache. The backend is selected with storage.backend in the
+config file.`,
+}
+
+var storageCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Remove leftovers of interrupted writes from local storage",
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := storage.Open()
+		if err != nil {
+			log.Fatalf("Error opening storage: %v", err)
+		}
+
+		logger.Debug("Compacting storage")
+		result, err := s.Compact()
+		if err != nil {
+			log.Fatalf("Error compacting storage: %v", err)
+		}
+
+		fmt.Printf("Removed %d leftover files, freeing %d KB\n", result.Removed, (result.Bytes+1023)/1024)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(storageCmd)
+	storageCmd.AddCommand(storageCompactCmd)
+}
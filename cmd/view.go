@@ -5,15 +5,18 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
-	"runtime"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/google/go-github/v69/github"
 	"github.com/jbrinkman/ghi/pkg/clients"
 	"github.com/jbrinkman/ghi/pkg/db"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/locale"
 	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/ui"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -21,7 +24,13 @@ import (
 var viewCmd = &cobra.Command{
 	Use:   "view",
 	Short: "View details of a specific pull request",
-	Long:  `The 'view' command retrieves and displays details of a specific pull request from a specified GitHub repository.`,
+	Long: `The 'view' command retrieves and displays details of a specific pull request from a specified GitHub repository.
+
+Several pull requests can be viewed at once by repeating --number, passing a comma separated
+list or a range such as 10-20. Multiple pull requests are shown as a compact summary.
+
+With --log, add --note to record context about the review and --verdict to record its
+outcome. When no verdict is given, it's taken from your submitted GitHub review.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		configFile, _ := cmd.Flags().GetString("config")
 		if configFile != "" {
@@ -36,22 +45,40 @@ var viewCmd = &cobra.Command{
 		viper.BindPFlag("number", cmd.Flags().Lookup("number"))
 		viper.BindPFlag("web", cmd.Flags().Lookup("web"))
 		viper.BindPFlag("log", cmd.Flags().Lookup("log"))
+		viper.BindPFlag("paths", cmd.Flags().Lookup("paths"))
+		viper.BindPFlag("select-paths", cmd.Flags().Lookup("select-paths"))
+		viper.BindPFlag("note", cmd.Flags().Lookup("note"))
+		viper.BindPFlag("verdict", cmd.Flags().Lookup("verdict"))
 
-		repo := viper.GetString("repo")
-		if repo == "" {
-			log.Fatal("The --repo flag is required")
+		repo, err := resolveRepo(viper.GetString("repo"))
+		if err != nil {
+			log.Fatalf("The --repo flag is required when not in a GitHub repository clone: %v", err)
 		}
 
-		number := viper.GetInt("number")
-		if number == 0 {
+		numbers, err := parsePRNumbers(viper.GetStringSlice("number"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(numbers) == 0 {
 			log.Fatal("The --number flag is required")
 		}
 
 		web := viper.GetBool("web")
 		logReview := viper.GetBool("log")
+		paths := viper.GetStringSlice("paths")
+		selectPaths := viper.GetBool("select-paths")
+		note := viper.GetString("note")
+		verdict := viper.GetString("verdict")
+
+		if (note != "" || verdict != "") && !logReview {
+			log.Fatal("The --note and --verdict flags can only be used with --log")
+		}
+		if err := db.ValidateVerdict(verdict); err != nil {
+			log.Fatal(err)
+		}
 
 		logger.Debug("Command arguments: %v", args)
-		logger.Debug("Repository: %s, PR Number: %d", repo, number)
+		logger.Debug("Repository: %s, PR Numbers: %v", repo, numbers)
 		logger.Debug("Web flag: %v, Log review flag: %v", web, logReview)
 
 		// Split the repo into owner and repo name
@@ -65,89 +92,263 @@ var viewCmd = &cobra.Command{
 		client, err := clients.NewGitHubClient()
 		if err != nil {
 			log.Fatalf("Failed to create GitHub client: %v", err)
-		 }
+		}
 
 		// Create context
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		// Fetch the PR data
-		var pr *github.PullRequest
+		var prs []*github.PullRequest
+		for _, number := range numbers {
+			// Get the pull request details
+			logger.Debug("Fetching pull request details for %s/%s #%d", owner, repoName, number)
+			pr, _, err := client.PullRequests.Get(ctx, owner, repoName, number)
+			if err != nil {
+				// A single PR is fatal, in a batch we report it and keep going
+				if len(numbers) == 1 {
+					log.Fatalf("Error fetching pull request #%d: %v", number, err)
+				}
+				fmt.Fprintf(os.Stderr, "Error fetching pull request #%d: %v\n", number, err)
+				continue
+			}
 
-		// Get the pull request details
-		logger.Debug("Fetching pull request details for %s/%s #%d", owner, repoName, number)
-		pr, _, err = client.PullRequests.Get(ctx, owner, repoName, number)
-		if err != nil {
-			log.Fatalf("Error fetching pull request #%d: %v", number, err)
+			logger.Debug("Successfully retrieved PR #%d: %s", number, pr.GetTitle())
+			prs = append(prs, pr)
 		}
 
-		logger.Debug("Successfully retrieved PR #%d: %s", number, *pr.Title)
-
 		// Log the review if requested
 		if logReview {
-			logger.Debug("Logging PR review for %s #%d", repo, number)
-			if err := logPRReview(ctx, repo, number); err != nil {
-				log.Printf("Warning: Failed to log review: %v", err)
-			} else {
-				fmt.Println("✅ Review logged successfully")
-				logger.Debug("Review logged successfully")
+			for _, pr := range prs {
+				logger.Debug("Logging PR review for %s #%d", repo, pr.GetNumber())
+				reviewed := reviewPaths(ctx, client, repo, pr.GetNumber(), paths, selectPaths)
+				review := db.Review{Repo: repo, PRNumber: pr.GetNumber(), Note: note, Verdict: verdict, Paths: reviewed}
+				if err := logPRReview(ctx, client, review); err != nil {
+					log.Printf("Warning: Failed to log review for #%d: %v", pr.GetNumber(), err)
+				} else {
+					fmt.Printf("✅ Review logged successfully for #%d\n", pr.GetNumber())
+					logger.Debug("Review logged successfully")
+				}
 			}
 		}
 
 		if web {
-			logger.Debug("Opening PR in web browser: %s", *pr.HTMLURL)
-			openBrowser(*pr.HTMLURL)
+			for _, pr := range prs {
+				logger.Debug("Opening PR in web browser: %s", pr.GetHTMLURL())
+				openBrowser(pr.GetHTMLURL())
+			}
+			return
+		}
+
+		if len(numbers) > 1 {
+			printPRSummary(prs)
+			return
+		}
+		if len(prs) == 0 {
 			return
 		}
+		pr := prs[0]
 
-		// Print the pull request details
-		fmt.Printf("Pull Request #%d\n", *pr.Number)
+		// Fetch submitted reviews to show approval status
+		logger.Debug("Fetching reviews for PR #%d", pr.GetNumber())
+		reviews, err := gh.ListAllReviews(ctx, client, owner, repoName, pr.GetNumber())
+		if err != nil {
+			logger.Debug("Failed to fetch reviews: %v", err)
+			fmt.Fprintf(os.Stderr, "Warning: Could not fetch reviews: %v\n", err)
+		}
 
-		// Add DRAFT: prefix to title if PR is in draft state
-		title := *pr.Title
-		if pr.GetDraft() {
-			title = "DRAFT: " + title
+		printPRDetails(pr, reviews)
+
+		// Stacks only form between open pull requests
+		if pr.GetState() == "open" {
+			if open, err := gh.ListOpenPullRequests(ctx, client, owner, repoName); err != nil {
+				logger.Debug("Failed to list open pull requests for stack detection: %v", err)
+			} else if stack := gh.StackOf(open, pr); stack != nil {
+				fmt.Println("\nStack:")
+				printStack(stack, pr.GetNumber())
+			}
+		}
+
+		// If requested to log review, also show previous reviews
+		if logReview {
+			logger.Debug("Showing previous reviews for PR #%d", pr.GetNumber())
+			showPreviousReviews(ctx, repo, pr.GetNumber())
+		}
+	},
+}
+
+// printPRDetails prints the full details of a single pull request
+// along with its submitted reviews and pending review requests
+func printPRDetails(pr *github.PullRequest, reviews []*github.PullRequestReview) {
+	// Print the pull request details
+	fmt.Printf("Pull Request #%d\n", pr.GetNumber())
+
+	// Add DRAFT: prefix to title if PR is in draft state
+	title := pr.GetTitle()
+	if pr.GetDraft() {
+		title = "DRAFT: " + title
+	}
+
+	fmt.Printf("Title: %s\n", title)
+	fmt.Printf("Author: %s\n", pr.GetUser().GetLogin())
+	fmt.Printf("State: %s\n", pr.GetState())
+
+	// Add draft status - using GetDraft() directly with v69
+	draftStatus := "[ ]"
+	if pr.GetDraft() {
+		draftStatus = "[X]" // Changed from "[✓]" to "[X]" to match reviewer indicator
+		logger.Debug("PR #%d is a draft", pr.GetNumber())
+	}
+	fmt.Printf("Draft: %s\n", draftStatus)
+
+	// Handle timestamps safely by checking if GetTime() returns nil
+	if createdAt := pr.CreatedAt.GetTime(); createdAt != nil {
+		fmt.Printf("Created At: %s\n", locale.DateTime(*createdAt))
+	}
+
+	if updatedAt := pr.UpdatedAt.GetTime(); updatedAt != nil {
+		fmt.Printf("Updated At: %s\n", locale.DateTime(*updatedAt))
+	}
+
+	if pr.MergedAt != nil {
+		if mergedAt := pr.MergedAt.GetTime(); mergedAt != nil {
+			fmt.Printf("Merged At: %s\n", locale.DateTime(*mergedAt))
+		}
+	}
+
+	fmt.Printf("URL: %s\n", pr.GetHTMLURL())
+
+	printGitHubReviews(pr, reviews)
+
+	fmt.Printf("Body:\n%s\n", pr.GetBody())
+}
+
+// printGitHubReviews prints the submitted reviews and the reviewers whose
+// review has been requested but not yet submitted
+func printGitHubReviews(pr *github.PullRequest, reviews []*github.PullRequestReview) {
+	fmt.Println("Reviews:")
+	submitted := 0
+	for _, review := range reviews {
+		// Pending reviews haven't been submitted yet
+		if review.GetState() == "PENDING" {
+			continue
 		}
+		submitted++
 
-		fmt.Printf("Title: %s\n", title)
-		fmt.Printf("Author: %s\n", *pr.User.Login)
-		fmt.Printf("State: %s\n", *pr.State)
+		submittedAt := ""
+		if review.SubmittedAt != nil {
+			submittedAt = " at " + locale.DateTime(review.SubmittedAt.Time)
+		}
+		fmt.Printf("  - %s: %s%s\n", review.GetUser().GetLogin(), review.GetState(), submittedAt)
+	}
+	if submitted == 0 {
+		fmt.Println("  none")
+	}
+
+	fmt.Println("Requested Reviewers:")
+	for _, user := range pr.RequestedReviewers {
+		fmt.Printf("  - %s\n", user.GetLogin())
+	}
+	for _, team := range pr.RequestedTeams {
+		fmt.Printf("  - %s (team)\n", team.GetSlug())
+	}
+	if len(pr.RequestedReviewers) == 0 && len(pr.RequestedTeams) == 0 {
+		fmt.Println("  none")
+	}
+}
 
-		// Add draft status - using GetDraft() directly with v69
+// printPRSummary prints a compact one-line-per-PR summary of several pull requests
+func printPRSummary(prs []*github.PullRequest) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Number\tTitle\tAuthor\tState\tDraft\tUpdated\tURL")
+	fmt.Fprintln(w, "------\t-----\t------\t-----\t-----\t-------\t---")
+	for _, pr := range prs {
 		draftStatus := "[ ]"
 		if pr.GetDraft() {
-			draftStatus = "[X]" // Changed from "[✓]" to "[X]" to match reviewer indicator
-			logger.Debug("PR #%d is a draft", *pr.Number)
+			draftStatus = "[X]"
 		}
-		fmt.Printf("Draft: %s\n", draftStatus)
 
-		// Handle timestamps safely by checking if GetTime() returns nil
-		if createdAt := pr.CreatedAt.GetTime(); createdAt != nil {
-			fmt.Printf("Created At: %s\n", createdAt.Format(time.RFC1123))
+		state := pr.GetState()
+		if pr.MergedAt != nil {
+			state = "merged"
 		}
 
+		updated := ""
 		if updatedAt := pr.UpdatedAt.GetTime(); updatedAt != nil {
-			fmt.Printf("Updated At: %s\n", updatedAt.Format(time.RFC1123))
+			updated = locale.Date(*updatedAt)
 		}
 
-		if pr.MergedAt != nil {
-			if mergedAt := pr.MergedAt.GetTime(); mergedAt != nil {
-				fmt.Printf("Merged At: %s\n", mergedAt.Format(time.RFC1123))
-			}
+		fmt.Fprintf(w, "#%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			pr.GetNumber(),
+			truncateTitle(pr.GetTitle(), 50),
+			pr.GetUser().GetLogin(),
+			state,
+			draftStatus,
+			updated,
+			pr.GetHTMLURL())
+	}
+	w.Flush()
+}
+
+// truncateTitle shortens a title to maxLen characters, adding "..." if truncated.
+// Characters are counted as runes so multi-byte characters aren't split.
+func truncateTitle(title string, maxLen int) string {
+	return gh.TruncateColumn(title, maxLen)
+}
+
+// maxPRRange is the most pull requests a single range such as 10-20 can
+// cover, so a typo like 1-100000 doesn't start that many requests
+const maxPRRange = 100
+
+// parsePRNumbers parses PR number arguments, each of which can be a single
+// number, a comma separated list or a range such as 10-20
+func parsePRNumbers(values []string) ([]int, error) {
+	var numbers []int
+	seen := make(map[int]bool)
+	add := func(n int) {
+		if !seen[n] {
+			seen[n] = true
+			numbers = append(numbers, n)
 		}
+	}
 
-		fmt.Printf("URL: %s\n", *pr.HTMLURL)
-		fmt.Printf("Body:\n%s\n", *pr.Body)
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
 
-		// If requested to log review, also show previous reviews
-		if logReview {
-			logger.Debug("Showing previous reviews for PR #%d", number)
-			showPreviousReviews(ctx, repo, number)
+			if from, to, isRange := strings.Cut(part, "-"); isRange {
+				start, err := strconv.Atoi(strings.TrimSpace(from))
+				if err != nil || start < 1 {
+					return nil, fmt.Errorf("invalid pull request range %q", part)
+				}
+				end, err := strconv.Atoi(strings.TrimSpace(to))
+				if err != nil || end < start {
+					return nil, fmt.Errorf("invalid pull request range %q", part)
+				}
+				if end-start >= maxPRRange {
+					return nil, fmt.Errorf("pull request range %q is too large, ranges can cover at most %d", part, maxPRRange)
+				}
+				for n := start; n <= end; n++ {
+					add(n)
+				}
+				continue
+			}
+
+			n, err := strconv.Atoi(part)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid pull request number %q", part)
+			}
+			add(n)
 		}
-	},
+	}
+
+	return numbers, nil
 }
 
-// logPRReview logs a code review to the database
-func logPRReview(ctx context.Context, repo string, prNumber int) error {
+// logPRReview logs a code review to the database as the current user
+func logPRReview(ctx context.Context, client *github.Client, review db.Review) error {
 	// Check for username
 	username := os.Getenv("GHI_USERNAME")
 	if username == "" {
@@ -173,8 +374,11 @@ func logPRReview(ctx context.Context, repo string, prNumber int) error {
 	}
 
 	// Log the review
+	review.Reviewer = username
+	linkGitHubReview(ctx, client, dbClient, &review)
+
 	logger.Debug("Writing review record to database")
-	if err := dbClient.LogReview(ctx, repo, prNumber, username); err != nil {
+	if err := dbClient.LogReview(ctx, review); err != nil {
 		logger.Debug("Failed to log review: %v", err)
 		return err
 	}
@@ -183,6 +387,77 @@ func logPRReview(ctx context.Context, repo string, prNumber int) error {
 	return nil
 }
 
+// reviewLinkWindow is how long before a review is logged the GitHub review it
+// links to can have been submitted
+const reviewLinkWindow = 15 * time.Minute
+
+// reviewLinkSince returns the time after which a GitHub review must have been
+// submitted to be linked to a review being logged now: within reviewLinkWindow,
+// and after the reviewer last logged a review of the pull request, so an older
+// review isn't linked to an unrelated log
+func reviewLinkSince(ctx context.Context, dbClient *db.Client, review db.Review) time.Time {
+	since := time.Now().Add(-reviewLinkWindow)
+
+	previous, err := dbClient.GetReviews(ctx, review.Repo, review.PRNumber)
+	if err != nil {
+		logger.Debug("Could not look up previous reviews of %s #%d: %v", review.Repo, review.PRNumber, err)
+		return since
+	}
+	for _, p := range previous {
+		if strings.EqualFold(p.Reviewer, review.Reviewer) {
+			// Reviews are newest first
+			if p.Timestamp.After(since) {
+				since = p.Timestamp
+			}
+			break
+		}
+	}
+	return since
+}
+
+// linkGitHubReview attaches the reviewer's most recent GitHub review submitted
+// since reviewLinkSince to a review record, and takes the verdict from it when
+// none was given. Failing to find one is not an error, the link is optional.
+func linkGitHubReview(ctx context.Context, client *github.Client, dbClient *db.Client, review *db.Review) {
+	parts := strings.Split(review.Repo, "/")
+	if client == nil || len(parts) != 2 {
+		return
+	}
+
+	since := reviewLinkSince(ctx, dbClient, *review)
+
+	ghReview, err := gh.LatestReviewBy(ctx, client, parts[0], parts[1], review.PRNumber, review.Reviewer, since)
+	if err != nil {
+		logger.Debug("Could not look up GitHub review for %s #%d: %v", review.Repo, review.PRNumber, err)
+		return
+	}
+	if ghReview == nil {
+		logger.Debug("No GitHub review by %s on %s #%d submitted since %s", review.Reviewer, review.Repo, review.PRNumber, since.Format(time.RFC3339))
+		return
+	}
+
+	review.GitHubReviewID = ghReview.GetID()
+	review.GitHubReviewURL = ghReview.GetHTMLURL()
+	if review.Verdict == "" {
+		review.Verdict = verdictFromState(ghReview.GetState())
+	}
+	logger.Debug("Linked GitHub review %d: %s", review.GitHubReviewID, review.GitHubReviewURL)
+}
+
+// verdictFromState converts the state of a submitted GitHub review to a verdict,
+// or an empty string for states that aren't a verdict, such as DISMISSED
+func verdictFromState(state string) string {
+	switch state {
+	case "APPROVED":
+		return db.VerdictApproved
+	case "CHANGES_REQUESTED":
+		return db.VerdictChangesRequested
+	case "COMMENTED":
+		return db.VerdictCommented
+	}
+	return ""
+}
+
 // showPreviousReviews displays previous reviews for this PR
 func showPreviousReviews(ctx context.Context, repo string, prNumber int) {
 	dbClient, err := db.NewClient()
@@ -214,28 +489,26 @@ func showPreviousReviews(ctx context.Context, repo string, prNumber int) {
 		fmt.Printf("- %s by %s at %s\n",
 			repo,
 			review.Reviewer,
-			review.Timestamp.Format(time.RFC1123))
+			locale.DateTime(review.Timestamp))
+		if review.Verdict != "" {
+			fmt.Printf("  Verdict: %s\n", review.Verdict)
+		}
+		if review.Note != "" {
+			fmt.Printf("  Note: %s\n", strings.ReplaceAll(review.Note, "\n", "\n        "))
+		}
+		if review.GitHubReviewURL != "" {
+			fmt.Printf("  %s\n", ui.Hyperlink(review.GitHubReviewURL, review.GitHubReviewURL))
+		}
+		if len(review.Paths) > 0 {
+			fmt.Printf("  Paths: %s\n", strings.Join(review.Paths, ", "))
+		}
 	}
 }
 
 func openBrowser(url string) {
-	var err error
-
-	logger.Debug("Attempting to open URL: %s", url)
-	switch runtime.GOOS {
-	case "linux":
-		err = exec.Command("xdg-open", url).Start()
-	case "windows":
-		err = exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
-	case "darwin":
-		err = exec.Command("open", url).Start()
-	default:
-		err = fmt.Errorf("unsupported platform")
-	}
-
-	if err != nil {
-		log.Fatalf("Failed to open browser: %v", err)
+	if err := ui.OpenURL(url); err != nil {
 		logger.Debug("Failed to open browser: %v", err)
+		log.Fatalf("Failed to open browser: %v", err)
 	}
 }
 
@@ -243,11 +516,10 @@ func init() {
 	prCmd.AddCommand(viewCmd)
 
 	// Define the --repo flag for viewCmd
-	viewCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo)")
-	viewCmd.MarkFlagRequired("repo")
+	viewCmd.Flags().StringP("repo", "r", "", "The name of the Github repository (owner/repo, default from git remote)")
 
 	// Define the --number flag for viewCmd
-	viewCmd.Flags().IntP("number", "n", 0, "The number of the pull request")
+	viewCmd.Flags().StringSliceP("number", "n", []string{}, "The number of the pull request (repeatable, comma separated or a range like 10-20)")
 	viewCmd.MarkFlagRequired("number")
 
 	// Define the --config flag for viewCmd
@@ -258,4 +530,12 @@ func init() {
 
 	// Define the --log flag for viewCmd
 	viewCmd.Flags().BoolP("log", "l", false, "Log that you are reviewing this PR")
+
+	// Define the flags for recording the reviewed paths with --log
+	viewCmd.Flags().StringArray("paths", []string{}, "Files or areas reviewed, e.g. 'pkg/db/**' (used with --log)")
+	viewCmd.Flags().Bool("select-paths", false, "Choose the reviewed files from the PR's changed files (used with --log)")
+
+	// Define the flags for recording the review's context with --log
+	viewCmd.Flags().String("note", "", "A note about the review, e.g. 'left comments on auth flow' (used with --log)")
+	viewCmd.Flags().String("verdict", "", "The outcome of the review: approved, changes-requested or commented (used with --log)")
 }
@@ -0,0 +1,27 @@
+/*
+Copyright © 2024 Joe Brinkman <joe.brinkman@improving.com>
+*/
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestPathsFlagKeepsBraceGlobs(t *testing.T) {
+	want := []string{"pkg/{db,ui}/**", "cmd/view.go"}
+	flags := pflag.NewFlagSet("view", pflag.ContinueOnError)
+	flags.StringArray("paths", []string{}, "")
+	if err := flags.Parse([]string{"--paths", want[0], "--paths", want[1]}); err != nil {
+		t.Fatal(err)
+	}
+
+	v := viper.New()
+	v.BindPFlag("paths", flags.Lookup("paths"))
+	if got := v.GetStringSlice("paths"); !slices.Equal(got, want) {
+		t.Errorf("paths = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+// Package auth obtains the tokens ghi authenticates with, so secrets can be
+// kept in a password manager or vault rather than in ghi's settings files.
+package auth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// commandTimeout bounds a token command, leaving time to unlock a password
+// manager when it asks
+const commandTimeout = 2 * time.Minute
+
+var (
+	mu sync.Mutex
+	// tokens are the tokens printed by each command, so a command runs once
+	// per invocation of ghi
+	tokens = make(map[string]string)
+)
+
+// CommandToken runs a command that prints a token, such as
+// "op read op://eng/github/token", and returns the token it printed. The
+// command is run by the shell, with the terminal available for any prompt it
+// shows, and its result is reused for the rest of the run.
+func CommandToken(command string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if token, ok := tokens[command]; ok {
+		return token, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	// The command isn't logged in full, it may hold a secret of its own
+	logger.Debug("Running token command %q", CommandName(command))
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout bytes.Buffer
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("token command %q failed: %w", CommandName(command), err)
+	}
+
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", fmt.Errorf("token command %q didn't print a token", CommandName(command))
+	}
+	if strings.ContainsAny(token, "\r\n") {
+		return "", fmt.Errorf("token command %q printed more than one line, it should only print the token", CommandName(command))
+	}
+	logger.AddSecret(token)
+	tokens[command] = token
+	return token, nil
+}
+
+// CommandName returns the program a command runs, so the command can be shown
+// without arguments that may be secret
+func CommandName(command string) string {
+	if fields := strings.Fields(command); len(fields) > 0 {
+		return fields[0]
+	}
+	return command
+}
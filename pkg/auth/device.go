@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"golang.org/x/oauth2"
+)
+
+// DeviceCode is the code the user enters at the verification URL to authorize
+// a device login
+type DeviceCode struct {
+	UserCode        string
+	VerificationURI string
+	Expires         time.Time
+}
+
+// DeviceEndpoint returns the OAuth endpoints of a GitHub host, such as
+// github.com or an Enterprise server
+func DeviceEndpoint(host string) oauth2.Endpoint {
+	base := "https://" + host
+	return oauth2.Endpoint{
+		AuthURL:       base + "/login/oauth/authorize",
+		TokenURL:      base + "/login/oauth/access_token",
+		DeviceAuthURL: base + "/login/device/code",
+	}
+}
+
+// DeviceLogin signs in with GitHub's device authorization flow. It requests a
+// code for the OAuth App with clientID, passes it to show so the user can
+// enter it in their browser, and polls until they authorize ghi, returning
+// the token. The OAuth App must have device flow enabled.
+func DeviceLogin(ctx context.Context, host, clientID string, scopes []string, show func(DeviceCode)) (string, error) {
+	config := &oauth2.Config{
+		ClientID: clientID,
+		Endpoint: DeviceEndpoint(host),
+		Scopes:   scopes,
+	}
+
+	logger.Debug("Requesting a device code from %s with scopes %v", host, scopes)
+	response, err := config.DeviceAuth(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to request a device code from %s: %w", host, deviceError(err))
+	}
+	show(DeviceCode{
+		UserCode:        response.UserCode,
+		VerificationURI: response.VerificationURI,
+		Expires:         response.Expiry,
+	})
+
+	// Polling waits out the interval GitHub asks for, and slows down when told to
+	token, err := config.DeviceAccessToken(ctx, response)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return "", fmt.Errorf("the code expired before it was entered, run the login again")
+		}
+		return "", deviceError(err)
+	}
+	logger.Debug("Device login authorized, token type %s", token.TokenType)
+	return token.AccessToken, nil
+}
+
+// deviceError explains the errors GitHub returns during a device login
+func deviceError(err error) error {
+	var retrieveErr *oauth2.RetrieveError
+	if !errors.As(err, &retrieveErr) {
+		return err
+	}
+	switch retrieveErr.ErrorCode {
+	case "access_denied":
+		return fmt.Errorf("the login was cancelled in the browser")
+	case "expired_token":
+		return fmt.Errorf("the code expired before it was entered, run the login again")
+	case "device_flow_disabled":
+		return fmt.Errorf("device flow isn't enabled for the OAuth App, enable it in the app's settings")
+	case "incorrect_client_credentials":
+		return fmt.Errorf("the OAuth App client ID isn't known to the server")
+	case "unsupported_grant_type", "incorrect_device_code":
+		return fmt.Errorf("the server rejected the login: %s", retrieveErr.ErrorDescription)
+	}
+	return err
+}
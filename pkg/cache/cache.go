@@ -0,0 +1,71 @@
+// Package cache provides a store for HTTP responses so conditional requests
+// can be answered locally when GitHub reports no changes.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/storage"
+)
+
+// bucket is the storage bucket holding the cached responses
+const bucket = "cache"
+
+// Entry is a cached HTTP response along with the ETag used to revalidate it
+type Entry struct {
+	ETag     string      `json:"etag"`
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"stored_at"`
+}
+
+// Store holds cached responses keyed by request
+type Store struct {
+	storage storage.Storage
+}
+
+// NewStore creates a store of cached responses in the given storage
+func NewStore(s storage.Storage) *Store {
+	return &Store{storage: s}
+}
+
+// Open opens the store in the configured storage
+func Open() (*Store, error) {
+	s, err := storage.Open()
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(s), nil
+}
+
+// Get returns the cached entry for key, if there is one
+func (s *Store) Get(key string) (*Entry, bool) {
+	data, ok, err := s.storage.Get(bucket, key)
+	if err != nil || !ok {
+		return nil, false
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Put stores the entry for key, replacing any existing entry
+func (s *Store) Put(key string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	return s.storage.Put(bucket, key, data)
+}
+
+// Clear removes all cached entries and returns the number removed
+func (s *Store) Clear() (int, error) {
+	return s.storage.Clear(bucket)
+}
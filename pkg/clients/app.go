@@ -0,0 +1,197 @@
+package clients
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// appJWTLifetime is how long the JWT signed with the app's private key is
+	// valid. GitHub accepts at most 10 minutes.
+	appJWTLifetime = 9 * time.Minute
+	// appClockSkew backdates the JWT, in case this machine's clock is ahead of GitHub's
+	appClockSkew = time.Minute
+	// installationTokenRefresh is how long before an installation token
+	// expires that a new one is created, so a request never uses an expired token
+	installationTokenRefresh = 5 * time.Minute
+)
+
+// AppConfig identifies a GitHub App installation ghi authenticates as
+type AppConfig struct {
+	AppID          int64
+	InstallationID int64
+	PrivateKey     *rsa.PrivateKey
+}
+
+var (
+	appOnce sync.Once
+	// appTokens creates installation tokens for the configured app, shared by
+	// every client so a token is only created again when it's about to expire
+	appTokens oauth2.TokenSource
+	appErr    error
+)
+
+// appTokenSource returns the source of installation tokens for the GitHub App
+// set in the environment, or nil when no app is set
+func appTokenSource() (oauth2.TokenSource, error) {
+	appOnce.Do(func() {
+		var config *AppConfig
+		config, appErr = appConfigFromEnv()
+		if appErr != nil || config == nil {
+			return
+		}
+		logger.Debug("Authenticating as installation %d of GitHub App %d", config.InstallationID, config.AppID)
+		source := &installationTokenSource{
+			config: config,
+			apiURL: apiURL(Host()),
+			client: &http.Client{Timeout: 30 * time.Second},
+		}
+		appTokens = oauth2.ReuseTokenSourceWithExpiry(nil, source, installationTokenRefresh)
+	})
+	return appTokens, appErr
+}
+
+// appConfigFromEnv reads the GitHub App settings: GHI_APP_ID,
+// GHI_APP_INSTALLATION_ID, and the private key from GHI_APP_PRIVATE_KEY or the
+// file named by GHI_APP_PRIVATE_KEY_FILE. It returns nil when GHI_APP_ID isn't set.
+func appConfigFromEnv() (*AppConfig, error) {
+	appID := os.Getenv("GHI_APP_ID")
+	if appID == "" {
+		return nil, nil
+	}
+
+	config := &AppConfig{}
+	var err error
+	if config.AppID, err = strconv.ParseInt(appID, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid GHI_APP_ID %q, it must be the numeric ID of the app", appID)
+	}
+	installationID := os.Getenv("GHI_APP_INSTALLATION_ID")
+	if config.InstallationID, err = strconv.ParseInt(installationID, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid GHI_APP_INSTALLATION_ID %q, it must be the numeric ID of the app's installation", installationID)
+	}
+
+	key := []byte(os.Getenv("GHI_APP_PRIVATE_KEY"))
+	if keyFile := os.Getenv("GHI_APP_PRIVATE_KEY_FILE"); len(key) == 0 && keyFile != "" {
+		if key, err = os.ReadFile(keyFile); err != nil {
+			return nil, fmt.Errorf("failed to read the GitHub App private key: %w", err)
+		}
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("GHI_APP_ID is set but the private key isn't, set GHI_APP_PRIVATE_KEY_FILE to the .pem file of the app")
+	}
+	if config.PrivateKey, err = parsePrivateKey(key); err != nil {
+		return nil, fmt.Errorf("invalid GitHub App private key: %w", err)
+	}
+	return config, nil
+}
+
+// parsePrivateKey parses an RSA private key in PEM format, as GitHub issues
+// them in PKCS #1 and as PKCS #8 when converted
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the key isn't an RSA key")
+	}
+	return key, nil
+}
+
+// apiURL returns the REST API URL of a GitHub host
+func apiURL(host string) string {
+	if host == DefaultHost {
+		return "https://api.github.com/"
+	}
+	return "https://" + host + "/api/v3/"
+}
+
+// installationTokenSource creates installation tokens for a GitHub App
+type installationTokenSource struct {
+	config *AppConfig
+	apiURL string
+	client *http.Client
+}
+
+// Token creates an installation token, authenticating as the app with a JWT
+func (s *installationTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := s.appJWT(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%sapp/installations/%d/access_tokens", s.apiURL, s.config.InstallationID)
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a GitHub App installation token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+		Message   string    `json:"message"`
+	}
+	decodeErr := json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("failed to create a token for installation %d of GitHub App %d: %s %s",
+			s.config.InstallationID, s.config.AppID, resp.Status, body.Message)
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to read the GitHub App installation token: %w", decodeErr)
+	}
+
+	logger.AddSecret(body.Token)
+	logger.Debug("Created an installation token expiring at %s", body.ExpiresAt.Format(time.RFC3339))
+	return &oauth2.Token{AccessToken: body.Token, TokenType: "token", Expiry: body.ExpiresAt}, nil
+}
+
+// appJWT signs the JWT that authenticates as the app itself, with RS256
+func (s *installationTokenSource) appJWT(now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iat": now.Add(-appClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(s.config.AppID, 10),
+	})
+
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.config.PrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign the GitHub App JWT: %w", err)
+	}
+	return strings.Join([]string{unsigned, encoding.EncodeToString(signature)}, "."), nil
+}
@@ -0,0 +1,110 @@
+package clients
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/cache"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// cacheEnabled controls whether new clients use the ETag cache
+var cacheEnabled = true
+
+// SetCacheEnabled enables or disables the ETag response cache for new clients
+func SetCacheEnabled(enabled bool) {
+	cacheEnabled = enabled
+}
+
+// etagTransport makes GET requests conditional using stored ETags. When GitHub
+// answers 304 Not Modified the cached response is returned, and the request
+// does not count against the rate limit.
+type etagTransport struct {
+	base  http.RoundTripper
+	store *cache.Store
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.base.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	entry, cached := t.store.Get(key)
+	if cached && entry.ETag != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if cached && resp.StatusCode == http.StatusNotModified {
+		logger.Debug("Cache hit for %s", req.URL.Path)
+		resp.Body.Close()
+		return cachedResponse(req, resp, entry), nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := t.store.Put(key, &cache.Entry{
+		ETag:     etag,
+		Status:   resp.StatusCode,
+		Header:   resp.Header.Clone(),
+		Body:     body,
+		StoredAt: time.Now(),
+	}); err != nil {
+		logger.Debug("Failed to cache response for %s: %v", req.URL.Path, err)
+	}
+
+	return resp, nil
+}
+
+// cachedResponse builds a response from a cache entry, keeping the rate limit
+// and token headers from the 304 response so rate tracking stays accurate and
+// a token's changed scopes are seen
+func cachedResponse(req *http.Request, notModified *http.Response, entry *cache.Entry) *http.Response {
+	header := entry.Header.Clone()
+	for _, name := range []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Used", "X-RateLimit-Reset", "X-RateLimit-Resource",
+		"X-OAuth-Scopes", "GitHub-Authentication-Token-Expiration"} {
+		if value := notModified.Header.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+
+	return &http.Response{
+		Status:        http.StatusText(entry.Status),
+		StatusCode:    entry.Status,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       req,
+	}
+}
+
+// cacheKey identifies a request in the cache. The credentials are hashed into
+// the key so responses are never shared between tokens.
+func cacheKey(req *http.Request) string {
+	auth := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	return req.URL.String() + "|" + req.Header.Get("Accept") + "|" + hex.EncodeToString(auth[:])
+}
@@ -0,0 +1,118 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/logger"
+	"github.com/jbrinkman/ghi/pkg/paths"
+	"gopkg.in/yaml.v3"
+)
+
+// ghCommandTimeout bounds 'gh auth token', which only reads local settings
+const ghCommandTimeout = 10 * time.Second
+
+var (
+	ghMu sync.Mutex
+	// ghTokens are the tokens found for each host, so gh is asked once per run
+	ghTokens = make(map[string]ghToken)
+)
+
+// ghToken is a token of the gh CLI and where it was found
+type ghToken struct {
+	token  string
+	source string
+}
+
+// ghCLIToken returns the token the gh CLI is signed in with for a host, and
+// where it was found. It asks 'gh auth token', which also reads tokens gh keeps
+// in the system keyring, and falls back to the gh hosts.yml file when gh isn't
+// installed. It returns an empty token when gh isn't signed in to the host.
+func ghCLIToken(host string) (string, string) {
+	ghMu.Lock()
+	defer ghMu.Unlock()
+	if found, ok := ghTokens[host]; ok {
+		return found.token, found.source
+	}
+
+	var found ghToken
+	if token := ghAuthToken(host); token != "" {
+		found = ghToken{token: token, source: TokenSourceGHCLI}
+	} else if token := ghHostsToken(host); token != "" {
+		found = ghToken{token: token, source: TokenSourceGHHosts}
+	}
+	if found.token != "" {
+		logger.AddSecret(found.token)
+	}
+	ghTokens[host] = found
+	return found.token, found.source
+}
+
+// ghAuthToken runs 'gh auth token' for a host
+func ghAuthToken(host string) string {
+	if _, err := exec.LookPath("gh"); err != nil {
+		logger.Debug("The gh CLI isn't installed")
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ghCommandTimeout)
+	defer cancel()
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "gh", "auth", "token", "--hostname", host)
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		logger.Debug("gh isn't signed in to %s: %v", host, err)
+		return ""
+	}
+	return strings.TrimSpace(stdout.String())
+}
+
+// ghHostsToken reads the token for a host from the gh hosts.yml file, where
+// gh keeps tokens when the keyring isn't available
+func ghHostsToken(host string) string {
+	path := filepath.Join(ghConfigDir(), "hosts.yml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.Debug("No gh hosts file: %v", err)
+		return ""
+	}
+
+	var hosts map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}
+	if err := yaml.Unmarshal(data, &hosts); err != nil {
+		logger.Debug("Ignoring the unreadable gh hosts file %s: %v", path, err)
+		return ""
+	}
+	for name, entry := range hosts {
+		if strings.EqualFold(name, host) {
+			return entry.OAuthToken
+		}
+	}
+	return ""
+}
+
+// ghConfigDir returns the directory gh keeps its settings in, following the
+// same rules as gh
+func ghConfigDir() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh")
+	}
+	if runtime.GOOS == "windows" {
+		if dir, err := paths.UserConfigDir(); err == nil {
+			return filepath.Join(dir, "GitHub CLI")
+		}
+	}
+	home, _ := paths.Home()
+	return filepath.Join(home, ".config", "gh")
+}
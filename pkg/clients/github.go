@@ -2,48 +2,193 @@
 package clients
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/auth"
+	"github.com/jbrinkman/ghi/pkg/cache"
+	"github.com/jbrinkman/ghi/pkg/logger"
 	"golang.org/x/oauth2"
 )
 
-// NewGitHubClient creates a new GitHub client with custom configuration
-// to prevent caching issues and ensure fresh data on each request.
-// It will use GHI_GITHUB_TOKEN environment variable for authentication if available.
+// NewGitHubClient creates a new GitHub client with custom configuration.
+// GET requests are revalidated with ETags so unchanged responses are served
+// from the local cache without using rate limit quota, while data stays fresh.
+// It authenticates with the first token source GitHubTokenSource finds.
 func NewGitHubClient() (*github.Client, error) {
 	// Check for GitHub token
-	token := os.Getenv("GHI_GITHUB_TOKEN")
-	var httpClient *http.Client
-
-	if token != "" {
-		// Create authenticated client with OAuth2
-		ts := oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: token},
-		)
-		httpClient = oauth2.NewClient(context.Background(), ts)
-	} else {
+	tokens, _, err := tokenSource()
+	if err != nil {
+		return nil, err
+	}
+	return newGitHubClient(tokens)
+}
+
+// NewGitHubClientWithToken creates a GitHub client like NewGitHubClient that
+// authenticates with token instead of the first token source, so a new token
+// can be checked before it's saved even when another source takes precedence
+func NewGitHubClientWithToken(token string) (*github.Client, error) {
+	return newGitHubClient(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+}
+
+// newGitHubClient creates a GitHub client authenticated with tokens, or an
+// unauthenticated one when tokens is nil
+func newGitHubClient(tokens oauth2.TokenSource) (*github.Client, error) {
+	httpClient := &http.Client{}
+	var transport http.RoundTripper = http.DefaultTransport
+
+	if tokens == nil {
 		// Create unauthenticated client with custom transport
-		transport := &http.Transport{
+		transport = &http.Transport{
 			DisableKeepAlives:     true,
 			IdleConnTimeout:       1 * time.Second,
 			ResponseHeaderTimeout: 30 * time.Second,
 		}
-
-		httpClient = &http.Client{
-			Transport: transport,
-			Timeout:   1 * time.Minute,
-		}
+		httpClient.Timeout = 1 * time.Minute
 
 		// Warn about rate limiting
 		fmt.Fprintln(os.Stderr, "Warning: No GitHub token found. Requests will be rate limited to 60 per hour.")
-		fmt.Fprintln(os.Stderr, "Set GHI_GITHUB_TOKEN environment variable to increase rate limit to 5000 per hour.")
+		fmt.Fprintln(os.Stderr, "Set GHI_GITHUB_TOKEN environment variable, or sign in with 'ghi auth login' or 'gh auth login', to increase rate limit to 5000 per hour.")
+	}
+
+	// Make GET requests conditional on the cached ETag
+	if cacheEnabled {
+		if store, err := openCache(); err != nil {
+			logger.Debug("ETag cache disabled: %v", err)
+		} else {
+			transport = &etagTransport{base: transport, store: store}
+		}
+	}
+
+	if tokens != nil {
+		// Authenticate with OAuth2. The cache sits below this transport so
+		// it can key entries on the Authorization header.
+		transport = &oauth2.Transport{
+			Source: tokens,
+			Base:   transport,
+		}
+	}
+
+	// Retry rate limited and transient failures, and surface SAML SSO
+	// authorization failures with the authorization URL
+	httpClient.Transport = &ssoTransport{base: newRetryTransport(transport)}
+
+	// Create GitHub client, for GitHub Enterprise Server when a host is set
+	client := github.NewClient(httpClient)
+	if host := Host(); host != DefaultHost {
+		logger.Debug("Using GitHub Enterprise Server at %s", host)
+		return client.WithEnterpriseURLs("https://"+host+"/", "https://"+host+"/")
+	}
+	return client, nil
+}
+
+// DefaultHost is the host of github.com, used when GHI_HOST isn't set
+const DefaultHost = "github.com"
+
+// Host returns the GitHub host from GHI_HOST, or github.com when it isn't set
+func Host() string {
+	host := strings.TrimSuffix(strings.TrimPrefix(os.Getenv("GHI_HOST"), "https://"), "/")
+	if host == "" {
+		return DefaultHost
+	}
+	return strings.ToLower(host)
+}
+
+// tokenCommand is the command that prints the GitHub token, if one is set
+var tokenCommand string
+
+// SetTokenCommand sets a command, such as "op read op://eng/github/token",
+// that prints the GitHub token. It's run the first time a client is created
+// and takes the place of GHI_GITHUB_TOKEN.
+func SetTokenCommand(command string) {
+	tokenCommand = strings.TrimSpace(command)
+}
+
+// Where the GitHub token comes from, in order of precedence
+const (
+	TokenSourceCommand = "token_command"
+	TokenSourceApp     = "GitHub App"
+	TokenSourceEnv     = "GHI_GITHUB_TOKEN"
+	TokenSourceGHCLI   = "gh auth token"
+	TokenSourceGHHosts = "gh hosts.yml"
+)
+
+// ghCredentials is set when the gh CLI's token may be used
+var ghCredentials = true
+
+// SetGHCredentials sets whether the token the gh CLI is signed in with is used
+// when ghi has no token of its own
+func SetGHCredentials(enabled bool) {
+	ghCredentials = enabled
+}
+
+// TokenOverride returns the token source that takes precedence over
+// GHI_GITHUB_TOKEN when one is set, the token command or a GitHub App, or an
+// empty string. Unlike GitHubTokenSource it doesn't run the command or create
+// an installation token.
+func TokenOverride() string {
+	if tokenCommand != "" {
+		return TokenSourceCommand
+	}
+	if os.Getenv("GHI_APP_ID") != "" {
+		return TokenSourceApp
+	}
+	return ""
+}
+
+// GitHubToken returns the GitHub token, which may be empty. See GitHubTokenSource.
+func GitHubToken() (string, error) {
+	token, _, err := GitHubTokenSource()
+	return token, err
+}
+
+// GitHubTokenSource returns the GitHub token and where it came from, which
+// is the first of: the token command when one is set, an installation token
+// of the GitHub App set with GHI_APP_ID, GHI_GITHUB_TOKEN, or the token the gh
+// CLI is signed in with for the host. It returns an empty token when there's none.
+func GitHubTokenSource() (string, string, error) {
+	tokens, source, err := tokenSource()
+	if err != nil || tokens == nil {
+		return "", source, err
+	}
+	token, err := tokens.Token()
+	if err != nil {
+		return "", source, err
+	}
+	return token.AccessToken, source, nil
+}
+
+// tokenSource returns the source of GitHub tokens GitHubTokenSource describes,
+// or nil when there's no token. GitHub App installation tokens expire after
+// an hour, so their source creates a new one when needed.
+func tokenSource() (oauth2.TokenSource, string, error) {
+	if tokenCommand != "" {
+		token, err := auth.CommandToken(tokenCommand)
+		if err != nil {
+			return nil, TokenSourceCommand, err
+		}
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), TokenSourceCommand, nil
+	}
+	if app, err := appTokenSource(); err != nil || app != nil {
+		return app, TokenSourceApp, err
+	}
+	if token := os.Getenv("GHI_GITHUB_TOKEN"); token != "" {
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), TokenSourceEnv, nil
 	}
+	if ghCredentials {
+		if token, source := ghCLIToken(Host()); token != "" {
+			logger.Debug("Using the token of the gh CLI for %s from %s", Host(), source)
+			return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), source, nil
+		}
+	}
+	return nil, "", nil
+}
 
-	// Create GitHub client
-	return github.NewClient(httpClient), nil
+// openCache opens the ETag cache store in the configured storage
+func openCache() (*cache.Store, error) {
+	return cache.Open()
 }
@@ -0,0 +1,130 @@
+package clients
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+const (
+	// defaultMaxRetries is the number of times a request is retried before giving up
+	defaultMaxRetries = 3
+	// defaultBaseDelay is the initial backoff delay, doubled on each retry
+	defaultBaseDelay = 1 * time.Second
+	// defaultMaxDelay is the longest the transport will wait before a retry. Waits
+	// longer than this (e.g. an hourly core quota reset) are returned to the caller.
+	defaultMaxDelay = 1 * time.Minute
+)
+
+// retryTransport retries requests that were rate limited or failed with a
+// transient server error. It honors the Retry-After and X-RateLimit-Reset
+// headers and otherwise uses exponential backoff with jitter.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+// newRetryTransport wraps base with the default retry settings
+func newRetryTransport(base http.RoundTripper) *retryTransport {
+	return &retryTransport{
+		base:       base,
+		maxRetries: defaultMaxRetries,
+		baseDelay:  defaultBaseDelay,
+		maxDelay:   defaultMaxDelay,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || attempt >= t.maxRetries || !shouldRetry(req, resp) {
+			return resp, err
+		}
+
+		delay := t.retryDelay(resp, attempt)
+		if delay > t.maxDelay {
+			logger.Debug("Not retrying %s %s, wait of %v exceeds %v", req.Method, req.URL.Path, delay, t.maxDelay)
+			return resp, nil
+		}
+
+		// Requests with a body can only be retried if the body can be replayed
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		logger.Debug("Request %s %s returned %d, retrying in %v (attempt %d of %d)",
+			req.Method, req.URL.Path, resp.StatusCode, delay, attempt+1, t.maxRetries)
+		resp.Body.Close()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry reports whether the response indicates a rate limit or a
+// transient failure that is worth retrying. Rate limited searches are returned
+// right away, since their callers fall back to listing with the core quota
+// rather than waiting for the search quota.
+func shouldRetry(req *http.Request, resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return !isSearch(req)
+	case http.StatusForbidden:
+		// Primary rate limits report no remaining requests, secondary
+		// rate limits include a Retry-After header
+		limited := resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != ""
+		return limited && !isSearch(req)
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return req.Method == http.MethodGet || req.Method == http.MethodHead
+	}
+	return false
+}
+
+// isSearch reports whether the request uses the search API, which has its own
+// rate limit. Enterprise Server serves it under /api/v3/search/.
+func isSearch(req *http.Request) bool {
+	return strings.Contains(req.URL.Path, "/search/")
+}
+
+// retryDelay works out how long to wait before the next attempt
+func (t *retryTransport) retryDelay(resp *http.Response, attempt int) time.Duration {
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+		if at, err := http.ParseTime(retryAfter); err == nil {
+			return time.Until(at)
+		}
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			// Add a second of slack since the reset time has second precision
+			return time.Until(time.Unix(reset, 0)) + time.Second
+		}
+	}
+
+	backoff := t.baseDelay << attempt
+	jitter := time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+	return backoff + jitter
+}
@@ -0,0 +1,55 @@
+package clients
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingTransport answers every request with a secondary rate limit
+type countingTransport struct {
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	header := http.Header{}
+	header.Set("Retry-After", "0")
+	return &http.Response{
+		StatusCode: http.StatusForbidden,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func TestRetryTransportRateLimits(t *testing.T) {
+	tests := []struct {
+		url      string
+		requests int
+	}{
+		{"https://api.github.com/search/issues?q=repo:octo/repo", 1},
+		{"https://github.example.com/api/v3/search/issues?q=repo:octo/repo", 1},
+		{"https://api.github.com/repos/octo/repo/pulls", 4},
+	}
+	for _, tt := range tests {
+		base := &countingTransport{}
+		transport := newRetryTransport(base)
+		transport.baseDelay = time.Millisecond
+
+		req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if base.requests != tt.requests {
+			t.Errorf("%s sent %d requests, want %d", tt.url, base.requests, tt.requests)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package clients
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ssoHeader is the response header GitHub uses to report SAML SSO enforcement
+const ssoHeader = "X-GitHub-SSO"
+
+// SSORequiredError is returned when the token has not been authorized for an
+// organization that enforces SAML single sign-on.
+type SSORequiredError struct {
+	// AuthorizationURL is where the token can be authorized for the organization
+	AuthorizationURL string
+}
+
+// Error implements the error interface
+func (e *SSORequiredError) Error() string {
+	if e.AuthorizationURL == "" {
+		return "GitHub token is not authorized for SAML SSO in this organization"
+	}
+	return fmt.Sprintf("GitHub token is not authorized for SAML SSO in this organization. Authorize it at %s", e.AuthorizationURL)
+}
+
+// ssoTransport converts 403 responses caused by SAML SSO enforcement into an
+// SSORequiredError so callers can show the authorization URL.
+type ssoTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *ssoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusForbidden {
+		return resp, err
+	}
+
+	if ssoErr := parseSSOHeader(resp.Header.Get(ssoHeader)); ssoErr != nil {
+		resp.Body.Close()
+		return nil, ssoErr
+	}
+
+	return resp, nil
+}
+
+// parseSSOHeader parses a header of the form "required; url=https://..." and
+// returns nil if the header does not indicate that authorization is required.
+func parseSSOHeader(header string) *SSORequiredError {
+	parts := strings.Split(header, ";")
+	if strings.TrimSpace(parts[0]) != "required" {
+		return nil
+	}
+
+	ssoErr := &SSORequiredError{}
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "url=") {
+			ssoErr.AuthorizationURL = strings.TrimPrefix(part, "url=")
+		}
+	}
+	return ssoErr
+}
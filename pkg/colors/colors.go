@@ -0,0 +1,57 @@
+// Package colors decides whether ghi writes terminal colors and provides the
+// ANSI colors used outside lipgloss styles. Colors are left out when
+// --no-color is given, when NO_COLOR is set or when output isn't a terminal.
+package colors
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+)
+
+// disabled is set by --no-color or NO_COLOR
+var disabled bool
+
+// codes are the ANSI SGR codes of the colors Paint accepts
+var codes = map[string]string{
+	"bold":    "1",
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"gray":    "90",
+}
+
+// Disable turns off colors for the rest of the run, including the lipgloss
+// styles of the tables and the interactive views
+func Disable() {
+	disabled = true
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
+// Enabled reports whether colors should be written to f: they haven't been
+// disabled, NO_COLOR isn't set and f is a terminal
+func Enabled(f *os.File) bool {
+	return !disabled && os.Getenv("NO_COLOR") == "" && term.IsTerminal(f.Fd())
+}
+
+// Valid reports whether name is a color Paint accepts
+func Valid(name string) bool {
+	_, ok := codes[strings.ToLower(name)]
+	return ok
+}
+
+// Paint wraps text in the ANSI escapes of a named color. Callers check
+// Enabled first; unknown names return text unchanged.
+func Paint(name, text string) string {
+	code, ok := codes[strings.ToLower(name)]
+	if !ok {
+		return text
+	}
+	return "\033[" + code + "m" + text + "\033[0m"
+}
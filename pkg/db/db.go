@@ -4,30 +4,88 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/jbrinkman/ghi/pkg/auth"
 	_ "github.com/tursodatabase/libsql-client-go/libsql"
 )
 
 const (
 	// ReviewsTableName is the name of the table storing review data
 	ReviewsTableName = "reviews"
+
+	// reviewColumns are the columns selected when reading reviews, in Review field order
+	reviewColumns = "id, repo, pr_number, reviewer, timestamp, COALESCE(note, ''), COALESCE(review_id, 0), COALESCE(review_url, ''), COALESCE(paths, ''), COALESCE(verdict, '')"
+
+	// legacyPathSeparator separated the reviewed paths stored in the paths
+	// column before they were stored as a JSON array
+	legacyPathSeparator = ","
+
+	// ModePersonal is a database holding the reviews of a single user
+	ModePersonal = "personal"
+	// ModeShared is a database shared by a team, holding everyone's reviews
+	ModeShared = "shared"
+
+	// Verdicts a review can be logged with
+	VerdictApproved         = "approved"
+	VerdictChangesRequested = "changes-requested"
+	VerdictCommented        = "commented"
 )
 
+// Verdicts are the valid review verdicts
+var Verdicts = []string{VerdictApproved, VerdictChangesRequested, VerdictCommented}
+
+// ErrReviewNotFound is returned when there's no review with an ID
+var ErrReviewNotFound = errors.New("review not found")
+
 // Review represents a code review entry in the database
 type Review struct {
-	ID        int64
-	Repo      string
-	PRNumber  int
-	Reviewer  string
-	Timestamp time.Time
+	ID        int64     `json:"id"`
+	Repo      string    `json:"repo"`
+	PRNumber  int       `json:"pr_number"`
+	Reviewer  string    `json:"reviewer"`
+	Timestamp time.Time `json:"timestamp"`
+	Note      string    `json:"note"`
+	// GitHubReviewID and GitHubReviewURL link to the submitted GitHub review, if known
+	GitHubReviewID  int64  `json:"github_review_id"`
+	GitHubReviewURL string `json:"github_review_url"`
+	// Paths are the files or glob patterns covered by the review
+	Paths []string `json:"paths"`
+	// Verdict is the outcome of the review, one of Verdicts, or empty if unknown
+	Verdict string `json:"verdict"`
+}
+
+// ValidateVerdict returns an error if the verdict isn't empty or one of Verdicts
+func ValidateVerdict(verdict string) error {
+	if verdict == "" {
+		return nil
+	}
+	for _, v := range Verdicts {
+		if v == verdict {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid verdict %q, use one of %s", verdict, strings.Join(Verdicts, ", "))
 }
 
 // Client handles database operations for review tracking
 type Client struct {
-	db *sql.DB
+	db   *sql.DB
+	mode string
+}
+
+// tokenCommand is the command that prints the database token, if one is set
+var tokenCommand string
+
+// SetTokenCommand sets a command that prints the database token. It's run when
+// a client is created and takes the place of GHI_AUTH_TOKEN.
+func SetTokenCommand(command string) {
+	tokenCommand = strings.TrimSpace(command)
 }
 
 // NewClient creates a new database client using environment variables for configuration
@@ -38,6 +96,22 @@ func NewClient() (*Client, error) {
 	}
 
 	authToken := os.Getenv("GHI_AUTH_TOKEN")
+	if tokenCommand != "" {
+		token, err := auth.CommandToken(tokenCommand)
+		if err != nil {
+			return nil, err
+		}
+		authToken = token
+	}
+
+	// The database mode only changes how commands present the data
+	mode := os.Getenv("GHI_DB_MODE")
+	if mode == "" {
+		mode = ModePersonal
+	}
+	if mode != ModePersonal && mode != ModeShared {
+		return nil, fmt.Errorf("invalid GHI_DB_MODE %q, use %q or %q", mode, ModePersonal, ModeShared)
+	}
 
 	// Create the connection string with auth token if available
 	connStr := dbURL
@@ -57,7 +131,13 @@ func NewClient() (*Client, error) {
 		return nil, fmt.Errorf("failed to connect to database (ping): %w", err)
 	}
 
-	return &Client{db: db}, nil
+	return &Client{db: db, mode: mode}, nil
+}
+
+// Shared reports whether the database is shared by a team rather than
+// holding only the current user's reviews
+func (c *Client) Shared() bool {
+	return c.mode == ModeShared
 }
 
 // InitSchema ensures the database schema exists
@@ -70,18 +150,72 @@ func (c *Client) InitSchema(ctx context.Context) error {
 			pr_number INTEGER NOT NULL,
 			reviewer TEXT NOT NULL,
 			timestamp DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			note TEXT,
+			review_id INTEGER,
+			review_url TEXT,
+			paths TEXT,
+			verdict TEXT,
 			UNIQUE(repo, pr_number, reviewer, timestamp)
 		)
 	`)
+	if err != nil {
+		return err
+	}
+
+	// Add columns introduced after the table was first created
+	columns := []struct{ name, definition string }{
+		{"note", "TEXT"},
+		{"review_id", "INTEGER"},
+		{"review_url", "TEXT"},
+		{"paths", "TEXT"},
+	}
+	for _, column := range columns {
+		if err := c.addColumnIfMissing(ctx, ReviewsTableName, column.name, column.definition); err != nil {
+			return err
+		}
+	}
 
-	return err
+	return c.initHandoffs(ctx)
 }
 
-// LogReview records a new code review in the database
-func (c *Client) LogReview(ctx context.Context, repo string, prNumber int, reviewer string) error {
+// addColumnIfMissing adds a column to an existing table if it isn't already present
+func (c *Client) addColumnIfMissing(ctx context.Context, table, column, definition string) error {
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to read %s schema: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return fmt.Errorf("failed to scan %s schema: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating %s schema: %w", table, err)
+	}
+
+	_, err = c.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	if err != nil {
+		return fmt.Errorf("failed to add %s column to %s: %w", column, table, err)
+	}
+	return nil
+}
+
+// LogReview records a new code review in the database. The note, GitHub
+// review link, paths and verdict are optional and stored as NULL when empty.
+func (c *Client) LogReview(ctx context.Context, review Review) error {
 	_, err := c.db.ExecContext(ctx,
-		"INSERT INTO reviews (repo, pr_number, reviewer) VALUES (?, ?, ?)",
-		repo, prNumber, reviewer)
+		"INSERT INTO reviews (repo, pr_number, reviewer, note, review_id, review_url, paths, verdict) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		review.Repo, review.PRNumber, review.Reviewer,
+		nullIfEmpty(review.Note), nullIfZero(review.GitHubReviewID), nullIfEmpty(review.GitHubReviewURL),
+		nullIfEmpty(joinPaths(review.Paths)), nullIfEmpty(review.Verdict))
 
 	if err != nil {
 		return fmt.Errorf("failed to log review: %w", err)
@@ -90,6 +224,128 @@ func (c *Client) LogReview(ctx context.Context, repo string, prNumber int, revie
 	return nil
 }
 
+// ImportReview records a review submitted on GitHub at the time it was
+// submitted, unless a review linked to the same GitHub review is already
+// logged. It reports whether the review was added. An existing review without
+// a verdict takes the imported one.
+func (c *Client) ImportReview(ctx context.Context, review Review) (bool, error) {
+	var id int64
+	err := c.db.QueryRowContext(ctx, "SELECT id FROM reviews WHERE review_id = ?", review.GitHubReviewID).Scan(&id)
+	if err == nil {
+		_, err = c.db.ExecContext(ctx, "UPDATE reviews SET verdict = ? WHERE id = ? AND verdict IS NULL",
+			nullIfEmpty(review.Verdict), id)
+		if err != nil {
+			return false, fmt.Errorf("failed to update review %d: %w", id, err)
+		}
+		return false, nil
+	}
+	if err != sql.ErrNoRows {
+		return false, fmt.Errorf("failed to look up GitHub review %d: %w", review.GitHubReviewID, err)
+	}
+
+	_, err = c.db.ExecContext(ctx,
+		"INSERT INTO reviews (repo, pr_number, reviewer, timestamp, note, review_id, review_url, paths, verdict) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		review.Repo, review.PRNumber, review.Reviewer, review.Timestamp.UTC().Format("2006-01-02 15:04:05"),
+		nullIfEmpty(review.Note), nullIfZero(review.GitHubReviewID), nullIfEmpty(review.GitHubReviewURL),
+		nullIfEmpty(joinPaths(review.Paths)), nullIfEmpty(review.Verdict))
+	if err != nil {
+		return false, fmt.Errorf("failed to import review: %w", err)
+	}
+	return true, nil
+}
+
+// GetReview retrieves a single review by its ID. It returns ErrReviewNotFound
+// when there's no review with the ID.
+func (c *Client) GetReview(ctx context.Context, id int64) (*Review, error) {
+	rows, err := c.db.QueryContext(ctx, "SELECT "+reviewColumns+" FROM reviews WHERE id = ?", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review %d: %w", id, err)
+	}
+	defer rows.Close()
+
+	reviews, err := scanReviews(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(reviews) == 0 {
+		return nil, fmt.Errorf("review %d: %w", id, ErrReviewNotFound)
+	}
+	return &reviews[0], nil
+}
+
+// UpdateReview changes the timestamp, note and verdict of a logged review
+func (c *Client) UpdateReview(ctx context.Context, review Review) error {
+	result, err := c.db.ExecContext(ctx,
+		"UPDATE reviews SET timestamp = ?, note = ?, verdict = ? WHERE id = ?",
+		review.Timestamp.UTC().Format("2006-01-02 15:04:05"), nullIfEmpty(review.Note), nullIfEmpty(review.Verdict), review.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update review %d: %w", review.ID, err)
+	}
+	return checkAffected(result, review.ID)
+}
+
+// DeleteReview removes a logged review
+func (c *Client) DeleteReview(ctx context.Context, id int64) error {
+	result, err := c.db.ExecContext(ctx, "DELETE FROM reviews WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete review %d: %w", id, err)
+	}
+	return checkAffected(result, id)
+}
+
+// checkAffected returns ErrReviewNotFound when a statement changed no reviews
+func checkAffected(result sql.Result, id int64) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check changes to review %d: %w", id, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("review %d: %w", id, ErrReviewNotFound)
+	}
+	return nil
+}
+
+// nullIfEmpty converts an empty string to a NULL column value
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// joinPaths formats reviewed paths for the paths column as a JSON array, so
+// paths holding commas, such as the brace glob pkg/{db,ui}/**, are kept whole
+func joinPaths(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(paths)
+	return string(data)
+}
+
+// splitPaths parses the stored paths column, either a JSON array or the comma
+// separated paths of reviews logged before
+func splitPaths(paths string) []string {
+	if paths == "" {
+		return nil
+	}
+	if strings.HasPrefix(paths, "[") {
+		var list []string
+		if err := json.Unmarshal([]byte(paths), &list); err == nil {
+			return list
+		}
+	}
+	return strings.Split(paths, legacyPathSeparator)
+}
+
+// nullIfZero converts a zero ID to a NULL column value
+func nullIfZero(id int64) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
 // parseTimestamp attempts to parse a timestamp string using multiple formats
 func parseTimestamp(timestamp string) (time.Time, error) {
 	// Try different time formats, from most specific to least specific
@@ -114,25 +370,18 @@ func parseTimestamp(timestamp string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("failed to parse timestamp '%s': %w", timestamp, err)
 }
 
-// GetReviews retrieves reviews for a specific PR
-func (c *Client) GetReviews(ctx context.Context, repo string, prNumber int) ([]Review, error) {
-	rows, err := c.db.QueryContext(ctx,
-		"SELECT id, repo, pr_number, reviewer, timestamp FROM reviews WHERE repo = ? AND pr_number = ? ORDER BY timestamp DESC",
-		repo, prNumber)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get reviews: %w", err)
-	}
-	defer rows.Close()
-
+// scanReviews reads all review rows selected with reviewColumns
+func scanReviews(rows *sql.Rows) ([]Review, error) {
 	var reviews []Review
 	for rows.Next() {
 		var review Review
-		var timestamp string
-
-		err := rows.Scan(&review.ID, &review.Repo, &review.PRNumber, &review.Reviewer, &timestamp)
+		var timestamp, paths string
+		err := rows.Scan(&review.ID, &review.Repo, &review.PRNumber, &review.Reviewer, &timestamp,
+			&review.Note, &review.GitHubReviewID, &review.GitHubReviewURL, &paths, &review.Verdict)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan review row: %w", err)
 		}
+		review.Paths = splitPaths(paths)
 
 		// Parse timestamp with the flexible parser
 		t, err := parseTimestamp(timestamp)
@@ -140,13 +389,29 @@ func (c *Client) GetReviews(ctx context.Context, repo string, prNumber int) ([]R
 			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
 		}
 		review.Timestamp = t
-
 		reviews = append(reviews, review)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating review rows: %w", err)
+	}
+
 	return reviews, nil
 }
 
+// GetReviews retrieves reviews for a specific PR
+func (c *Client) GetReviews(ctx context.Context, repo string, prNumber int) ([]Review, error) {
+	rows, err := c.db.QueryContext(ctx,
+		"SELECT "+reviewColumns+" FROM reviews WHERE repo = ? AND pr_number = ? ORDER BY timestamp DESC",
+		repo, prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews: %w", err)
+	}
+	defer rows.Close()
+
+	return scanReviews(rows)
+}
+
 // GetReviewsByDateRange retrieves reviews within the specified date range
 func (c *Client) GetReviewsByDateRange(ctx context.Context, repo string, startDate, endDate time.Time) ([]Review, error) {
 	// Construct query based on whether we have a repo filter
@@ -154,15 +419,15 @@ func (c *Client) GetReviewsByDateRange(ctx context.Context, repo string, startDa
 	var args []interface{}
 
 	if repo != "" {
-		query = `SELECT id, repo, pr_number, reviewer, timestamp 
-				FROM reviews 
-				WHERE repo = ? AND timestamp >= ? AND timestamp <= ? 
+		query = `SELECT ` + reviewColumns + `
+				FROM reviews
+				WHERE repo = ? AND timestamp >= ? AND timestamp <= ?
 				ORDER BY timestamp DESC`
 		args = []interface{}{repo, startDate.Format("2006-01-02"), endDate.Format("2006-01-02 23:59:59")}
 	} else {
-		query = `SELECT id, repo, pr_number, reviewer, timestamp 
-				FROM reviews 
-				WHERE timestamp >= ? AND timestamp <= ? 
+		query = `SELECT ` + reviewColumns + `
+				FROM reviews
+				WHERE timestamp >= ? AND timestamp <= ?
 				ORDER BY timestamp DESC`
 		args = []interface{}{startDate.Format("2006-01-02"), endDate.Format("2006-01-02 23:59:59")}
 	}
@@ -173,27 +438,7 @@ func (c *Client) GetReviewsByDateRange(ctx context.Context, repo string, startDa
 	}
 	defer rows.Close()
 
-	var reviews []Review
-	for rows.Next() {
-		var review Review
-		var timestamp string
-
-		err := rows.Scan(&review.ID, &review.Repo, &review.PRNumber, &review.Reviewer, &timestamp)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan review row: %w", err)
-		}
-
-		// Parse timestamp with the flexible parser
-		t, err := parseTimestamp(timestamp)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
-		}
-		review.Timestamp = t
-
-		reviews = append(reviews, review)
-	}
-
-	return reviews, nil
+	return scanReviews(rows)
 }
 
 // GetReviewsByReviewer retrieves all reviews by a specific reviewer, optionally filtered by repository
@@ -202,15 +447,15 @@ func (c *Client) GetReviewsByReviewer(ctx context.Context, reviewer string, repo
 	var args []interface{}
 
 	if repo != "" {
-		query = `SELECT id, repo, pr_number, reviewer, timestamp 
-				FROM reviews 
-				WHERE reviewer = ? AND repo = ? 
+		query = `SELECT ` + reviewColumns + `
+				FROM reviews
+				WHERE reviewer = ? AND repo = ?
 				ORDER BY timestamp DESC`
 		args = []interface{}{reviewer, repo}
 	} else {
-		query = `SELECT id, repo, pr_number, reviewer, timestamp 
-				FROM reviews 
-				WHERE reviewer = ? 
+		query = `SELECT ` + reviewColumns + `
+				FROM reviews
+				WHERE reviewer = ?
 				ORDER BY timestamp DESC`
 		args = []interface{}{reviewer}
 	}
@@ -221,29 +466,27 @@ func (c *Client) GetReviewsByReviewer(ctx context.Context, reviewer string, repo
 	}
 	defer rows.Close()
 
-	var reviews []Review
-	for rows.Next() {
-		var review Review
-		var timestamp string
-		err := rows.Scan(&review.ID, &review.Repo, &review.PRNumber, &review.Reviewer, &timestamp)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan review row: %w", err)
-		}
+	return scanReviews(rows)
+}
 
-		// Parse timestamp with the flexible parser
-		t, err := parseTimestamp(timestamp)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
-		}
-		review.Timestamp = t
-		reviews = append(reviews, review)
+// GetReviewsAfter retrieves the reviews logged after the review with the given
+// ID, in the order they were logged, optionally filtered by repository
+func (c *Client) GetReviewsAfter(ctx context.Context, repo string, afterID int64) ([]Review, error) {
+	query := `SELECT ` + reviewColumns + ` FROM reviews WHERE id > ?`
+	args := []interface{}{afterID}
+	if repo != "" {
+		query += " AND repo = ?"
+		args = append(args, repo)
 	}
+	query += " ORDER BY id"
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating review rows: %w", err)
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews after %d: %w", afterID, err)
 	}
+	defer rows.Close()
 
-	return reviews, nil
+	return scanReviews(rows)
 }
 
 // Close closes the database connection
@@ -0,0 +1,25 @@
+package db
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPathsKeepBraceGlobs(t *testing.T) {
+	paths := []string{"pkg/{db,ui}/**", "cmd/view.go"}
+
+	stored := joinPaths(paths)
+	if got := splitPaths(stored); !slices.Equal(got, paths) {
+		t.Errorf("splitPaths(%q) = %q, want %q", stored, got, paths)
+	}
+}
+
+func TestSplitPathsLegacy(t *testing.T) {
+	want := []string{"pkg/db/**", "cmd/view.go"}
+	if got := splitPaths("pkg/db/**,cmd/view.go"); !slices.Equal(got, want) {
+		t.Errorf("splitPaths = %q, want %q", got, want)
+	}
+	if got := splitPaths(""); got != nil {
+		t.Errorf("splitPaths of an empty column = %q, want nil", got)
+	}
+}
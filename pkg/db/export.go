@@ -0,0 +1,65 @@
+package db
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/export"
+)
+
+// ExportedReview is a review along with fields computed when it's exported
+type ExportedReview struct {
+	Review
+	// PRState is the state of the pull request at export time: open, closed,
+	// merged, or empty when it wasn't looked up
+	PRState string `json:"pr_state"`
+	PRTitle string `json:"pr_title"`
+}
+
+// exportColumns are the header of a CSV or TSV export
+var exportColumns = []string{
+	"id", "repo", "pr_number", "reviewer", "timestamp", "verdict", "note", "paths",
+	"github_review_id", "github_review_url", "pr_state", "pr_title",
+}
+
+// WriteReviews writes the reviews in one of the export formats
+func WriteReviews(w io.Writer, format string, reviews []ExportedReview) error {
+	if reviews == nil {
+		reviews = []ExportedReview{}
+	}
+	rows := make([][]string, 0, len(reviews))
+	for _, review := range reviews {
+		rows = append(rows, exportRecord(review))
+	}
+	if err := export.Write(w, format, exportColumns, rows, reviews); err != nil {
+		return fmt.Errorf("failed to write reviews: %w", err)
+	}
+	return nil
+}
+
+// exportRecord returns the fields of a review in the order of exportColumns.
+// Paths are joined with a space within their field, since brace globs such as
+// pkg/{db,ui}/** hold commas.
+func exportRecord(review ExportedReview) []string {
+	reviewID := ""
+	if review.GitHubReviewID != 0 {
+		reviewID = strconv.FormatInt(review.GitHubReviewID, 10)
+	}
+	return []string{
+		strconv.FormatInt(review.ID, 10),
+		review.Repo,
+		strconv.Itoa(review.PRNumber),
+		review.Reviewer,
+		review.Timestamp.UTC().Format(time.RFC3339),
+		review.Verdict,
+		review.Note,
+		strings.Join(review.Paths, " "),
+		reviewID,
+		review.GitHubReviewURL,
+		review.PRState,
+		review.PRTitle,
+	}
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// HandoffsTableName is the name of the table storing review handoffs
+const HandoffsTableName = "handoffs"
+
+// handoffColumns are the columns selected when reading handoffs, in Handoff field order
+const handoffColumns = "id, repo, pr_number, from_reviewer, to_reviewer, timestamp, COALESCE(note, ''), COALESCE(comment_url, '')"
+
+// Handoff records a reviewer passing the rest of a review on to someone else,
+// with a note of what they covered
+type Handoff struct {
+	ID        int64     `json:"id"`
+	Repo      string    `json:"repo"`
+	PRNumber  int       `json:"pr_number"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Timestamp time.Time `json:"timestamp"`
+	Note      string    `json:"note"`
+	// CommentURL links to the comment posted on the pull request, if any
+	CommentURL string `json:"comment_url"`
+}
+
+// initHandoffs creates the handoffs table
+func (c *Client) initHandoffs(ctx context.Context) error {
+	_, err := c.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS handoffs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			repo TEXT NOT NULL,
+			pr_number INTEGER NOT NULL,
+			from_reviewer TEXT NOT NULL,
+			to_reviewer TEXT NOT NULL,
+			timestamp DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			note TEXT,
+			comment_url TEXT
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create handoffs table: %w", err)
+	}
+	return nil
+}
+
+// LogHandoff records a handoff and returns its ID. The note and comment link
+// are optional and stored as NULL when empty.
+func (c *Client) LogHandoff(ctx context.Context, handoff Handoff) (int64, error) {
+	result, err := c.db.ExecContext(ctx,
+		"INSERT INTO handoffs (repo, pr_number, from_reviewer, to_reviewer, note, comment_url) VALUES (?, ?, ?, ?, ?, ?)",
+		handoff.Repo, handoff.PRNumber, handoff.From, handoff.To,
+		nullIfEmpty(handoff.Note), nullIfEmpty(handoff.CommentURL))
+	if err != nil {
+		return 0, fmt.Errorf("failed to log handoff: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the handoff ID: %w", err)
+	}
+	return id, nil
+}
+
+// GetHandoffs retrieves the handoffs of a pull request, newest first
+func (c *Client) GetHandoffs(ctx context.Context, repo string, prNumber int) ([]Handoff, error) {
+	rows, err := c.db.QueryContext(ctx,
+		"SELECT "+handoffColumns+" FROM handoffs WHERE repo = ? AND pr_number = ? ORDER BY timestamp DESC, id DESC",
+		repo, prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get handoffs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanHandoffs(rows)
+}
+
+// GetHandoffsTo retrieves the handoffs passed to a reviewer, newest first,
+// optionally filtered by repository
+func (c *Client) GetHandoffsTo(ctx context.Context, reviewer, repo string) ([]Handoff, error) {
+	query := "SELECT " + handoffColumns + " FROM handoffs WHERE to_reviewer = ? COLLATE NOCASE"
+	args := []interface{}{reviewer}
+	if repo != "" {
+		query += " AND repo = ?"
+		args = append(args, repo)
+	}
+	query += " ORDER BY timestamp DESC, id DESC"
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get handoffs to %s: %w", reviewer, err)
+	}
+	defer rows.Close()
+
+	return scanHandoffs(rows)
+}
+
+// scanHandoffs reads all handoff rows selected with handoffColumns
+func scanHandoffs(rows *sql.Rows) ([]Handoff, error) {
+	var handoffs []Handoff
+	for rows.Next() {
+		var handoff Handoff
+		var timestamp string
+		err := rows.Scan(&handoff.ID, &handoff.Repo, &handoff.PRNumber, &handoff.From, &handoff.To,
+			&timestamp, &handoff.Note, &handoff.CommentURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan handoff row: %w", err)
+		}
+		t, err := parseTimestamp(timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		handoff.Timestamp = t
+		handoffs = append(handoffs, handoff)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating handoff rows: %w", err)
+	}
+
+	return handoffs, nil
+}
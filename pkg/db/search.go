@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ReviewsSearchTableName is the name of the full-text index over review notes
+const ReviewsSearchTableName = "reviews_fts"
+
+// InitSearchIndex creates the full-text index over review notes and the triggers
+// that keep it in sync with the reviews table. Notes logged before the index
+// existed are indexed when it is first created. It isn't part of InitSchema
+// because FTS5 isn't in every SQLite build, and only searching needs it.
+func (c *Client) InitSearchIndex(ctx context.Context) error {
+	var name string
+	err := c.db.QueryRowContext(ctx,
+		"SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?", ReviewsSearchTableName).Scan(&name)
+	exists := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check for search index: %w", err)
+	}
+
+	statements := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS reviews_fts USING fts5(
+			note,
+			content='reviews',
+			content_rowid='id'
+		)`,
+		`CREATE TRIGGER IF NOT EXISTS reviews_fts_insert AFTER INSERT ON reviews BEGIN
+			INSERT INTO reviews_fts(rowid, note) VALUES (new.id, new.note);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS reviews_fts_delete AFTER DELETE ON reviews BEGIN
+			INSERT INTO reviews_fts(reviews_fts, rowid, note) VALUES ('delete', old.id, old.note);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS reviews_fts_update AFTER UPDATE OF note ON reviews BEGIN
+			INSERT INTO reviews_fts(reviews_fts, rowid, note) VALUES ('delete', old.id, old.note);
+			INSERT INTO reviews_fts(rowid, note) VALUES (new.id, new.note);
+		END`,
+	}
+	for _, statement := range statements {
+		if _, err := c.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to create search index: %w", err)
+		}
+	}
+
+	if !exists {
+		if _, err := c.db.ExecContext(ctx, "INSERT INTO reviews_fts(reviews_fts) VALUES ('rebuild')"); err != nil {
+			return fmt.Errorf("failed to build search index: %w", err)
+		}
+	}
+	return nil
+}
+
+// SearchReviews finds reviews whose notes match the query, best matches first.
+// Every word in the query must appear in the note, and a trailing * matches
+// words by prefix. The results can be limited to a repository and a maximum count.
+func (c *Client) SearchReviews(ctx context.Context, query, repo string, limit int) ([]Review, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, fmt.Errorf("search query is empty")
+	}
+
+	sqlQuery := `SELECT ` + reviewColumns + `
+			FROM reviews
+			JOIN (SELECT rowid AS match_id, rank AS match_rank FROM reviews_fts WHERE reviews_fts MATCH ?) matches
+				ON matches.match_id = reviews.id`
+	args := []interface{}{match}
+	if repo != "" {
+		sqlQuery += " WHERE repo = ?"
+		args = append(args, repo)
+	}
+	sqlQuery += " ORDER BY matches.match_rank, timestamp DESC"
+	if limit > 0 {
+		sqlQuery += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := c.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search reviews: %w", err)
+	}
+	defer rows.Close()
+
+	return scanReviews(rows)
+}
+
+// ftsQuery converts free text into an FTS5 query that matches all of its words.
+// Each word is quoted so punctuation can't be mistaken for query syntax.
+func ftsQuery(query string) string {
+	var terms []string
+	for _, word := range strings.Fields(query) {
+		prefix := strings.HasSuffix(word, "*")
+		word = strings.ReplaceAll(strings.TrimRight(word, "*"), `"`, `""`)
+		if word == "" {
+			continue
+		}
+
+		term := `"` + word + `"`
+		if prefix {
+			term += "*"
+		}
+		terms = append(terms, term)
+	}
+	return strings.Join(terms, " ")
+}
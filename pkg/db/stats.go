@@ -0,0 +1,373 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StatsGrouping identifies how reviews are grouped when aggregating
+type StatsGrouping string
+
+const (
+	// GroupByReviewer aggregates reviews by reviewer
+	GroupByReviewer StatsGrouping = "reviewer"
+	// GroupByRepo aggregates reviews by repository
+	GroupByRepo StatsGrouping = "repo"
+	// GroupByWeek aggregates reviews by ISO 8601 year and week number, such as 2024-W01
+	GroupByWeek StatsGrouping = "week"
+	// GroupByMonth aggregates reviews by year and month
+	GroupByMonth StatsGrouping = "month"
+)
+
+// isoWeekExpression is the ISO 8601 week of the timestamp column. An ISO week
+// runs Monday to Sunday and belongs to the year, and has the number, of its
+// Thursday, so the days around New Year can fall in the previous or next year.
+// SQLite's %W counts weeks from the first Monday of the calendar year instead.
+const isoWeekExpression = "printf('%s-W%02d', strftime('%Y', timestamp, '-3 days', 'weekday 4'), " +
+	"(strftime('%j', timestamp, '-3 days', 'weekday 4') - 1) / 7 + 1)"
+
+// groupingExpressions maps each grouping to the SQL expression used to group rows
+var groupingExpressions = map[StatsGrouping]string{
+	GroupByReviewer: "reviewer",
+	GroupByRepo:     "repo",
+	GroupByWeek:     isoWeekExpression,
+	GroupByMonth:    "strftime('%Y-%m', timestamp)",
+}
+
+// ReviewCount is the number of reviews logged for a single grouping key
+type ReviewCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// ReviewStats summarizes the reviews logged within a date range
+type ReviewStats struct {
+	StartDate     time.Time     `json:"start_date"`
+	EndDate       time.Time     `json:"end_date"`
+	Total         int           `json:"total"`
+	PerDayAverage float64       `json:"per_day_average"`
+	ByReviewer    []ReviewCount `json:"by_reviewer"`
+	ByRepo        []ReviewCount `json:"by_repo"`
+	ByWeek        []ReviewCount `json:"by_week"`
+	ByMonth       []ReviewCount `json:"by_month"`
+	ByPath        []ReviewCount `json:"by_path"`
+}
+
+// GetReviewCounts aggregates reviews within the date range using the given grouping.
+// Reviewer and repository groupings are ordered by count, time groupings chronologically.
+func (c *Client) GetReviewCounts(ctx context.Context, grouping StatsGrouping, repo string, startDate, endDate time.Time) ([]ReviewCount, error) {
+	expr, ok := groupingExpressions[grouping]
+	if !ok {
+		return nil, fmt.Errorf("unsupported grouping: %s", grouping)
+	}
+
+	order := "COUNT(*) DESC, key"
+	if grouping == GroupByWeek || grouping == GroupByMonth {
+		order = "key"
+	}
+
+	query := fmt.Sprintf(`SELECT %s AS key, COUNT(*)
+			FROM reviews
+			WHERE timestamp >= ? AND timestamp <= ?`, expr)
+	args := []interface{}{startDate.Format("2006-01-02"), endDate.Format("2006-01-02 23:59:59")}
+	if repo != "" {
+		query += " AND repo = ?"
+		args = append(args, repo)
+	}
+	query += fmt.Sprintf(" GROUP BY key ORDER BY %s", order)
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review counts by %s: %w", grouping, err)
+	}
+	defer rows.Close()
+
+	var counts []ReviewCount
+	for rows.Next() {
+		var count ReviewCount
+		if err := rows.Scan(&count.Key, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan review count row: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating review count rows: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetReviewStats builds a summary of all reviews within the date range,
+// optionally filtered by repository
+func (c *Client) GetReviewStats(ctx context.Context, repo string, startDate, endDate time.Time) (*ReviewStats, error) {
+	stats := &ReviewStats{
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+
+	groupings := []struct {
+		grouping StatsGrouping
+		target   *[]ReviewCount
+	}{
+		{GroupByReviewer, &stats.ByReviewer},
+		{GroupByRepo, &stats.ByRepo},
+		{GroupByWeek, &stats.ByWeek},
+		{GroupByMonth, &stats.ByMonth},
+	}
+
+	for _, g := range groupings {
+		counts, err := c.GetReviewCounts(ctx, g.grouping, repo, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		*g.target = counts
+	}
+
+	byPath, err := c.GetPathCounts(ctx, repo, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	stats.ByPath = byPath
+
+	for _, count := range stats.ByReviewer {
+		stats.Total += count.Count
+	}
+
+	// Both ends of the range are inclusive
+	days := math.Floor(endDate.Sub(startDate).Hours()/24) + 1
+	if days > 0 {
+		stats.PerDayAverage = float64(stats.Total) / days
+	}
+
+	return stats, nil
+}
+
+// GetPathCounts counts how many reviews within the date range covered each
+// recorded path, ordered from most to least reviewed. Reviews without
+// recorded paths are not counted.
+func (c *Client) GetPathCounts(ctx context.Context, repo string, startDate, endDate time.Time) ([]ReviewCount, error) {
+	query := `SELECT paths
+			FROM reviews
+			WHERE paths IS NOT NULL AND paths != '' AND timestamp >= ? AND timestamp <= ?`
+	args := []interface{}{startDate.Format("2006-01-02"), endDate.Format("2006-01-02 23:59:59")}
+	if repo != "" {
+		query += " AND repo = ?"
+		args = append(args, repo)
+	}
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review counts by path: %w", err)
+	}
+	defer rows.Close()
+
+	// Paths are stored as a list per review, so they are counted here rather than in SQL
+	totals := make(map[string]int)
+	for rows.Next() {
+		var paths string
+		if err := rows.Scan(&paths); err != nil {
+			return nil, fmt.Errorf("failed to scan review paths row: %w", err)
+		}
+		for _, path := range splitPaths(paths) {
+			totals[path]++
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating review paths rows: %w", err)
+	}
+
+	counts := make([]ReviewCount, 0, len(totals))
+	for path, count := range totals {
+		counts = append(counts, ReviewCount{Key: path, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Key < counts[j].Key
+	})
+
+	return counts, nil
+}
+
+// GetDailyReviewCounts returns the number of reviews logged by the reviewer on
+// each day within the date range, keyed by YYYY-MM-DD. An empty reviewer
+// includes everyone and an empty repo includes all repositories.
+func (c *Client) GetDailyReviewCounts(ctx context.Context, reviewer, repo string, startDate, endDate time.Time) (map[string]int, error) {
+	query := `SELECT date(timestamp) AS day, COUNT(*)
+			FROM reviews
+			WHERE timestamp >= ? AND timestamp <= ?`
+	args := []interface{}{startDate.Format("2006-01-02"), endDate.Format("2006-01-02 23:59:59")}
+	if reviewer != "" {
+		query += " AND reviewer = ?"
+		args = append(args, reviewer)
+	}
+	if repo != "" {
+		query += " AND repo = ?"
+		args = append(args, repo)
+	}
+	query += " GROUP BY day"
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily review counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var day string
+		var count int
+		if err := rows.Scan(&day, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan daily review count row: %w", err)
+		}
+		counts[day] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating daily review count rows: %w", err)
+	}
+
+	return counts, nil
+}
+
+// ReviewerSummary summarizes one reviewer's activity within a date range
+type ReviewerSummary struct {
+	Reviewer     string    `json:"reviewer"`
+	Reviews      int       `json:"reviews"`
+	PullRequests int       `json:"pull_requests"`
+	Repos        int       `json:"repos"`
+	LastReview   time.Time `json:"last_review"`
+}
+
+// GetReviewerSummaries summarizes the reviews each reviewer logged within the
+// date range, most active first. An empty repo includes all repositories and
+// no reviewers includes everyone.
+func (c *Client) GetReviewerSummaries(ctx context.Context, repo string, reviewers []string, startDate, endDate time.Time) ([]ReviewerSummary, error) {
+	query := `SELECT reviewer, COUNT(*), COUNT(DISTINCT repo || '#' || pr_number), COUNT(DISTINCT repo), MAX(timestamp)
+			FROM reviews
+			WHERE timestamp >= ? AND timestamp <= ?`
+	args := []interface{}{startDate.Format("2006-01-02"), endDate.Format("2006-01-02 23:59:59")}
+	if repo != "" {
+		query += " AND repo = ?"
+		args = append(args, repo)
+	}
+	query, args = filterReviewers(query, args, reviewers)
+	query += " GROUP BY reviewer ORDER BY COUNT(*) DESC, reviewer"
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewer summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []ReviewerSummary
+	for rows.Next() {
+		var summary ReviewerSummary
+		var lastReview string
+		if err := rows.Scan(&summary.Reviewer, &summary.Reviews, &summary.PullRequests, &summary.Repos, &lastReview); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewer summary row: %w", err)
+		}
+		if summary.LastReview, err = parseTimestamp(lastReview); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviewer summary rows: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// filterReviewers adds a condition matching any of the reviewers, ignoring
+// case, to a query. No reviewers matches everyone.
+func filterReviewers(query string, args []interface{}, reviewers []string) (string, []interface{}) {
+	if len(reviewers) == 0 {
+		return query, args
+	}
+	placeholders := make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		placeholders = append(placeholders, "?")
+		args = append(args, strings.ToLower(reviewer))
+	}
+	return query + " AND LOWER(reviewer) IN (" + strings.Join(placeholders, ", ") + ")", args
+}
+
+// RepoCoverage summarizes the reviews logged in one repository within a date range
+type RepoCoverage struct {
+	Repo         string `json:"repo"`
+	Reviews      int    `json:"reviews"`
+	PullRequests int    `json:"pull_requests"`
+	Reviewers    int    `json:"reviewers"`
+}
+
+// GetRepoCoverage summarizes the reviews logged in each repository within the
+// date range, most reviewed first. An empty repo includes all repositories and
+// no reviewers includes everyone.
+func (c *Client) GetRepoCoverage(ctx context.Context, repo string, reviewers []string, startDate, endDate time.Time) ([]RepoCoverage, error) {
+	query := `SELECT repo, COUNT(*), COUNT(DISTINCT pr_number), COUNT(DISTINCT reviewer)
+			FROM reviews
+			WHERE timestamp >= ? AND timestamp <= ?`
+	args := []interface{}{startDate.Format("2006-01-02"), endDate.Format("2006-01-02 23:59:59")}
+	if repo != "" {
+		query += " AND repo = ?"
+		args = append(args, repo)
+	}
+	query, args = filterReviewers(query, args, reviewers)
+	query += " GROUP BY repo ORDER BY COUNT(*) DESC, repo"
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository coverage: %w", err)
+	}
+	defer rows.Close()
+
+	var coverage []RepoCoverage
+	for rows.Next() {
+		var repoCoverage RepoCoverage
+		if err := rows.Scan(&repoCoverage.Repo, &repoCoverage.Reviews, &repoCoverage.PullRequests, &repoCoverage.Reviewers); err != nil {
+			return nil, fmt.Errorf("failed to scan repository coverage row: %w", err)
+		}
+		coverage = append(coverage, repoCoverage)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating repository coverage rows: %w", err)
+	}
+
+	return coverage, nil
+}
+
+// GetReviewedPRNumbers returns the numbers of the pull requests in a repository
+// that have a logged review at any time. No reviewers includes everyone.
+func (c *Client) GetReviewedPRNumbers(ctx context.Context, repo string, reviewers []string) (map[int]bool, error) {
+	query, args := filterReviewers("SELECT DISTINCT pr_number FROM reviews WHERE repo = ?", []interface{}{repo}, reviewers)
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewed pull requests: %w", err)
+	}
+	defer rows.Close()
+
+	numbers := make(map[int]bool)
+	for rows.Next() {
+		var number int
+		if err := rows.Scan(&number); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewed pull request row: %w", err)
+		}
+		numbers[number] = true
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviewed pull request rows: %w", err)
+	}
+
+	return numbers, nil
+}
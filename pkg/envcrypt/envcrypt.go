@@ -0,0 +1,116 @@
+// Package envcrypt encrypts the contents of the ghi env file with a passphrase,
+// so tokens aren't stored as plaintext.
+package envcrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// header marks the first line of an encrypted file
+	header = "GHI-ENCRYPTED-V1"
+
+	saltSize   = 16
+	keySize    = 32
+	iterations = 600000
+)
+
+// ErrWrongPassphrase is returned when the file can't be decrypted with the passphrase
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted file")
+
+// IsEncrypted reports whether data was produced by Encrypt
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(header+"\n"))
+}
+
+// Encrypt encrypts plaintext with AES-256-GCM using a key derived from the
+// passphrase. The result is a header line followed by the base64 encoded salt,
+// nonce and ciphertext.
+func Encrypt(plaintext []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase is empty")
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := append(salt, nonce...)
+	sealed = gcm.Seal(sealed, nonce, plaintext, []byte(header))
+
+	var out bytes.Buffer
+	out.WriteString(header + "\n")
+	out.WriteString(base64.StdEncoding.EncodeToString(sealed))
+	out.WriteString("\n")
+	return out.Bytes(), nil
+}
+
+// Decrypt reverses Encrypt
+func Decrypt(data []byte, passphrase string) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return nil, fmt.Errorf("data is not encrypted")
+	}
+
+	encoded := bytes.TrimSpace(data[len(header)+1:])
+	sealed, err := base64.StdEncoding.DecodeString(string(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encrypted data: %w", err)
+	}
+	if len(sealed) < saltSize {
+		return nil, ErrWrongPassphrase
+	}
+
+	salt, rest := sealed[:saltSize], sealed[saltSize:]
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+
+	nonce, ciphertext := rest[:gcm.NonceSize()], rest[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte(header))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+// newGCM creates the AES-GCM cipher for a passphrase and salt
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(deriveKey([]byte(passphrase), salt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return gcm, nil
+}
+
+// deriveKey derives a key from the passphrase with PBKDF2-HMAC-SHA256
+func deriveKey(passphrase, salt []byte) []byte {
+	return pbkdf2.Key(passphrase, salt, iterations, keySize, sha256.New)
+}
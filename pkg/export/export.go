@@ -0,0 +1,118 @@
+// Package export writes rows of exported data in the formats every exporting
+// command accepts with --format, so they all support the same formats.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Formats data can be exported in
+const (
+	CSV   = "csv"
+	JSON  = "json"
+	TSV   = "tsv"
+	Plain = "plain"
+)
+
+// Formats are the formats data can be exported in
+var Formats = []string{CSV, JSON, TSV, Plain}
+
+// Validate returns an error when format isn't one of Formats
+func Validate(format string) error {
+	if !slices.Contains(Formats, format) {
+		return fmt.Errorf("invalid format %q, use one of %s", format, strings.Join(Formats, ", "))
+	}
+	return nil
+}
+
+// Extension returns the file extension of a format, txt for plain
+func Extension(format string) string {
+	if format == Plain {
+		return "txt"
+	}
+	return format
+}
+
+// Write writes the data in the given format: CSV or TSV with a header row,
+// plain columns for awk and cut, or value encoded as JSON. Rows must have a
+// field for each column of the header.
+func Write(w io.Writer, format string, header []string, rows [][]string, value interface{}) error {
+	switch format {
+	case CSV:
+		return writeCSV(w, header, rows)
+	case TSV:
+		return writeTSV(w, header, rows)
+	case Plain:
+		return writePlain(w, rows)
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(value)
+	default:
+		return Validate(format)
+	}
+}
+
+// writeCSV writes the rows as CSV with a header row
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeTSV writes the rows as tab-separated values with a header row. Tabs
+// and line breaks within a field are replaced by spaces, so every row is one
+// line with the same number of fields.
+func writeTSV(w io.Writer, header []string, rows [][]string) error {
+	if _, err := fmt.Fprintln(w, strings.Join(header, "\t")); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		fields := make([]string, len(row))
+		for i, field := range row {
+			fields[i] = flattenField(field)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writePlain writes the rows as columns padded to a fixed width and separated
+// by a space, without a header, borders or colors. Empty fields are written as
+// "-" so the fields after them stay in place for awk.
+func writePlain(w io.Writer, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	for _, row := range rows {
+		fields := make([]string, len(row))
+		for i, field := range row {
+			if fields[i] = flattenField(field); fields[i] == "" {
+				fields[i] = "-"
+			}
+		}
+		if _, err := fmt.Fprintln(tw, strings.Join(fields, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// flattenField replaces the tabs and line breaks in a field with spaces
+func flattenField(field string) string {
+	return strings.Join(strings.Fields(field), " ")
+}
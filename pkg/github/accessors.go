@@ -0,0 +1,121 @@
+package github
+
+import "time"
+
+// The accessors below never dereference a nil pointer, so display and
+// pipeline code can use them on pull requests with missing fields, or before
+// the pull request details are fetched. Missing values are zero, apart from
+// Author and State, which are "unknown".
+
+// Valid reports whether the pull request has an issue with a number, which
+// every pull request that can be shown has
+func (p *PullRequestData) Valid() bool {
+	return p != nil && p.Issue != nil && p.Issue.Number != nil
+}
+
+// Number returns the number of the pull request
+func (p *PullRequestData) Number() int {
+	if p == nil {
+		return 0
+	}
+	return p.Issue.GetNumber()
+}
+
+// Title returns the title of the pull request
+func (p *PullRequestData) Title() string {
+	if p == nil {
+		return ""
+	}
+	return p.Issue.GetTitle()
+}
+
+// Body returns the description of the pull request, from its details when
+// they were fetched
+func (p *PullRequestData) Body() string {
+	if p == nil {
+		return ""
+	}
+	if p.PullRequest != nil && p.PullRequest.Body != nil {
+		return p.PullRequest.GetBody()
+	}
+	return p.Issue.GetBody()
+}
+
+// Author returns the login of the pull request's author
+func (p *PullRequestData) Author() string {
+	if p == nil || p.Issue == nil {
+		return getUserLogin(nil)
+	}
+	return getUserLogin(p.Issue.User)
+}
+
+// AuthorAssociation returns the author's association with the repository,
+// such as MEMBER or CONTRIBUTOR
+func (p *PullRequestData) AuthorAssociation() string {
+	if p == nil {
+		return ""
+	}
+	return p.Issue.GetAuthorAssociation()
+}
+
+// State returns the state of the pull request, open or closed
+func (p *PullRequestData) State() string {
+	if p == nil {
+		return getState(nil)
+	}
+	return getState(p.Issue)
+}
+
+// URL returns the web address of the pull request
+func (p *PullRequestData) URL() string {
+	if p == nil {
+		return ""
+	}
+	return p.Issue.GetHTMLURL()
+}
+
+// CreatedAt returns when the pull request was opened
+func (p *PullRequestData) CreatedAt() time.Time {
+	if p == nil {
+		return time.Time{}
+	}
+	return p.Issue.GetCreatedAt().Time
+}
+
+// UpdatedAt returns when the pull request was last updated
+func (p *PullRequestData) UpdatedAt() time.Time {
+	if p == nil {
+		return time.Time{}
+	}
+	return p.Issue.GetUpdatedAt().Time
+}
+
+// HeadRef returns the branch the pull request merges from, once its details
+// are fetched
+func (p *PullRequestData) HeadRef() string {
+	if p == nil {
+		return ""
+	}
+	return p.PullRequest.GetHead().GetRef()
+}
+
+// BaseRef returns the branch the pull request merges into, once its details
+// are fetched
+func (p *PullRequestData) BaseRef() string {
+	if p == nil {
+		return ""
+	}
+	return p.PullRequest.GetBase().GetRef()
+}
+
+// LabelNames returns the names of the pull request's labels
+func (p *PullRequestData) LabelNames() []string {
+	if p == nil {
+		return nil
+	}
+	names := make([]string, 0, len(p.Labels))
+	for _, label := range p.Labels {
+		names = append(names, label.GetName())
+	}
+	return names
+}
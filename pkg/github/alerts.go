@@ -0,0 +1,165 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kinds of review latency alerts
+const (
+	AlertNoReview         = "no-review"
+	AlertApprovedUnmerged = "approved-unmerged"
+)
+
+// AlertThresholds are how long a PR can wait before an alert is sent. A zero
+// threshold sends no alerts of that kind.
+type AlertThresholds struct {
+	// NoReview is how long an open PR can go without a review from anyone
+	// but its author
+	NoReview time.Duration
+	// ApprovedUnmerged is how long an approved PR can stay open
+	ApprovedUnmerged time.Duration
+}
+
+// AlertPolicy holds the default thresholds and the overrides of repositories
+type AlertPolicy struct {
+	Default AlertThresholds
+	// Repos are keyed by lowercase owner/repo. Their zero thresholds fall
+	// back to the defaults.
+	Repos map[string]AlertThresholds
+}
+
+// Empty reports whether the policy can't send any alerts
+func (p AlertPolicy) Empty() bool {
+	if p.Default != (AlertThresholds{}) {
+		return false
+	}
+	for _, thresholds := range p.Repos {
+		if thresholds != (AlertThresholds{}) {
+			return false
+		}
+	}
+	return true
+}
+
+// For returns the thresholds of a repository
+func (p AlertPolicy) For(repo string) AlertThresholds {
+	thresholds := p.Default
+	override, ok := p.Repos[strings.ToLower(repo)]
+	if !ok {
+		return thresholds
+	}
+	if override.NoReview > 0 {
+		thresholds.NoReview = override.NoReview
+	}
+	if override.ApprovedUnmerged > 0 {
+		thresholds.ApprovedUnmerged = override.ApprovedUnmerged
+	}
+	return thresholds
+}
+
+// Alert is a PR that has waited longer than a threshold allows
+type Alert struct {
+	Kind string
+	PR   *PullRequestData
+	// Waiting is how long the PR has waited
+	Waiting time.Duration
+}
+
+// key identifies the alert so it's only sent once
+func (a Alert) key() string {
+	return fmt.Sprintf("%s#%d %s", strings.ToLower(a.PR.RepoFullName()), a.PR.Number(), a.Kind)
+}
+
+// String describes the alert, such as "owner/repo#12 Add retries has had no review for 2d 3h"
+func (a Alert) String() string {
+	waiting := FormatWaiting(a.Waiting)
+	switch a.Kind {
+	case AlertNoReview:
+		return fmt.Sprintf("%s#%d %s has had no review for %s", a.PR.RepoFullName(), a.PR.Number(), a.PR.Title(), waiting)
+	case AlertApprovedUnmerged:
+		return fmt.Sprintf("%s#%d %s has been approved but unmerged for %s", a.PR.RepoFullName(), a.PR.Number(), a.PR.Title(), waiting)
+	}
+	return fmt.Sprintf("%s#%d %s: %s", a.PR.RepoFullName(), a.PR.Number(), a.PR.Title(), a.Kind)
+}
+
+// FormatWaiting formats a duration in days and hours, such as "2d 3h" or "5h"
+func FormatWaiting(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	if days == 0 {
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dd %dh", days, hours)
+}
+
+// ApprovedAt returns when the latest approval of the PR was submitted, or the
+// zero time when it has none. It must run after EnrichWithReviews.
+func (p *PullRequestData) ApprovedAt() time.Time {
+	var approved time.Time
+	for _, review := range p.Reviews {
+		if review.GetState() == "APPROVED" && review.GetSubmittedAt().After(approved) {
+			approved = review.GetSubmittedAt().Time
+		}
+	}
+	return approved
+}
+
+// CheckAlerts returns the open PRs that have waited longer than the policy
+// allows. Drafts aren't waiting on reviewers, so they never alert. It must
+// run after EnrichWithReviews.
+func (p AlertPolicy) CheckAlerts(items []*PullRequestData) []Alert {
+	var alerts []Alert
+	for _, prData := range items {
+		if prData == nil || prData.Issue == nil || prData.State() != "open" || prData.IsDraft {
+			continue
+		}
+		thresholds := p.For(prData.RepoFullName())
+
+		if thresholds.NoReview > 0 && !hasOtherReviews(prData) && prData.Age() > thresholds.NoReview {
+			alerts = append(alerts, Alert{Kind: AlertNoReview, PR: prData, Waiting: prData.Age()})
+		}
+		if thresholds.ApprovedUnmerged > 0 && prData.ReviewDecision == ReviewDecisionApproved {
+			if approvedAt := prData.ApprovedAt(); !approvedAt.IsZero() {
+				if waiting := time.Since(approvedAt); waiting > thresholds.ApprovedUnmerged {
+					alerts = append(alerts, Alert{Kind: AlertApprovedUnmerged, PR: prData, Waiting: waiting})
+				}
+			}
+		}
+	}
+	return alerts
+}
+
+// AlertTracker remembers the alerts that were sent, so each is only sent once
+// while its PR stays past the threshold
+type AlertTracker struct {
+	mu   sync.Mutex
+	sent map[string]bool
+}
+
+// NewAlertTracker creates a tracker that hasn't sent any alerts
+func NewAlertTracker() *AlertTracker {
+	return &AlertTracker{sent: make(map[string]bool)}
+}
+
+// New returns the alerts that weren't returned by the previous call. Alerts
+// that are no longer current are forgotten, so they're sent again if the PR
+// crosses the threshold again.
+func (t *AlertTracker) New(alerts []Alert) []Alert {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := make(map[string]bool, len(alerts))
+	var fresh []Alert
+	for _, alert := range alerts {
+		key := alert.key()
+		current[key] = true
+		if !t.sent[key] {
+			fresh = append(fresh, alert)
+		}
+	}
+	t.sent = current
+	return fresh
+}
@@ -0,0 +1,217 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// splitThreshold is the size rank, L, from which a pull request is large
+// enough to be worth splitting
+const splitThreshold = 3
+
+// FileGroup sums up the changes to a group of files, such as a directory
+type FileGroup struct {
+	Name      string
+	Files     int
+	Additions int
+	Deletions int
+}
+
+// Lines returns the number of lines the group adds and deletes
+func (g FileGroup) Lines() int {
+	return g.Additions + g.Deletions
+}
+
+// add counts a changed file in the group
+func (g *FileGroup) add(file *github.CommitFile) {
+	g.Files++
+	g.Additions += file.GetAdditions()
+	g.Deletions += file.GetDeletions()
+}
+
+// PRAnalysis describes how the changes of a pull request are spread out
+type PRAnalysis struct {
+	PullRequest *github.PullRequest
+	Files       []*github.CommitFile
+	// Classifier decides the kind of each file
+	Classifier *FileClassifier
+	// Directories are the top-level directories touched, largest first.
+	// Files at the root of the repository are grouped as "/".
+	Directories []FileGroup
+	// Excluded sums up the binary, vendored and generated files, one group per
+	// kind with files. They're left out of the effective size.
+	Excluded []FileGroup
+	// SplitPoints are the groups of files that could each be their own pull
+	// request, empty when the pull request is small or focused
+	SplitPoints []FileGroup
+}
+
+// Lines returns the number of lines the pull request adds and deletes
+func (a *PRAnalysis) Lines() int {
+	return a.PullRequest.GetAdditions() + a.PullRequest.GetDeletions()
+}
+
+// ExcludedLines returns the number of lines changed in binary, vendored and
+// generated files
+func (a *PRAnalysis) ExcludedLines() int {
+	lines := 0
+	for _, group := range a.Excluded {
+		lines += group.Lines()
+	}
+	return lines
+}
+
+// EffectiveLines returns the number of lines changed outside binary, vendored
+// and generated files
+func (a *PRAnalysis) EffectiveLines() int {
+	return a.Lines() - a.ExcludedLines()
+}
+
+// ExcludedRatio returns the share of changed lines left out of the effective size
+func (a *PRAnalysis) ExcludedRatio() float64 {
+	if a.Lines() == 0 {
+		return 0
+	}
+	return float64(a.ExcludedLines()) / float64(a.Lines())
+}
+
+// AnalyzePR fetches a pull request and its changed files and analyzes them,
+// classifying files with the .gitattributes of the pull request's head
+func AnalyzePR(ctx context.Context, client *github.Client, owner, repo string, number int) (*PRAnalysis, error) {
+	pr, _, err := client.PullRequests.Get(ctx, owner, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching pull request #%d: %w", number, err)
+	}
+	files, err := ListPRFiles(ctx, client, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	classifier := LoadFileClassifier(ctx, client, owner, repo, pr.GetHead().GetSHA())
+	return analyzeFiles(pr, files, classifier), nil
+}
+
+// analyzeFiles groups the changed files by directory and kind and looks for
+// split points
+func analyzeFiles(pr *github.PullRequest, files []*github.CommitFile, classifier *FileClassifier) *PRAnalysis {
+	analysis := &PRAnalysis{PullRequest: pr, Files: files, Classifier: classifier}
+
+	directories := make(map[string]*FileGroup)
+	kinds := make(map[string]*FileGroup)
+	var source []*github.CommitFile
+	for _, file := range files {
+		dir := topLevelDir(file.GetFilename(), 1)
+		if directories[dir] == nil {
+			directories[dir] = &FileGroup{Name: dir}
+		}
+		directories[dir].add(file)
+
+		kind := classifier.Kind(file)
+		if kind == FileKindSource {
+			source = append(source, file)
+			continue
+		}
+		if kinds[kind] == nil {
+			kinds[kind] = &FileGroup{Name: kind + " files"}
+		}
+		kinds[kind].add(file)
+	}
+	analysis.Directories = sortedGroups(directories)
+	for _, kind := range []string{FileKindGenerated, FileKindVendored, FileKindBinary} {
+		if kinds[kind] != nil {
+			analysis.Excluded = append(analysis.Excluded, *kinds[kind])
+		}
+	}
+
+	// Excluded files can go in their own pull request when they make it large
+	if sizeRankOf(analysis.Lines()) >= splitThreshold {
+		analysis.SplitPoints = splitPoints(source)
+		if len(analysis.SplitPoints) > 0 || sizeRankOf(analysis.EffectiveLines()) < splitThreshold {
+			for _, group := range analysis.Excluded {
+				if group.Lines() > 0 {
+					analysis.SplitPoints = append(analysis.SplitPoints, group)
+				}
+			}
+		}
+	}
+	return analysis
+}
+
+// splitPoints groups source files into areas that could be reviewed on
+// their own: documentation, and each directory. When every other file is under
+// the same top-level directory, the directories below it are used instead. It
+// returns nothing when the files form a single area.
+func splitPoints(files []*github.CommitFile) []FileGroup {
+	depth := 2
+	first := ""
+	for _, file := range files {
+		if isDocFile(file.GetFilename()) {
+			continue
+		}
+		dir := topLevelDir(file.GetFilename(), 1)
+		if first == "" {
+			first = dir
+		} else if dir != first {
+			depth = 1
+			break
+		}
+	}
+
+	areas := make(map[string]*FileGroup)
+	for _, file := range files {
+		area := topLevelDir(file.GetFilename(), depth)
+		if isDocFile(file.GetFilename()) {
+			area = "documentation"
+		}
+		if areas[area] == nil {
+			areas[area] = &FileGroup{Name: area}
+		}
+		areas[area].add(file)
+	}
+
+	if len(areas) < 2 {
+		return nil
+	}
+	return sortedGroups(areas)
+}
+
+// topLevelDir returns the first depth directories of a file's path, or "/"
+// for files at the root of the repository
+func topLevelDir(file string, depth int) string {
+	parts := strings.Split(path.Dir(file), "/")
+	if parts[0] == "." {
+		return "/"
+	}
+	if len(parts) > depth {
+		parts = parts[:depth]
+	}
+	return strings.Join(parts, "/") + "/"
+}
+
+// isDocFile reports whether a file is documentation
+func isDocFile(file string) bool {
+	switch strings.ToLower(path.Ext(file)) {
+	case ".md", ".markdown", ".rst", ".adoc":
+		return true
+	}
+	return strings.HasPrefix(file, "docs/") || strings.HasPrefix(file, "doc/")
+}
+
+// sortedGroups returns the groups ordered by lines changed, largest first
+func sortedGroups(groups map[string]*FileGroup) []FileGroup {
+	sorted := make([]FileGroup, 0, len(groups))
+	for _, group := range groups {
+		sorted = append(sorted, *group)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Lines() != sorted[j].Lines() {
+			return sorted[i].Lines() > sorted[j].Lines()
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
@@ -0,0 +1,60 @@
+package github
+
+import (
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// WithAsOf shows the PRs as they were at a past time. Reviews submitted after
+// it are ignored, so approvals and review decisions are those of the time.
+func (c *PRCollection) WithAsOf(asOf time.Time) *PRCollection {
+	c.AsOf = asOf
+	return c
+}
+
+// FilterOpenAsOf keeps only the PRs that were open at the collection's AsOf
+// time, and shows them as open even if they've since been closed or merged
+func (c *PRCollection) FilterOpenAsOf() *PRCollection {
+	if c.AsOf.IsZero() {
+		return c
+	}
+
+	filtered := make([]*PullRequestData, 0, len(c.Items))
+	for _, prData := range c.Items {
+		if !WasOpenAt(prData.Issue, c.AsOf) {
+			continue
+		}
+		prData.Issue.State = github.Ptr("open")
+		prData.AsOf = c.AsOf
+		filtered = append(filtered, prData)
+	}
+
+	if c.Debug {
+		logger.Debug("Open as of %s filter kept %d of %d PRs", c.AsOf.Format(time.DateTime), len(filtered), len(c.Items))
+	}
+
+	c.Items = filtered
+	return c
+}
+
+// WasOpenAt reports whether an issue or PR had been opened and not yet closed
+// at the given time
+func WasOpenAt(issue *github.Issue, t time.Time) bool {
+	if issue.GetCreatedAt().After(t) {
+		return false
+	}
+	return issue.ClosedAt == nil || issue.GetClosedAt().After(t)
+}
+
+// reviewsBefore returns the reviews submitted before the given time
+func reviewsBefore(reviews []*github.PullRequestReview, t time.Time) []*github.PullRequestReview {
+	kept := make([]*github.PullRequestReview, 0, len(reviews))
+	for _, review := range reviews {
+		if !review.GetSubmittedAt().After(t) {
+			kept = append(kept, review)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,68 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// associationNames are the short names shown for the author associations
+// GitHub reports
+var associationNames = map[string]string{
+	"OWNER":                  "owner",
+	"MEMBER":                 "member",
+	"COLLABORATOR":           "collaborator",
+	"CONTRIBUTOR":            "contributor",
+	"FIRST_TIME_CONTRIBUTOR": "first-time",
+	"FIRST_TIMER":            "first-timer",
+	"MANNEQUIN":              "mannequin",
+	"NONE":                   "none",
+}
+
+// FormatAssociation returns the short name of an author association, such as
+// "first-time" for FIRST_TIME_CONTRIBUTOR
+func FormatAssociation(association string) string {
+	if name, ok := associationNames[association]; ok {
+		return name
+	}
+	return strings.ToLower(association)
+}
+
+// NormalizeAssociation converts an association given by the user, either as
+// GitHub names it or by its short name, to GitHub's name
+func NormalizeAssociation(name string) (string, error) {
+	association := strings.ToUpper(strings.NewReplacer("-", "_", " ", "_").Replace(strings.TrimSpace(name)))
+	if _, ok := associationNames[association]; ok {
+		return association, nil
+	}
+	for association, short := range associationNames {
+		if strings.EqualFold(short, name) {
+			return association, nil
+		}
+	}
+	return "", fmt.Errorf("unknown author association %q", name)
+}
+
+// FilterAssociations keeps only PRs whose author has one of the associations
+// with the repository, such as FIRST_TIME_CONTRIBUTOR. The association comes
+// with the issue, so this can run right after FetchIssues.
+func (c *PRCollection) FilterAssociations(associations []string) *PRCollection {
+	if len(associations) == 0 {
+		return c
+	}
+
+	filtered := make([]*PullRequestData, 0, len(c.Items))
+	for _, prData := range c.Items {
+		if contains(associations, prData.AuthorAssociation()) {
+			filtered = append(filtered, prData)
+		}
+	}
+
+	if c.Debug {
+		logger.Debug("Association filter kept %d of %d PRs (associations: %v)", len(filtered), len(c.Items), associations)
+	}
+
+	c.Items = filtered
+	return c
+}
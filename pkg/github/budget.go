@@ -0,0 +1,35 @@
+package github
+
+import "sync"
+
+// RequestBudget limits how many API requests a long job can make, so it
+// leaves part of the rate limit quota for other use. It's safe for
+// concurrent use.
+type RequestBudget struct {
+	mu        sync.Mutex
+	remaining int
+}
+
+// NewRequestBudget creates a budget of n requests
+func NewRequestBudget(n int) *RequestBudget {
+	return &RequestBudget{remaining: n}
+}
+
+// Take spends n requests, reporting false without spending any when fewer
+// than n are left
+func (b *RequestBudget) Take(n int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.remaining < n {
+		return false
+	}
+	b.remaining -= n
+	return true
+}
+
+// Remaining returns the requests left in the budget
+func (b *RequestBudget) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.remaining
+}
@@ -0,0 +1,83 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// BurndownPoint is the backlog of open pull requests at a point in time
+type BurndownPoint struct {
+	Time      time.Time
+	Open      int
+	MedianAge time.Duration
+}
+
+// SearchPRsOpenSince returns the pull requests of a repository that were open
+// at any time since the given time: those open now and those closed since.
+// Complete is false when the search limit of 1000 cut either list short.
+func SearchPRsOpenSince(ctx context.Context, client *github.Client, owner, repo string, since time.Time) ([]*github.Issue, bool, error) {
+	base := fmt.Sprintf("repo:%s/%s is:pr", owner, repo)
+	queries := []string{
+		base + " is:open",
+		base + " is:closed closed:>=" + since.Format(time.DateOnly),
+	}
+
+	var issues []*github.Issue
+	complete := true
+	for _, query := range queries {
+		results, total, err := SearchAllIssues(ctx, client, query)
+		if err != nil {
+			return nil, false, fmt.Errorf("error searching pull requests in %s/%s: %w", owner, repo, err)
+		}
+		issues = append(issues, results...)
+		complete = complete && len(results) >= total
+	}
+	return issues, complete, nil
+}
+
+// Burndown returns the number of open pull requests and their median age at
+// since and every week after it, ending with now
+func Burndown(issues []*github.Issue, since, now time.Time) []BurndownPoint {
+	var times []time.Time
+	for t := since; t.Before(now); t = t.AddDate(0, 0, 7) {
+		times = append(times, t)
+	}
+	times = append(times, now)
+
+	points := make([]BurndownPoint, 0, len(times))
+	for _, t := range times {
+		points = append(points, BacklogAt(issues, t))
+	}
+	return points
+}
+
+// BacklogAt returns the number of the pull requests that were open at t and
+// their median age
+func BacklogAt(issues []*github.Issue, t time.Time) BurndownPoint {
+	var ages []time.Duration
+	for _, issue := range issues {
+		if WasOpenAt(issue, t) {
+			ages = append(ages, t.Sub(issue.GetCreatedAt().Time))
+		}
+	}
+	return BurndownPoint{Time: t, Open: len(ages), MedianAge: medianDuration(ages)}
+}
+
+// medianDuration returns the median of the durations, or 0 when there are none
+func medianDuration(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,186 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ColumnOptions control how column values are formatted
+type ColumnOptions struct {
+	// SLA decides which PRs are shown as new or past the review SLA
+	SLA SLA
+	// ShowDraft prefixes the titles of draft PRs with DRAFT:
+	ShowDraft bool
+	// Color adds terminal colors to the columns that support them
+	Color bool
+}
+
+// Column describes a column of pull request listings. Both the plain table and
+// the interactive table are built from these definitions.
+type Column struct {
+	// Name is how the column is chosen with --columns
+	Name  string
+	Title string
+	// Width is the width of the column in the interactive table, and the
+	// length values are truncated to in the plain table
+	Width int
+	// Colored is set when Value adds colors with ColumnOptions.Color, so the
+	// value can't be truncated
+	Colored bool
+	Value   func(p *PullRequestData, opts ColumnOptions) string
+}
+
+// columnRegistry holds every column that can be chosen, in the default order
+var columnRegistry = []Column{
+	{Name: "repo", Title: "Repo", Width: 25, Value: func(p *PullRequestData, _ ColumnOptions) string {
+		return p.RepoFullName()
+	}},
+	{Name: "number", Title: "#", Width: 7, Colored: true, Value: formatPRNumber},
+	{Name: "age", Title: "Age", Width: 6, Value: func(p *PullRequestData, opts ColumnOptions) string {
+		return FormatAge(p, opts.SLA)
+	}},
+	{Name: "title", Title: "Title", Width: 40, Value: func(p *PullRequestData, opts ColumnOptions) string {
+		return formatTitle(p, opts.ShowDraft)
+	}},
+	{Name: "author", Title: "Author", Width: 15, Value: func(p *PullRequestData, _ ColumnOptions) string {
+		return p.Author()
+	}},
+	{Name: "association", Title: "Association", Width: 12, Value: func(p *PullRequestData, _ ColumnOptions) string {
+		return FormatAssociation(p.AuthorAssociation())
+	}},
+	{Name: "state", Title: "State", Width: 8, Value: func(p *PullRequestData, _ ColumnOptions) string {
+		return getState(p.Issue)
+	}},
+	{Name: "status", Title: "Status", Width: 17, Value: func(p *PullRequestData, _ ColumnOptions) string {
+		return p.ReviewDecision
+	}},
+	{Name: "merge", Title: "Merge", Width: 10, Value: func(p *PullRequestData, _ ColumnOptions) string {
+		return p.MergeStatus()
+	}},
+	{Name: "size", Title: "Size", Width: 5, Value: func(p *PullRequestData, _ ColumnOptions) string {
+		return p.Size()
+	}},
+	{Name: "labels", Title: "Labels", Width: 20, Colored: true, Value: func(p *PullRequestData, opts ColumnOptions) string {
+		if opts.Color {
+			return FormatLabels(p.Labels)
+		}
+		names := make([]string, 0, len(p.Labels))
+		for _, label := range p.Labels {
+			names = append(names, label.GetName())
+		}
+		return strings.Join(names, ", ")
+	}},
+	{Name: "reviews", Title: "Reviews", Width: 8, Value: func(p *PullRequestData, _ ColumnOptions) string {
+		return fmt.Sprintf("%d", len(p.Reviews))
+	}},
+	{Name: "draft", Title: "Draft", Width: 6, Value: func(p *PullRequestData, _ ColumnOptions) string {
+		if p.IsDraft {
+			return "[X]"
+		}
+		return "[ ]"
+	}},
+	{Name: "reviewer", Title: "Reviewer", Width: 9, Value: func(p *PullRequestData, _ ColumnOptions) string {
+		return p.ReviewerStatus
+	}},
+	{Name: "approvals", Title: "Approvals", Width: 10, Value: func(p *PullRequestData, _ ColumnOptions) string {
+		return p.ApprovalStatus()
+	}},
+	{Name: "signed", Title: "Signed", Width: 6, Value: func(p *PullRequestData, _ ColumnOptions) string {
+		return p.VerificationStatus()
+	}},
+	{Name: "blocked", Title: "Blocked By", Width: 15, Value: func(p *PullRequestData, _ ColumnOptions) string {
+		return p.BlockedBy()
+	}},
+}
+
+// ColumnNames returns the names of every column that can be chosen
+func ColumnNames() []string {
+	names := make([]string, 0, len(columnRegistry))
+	for _, column := range columnRegistry {
+		names = append(names, column.Name)
+	}
+	return names
+}
+
+// DefaultColumnNames returns the columns shown when none are chosen. The
+// draft, reviewer, signed, merge and blocked columns are only included when
+// their data is shown.
+func DefaultColumnNames(showDraft, showReviewer, showVerified, showMerge, showBlocked bool) []string {
+	var names []string
+	for _, column := range columnRegistry {
+		switch {
+		case column.Name == "draft" && !showDraft,
+			column.Name == "reviewer" && !showReviewer,
+			column.Name == "signed" && !showVerified,
+			column.Name == "merge" && !showMerge,
+			column.Name == "blocked" && !showBlocked:
+			continue
+		}
+		names = append(names, column.Name)
+	}
+	return names
+}
+
+// LookupColumns returns the columns with the given names in the given order,
+// ignoring case
+func LookupColumns(names []string) ([]Column, error) {
+	columns := make([]Column, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		found := false
+		for _, column := range columnRegistry {
+			if column.Name == name {
+				columns = append(columns, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %q. Valid columns are %s", name, strings.Join(ColumnNames(), ", "))
+		}
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns chosen")
+	}
+	return columns, nil
+}
+
+// ApplyWidths returns the columns with the widths set by column name. A width
+// of 0 leaves values untruncated: the plain table shows them in full and the
+// interactive table widens the column to fit them.
+func ApplyWidths(columns []Column, widths map[string]int) []Column {
+	resized := make([]Column, len(columns))
+	copy(resized, columns)
+	for i, column := range resized {
+		if width, ok := widths[column.Name]; ok {
+			resized[i].Width = width
+		}
+	}
+	return resized
+}
+
+// ValidateWidths checks that the widths name known columns and aren't negative
+func ValidateWidths(widths map[string]int) error {
+	for name, width := range widths {
+		if _, err := LookupColumns([]string{name}); err != nil {
+			return err
+		}
+		if width < 0 {
+			return fmt.Errorf("the width of column %q can't be negative", name)
+		}
+	}
+	return nil
+}
+
+// TruncateColumn shortens a value to fit the column, adding "..." when it's cut
+func TruncateColumn(value string, width int) string {
+	runes := []rune(value)
+	if width <= 3 || len(runes) <= width {
+		return value
+	}
+	return string(runes[:width-3]) + "..."
+}
@@ -0,0 +1,21 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// AddComment posts a comment on the conversation of a pull request or issue
+func AddComment(ctx context.Context, client *github.Client, owner, repo string, number int, body string) (*github.IssueComment, error) {
+	if body == "" {
+		return nil, fmt.Errorf("a comment body is required")
+	}
+
+	comment, _, err := client.Issues.CreateComment(ctx, owner, repo, number, &github.IssueComment{Body: github.Ptr(body)})
+	if err != nil {
+		return nil, fmt.Errorf("error posting comment on %s/%s #%d: %w", owner, repo, number, err)
+	}
+	return comment, nil
+}
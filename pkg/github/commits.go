@@ -0,0 +1,106 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// ListPRCommits returns the commits of a pull request, following pagination.
+// GitHub lists at most 250 commits per pull request.
+func ListPRCommits(ctx context.Context, client *github.Client, owner, repo string, number int) ([]*github.RepositoryCommit, error) {
+	opts := &github.ListOptions{PerPage: 100}
+
+	var all []*github.RepositoryCommit
+	for {
+		commits, resp, err := client.PullRequests.ListCommits(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error listing commits for #%d: %w", number, err)
+		}
+		all = append(all, commits...)
+
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return all, nil
+}
+
+// LatestCommitTime returns when the most recent commit of a pull request was
+// committed. Rebased or amended commits count from when they were rewritten.
+func LatestCommitTime(ctx context.Context, client *github.Client, owner, repo string, number int) (time.Time, error) {
+	commits, err := ListPRCommits(ctx, client, owner, repo, number)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var latest time.Time
+	for _, commit := range commits {
+		if committed := commit.GetCommit().GetCommitter().GetDate().Time; committed.After(latest) {
+			latest = committed
+		}
+	}
+	return latest, nil
+}
+
+// VerificationStatus returns ✓ when every commit of the PR has a verified
+// signature, ✗ when any doesn't, and nothing when the commits weren't checked
+func (p *PullRequestData) VerificationStatus() string {
+	switch {
+	case !p.VerificationChecked:
+		return ""
+	case p.UnverifiedCommits == 0:
+		return "✓"
+	default:
+		return "✗"
+	}
+}
+
+// EnrichWithVerification checks the signature of every commit of each PR,
+// counting the commits GitHub couldn't verify
+func (c *PRCollection) EnrichWithVerification() *PRCollection {
+	c.forEachItem("commit signatures", func(i int, prData *PullRequestData) {
+		commits, err := ListPRCommits(c.Context, c.Client, prData.Owner, prData.Repo, prData.Number())
+		if err != nil {
+			logger.Debug("Error listing commits for %s#%d: %v", prData.RepoFullName(), prData.Number(), err)
+			return
+		}
+
+		prData.UnverifiedCommits = 0
+		for _, commit := range commits {
+			if !commit.GetCommit().GetVerification().GetVerified() {
+				prData.UnverifiedCommits++
+			}
+		}
+		prData.VerificationChecked = true
+
+		if c.Debug {
+			logger.Debug("PR %s#%d has %d unverified commits of %d",
+				prData.RepoFullName(), prData.Number(), prData.UnverifiedCommits, len(commits))
+		}
+	})
+	return c
+}
+
+// FilterUnverified keeps only the PRs with a commit that isn't verified.
+// It must run after EnrichWithVerification.
+func (c *PRCollection) FilterUnverified() *PRCollection {
+	filtered := make([]*PullRequestData, 0, len(c.Items))
+	for _, prData := range c.Items {
+		if prData.VerificationChecked && prData.UnverifiedCommits > 0 {
+			filtered = append(filtered, prData)
+		}
+	}
+
+	if c.Debug {
+		logger.Debug("Unverified filter kept %d of %d PRs", len(filtered), len(c.Items))
+	}
+
+	c.Items = filtered
+	return c
+}
@@ -0,0 +1,174 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/export"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// Where the reviews in compliance evidence come from
+const (
+	ReviewSourceGitHub = "github"
+	ReviewSourceLog    = "log"
+)
+
+// ComplianceReview is a review of a merged pull request, submitted on GitHub
+// or logged in the review database
+type ComplianceReview struct {
+	Reviewer string `json:"reviewer"`
+	// Source is ReviewSourceGitHub or ReviewSourceLog
+	Source string `json:"source"`
+	// State is the GitHub review state, such as APPROVED, or the verdict of a
+	// logged review
+	State      string    `json:"state"`
+	ReviewedAt time.Time `json:"reviewed_at"`
+	URL        string    `json:"url"`
+}
+
+// ComplianceRecord is the review evidence of a merged pull request
+type ComplianceRecord struct {
+	Repo     string    `json:"repo"`
+	Number   int       `json:"number"`
+	Title    string    `json:"title"`
+	Author   string    `json:"author"`
+	URL      string    `json:"url"`
+	MergedAt time.Time `json:"merged_at"`
+	// Approved is set when someone other than the author approved it on GitHub
+	Approved bool               `json:"approved"`
+	Reviews  []ComplianceReview `json:"reviews"`
+}
+
+// AddReviews adds reviews to the record, keeping them in the order they were done
+func (r *ComplianceRecord) AddReviews(reviews ...ComplianceReview) {
+	r.Reviews = append(r.Reviews, reviews...)
+	sort.SliceStable(r.Reviews, func(i, j int) bool {
+		return r.Reviews[i].ReviewedAt.Before(r.Reviews[j].ReviewedAt)
+	})
+}
+
+// CollectCompliance finds the pull requests merged into a repository between
+// the start and end dates, both included, with the reviews submitted on
+// GitHub for each, fetching up to concurrency pull requests at once. The
+// records are in the order the pull requests were merged. Complete is false
+// when the search limit of 1000 cut the list short.
+func CollectCompliance(ctx context.Context, client *github.Client, repo string, start, end time.Time, concurrency int) ([]ComplianceRecord, bool, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok {
+		return nil, false, fmt.Errorf("invalid repository %q, use owner/repo", repo)
+	}
+
+	query := fmt.Sprintf("is:pr is:merged repo:%s merged:%s..%s", repo, start.Format(time.DateOnly), end.Format(time.DateOnly))
+	issues, total, err := SearchAllIssues(ctx, client, query)
+	if err != nil {
+		return nil, false, fmt.Errorf("error searching pull requests merged in %s: %w", repo, err)
+	}
+	logger.Debug("Found %d of %d pull requests merged in %s", len(issues), total, repo)
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	records := make([]ComplianceRecord, len(issues))
+	errs := make([]error, len(issues))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, issue := range issues {
+		wg.Add(1)
+		go func(i int, issue *github.Issue) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			record := ComplianceRecord{
+				Repo:     repo,
+				Number:   issue.GetNumber(),
+				Title:    issue.GetTitle(),
+				Author:   issue.GetUser().GetLogin(),
+				URL:      issue.GetHTMLURL(),
+				MergedAt: issue.GetPullRequestLinks().GetMergedAt().Time,
+			}
+			reviews, err := ListAllReviews(ctx, client, owner, name, record.Number)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", repo, err)
+				return
+			}
+			for _, review := range SubmittedReviews(reviews) {
+				reviewer := getReviewerLogin(review)
+				if review.GetState() == "APPROVED" && !strings.EqualFold(reviewer, record.Author) {
+					record.Approved = true
+				}
+				record.AddReviews(ComplianceReview{
+					Reviewer:   reviewer,
+					Source:     ReviewSourceGitHub,
+					State:      review.GetState(),
+					ReviewedAt: review.GetSubmittedAt().Time,
+					URL:        review.GetHTMLURL(),
+				})
+			}
+			records[i] = record
+		}(i, issue)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].MergedAt.Before(records[j].MergedAt)
+	})
+	return records, len(issues) >= total, nil
+}
+
+// complianceColumns are the header of compliance evidence in CSV or TSV
+var complianceColumns = []string{
+	"repo", "pr_number", "pr_title", "author", "pr_url", "merged_at", "approved",
+	"reviewer", "source", "state", "reviewed_at", "review_url",
+}
+
+// WriteCompliance writes the evidence in one of the export formats. JSON is an
+// array of pull requests, each with its reviews. The other formats have one
+// row per review, and pull requests merged without any review get a row with
+// empty review fields, so they stand out.
+func WriteCompliance(w io.Writer, format string, records []ComplianceRecord) error {
+	if records == nil {
+		records = []ComplianceRecord{}
+	}
+	var rows [][]string
+	for i, record := range records {
+		if record.Reviews == nil {
+			records[i].Reviews = []ComplianceReview{}
+		}
+		pr := []string{
+			record.Repo,
+			strconv.Itoa(record.Number),
+			record.Title,
+			record.Author,
+			record.URL,
+			record.MergedAt.UTC().Format(time.RFC3339),
+			strconv.FormatBool(record.Approved),
+		}
+		if len(record.Reviews) == 0 {
+			rows = append(rows, append(pr, "", "", "", "", ""))
+			continue
+		}
+		for _, review := range record.Reviews {
+			rows = append(rows, append(append([]string{}, pr...),
+				review.Reviewer,
+				review.Source,
+				review.State,
+				review.ReviewedAt.UTC().Format(time.RFC3339),
+				review.URL))
+		}
+	}
+	return export.Write(w, format, complianceColumns, rows, records)
+}
@@ -0,0 +1,117 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/locale"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// DefaultConflictComment is the comment posted to ask an author to resolve
+// merge conflicts when no template is configured
+const DefaultConflictComment = `@{{.Author}} this pull request has merge conflicts with ` + "`{{.Base}}`" + `. Could you rebase it or merge ` + "`{{.Base}}`" + ` into ` + "`{{.Head}}`" + `?`
+
+// ConflictNotice is the data the conflict comment template is executed with
+type ConflictNotice struct {
+	Repo   string
+	Number int
+	Title  string
+	Author string
+	Base   string
+	Head   string
+	URL    string
+	// Age is how long ago the pull request was opened, such as "3 days ago"
+	Age string
+}
+
+// CollectConflicts lists the open pull requests of the repositories and keeps
+// those with merge conflicts, oldest first
+func CollectConflicts(ctx context.Context, client *github.Client, repos []string, concurrency int, debug bool) ([]*PullRequestData, error) {
+	collection := NewPRCollection(ctx, client, debug).WithConcurrency(concurrency)
+	for _, repo := range repos {
+		owner, name, ok := strings.Cut(repo, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid repository %q, use owner/repo", repo)
+		}
+		issues, err := ListPullRequestIssues(ctx, client, owner, name, "open", nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", repo, err)
+		}
+		collection.FetchIssues(owner, name, issues)
+	}
+
+	// The list doesn't carry mergeability, each pull request is fetched for it
+	collection.EnrichWithPullRequests()
+	collection.EnrichWithMergeability()
+	collection.FilterConflicts()
+
+	sort.SliceStable(collection.Items, func(i, j int) bool {
+		return collection.Items[i].CreatedAt().Before(collection.Items[j].CreatedAt())
+	})
+	logger.Debug("Found %d pull requests with conflicts in %d repositories", len(collection.Items), len(repos))
+	return collection.Items, nil
+}
+
+// NewConflictNotice returns the template data for a pull request
+func NewConflictNotice(prData *PullRequestData) ConflictNotice {
+	notice := ConflictNotice{
+		Repo:   prData.RepoFullName(),
+		Number: prData.Number(),
+		Title:  prData.Title(),
+		Author: getPRAuthor(prData),
+		Age:    locale.TimeAgo(prData.CreatedAt()),
+	}
+	if pr := prData.PullRequest; pr != nil {
+		notice.Base = pr.GetBase().GetRef()
+		notice.Head = pr.GetHead().GetRef()
+		notice.URL = pr.GetHTMLURL()
+	}
+	return notice
+}
+
+// ParseConflictComment parses a conflict comment template, using
+// DefaultConflictComment when text is empty
+func ParseConflictComment(text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultConflictComment
+	}
+	tmpl, err := template.New("comment").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid comment template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderConflictComment executes the comment template for a pull request
+func RenderConflictComment(tmpl *template.Template, prData *PullRequestData) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, NewConflictNotice(prData)); err != nil {
+		return "", fmt.Errorf("error rendering the comment for %s#%d: %w", prData.RepoFullName(), prData.Number(), err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// UpdateBranch asks GitHub to merge the base branch into the head branch of a
+// pull request. GitHub accepts the request and updates the branch in the
+// background; pull requests whose conflicts need a person fail.
+func UpdateBranch(ctx context.Context, client *github.Client, prData *PullRequestData) error {
+	opts := &github.PullRequestBranchUpdateOptions{}
+	if prData.PullRequest != nil {
+		// Refuse to update a branch that changed since it was listed
+		opts.ExpectedHeadSHA = github.Ptr(prData.PullRequest.GetHead().GetSHA())
+	}
+
+	_, _, err := client.PullRequests.UpdateBranch(ctx, prData.Owner, prData.Repo, prData.Number(), opts)
+	var accepted *github.AcceptedError
+	if err != nil && !errors.As(err, &accepted) {
+		return fmt.Errorf("error updating the branch of %s#%d: %w", prData.RepoFullName(), prData.Number(), err)
+	}
+	logger.Debug("Requested a branch update for %s#%d", prData.RepoFullName(), prData.Number())
+	return nil
+}
@@ -0,0 +1,79 @@
+package github
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// signOffPattern matches a Signed-off-by trailer, capturing the name and email
+var signOffPattern = regexp.MustCompile(`(?mi)^\s*Signed-off-by:\s*(.*?)\s*<([^>]+)>\s*$`)
+
+// DCOProblem is a commit that doesn't pass the Developer Certificate of Origin check
+type DCOProblem struct {
+	Commit *github.RepositoryCommit
+	Reason string
+}
+
+// CheckDCO returns the commits without a Signed-off-by trailer from their
+// author. A sign-off matches when its email or name is the author's, ignoring
+// case. Merge commits are skipped, like the DCO GitHub app does.
+func CheckDCO(commits []*github.RepositoryCommit) []DCOProblem {
+	var problems []DCOProblem
+	for _, commit := range commits {
+		if len(commit.Parents) > 1 {
+			continue
+		}
+
+		author := commit.GetCommit().GetAuthor()
+		signOffs := signOffPattern.FindAllStringSubmatch(commit.GetCommit().GetMessage(), -1)
+		if len(signOffs) == 0 {
+			problems = append(problems, DCOProblem{Commit: commit, Reason: "missing Signed-off-by"})
+			continue
+		}
+
+		matched := false
+		for _, signOff := range signOffs {
+			if strings.EqualFold(signOff[2], author.GetEmail()) || strings.EqualFold(signOff[1], author.GetName()) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			problems = append(problems, DCOProblem{
+				Commit: commit,
+				Reason: fmt.Sprintf("signed off by %s <%s>, not the author", signOffs[0][1], signOffs[0][2]),
+			})
+		}
+	}
+	return problems
+}
+
+// DCORemediation is the comment posted on pull requests that fail the DCO check,
+// listing the commits to fix
+func DCORemediation(problems []DCOProblem, commitCount int) string {
+	var b strings.Builder
+	b.WriteString("Thanks for your contribution! This project requires every commit to be signed off under the ")
+	b.WriteString("[Developer Certificate of Origin](https://developercertificate.org/), ")
+	b.WriteString("by adding a `Signed-off-by` line that matches the commit author.\n\n")
+	b.WriteString("These commits need a sign-off:\n\n")
+	for _, problem := range problems {
+		sha := problem.Commit.GetSHA()
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		fmt.Fprintf(&b, "- %s %s (%s)\n", sha, firstLine(problem.Commit.GetCommit().GetMessage()), problem.Reason)
+	}
+	b.WriteString("\nTo sign off every commit of this pull request, run:\n\n")
+	fmt.Fprintf(&b, "```sh\ngit rebase HEAD~%d --signoff\ngit push --force-with-lease\n```\n\n", commitCount)
+	b.WriteString("Future commits can be signed off with `git commit -s`.\n")
+	return b.String()
+}
+
+// firstLine returns the first line of a commit message
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,197 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// dependencyPattern matches "depends on" and "blocked by" followed by one or
+// more references, such as "depends on #12, #14 and octo/lib#3"
+var dependencyPattern = regexp.MustCompile(`(?i)\b(?:depends\s+on|blocked\s+by)\s*:?\s+((?:[\w.-]+/[\w.-]+)?#\d+(?:\s*(?:,|and|&)\s*(?:[\w.-]+/[\w.-]+)?#\d+)*)`)
+
+// refPattern matches a single #123 or owner/repo#123 reference
+var refPattern = regexp.MustCompile(`(?:([\w.-]+)/([\w.-]+))?#(\d+)`)
+
+// PRRef identifies a pull request or issue, possibly in another repository
+type PRRef struct {
+	Owner  string
+	Repo   string
+	Number int
+}
+
+// String returns the reference as owner/repo#number
+func (r PRRef) String() string {
+	return fmt.Sprintf("%s/%s#%d", r.Owner, r.Repo, r.Number)
+}
+
+// Dependency is a pull request or issue another pull request depends on
+type Dependency struct {
+	Ref   PRRef
+	Title string
+	// State is "open", "closed" or "merged"
+	State string
+}
+
+// Open reports whether the dependency still blocks the pull request depending on it
+func (d *Dependency) Open() bool {
+	return d.State == "open"
+}
+
+// ParseDependencies returns the references that follow "depends on" or
+// "blocked by" in a pull request body, without duplicates. References without
+// a repository, like #123, are in the given owner/repo.
+func ParseDependencies(owner, repo, body string) []PRRef {
+	var refs []PRRef
+	seen := make(map[string]bool)
+	for _, match := range dependencyPattern.FindAllStringSubmatch(body, -1) {
+		for _, m := range refPattern.FindAllStringSubmatch(match[1], -1) {
+			ref := PRRef{Owner: owner, Repo: repo}
+			if m[1] != "" {
+				ref.Owner, ref.Repo = m[1], m[2]
+			}
+			ref.Number, _ = strconv.Atoi(m[3])
+
+			key := strings.ToLower(ref.String())
+			if !seen[key] {
+				seen[key] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
+// FetchDependency looks up the title and state of a referenced pull request or issue
+func FetchDependency(ctx context.Context, client *github.Client, ref PRRef) (*Dependency, error) {
+	dep, _, err := fetchDependencyIssue(ctx, client, ref)
+	return dep, err
+}
+
+// fetchDependencyIssue returns the dependency and the issue it was read from
+func fetchDependencyIssue(ctx context.Context, client *github.Client, ref PRRef) (*Dependency, *github.Issue, error) {
+	issue, _, err := client.Issues.Get(ctx, ref.Owner, ref.Repo, ref.Number)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching %s: %w", ref, err)
+	}
+
+	dep := &Dependency{Ref: ref, Title: issue.GetTitle(), State: issue.GetState()}
+	if issue.PullRequestLinks != nil && issue.PullRequestLinks.MergedAt != nil {
+		dep.State = "merged"
+	}
+	return dep, issue, nil
+}
+
+// EnrichWithDependencies looks up the dependencies referenced in each PR's body
+// and marks the PR blocked while any of them is still open
+func (c *PRCollection) EnrichWithDependencies() *PRCollection {
+	c.forEachItem("dependencies", func(i int, prData *PullRequestData) {
+		if prData.Issue == nil {
+			return
+		}
+
+		for _, ref := range ParseDependencies(prData.Owner, prData.Repo, prData.Body()) {
+			dep, err := FetchDependency(c.Context, c.Client, ref)
+			if err != nil {
+				// Keep unresolved references visible rather than dropping them
+				logger.Debug("Could not resolve dependency of %s#%d: %v", prData.RepoFullName(), prData.Number(), err)
+				dep = &Dependency{Ref: ref, State: "unknown"}
+			}
+			prData.Dependencies = append(prData.Dependencies, dep)
+			if dep.Open() {
+				prData.Blocked = true
+			}
+		}
+	})
+	return c
+}
+
+// BlockedBy lists the dependencies that are still open, as #123 for those in
+// the PR's own repository and owner/repo#45 for the others. It's empty until
+// EnrichWithDependencies has run.
+func (p *PullRequestData) BlockedBy() string {
+	var refs []string
+	for _, dep := range p.Dependencies {
+		if !dep.Open() {
+			continue
+		}
+		if strings.EqualFold(dep.Ref.Owner, p.Owner) && strings.EqualFold(dep.Ref.Repo, p.Repo) {
+			refs = append(refs, fmt.Sprintf("#%d", dep.Ref.Number))
+		} else {
+			refs = append(refs, dep.Ref.String())
+		}
+	}
+	return strings.Join(refs, ", ")
+}
+
+// FilterBlocked keeps only the PRs with a dependency that is still open. It
+// must run after EnrichWithDependencies.
+func (c *PRCollection) FilterBlocked() *PRCollection {
+	filtered := make([]*PullRequestData, 0, len(c.Items))
+	for _, prData := range c.Items {
+		if prData.Blocked {
+			filtered = append(filtered, prData)
+		}
+	}
+
+	if c.Debug {
+		logger.Debug("Blocked filter kept %d of %d PRs", len(filtered), len(c.Items))
+	}
+
+	c.Items = filtered
+	return c
+}
+
+// DependencyNode is a pull request or issue in a dependency tree
+type DependencyNode struct {
+	Dependency
+	Dependencies []*DependencyNode
+	// Cycle is set when the node already appears higher up the tree, in
+	// which case its dependencies aren't followed again
+	Cycle bool
+	Err   error
+}
+
+// FetchDependencyTree follows the dependencies of a pull request up to
+// maxDepth levels deep
+func FetchDependencyTree(ctx context.Context, client *github.Client, ref PRRef, maxDepth int) (*DependencyNode, error) {
+	return fetchDependencyNode(ctx, client, ref, maxDepth, map[string]bool{})
+}
+
+// fetchDependencyNode fetches one node of a dependency tree. Path holds the
+// references above the node, to detect cycles.
+func fetchDependencyNode(ctx context.Context, client *github.Client, ref PRRef, depth int, path map[string]bool) (*DependencyNode, error) {
+	dep, issue, err := fetchDependencyIssue(ctx, client, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &DependencyNode{Dependency: *dep}
+	if depth <= 0 {
+		return node, nil
+	}
+
+	key := strings.ToLower(ref.String())
+	path[key] = true
+	defer delete(path, key)
+
+	for _, child := range ParseDependencies(ref.Owner, ref.Repo, issue.GetBody()) {
+		if path[strings.ToLower(child.String())] {
+			node.Dependencies = append(node.Dependencies, &DependencyNode{Dependency: Dependency{Ref: child}, Cycle: true})
+			continue
+		}
+
+		childNode, err := fetchDependencyNode(ctx, client, child, depth-1, path)
+		if err != nil {
+			logger.Debug("Could not resolve dependency %s of %s: %v", child, ref, err)
+			childNode = &DependencyNode{Dependency: Dependency{Ref: child, State: "unknown"}, Err: err}
+		}
+		node.Dependencies = append(node.Dependencies, childNode)
+	}
+	return node, nil
+}
@@ -0,0 +1,50 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// PRDetail holds the data shown in the detail view of a single pull request
+type PRDetail struct {
+	PullRequest      *github.PullRequest
+	Reviews          []*github.PullRequestReview
+	Approvals        int
+	ChangesRequested []string
+	Checks           []*github.CheckRun
+	// ChecksErr is set when the check runs couldn't be listed, which doesn't
+	// prevent showing the rest of the detail
+	ChecksErr error
+}
+
+// FetchPRDetail fetches a pull request with its reviews and the check runs on
+// its head commit
+func FetchPRDetail(ctx context.Context, client *github.Client, owner, repo string, number int) (*PRDetail, error) {
+	pr, _, err := client.PullRequests.Get(ctx, owner, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching pull request #%d: %w", number, err)
+	}
+
+	reviews, err := ListAllReviews(ctx, client, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &PRDetail{PullRequest: pr, Reviews: reviews}
+	detail.Approvals, detail.ChangesRequested = reviewVerdicts(reviews)
+
+	opts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	checks, _, err := client.Checks.ListCheckRunsForRef(ctx, owner, repo, pr.GetHead().GetSHA(), opts)
+	if err != nil {
+		logger.Debug("Failed to list check runs for %s/%s #%d: %v", owner, repo, number, err)
+		detail.ChecksErr = fmt.Errorf("error listing check runs: %w", err)
+	} else {
+		detail.Checks = checks.CheckRuns
+	}
+
+	logger.Debug("Fetched detail for %s/%s #%d: %d reviews, %d checks", owner, repo, number, len(reviews), len(detail.Checks))
+	return detail, nil
+}
@@ -3,10 +3,13 @@ package github
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"time"
+	"strings"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/colors"
 	"github.com/jbrinkman/ghi/pkg/logger"
 	"github.com/jedib0t/go-pretty/v6/table"
 )
@@ -15,7 +18,18 @@ import (
 type DisplayOptions struct {
 	ShowDraft    bool
 	ShowReviewer bool
+	ShowVerified bool
+	ShowMerge    bool
+	ShowBlocked  bool
 	Debug        bool
+	// Color adds terminal colors to the columns that support them
+	Color bool
+	// SLA decides which PR numbers are colored as stale or new
+	SLA SLA
+	// Columns are the names of the columns to show, the defaults when empty
+	Columns []string
+	// Widths override the widths of columns by name, 0 for no truncation
+	Widths map[string]int
 }
 
 // PRDisplay handles the display of pull request data
@@ -32,6 +46,8 @@ func NewPRDisplay(collection *PRCollection) *PRDisplay {
 			ShowDraft:    true, // Always show draft status
 			ShowReviewer: false,
 			Debug:        collection.Debug,
+			SLA:          DefaultSLA,
+			Color:        true,
 		},
 	}
 }
@@ -42,14 +58,54 @@ func (d *PRDisplay) WithReviewers(hasReviewers bool) *PRDisplay {
 	return d
 }
 
+// WithSLA sets the ages at which PR numbers are colored as stale or new
+func (d *PRDisplay) WithSLA(sla SLA) *PRDisplay {
+	d.Options.SLA = sla
+	return d
+}
+
+// WithColumns sets the columns to show, by name
+func (d *PRDisplay) WithColumns(names []string) *PRDisplay {
+	d.Options.Columns = names
+	return d
+}
+
+// WithWidths sets the widths values are truncated to, by column name
+func (d *PRDisplay) WithWidths(widths map[string]int) *PRDisplay {
+	d.Options.Widths = widths
+	return d
+}
+
+// WithColor configures whether the numbers of PRs are colored by age
+func (d *PRDisplay) WithColor(color bool) *PRDisplay {
+	d.Options.Color = color
+	return d
+}
+
+// WithVerification configures the display to show whether commits are verified
+func (d *PRDisplay) WithVerification(checked bool) *PRDisplay {
+	d.Options.ShowVerified = checked
+	return d
+}
+
+// WithMergeability configures the display to show whether PRs can be merged
+func (d *PRDisplay) WithMergeability(shown bool) *PRDisplay {
+	d.Options.ShowMerge = shown
+	return d
+}
+
+// WithDependencies configures the display to show the open dependencies of PRs
+func (d *PRDisplay) WithDependencies(shown bool) *PRDisplay {
+	d.Options.ShowBlocked = shown
+	return d
+}
+
 // RenderTable displays the PR collection as a formatted table
 func (d *PRDisplay) RenderTable() {
 	items := d.Collection.Items
-	owner := d.Collection.Owner
-	repo := d.Collection.Repo
 
 	fmt.Println("=====================================")
-	fmt.Printf("Pull requests for %s/%s\n", owner, repo)
+	fmt.Printf("Pull requests for %s\n", strings.Join(d.Collection.Repositories(), ", "))
 	fmt.Printf("Count: %d\n", len(items))
 	fmt.Println("=====================================")
 	fmt.Println()
@@ -61,12 +117,30 @@ func (d *PRDisplay) RenderTable() {
 	t.Style().Options.DrawBorder = true
 	t.Style().Options.SeparateRows = false
 
+	columns := d.resolvedColumns()
+	opts := ColumnOptions{SLA: d.Options.SLA, ShowDraft: d.Options.ShowDraft, Color: d.Options.Color}
+
 	// Set up headers
-	d.appendTableHeader(t)
+	header := make(table.Row, 0, len(columns))
+	for _, column := range columns {
+		header = append(header, strings.ToUpper(column.Title))
+	}
+	t.AppendHeader(header)
 
 	// Add table rows
 	for _, prData := range items {
-		d.appendTableRow(t, prData)
+		if !prData.Valid() {
+			continue
+		}
+		row := make(table.Row, 0, len(columns))
+		for _, column := range columns {
+			value := column.Value(prData, opts)
+			if !column.Colored {
+				value = TruncateColumn(value, column.Width)
+			}
+			row = append(row, value)
+		}
+		t.AppendRow(row)
 	}
 
 	t.Render()
@@ -76,127 +150,158 @@ func (d *PRDisplay) RenderTable() {
 	}
 }
 
-// appendTableHeader adds the appropriate header row to the table based on options
-func (d *PRDisplay) appendTableHeader(t table.Writer) {
-	var header table.Row
+// RenderTSV writes the PR collection as tab-separated values for scripts: a
+// header of column names, as given to --columns, then a row per PR. Values
+// aren't truncated or colored, and tabs and newlines in them become spaces.
+func (d *PRDisplay) RenderTSV(w io.Writer) error {
+	columns := d.resolvedColumns()
+	opts := ColumnOptions{SLA: d.Options.SLA, ShowDraft: d.Options.ShowDraft}
 
-	// Always show these base columns
-	header = append(header, "NUMBER", "TITLE", "AUTHOR", "STATE", "REVIEWS")
-
-	// Show draft status column only when we're showing drafts
-	if d.Collection.DraftOption == "show" {
-		header = append(header, "DRAFT")
+	names := make([]string, 0, len(columns))
+	for _, column := range columns {
+		names = append(names, column.Name)
 	}
-
-	// Show reviewer status when reviewers are specified
-	if d.Options.ShowReviewer {
-		header = append(header, "REVIEWER")
+	if _, err := fmt.Fprintln(w, strings.Join(names, "\t")); err != nil {
+		return err
 	}
 
-	// Always show approvals
-	header = append(header, "APPROVALS")
-
-	t.AppendHeader(header)
+	for _, prData := range d.Collection.Items {
+		if !prData.Valid() {
+			continue
+		}
+		values := make([]string, 0, len(columns))
+		for _, column := range columns {
+			values = append(values, tsvEscaper.Replace(column.Value(prData, opts)))
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(values, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// appendTableRow formats and adds a PR data row to the table
-func (d *PRDisplay) appendTableRow(t table.Writer, prData *PullRequestData) {
-	if prData == nil || prData.Issue == nil {
-		return
-	}
+// tsvEscaper replaces the characters that would break a tab-separated row
+var tsvEscaper = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
 
-	// Always included columns
-	row := table.Row{
-		formatPRNumber(prData),
-		formatTitle(prData, d.Options.ShowDraft),
-		getUserLogin(prData.Issue.User),
-		getState(prData.Issue),
-		len(prData.Reviews), // Show total review count
-	}
-
-	// Show draft status column only when we're showing drafts
-	if d.Collection.DraftOption == "show" {
-		draftStatus := "[ ]"
-		if prData.IsDraft {
-			draftStatus = "[X]"
-		}
-		row = append(row, draftStatus)
+// resolvedColumns returns the columns to show, falling back to the defaults
+// with a warning when the chosen ones are invalid
+func (d *PRDisplay) resolvedColumns() []Column {
+	columns, err := d.columns()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v, showing the default columns\n", err)
+		columns, _ = LookupColumns(d.defaultColumns())
 	}
+	return ApplyWidths(columns, d.Options.Widths)
+}
 
-	// Show reviewer status when reviewers are specified
-	if d.Options.ShowReviewer {
-		row = append(row, prData.ReviewerStatus)
+// columns returns the chosen columns, or the defaults for the display options
+func (d *PRDisplay) columns() ([]Column, error) {
+	if len(d.Options.Columns) > 0 {
+		return LookupColumns(d.Options.Columns)
 	}
+	return LookupColumns(d.defaultColumns())
+}
 
-	// Always show approvals
-	row = append(row, prData.ApprovalCount)
-
-	t.AppendRow(row)
+// defaultColumns returns the default column names, showing the draft, reviewer,
+// signed, merge and blocked columns when drafts, reviewers, verification,
+// mergeability and dependencies are shown
+func (d *PRDisplay) defaultColumns() []string {
+	return DefaultColumnNames(d.Collection.DraftOption == "show", d.Options.ShowReviewer, d.Options.ShowVerified,
+		d.Options.ShowMerge, d.Options.ShowBlocked)
 }
 
 // Helper functions for formatting
 
-func formatPRNumber(prData *PullRequestData) string {
-	if prData.Issue == nil || prData.Issue.Number == nil {
+func formatPRNumber(prData *PullRequestData, opts ColumnOptions) string {
+	if !prData.Valid() {
 		return "N/A"
 	}
 
-	prNumber := fmt.Sprintf("%d", *prData.Issue.Number)
-	createdAt := prData.Issue.CreatedAt
-
-	if createdAt == nil {
+	prNumber := fmt.Sprintf("#%d", prData.Number())
+	if !opts.Color || prData.CreatedAt().IsZero() {
 		return prNumber
 	}
 
-	timestamp := createdAt.GetTime()
-	if timestamp == nil {
-		return prNumber
-	}
-
-	daysOld := time.Since(*timestamp).Hours() / 24
-
-	// Color priority: age over 30 days is always red, then drafts are gray, new PRs are green
-	if daysOld > 30 {
-		return fmt.Sprintf("\033[31m%d\033[0m", *prData.Issue.Number) // Red for old PRs
+	// Color priority: PRs past the SLA are always red, then drafts are gray, new PRs are green
+	if opts.SLA.IsStale(prData) {
+		return colors.Paint("red", prNumber) // Red for old PRs
 	} else if prData.IsDraft {
-		return fmt.Sprintf("\033[90m%d\033[0m", *prData.Issue.Number) // Mid-gray for drafts
-	} else if daysOld <= 1 {
-		return fmt.Sprintf("\033[32m%d\033[0m", *prData.Issue.Number) // Green for new PRs
+		return colors.Paint("gray", prNumber) // Mid-gray for drafts
+	} else if opts.SLA.IsFresh(prData) {
+		return colors.Paint("green", prNumber) // Green for new PRs
 	}
 
 	return prNumber
 }
 
 func formatTitle(prData *PullRequestData, showDraft bool) string {
-	if prData.Issue == nil || prData.Issue.Title == nil {
+	if prData == nil || prData.Issue == nil || prData.Issue.Title == nil {
 		return "N/A"
 	}
 
-	title := *prData.Issue.Title
+	title := prData.Title()
 
 	// Add DRAFT: prefix to title if it's a draft PR and we're showing drafts
 	if prData.IsDraft && showDraft {
 		title = "DRAFT: " + title
 	}
 
-	// Truncate long titles
-	if len(title) > 25 {
-		title = title[:22] + "..."
+	// Add BLOCKED: prefix while a dependency is still open
+	if prData.Blocked {
+		title = "BLOCKED: " + title
+	}
+
+	// Flag PRs that change the paths being watched
+	if prData.TouchesPaths {
+		title = "★ " + title
+	}
+
+	// Point stacked PRs at the PR they're stacked on
+	if prData.StackedOn != 0 {
+		title = fmt.Sprintf("↳#%d %s", prData.StackedOn, title)
 	}
 
 	return title
 }
 
 func getUserLogin(user *github.User) string {
-	if user != nil && user.Login != nil {
-		return *user.Login
+	if login := user.GetLogin(); login != "" {
+		return login
 	}
 	return "unknown"
 }
 
 func getState(issue *github.Issue) string {
-	if issue != nil && issue.State != nil {
-		return *issue.State
+	if state := issue.GetState(); state != "" {
+		return state
 	}
 	return "unknown"
 }
+
+// FormatLabels renders label names separated by spaces, each in its GitHub color
+func FormatLabels(labels []*github.Label) string {
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, RenderLabel(label))
+	}
+	return strings.Join(names, " ")
+}
+
+// RenderLabel renders a label name on its GitHub background color, with black
+// or white text depending on how light the color is
+func RenderLabel(label *github.Label) string {
+	hex := strings.TrimPrefix(label.GetColor(), "#")
+	var r, g, b int
+	if _, err := fmt.Sscanf(hex, "%02x%02x%02x", &r, &g, &b); err != nil || len(hex) != 6 {
+		return label.GetName()
+	}
+
+	foreground := "#ffffff"
+	if 0.299*float64(r)+0.587*float64(g)+0.114*float64(b) > 150 {
+		foreground = "#000000"
+	}
+	return lipgloss.NewStyle().
+		Background(lipgloss.Color("#" + hex)).
+		Foreground(lipgloss.Color(foreground)).
+		Render(" " + label.GetName() + " ")
+}
@@ -0,0 +1,99 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// PRRecord is a pull request as a flat record for exporting
+type PRRecord struct {
+	Repo      string     `json:"repo"`
+	Number    int        `json:"number"`
+	Title     string     `json:"title"`
+	Author    string     `json:"author"`
+	State     string     `json:"state"`
+	Draft     bool       `json:"draft"`
+	Merged    bool       `json:"merged"`
+	Labels    []string   `json:"labels"`
+	URL       string     `json:"url"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+	MergedAt  *time.Time `json:"merged_at"`
+}
+
+// NewPRRecord creates the record of a pull request found by search
+func NewPRRecord(repo string, issue *github.Issue) PRRecord {
+	labels := make([]string, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		labels = append(labels, label.GetName())
+	}
+
+	record := PRRecord{
+		Repo:      repo,
+		Number:    issue.GetNumber(),
+		Title:     issue.GetTitle(),
+		Author:    issue.GetUser().GetLogin(),
+		State:     issue.GetState(),
+		Draft:     issue.GetDraft(),
+		Labels:    labels,
+		URL:       issue.GetHTMLURL(),
+		CreatedAt: issue.GetCreatedAt().Time,
+		UpdatedAt: issue.GetUpdatedAt().Time,
+	}
+	if issue.ClosedAt != nil {
+		record.ClosedAt = &issue.ClosedAt.Time
+	}
+	if mergedAt := issue.GetPullRequestLinks().GetMergedAt(); !mergedAt.IsZero() {
+		record.Merged = true
+		record.MergedAt = &mergedAt.Time
+	}
+	return record
+}
+
+// SnapshotRecord is the open pull request backlog of a repository at a point
+// in time, as a flat record for exporting
+type SnapshotRecord struct {
+	Repo           string    `json:"repo"`
+	Time           time.Time `json:"time"`
+	Open           int       `json:"open"`
+	MedianAgeHours float64   `json:"median_age_hours"`
+}
+
+// NewSnapshotRecord creates the record of a point of a repository's backlog
+func NewSnapshotRecord(repo string, point BurndownPoint) SnapshotRecord {
+	return SnapshotRecord{
+		Repo:           repo,
+		Time:           point.Time,
+		Open:           point.Open,
+		MedianAgeHours: point.MedianAge.Hours(),
+	}
+}
+
+// SearchPRsUpdatedSince returns the pull requests of a repository updated
+// after the given time, least recently updated first, so an export cut short
+// by the search limit of 1000 can resume from the last one. A zero time
+// returns all pull requests. Complete is false when the limit was reached.
+func SearchPRsUpdatedSince(ctx context.Context, client *github.Client, owner, repo string, since time.Time) ([]*github.Issue, bool, error) {
+	query := fmt.Sprintf("repo:%s/%s is:pr", owner, repo)
+	if !since.IsZero() {
+		query += " updated:>=" + since.UTC().Format(time.RFC3339)
+	}
+
+	results, total, err := searchAllIssues(ctx, client, query, "updated", "asc")
+	if err != nil {
+		return nil, false, fmt.Errorf("error searching pull requests in %s/%s: %w", owner, repo, err)
+	}
+
+	// The search qualifier includes the given time, the export shouldn't
+	var issues []*github.Issue
+	for _, issue := range results {
+		if issue.GetUpdatedAt().After(since) {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, len(results) >= total, nil
+}
@@ -0,0 +1,174 @@
+package github
+
+import (
+	"bufio"
+	"context"
+	"path"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// Kinds of changed files. Files of every kind but FileKindSource are
+// collapsed in file listings and left out of the effective size.
+const (
+	FileKindSource    = "source"
+	FileKindBinary    = "binary"
+	FileKindVendored  = "vendored"
+	FileKindGenerated = "generated"
+)
+
+// binaryExtensions are the extensions of files that are treated as binary
+var binaryExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".ico": true, ".webp": true, ".bmp": true,
+	".pdf": true, ".zip": true, ".gz": true, ".tgz": true, ".jar": true, ".war": true,
+	".exe": true, ".dll": true, ".so": true, ".dylib": true, ".a": true, ".wasm": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".eot": true,
+	".mp3": true, ".mp4": true, ".mov": true, ".webm": true,
+}
+
+// vendoredDirs are directories that usually hold third-party code
+var vendoredDirs = []string{"vendor", "node_modules", "third_party", "third-party", "bower_components"}
+
+// attributeRule is a .gitattributes line setting linguist attributes
+type attributeRule struct {
+	pattern   string
+	generated *bool
+	vendored  *bool
+}
+
+// FileClassifier decides the kind of changed files, honoring the
+// linguist-generated and linguist-vendored attributes of a repository
+type FileClassifier struct {
+	rules []attributeRule
+}
+
+// LoadFileClassifier reads the .gitattributes of a repository at the given ref.
+// Without one, or when it can't be read, files are classified by name only.
+func LoadFileClassifier(ctx context.Context, client *github.Client, owner, repo, ref string) *FileClassifier {
+	opts := &github.RepositoryContentGetOptions{Ref: ref}
+	file, _, _, err := client.Repositories.GetContents(ctx, owner, repo, ".gitattributes", opts)
+	if err != nil || file == nil {
+		logger.Debug("No .gitattributes read from %s/%s@%s: %v", owner, repo, ref, err)
+		return &FileClassifier{}
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		logger.Debug("Could not decode .gitattributes of %s/%s: %v", owner, repo, err)
+		return &FileClassifier{}
+	}
+	return ParseGitattributes(content)
+}
+
+// ParseGitattributes builds a classifier from the content of a .gitattributes file
+func ParseGitattributes(content string) *FileClassifier {
+	classifier := &FileClassifier{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		rule := attributeRule{pattern: fields[0]}
+		for _, attr := range fields[1:] {
+			switch attr {
+			case "linguist-generated", "linguist-generated=true":
+				rule.generated = github.Ptr(true)
+			case "-linguist-generated", "linguist-generated=false":
+				rule.generated = github.Ptr(false)
+			case "linguist-vendored", "linguist-vendored=true":
+				rule.vendored = github.Ptr(true)
+			case "-linguist-vendored", "linguist-vendored=false":
+				rule.vendored = github.Ptr(false)
+			}
+		}
+		if rule.generated != nil || rule.vendored != nil {
+			classifier.rules = append(classifier.rules, rule)
+		}
+	}
+	return classifier
+}
+
+// Kind returns the kind of a changed file. Attributes from .gitattributes take
+// precedence over the file's name, and later lines override earlier ones.
+func (c *FileClassifier) Kind(file *github.CommitFile) string {
+	name := file.GetFilename()
+
+	var generated, vendored *bool
+	if c != nil {
+		for _, rule := range c.rules {
+			if !attributeMatches(rule.pattern, name) {
+				continue
+			}
+			if rule.generated != nil {
+				generated = rule.generated
+			}
+			if rule.vendored != nil {
+				vendored = rule.vendored
+			}
+		}
+	}
+
+	switch {
+	case isBinaryFile(file):
+		return FileKindBinary
+	case vendored != nil && *vendored, vendored == nil && isVendoredPath(name):
+		return FileKindVendored
+	case generated != nil && *generated, generated == nil && IsGeneratedFile(name):
+		return FileKindGenerated
+	}
+	return FileKindSource
+}
+
+// isBinaryFile reports whether a changed file is binary, either by extension
+// or because GitHub shows no line changes and no patch for it
+func isBinaryFile(file *github.CommitFile) bool {
+	if binaryExtensions[strings.ToLower(path.Ext(file.GetFilename()))] {
+		return true
+	}
+	return file.GetPatch() == "" && file.GetChanges() == 0 &&
+		(file.GetStatus() == "added" || file.GetStatus() == "modified")
+}
+
+// isVendoredPath reports whether a file is in a directory of third-party code
+func isVendoredPath(file string) bool {
+	for _, dir := range strings.Split(path.Dir(file), "/") {
+		for _, vendored := range vendoredDirs {
+			if dir == vendored {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// attributeMatches reports whether a .gitattributes pattern matches a file.
+// Patterns without a slash match the file's name in any directory, others
+// match from the root of the repository. Like git, a pattern naming a
+// directory doesn't match the files in it, use dir/** for that.
+func attributeMatches(pattern, file string) bool {
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(file))
+		return matched
+	}
+
+	pattern = strings.TrimPrefix(pattern, "/")
+	if rest, ok := strings.CutPrefix(pattern, "**/"); ok {
+		// Match the rest of the pattern against every trailing part of the path
+		parts := strings.Split(file, "/")
+		for i := range parts {
+			if attributeMatches("/"+rest, strings.Join(parts[i:], "/")) {
+				return true
+			}
+		}
+		return false
+	}
+	if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return strings.HasPrefix(file, dir+"/")
+	}
+	matched, _ := path.Match(pattern, file)
+	return matched
+}
@@ -0,0 +1,130 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// ListPRFiles returns the files changed by a pull request with their line
+// counts, following pagination. GitHub lists at most 3000 files.
+func ListPRFiles(ctx context.Context, client *github.Client, owner, repo string, number int) ([]*github.CommitFile, error) {
+	opts := &github.ListOptions{PerPage: 100}
+
+	var all []*github.CommitFile
+	for {
+		page, resp, err := client.PullRequests.ListFiles(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error listing changed files for #%d: %w", number, err)
+		}
+		all = append(all, page...)
+
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return all, nil
+}
+
+// ChangedFiles lists the names of the files changed by a pull request
+func ChangedFiles(ctx context.Context, client *github.Client, owner, repo string, number int) ([]string, error) {
+	all, err := ListPRFiles(ctx, client, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(all))
+	for _, file := range all {
+		files = append(files, file.GetFilename())
+	}
+	return files, nil
+}
+
+// generatedPatterns match the names of files that are usually generated or
+// updated by tools rather than written by hand
+var generatedPatterns = []string{
+	"go.sum", "package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml",
+	"Cargo.lock", "poetry.lock", "Pipfile.lock", "Gemfile.lock", "composer.lock",
+	"*.pb.go", "*_generated.go", "*.gen.go", "zz_generated*", "*_pb2.py",
+	"*.min.js", "*.min.css", "*.snap",
+}
+
+// IsGeneratedFile reports whether a file is usually generated, such as a
+// lockfile, generated code or minified assets
+func IsGeneratedFile(file string) bool {
+	name := path.Base(file)
+	for _, pattern := range generatedPatterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// PathCovers reports whether a path pattern covers a file. Patterns can be
+// exact files, directories, shell globs or directory globs ending in /**.
+func PathCovers(pattern, file string) bool {
+	pattern = strings.TrimSuffix(strings.TrimSpace(pattern), "/")
+	if pattern == "" {
+		return false
+	}
+	if pattern == file {
+		return true
+	}
+	if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return strings.HasPrefix(file, dir+"/")
+	}
+	if matched, err := path.Match(pattern, file); err == nil && matched {
+		return true
+	}
+	return strings.HasPrefix(file, pattern+"/")
+}
+
+// EnrichWithPaths marks the PRs that change a file covered by any of the path
+// patterns, listing each PR's changed files
+func (c *PRCollection) EnrichWithPaths(patterns []string) *PRCollection {
+	if len(patterns) == 0 {
+		return c
+	}
+
+	c.forEachItem("changed files", func(i int, prData *PullRequestData) {
+		files, err := ChangedFiles(c.Context, c.Client, prData.Owner, prData.Repo, prData.Number())
+		if err != nil {
+			logger.Debug("Error listing changed files for %s#%d: %v", prData.RepoFullName(), prData.Number(), err)
+			return
+		}
+
+		for _, file := range files {
+			for _, pattern := range patterns {
+				if PathCovers(pattern, file) {
+					prData.TouchesPaths = true
+					return
+				}
+			}
+		}
+	})
+	return c
+}
+
+// FilterPaths keeps only the PRs marked by EnrichWithPaths
+func (c *PRCollection) FilterPaths() *PRCollection {
+	filtered := make([]*PullRequestData, 0, len(c.Items))
+	for _, prData := range c.Items {
+		if prData.TouchesPaths {
+			filtered = append(filtered, prData)
+		}
+	}
+
+	if c.Debug {
+		logger.Debug("Path filter kept %d of %d PRs", len(filtered), len(c.Items))
+	}
+
+	c.Items = filtered
+	return c
+}
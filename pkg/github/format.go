@@ -0,0 +1,89 @@
+package github
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/colors"
+	"github.com/jbrinkman/ghi/pkg/locale"
+)
+
+// ParseFormat parses a --format template, which is executed once per pull
+// request. Besides the template builtins it can use:
+//
+//	color "red" .Title   wraps text in a terminal color, a no-op when color is false
+//	truncate 30 .Title   shortens text to a length, ending it with "..."
+//	timeago .CreatedAt   formats a time as how long ago it was, such as "3 days ago"
+func ParseFormat(format string, color bool) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"color": func(name string, text any) (string, error) {
+			if !colors.Valid(name) {
+				return "", fmt.Errorf("unknown color %q", name)
+			}
+			if !color {
+				return fmt.Sprint(text), nil
+			}
+			return colors.Paint(name, fmt.Sprint(text)), nil
+		},
+		"truncate": func(length int, text any) string {
+			return TruncateColumn(fmt.Sprint(text), length)
+		},
+		"timeago": timeAgo,
+	}
+
+	tmpl, err := template.New("format").Funcs(funcs).Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderFormat executes the template for each pull request, ending each with a
+// newline unless the template already does
+func RenderFormat(w io.Writer, tmpl *template.Template, items []*PullRequestData) error {
+	for _, prData := range items {
+		if prData == nil || prData.Issue == nil {
+			continue
+		}
+
+		var b strings.Builder
+		if err := tmpl.Execute(&b, prData); err != nil {
+			return fmt.Errorf("error formatting %s#%d: %w", prData.RepoFullName(), prData.Number(), err)
+		}
+		line := b.String()
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// timeAgo formats a time, or a GitHub timestamp, as how long ago it was in
+// the user's locale
+func timeAgo(value any) (string, error) {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v != nil {
+			t = *v
+		}
+	case github.Timestamp:
+		t = v.Time
+	case *github.Timestamp:
+		if v != nil {
+			t = v.Time
+		}
+	default:
+		return "", fmt.Errorf("timeago expects a time, got %T", value)
+	}
+	return locale.TimeAgo(t), nil
+}
@@ -0,0 +1,194 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// Names of the rules a pull request is checked against by the merge gate
+const (
+	GateOpen             = "open"
+	GateDraft            = "draft"
+	GateApprovals        = "approvals"
+	GateChangesRequested = "changes-requested"
+	GateChecks           = "checks"
+)
+
+// GatePolicy is what a pull request must meet to pass the merge gate
+type GatePolicy struct {
+	// RequiredApprovals is the minimum number of approvals. When it's
+	// negative, the approvals required by branch protection are used.
+	RequiredApprovals int
+	AllowDraft        bool
+	// SkipChecks leaves check runs and commit statuses out of the gate
+	SkipChecks bool
+}
+
+// GateRule is the outcome of one rule of the merge gate
+type GateRule struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Reason string `json:"reason"`
+}
+
+// GateResult is the outcome of checking a pull request against the merge gate
+type GateResult struct {
+	Repo   string     `json:"repo"`
+	Number int        `json:"number"`
+	Title  string     `json:"title"`
+	URL    string     `json:"url"`
+	Passed bool       `json:"passed"`
+	Rules  []GateRule `json:"rules"`
+	// Merged and Closed are set when the pull request can no longer be merged
+	Merged bool `json:"merged"`
+	Closed bool `json:"closed"`
+	// ChecksPending is set when the checks haven't failed but some are still running
+	ChecksPending bool `json:"checks_pending"`
+}
+
+// Failed returns the names of the rules that didn't pass
+func (r *GateResult) Failed() []string {
+	var names []string
+	for _, rule := range r.Rules {
+		if !rule.Passed {
+			names = append(names, rule.Name)
+		}
+	}
+	return names
+}
+
+// add records the outcome of a rule
+func (r *GateResult) add(name string, passed bool, reason string, args ...any) {
+	r.Rules = append(r.Rules, GateRule{Name: name, Passed: passed, Reason: fmt.Sprintf(reason, args...)})
+	if !passed {
+		r.Passed = false
+	}
+}
+
+// EvaluateGate fetches a pull request with its reviews and checks and checks
+// it against the policy: open, not a draft, the minimum approvals, no
+// requested changes and every check successful.
+func EvaluateGate(ctx context.Context, client *github.Client, owner, repo string, number int, policy GatePolicy) (*GateResult, error) {
+	pr, _, err := client.PullRequests.Get(ctx, owner, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching pull request #%d: %w", number, err)
+	}
+
+	result := &GateResult{
+		Repo:   owner + "/" + repo,
+		Number: number,
+		Title:  pr.GetTitle(),
+		URL:    pr.GetHTMLURL(),
+		Passed: true,
+	}
+
+	switch {
+	case pr.GetMerged():
+		result.Merged = true
+		result.add(GateOpen, false, "the pull request is already merged")
+	case pr.GetState() != "open":
+		result.Closed = true
+		result.add(GateOpen, false, "the pull request is closed")
+	default:
+		result.add(GateOpen, true, "the pull request is open")
+	}
+
+	switch {
+	case !pr.GetDraft():
+		result.add(GateDraft, true, "the pull request is ready for review")
+	case policy.AllowDraft:
+		result.add(GateDraft, true, "the pull request is a draft, which is allowed")
+	default:
+		result.add(GateDraft, false, "the pull request is a draft")
+	}
+
+	reviews, err := ListAllReviews(ctx, client, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	approvals, changesRequested := reviewVerdicts(reviews)
+
+	required := policy.RequiredApprovals
+	if required < 0 {
+		base := pr.GetBase().GetRef()
+		if required, err = RequiredApprovals(ctx, client, owner, repo, base); err != nil {
+			return nil, fmt.Errorf("%w. Set the required approvals instead", err)
+		}
+	}
+	result.add(GateApprovals, approvals >= required, "%d of %d required approvals", approvals, required)
+
+	if len(changesRequested) > 0 {
+		result.add(GateChangesRequested, false, "changes requested by %s", strings.Join(changesRequested, ", "))
+	} else {
+		result.add(GateChangesRequested, true, "no changes requested")
+	}
+
+	if !policy.SkipChecks {
+		if err := evaluateChecks(ctx, client, owner, repo, pr.GetHead().GetSHA(), result); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Debug("Gate for %s #%d: passed %v, failed rules %v", result.Repo, number, result.Passed, result.Failed())
+	return result, nil
+}
+
+// evaluateChecks adds the rule for the check runs and commit statuses of the
+// head commit. Skipped and neutral check runs count as successful.
+func evaluateChecks(ctx context.Context, client *github.Client, owner, repo, sha string, result *GateResult) error {
+	var failed, pending []string
+	total := 0
+
+	opts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		runs, resp, err := client.Checks.ListCheckRunsForRef(ctx, owner, repo, sha, opts)
+		if err != nil {
+			return fmt.Errorf("error listing check runs: %w", err)
+		}
+		for _, run := range runs.CheckRuns {
+			total++
+			switch {
+			case run.GetStatus() != "completed":
+				pending = append(pending, run.GetName())
+			case run.GetConclusion() != "success" && run.GetConclusion() != "neutral" && run.GetConclusion() != "skipped":
+				failed = append(failed, run.GetName())
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	// Commit statuses are reported by integrations that don't use check runs
+	combined, _, err := client.Repositories.GetCombinedStatus(ctx, owner, repo, sha, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return fmt.Errorf("error reading commit statuses: %w", err)
+	}
+	for _, status := range combined.Statuses {
+		total++
+		switch status.GetState() {
+		case "pending":
+			pending = append(pending, status.GetContext())
+		case "failure", "error":
+			failed = append(failed, status.GetContext())
+		}
+	}
+
+	switch {
+	case len(failed) > 0:
+		result.add(GateChecks, false, "failing checks: %s", strings.Join(failed, ", "))
+	case len(pending) > 0:
+		result.ChecksPending = true
+		result.add(GateChecks, false, "pending checks: %s", strings.Join(pending, ", "))
+	case total == 0:
+		result.add(GateChecks, true, "no checks")
+	default:
+		result.add(GateChecks, true, "%d checks successful", total)
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ package github
 import (
 	"context"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/go-github/v69/github"
@@ -14,8 +16,12 @@ import (
 // PullRequestData represents a consolidated view of a GitHub pull request
 // with all related information needed for display.
 type PullRequestData struct {
+	// Owner and Repo identify the repository the pull request belongs to
+	Owner           string
+	Repo            string
 	Issue           *github.Issue
 	PullRequest     *github.PullRequest
+	Labels          []*github.Label
 	Reviews         []*github.PullRequestReview
 	Authors         []string
 	Reviewers       []string
@@ -24,26 +30,62 @@ type PullRequestData struct {
 	ReviewerStatus  string
 	IsDraft         bool
 	DraftStatus     string
+	// ReviewStates holds each reviewer's latest verdict, such as APPROVED or
+	// CHANGES_REQUESTED, and ReviewDecision summarizes them
+	ReviewStates   map[string]string
+	ReviewDecision string
+	// Dependencies are the PRs and issues referenced with "depends on" or
+	// "blocked by" in the body, and Blocked is set while any is still open
+	Dependencies []*Dependency
+	Blocked      bool
+	// StackedOn is the number of the PR whose head branch this PR targets,
+	// or 0 when it isn't stacked
+	StackedOn int
+	// TouchesPaths is set when the PR changes a file under the paths given
+	// to EnrichWithPaths
+	TouchesPaths bool
+	// RequiredApprovals is the number of approvals the protection rules of
+	// the base branch require, only set when RequirementKnown
+	RequiredApprovals int
+	RequirementKnown  bool
+	// UnverifiedCommits counts the commits without a verified signature,
+	// only set when VerificationChecked
+	UnverifiedCommits   int
+	VerificationChecked bool
+	// MergeableState is GitHub's mergeable_state for the PR, such as clean,
+	// dirty when it has conflicts, blocked or behind
+	MergeableState string
+	// AsOf is the past time the PR is shown as of, or the zero time when it's
+	// shown as it is now
+	AsOf time.Time
 }
 
-// PRCollection holds a collection of pull request data and context for operations
+// RepoFullName returns the owner/repo name of the pull request's repository
+func (p *PullRequestData) RepoFullName() string {
+	return p.Owner + "/" + p.Repo
+}
+
+// PRCollection holds a collection of pull request data and context for operations.
+// Items may come from several repositories.
 type PRCollection struct {
 	Items       []*PullRequestData
 	Client      *github.Client
-	Owner       string
-	Repo        string
 	Context     context.Context
 	Debug       bool
 	DraftOption string
+	// Concurrency is the number of pull requests enriched at the same time
+	Concurrency int
+	// AsOf is the past time the PRs are shown as of, or the zero time for now
+	AsOf time.Time
+	// Progress receives how far each enrichment step has got, when set
+	Progress chan<- Progress
 }
 
 // NewPRCollection creates a new PRCollection with the given client and context
-func NewPRCollection(ctx context.Context, client *github.Client, owner, repo string, debug bool) *PRCollection {
+func NewPRCollection(ctx context.Context, client *github.Client, debug bool) *PRCollection {
 	return &PRCollection{
 		Items:   make([]*PullRequestData, 0),
 		Client:  client,
-		Owner:   owner,
-		Repo:    repo,
 		Context: ctx,
 		Debug:   debug,
 	}
@@ -55,77 +97,111 @@ func (c *PRCollection) WithDraftOption(option string) *PRCollection {
 	return c
 }
 
-// FetchIssues retrieves issues from GitHub and initializes the PR collection
-func (c *PRCollection) FetchIssues(issues []*github.Issue) *PRCollection {
+// WithConcurrency sets how many pull requests are enriched at the same time
+func (c *PRCollection) WithConcurrency(n int) *PRCollection {
+	c.Concurrency = n
+	return c
+}
+
+// forEachItem calls fn for every item, running up to Concurrency calls at once.
+// Once the context is cancelled the remaining items are skipped, so an
+// interrupted enrichment leaves them as they were.
+func (c *PRCollection) forEachItem(stage string, fn func(i int, prData *PullRequestData)) {
+	tracker := c.newProgress(stage)
+	if c.Concurrency <= 1 {
+		for i, prData := range c.Items {
+			if c.Context.Err() != nil {
+				c.logSkipped(len(c.Items) - i)
+				return
+			}
+			fn(i, prData)
+			tracker.itemDone(prData)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	var skipped atomic.Int64
+	sem := make(chan struct{}, c.Concurrency)
+	for i, prData := range c.Items {
+		wg.Add(1)
+		go func(i int, prData *PullRequestData) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if c.Context.Err() != nil {
+				skipped.Add(1)
+				return
+			}
+			fn(i, prData)
+			tracker.itemDone(prData)
+		}(i, prData)
+	}
+	wg.Wait()
+	if n := skipped.Load(); n > 0 {
+		c.logSkipped(int(n))
+	}
+}
+
+// logSkipped records how many items an interrupted enrichment left out
+func (c *PRCollection) logSkipped(n int) {
+	logger.Debug("Context cancelled, skipped %d of %d pull requests: %v", n, len(c.Items), context.Cause(c.Context))
+}
+
+// FetchIssues adds the issues found in a repository to the PR collection. It can
+// be called once per repository to collect pull requests from several repositories.
+func (c *PRCollection) FetchIssues(owner, repo string, issues []*github.Issue) *PRCollection {
 	for _, issue := range issues {
 		prData := &PullRequestData{
+			Owner:           owner,
+			Repo:            repo,
 			Issue:           issue,
+			Labels:          issue.Labels,
 			UniqueReviewers: make(map[string]struct{}),
 		}
 		c.Items = append(c.Items, prData)
 	}
 
 	if c.Debug {
-		logger.Debug("Initialized %d pull request data objects", len(c.Items))
+		logger.Debug("Initialized %d pull request data objects from %s/%s", len(issues), owner, repo)
 	}
 
 	return c
 }
 
-// handleRateLimit attempts to handle rate limit errors with retries
-func (c *PRCollection) handleRateLimit(err error) bool {
-	if _, ok := err.(*github.RateLimitError); ok {
-		// If we hit rate limit, wait 5 seconds and try again
-		if c.Debug {
-			logger.Debug("Hit rate limit, waiting 5 seconds before retry...")
-		}
-		time.Sleep(5 * time.Second)
-		return true
-	}
-	return false
-}
-
 // EnrichWithPullRequests retrieves and attaches pull request data for each issue
 func (c *PRCollection) EnrichWithPullRequests() *PRCollection {
-	for i, prData := range c.Items {
+	c.forEachItem("pull requests", func(i int, prData *PullRequestData) {
 		if c.Debug {
 			logger.Debug("Fetching PR details for #%d (%d of %d)",
-				*prData.Issue.Number, i+1, len(c.Items))
-		}
-
-		// Try up to 3 times if we hit rate limits
-		var pr *github.PullRequest
-		var err error
-		for attempts := 0; attempts < 3; attempts++ {
-			pr, _, err = c.Client.PullRequests.Get(c.Context, c.Owner, c.Repo, *prData.Issue.Number)
-			if err != nil {
-				if attempts < 2 && c.handleRateLimit(err) {
-					continue
-				}
-				if c.Debug {
-					logger.Debug("Error fetching PR details for #%d: %v", *prData.Issue.Number, err)
-				}
-				break
-			}
-			break
+				prData.Number(), i+1, len(c.Items))
 		}
 
+		// Rate limits are retried by the client's transport
+		pr, _, err := c.Client.PullRequests.Get(c.Context, prData.Owner, prData.Repo, prData.Number())
 		if err != nil {
-			continue
+			if c.Debug {
+				logger.Debug("Error fetching PR details for #%d: %v", prData.Number(), err)
+			}
+			return
 		}
 
 		prData.PullRequest = pr
 		prData.IsDraft = pr.GetDraft()
+		// Mergeability is only known for now
+		if c.AsOf.IsZero() {
+			prData.MergeableState = pr.GetMergeableState()
+		}
 
 		if prData.IsDraft {
 			prData.DraftStatus = "[X]"
 			if c.Debug {
-				logger.Debug("PR #%d is a draft", *prData.Issue.Number)
+				logger.Debug("PR #%d is a draft", prData.Number())
 			}
 		} else {
 			prData.DraftStatus = "[ ]"
 		}
-	}
+	})
 
 	return c
 }
@@ -143,41 +219,32 @@ func (c *PRCollection) EnrichWithReviews(reviewers []string) *PRCollection {
 			len(c.Items), len(reviewers), reviewers)
 	}
 
-	for i, prData := range c.Items {
+	c.forEachItem("reviews", func(i int, prData *PullRequestData) {
 		// Always initialize reviewer status to [ ] for all PRs
 		prData.ReviewerStatus = "[ ]"
 
 		if c.Debug {
 			logger.Debug("Fetching reviews for PR #%d (%d of %d)",
-				*prData.Issue.Number, i+1, len(c.Items))
+				prData.Number(), i+1, len(c.Items))
 		}
 
-		// Try up to 3 times if we hit rate limits
-		var reviews []*github.PullRequestReview
-		var err error
-		for attempts := 0; attempts < 3; attempts++ {
-			reviews, _, err = c.Client.PullRequests.ListReviews(
-				c.Context, c.Owner, c.Repo, *prData.Issue.Number, nil)
-			if err != nil {
-				if attempts < 2 && c.handleRateLimit(err) {
-					continue
-				}
-				if c.Debug {
-					logger.Debug("Error fetching reviews for PR #%d: %v", *prData.Issue.Number, err)
-				}
-				break
+		// Rate limits are retried by the client's transport. Every page is
+		// needed, the latest verdict of each reviewer may be on any of them.
+		reviews, err := ListAllReviews(c.Context, c.Client, prData.Owner, prData.Repo, prData.Number())
+		if err != nil {
+			if c.Debug {
+				logger.Debug("Error fetching reviews for PR #%d: %v", prData.Number(), err)
 			}
-			break
+			return
 		}
 
-		if err != nil {
-			continue
+		if !c.AsOf.IsZero() {
+			reviews = reviewsBefore(reviews, c.AsOf)
 		}
-
 		prData.Reviews = reviews
 
 		if c.Debug {
-			logger.Debug("PR #%d has %d reviews", *prData.Issue.Number, len(reviews))
+			logger.Debug("PR #%d has %d reviews", prData.Number(), len(reviews))
 		}
 
 		// Always process review counts, regardless of whether reviewers were specified
@@ -186,9 +253,9 @@ func (c *PRCollection) EnrichWithReviews(reviewers []string) *PRCollection {
 
 		for _, review := range reviews {
 			reviewer := strings.ToLower(getReviewerLogin(review))
-			reviewState := "none"
-			if review.State != nil {
-				reviewState = *review.State
+			reviewState := review.GetState()
+			if reviewState == "" {
+				reviewState = "none"
 			}
 
 			if c.Debug {
@@ -198,39 +265,41 @@ func (c *PRCollection) EnrichWithReviews(reviewers []string) *PRCollection {
 			// Check if this PR has been reviewed by one of the specified reviewers
 			if len(lowercaseReviewers) > 0 &&
 				contains(lowercaseReviewers, reviewer) &&
-				isApprovedOrCommented(review) {
+				isReviewed(review) {
 				reviewerFound = true
 				prData.ReviewerStatus = "[X]"
 				if c.Debug {
 					logger.Debug("PR #%d has been reviewed by specified reviewer: %s",
-						*prData.Issue.Number, reviewer)
+						prData.Number(), reviewer)
 				}
 			}
 
 			// Count unique reviewers (excluding the PR author)
-			if reviewer != "" && reviewer != prAuthor && isApprovedOrCommented(review) {
+			if reviewer != "" && reviewer != prAuthor && isReviewed(review) {
 				prData.UniqueReviewers[reviewer] = struct{}{}
 				if c.Debug {
 					logger.Debug("Added %s to unique reviewers for PR #%d",
-						reviewer, *prData.Issue.Number)
+						reviewer, prData.Number())
 				}
 			}
+		}
 
-			// Count approvals
-			if isApproved(review) {
+		// Count approvals from each reviewer's latest verdict, like GitHub does.
+		// Dismissed approvals and repeat approvals by the same reviewer don't count.
+		prData.ReviewStates, _ = latestVerdicts(reviews)
+		prData.ReviewDecision = reviewDecision(prData.ReviewStates)
+		prData.ApprovalCount = 0
+		for _, state := range prData.ReviewStates {
+			if state == "APPROVED" {
 				prData.ApprovalCount++
-				if c.Debug {
-					logger.Debug("Incremented approval count for PR #%d (now: %d)",
-						*prData.Issue.Number, prData.ApprovalCount)
-				}
 			}
 		}
 
 		if c.Debug {
-			logger.Debug("PR #%d processing complete: %d unique reviewers, %d approvals, reviewer found: %v",
-				*prData.Issue.Number, len(prData.UniqueReviewers), prData.ApprovalCount, reviewerFound)
+			logger.Debug("PR #%d processing complete: %d unique reviewers, %d approvals, decision: %s, reviewer found: %v",
+				prData.Number(), len(prData.UniqueReviewers), prData.ApprovalCount, prData.ReviewDecision, reviewerFound)
 		}
-	}
+	})
 
 	return c
 }
@@ -267,7 +336,7 @@ func (c *PRCollection) FilterDrafts() *PRCollection {
 			filtered = append(filtered, prData)
 		} else if c.Debug {
 			logger.Debug("Filtering out draft PR #%d (isDraft=%v)",
-				*prData.Issue.Number, prData.IsDraft)
+				prData.Number(), prData.IsDraft)
 		}
 	}
 
@@ -280,6 +349,172 @@ func (c *PRCollection) FilterDrafts() *PRCollection {
 	return c
 }
 
+// FilterNoReviews keeps only PRs that nobody but the author has reviewed.
+// It must run after EnrichWithReviews.
+func (c *PRCollection) FilterNoReviews() *PRCollection {
+	filtered := make([]*PullRequestData, 0, len(c.Items))
+	for _, prData := range c.Items {
+		if !hasOtherReviews(prData) {
+			filtered = append(filtered, prData)
+		}
+	}
+
+	if c.Debug {
+		logger.Debug("No reviews filter kept %d of %d PRs", len(filtered), len(c.Items))
+	}
+
+	c.Items = filtered
+	return c
+}
+
+// hasOtherReviews reports whether anyone but the author has submitted a review
+func hasOtherReviews(prData *PullRequestData) bool {
+	author := strings.ToLower(getPRAuthor(prData))
+	for _, review := range prData.Reviews {
+		reviewer := strings.ToLower(getReviewerLogin(review))
+		if reviewer != "" && reviewer != author && review.GetState() != "PENDING" {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterNeedsApproval keeps only PRs with fewer than the given number of
+// approvals. It must run after EnrichWithReviews.
+func (c *PRCollection) FilterNeedsApproval(required int) *PRCollection {
+	filtered := make([]*PullRequestData, 0, len(c.Items))
+	for _, prData := range c.Items {
+		if prData.ApprovalCount < required {
+			filtered = append(filtered, prData)
+		}
+	}
+
+	if c.Debug {
+		logger.Debug("Needs approval filter (%d) kept %d of %d PRs", required, len(filtered), len(c.Items))
+	}
+
+	c.Items = filtered
+	return c
+}
+
+// FilterLabels keeps only PRs that have every label in include and none of the
+// labels in exclude, ignoring case. The search query already filters labels,
+// but results listed without search need filtering locally.
+func (c *PRCollection) FilterLabels(include, exclude []string) *PRCollection {
+	if len(include) == 0 && len(exclude) == 0 {
+		return c
+	}
+
+	filtered := make([]*PullRequestData, 0, len(c.Items))
+	for _, prData := range c.Items {
+		if hasAllLabels(prData, include) && !hasAnyLabel(prData, exclude) {
+			filtered = append(filtered, prData)
+		}
+	}
+
+	if c.Debug {
+		logger.Debug("Label filter kept %d of %d PRs (include: %v, exclude: %v)",
+			len(filtered), len(c.Items), include, exclude)
+	}
+
+	c.Items = filtered
+	return c
+}
+
+// hasLabel reports whether the PR has a label with the given name, ignoring case
+func hasLabel(prData *PullRequestData, name string) bool {
+	for _, label := range prData.Labels {
+		if strings.EqualFold(label.GetName(), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAllLabels reports whether the PR has every one of the labels
+func hasAllLabels(prData *PullRequestData, names []string) bool {
+	for _, name := range names {
+		if !hasLabel(prData, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasAnyLabel reports whether the PR has at least one of the labels
+func hasAnyLabel(prData *PullRequestData, names []string) bool {
+	for _, name := range names {
+		if hasLabel(prData, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterReviewRequested keeps only PRs where a review has been requested from the
+// given user, either directly or through one of the given teams. Team slugs are
+// keyed by the organization they belong to.
+func (c *PRCollection) FilterReviewRequested(username string, teamSlugs map[string][]string) *PRCollection {
+	username = strings.ToLower(username)
+
+	if c.Debug {
+		logger.Debug("Filtering PRs with review requested from %s or teams %v", username, teamSlugs)
+	}
+
+	filtered := make([]*PullRequestData, 0)
+	for _, prData := range c.Items {
+		if prData.PullRequest == nil {
+			continue
+		}
+
+		requested := false
+		for _, user := range prData.PullRequest.RequestedReviewers {
+			if strings.ToLower(user.GetLogin()) == username {
+				requested = true
+				break
+			}
+		}
+		for _, team := range prData.PullRequest.RequestedTeams {
+			if requested {
+				break
+			}
+			requested = contains(teamSlugs[prData.Owner], strings.ToLower(team.GetSlug()))
+		}
+
+		if requested {
+			filtered = append(filtered, prData)
+		} else if c.Debug {
+			logger.Debug("Filtering out PR #%d (review not requested)", prData.Number())
+		}
+	}
+
+	if c.Debug {
+		logger.Debug("After review-requested filtering, PR count reduced from %d to %d",
+			len(c.Items), len(filtered))
+	}
+
+	c.Items = filtered
+	return c
+}
+
+// Repositories returns the owner/repo names of the repositories in the
+// collection, in the order they were added
+func (c *PRCollection) Repositories() []string {
+	var repos []string
+	seen := make(map[string]bool)
+	for _, prData := range c.Items {
+		if prData == nil {
+			continue
+		}
+		name := prData.RepoFullName()
+		if !seen[name] {
+			seen[name] = true
+			repos = append(repos, name)
+		}
+	}
+	return repos
+}
+
 // GetItems returns the final collection of PR data
 func (c *PRCollection) GetItems() []*PullRequestData {
 	return c.Items
@@ -288,33 +523,20 @@ func (c *PRCollection) GetItems() []*PullRequestData {
 // Helper functions
 
 func getPRAuthor(prData *PullRequestData) string {
-	if prData.Issue != nil && prData.Issue.User != nil && prData.Issue.User.Login != nil {
-		return *prData.Issue.User.Login
+	if prData == nil {
+		return ""
 	}
-	return ""
+	return prData.Issue.GetUser().GetLogin()
 }
 
 func getReviewerLogin(review *github.PullRequestReview) string {
-	if review != nil && review.User != nil && review.User.Login != nil {
-		return *review.User.Login
-	}
-	return ""
+	return review.GetUser().GetLogin()
 }
 
-// isApprovedOrCommented safely checks if a review has APPROVED or COMMENTED state
-func isApprovedOrCommented(review *github.PullRequestReview) bool {
-	if review == nil || review.State == nil {
-		return false
-	}
-	return *review.State == "APPROVED" || *review.State == "COMMENTED"
-}
-
-// isApproved safely checks if a review has APPROVED state
-func isApproved(review *github.PullRequestReview) bool {
-	if review == nil || review.State == nil {
-		return false
-	}
-	return *review.State == "APPROVED"
+// isReviewed safely checks if a review has APPROVED, COMMENTED or CHANGES_REQUESTED state
+func isReviewed(review *github.PullRequestReview) bool {
+	state := review.GetState()
+	return state == "APPROVED" || state == "COMMENTED" || state == "CHANGES_REQUESTED"
 }
 
 func contains(slice []string, item string) bool {
@@ -0,0 +1,199 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// PRLatency is when a pull request was opened, first reviewed and merged.
+// FirstReview and Merged are zero when it hasn't been.
+type PRLatency struct {
+	Number      int
+	Title       string
+	Author      string
+	Created     time.Time
+	FirstReview time.Time
+	Merged      time.Time
+}
+
+// LatencyStats summarizes how long a step took for the pull requests that
+// completed it
+type LatencyStats struct {
+	Count  int
+	Median time.Duration
+	P90    time.Duration
+	Mean   time.Duration
+}
+
+// MarshalJSON writes the durations in hours
+func (s LatencyStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Count       int     `json:"count"`
+		MedianHours float64 `json:"median_hours"`
+		P90Hours    float64 `json:"p90_hours"`
+		MeanHours   float64 `json:"mean_hours"`
+	}{s.Count, roundHours(s.Median), roundHours(s.P90), roundHours(s.Mean)})
+}
+
+// roundHours converts a duration to hours, rounded to a tenth
+func roundHours(d time.Duration) float64 {
+	return math.Round(d.Hours()*10) / 10
+}
+
+// LatencyWeek summarizes the pull requests opened in a week
+type LatencyWeek struct {
+	Start         time.Time    `json:"start"`
+	Opened        int          `json:"opened"`
+	FirstReview   LatencyStats `json:"first_review"`
+	Merge         LatencyStats `json:"merge"`
+	ReviewToMerge LatencyStats `json:"review_to_merge"`
+}
+
+// LatencyReport summarizes how long the pull requests opened in a period took
+// to be first reviewed and merged, overall and by the week they were opened
+type LatencyReport struct {
+	Since         time.Time     `json:"since"`
+	Opened        int           `json:"opened"`
+	FirstReview   LatencyStats  `json:"first_review"`
+	Merge         LatencyStats  `json:"merge"`
+	ReviewToMerge LatencyStats  `json:"review_to_merge"`
+	Weeks         []LatencyWeek `json:"weeks"`
+}
+
+// SearchPRsCreatedSince returns the pull requests opened in a repository since
+// the given time. Complete is false when the search limit of 1000 cut it short.
+func SearchPRsCreatedSince(ctx context.Context, client *github.Client, owner, repo string, since time.Time) ([]*github.Issue, bool, error) {
+	query := fmt.Sprintf("repo:%s/%s is:pr created:>=%s", owner, repo, since.Format(time.DateOnly))
+	issues, total, err := SearchAllIssues(ctx, client, query)
+	if err != nil {
+		return nil, false, fmt.Errorf("error searching pull requests in %s/%s: %w", owner, repo, err)
+	}
+	return issues, len(issues) >= total, nil
+}
+
+// FetchPRLatencies lists the reviews of each pull request, using up to workers
+// concurrent requests, to find when it was first reviewed. Reviews by the
+// author and pending reviews don't count.
+func FetchPRLatencies(ctx context.Context, client *github.Client, owner, repo string, issues []*github.Issue, workers int) ([]PRLatency, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	latencies := make([]PRLatency, len(issues))
+	errs := make([]error, len(issues))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i, issue := range issues {
+		wg.Add(1)
+		go func(i int, issue *github.Issue) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			latency := PRLatency{
+				Number:  issue.GetNumber(),
+				Title:   issue.GetTitle(),
+				Author:  getUserLogin(issue.User),
+				Created: issue.GetCreatedAt().Time,
+			}
+			if issue.PullRequestLinks != nil {
+				latency.Merged = issue.PullRequestLinks.GetMergedAt().Time
+			}
+
+			reviews, err := ListAllReviews(ctx, client, owner, repo, issue.GetNumber())
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			for _, review := range reviews {
+				if review.GetState() == "PENDING" || strings.EqualFold(review.GetUser().GetLogin(), latency.Author) {
+					continue
+				}
+				submitted := review.GetSubmittedAt().Time
+				if latency.FirstReview.IsZero() || submitted.Before(latency.FirstReview) {
+					latency.FirstReview = submitted
+				}
+			}
+			latencies[i] = latency
+		}(i, issue)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("error fetching reviews of %s/%s#%d: %w", owner, repo, issues[i].GetNumber(), err)
+		}
+	}
+	return latencies, nil
+}
+
+// BuildLatencyReport summarizes the latencies overall and by the week the pull
+// requests were opened, starting at since
+func BuildLatencyReport(latencies []PRLatency, since time.Time) *LatencyReport {
+	report := &LatencyReport{Since: since}
+	report.Opened, report.FirstReview, report.Merge, report.ReviewToMerge = latencyStats(latencies)
+
+	weeks := make(map[int][]PRLatency)
+	lastWeek := 0
+	for _, latency := range latencies {
+		week := int(latency.Created.Sub(since).Hours() / (24 * 7))
+		weeks[week] = append(weeks[week], latency)
+		lastWeek = max(lastWeek, week)
+	}
+	for week := 0; week <= lastWeek && len(latencies) > 0; week++ {
+		summary := LatencyWeek{Start: since.AddDate(0, 0, 7*week)}
+		summary.Opened, summary.FirstReview, summary.Merge, summary.ReviewToMerge = latencyStats(weeks[week])
+		report.Weeks = append(report.Weeks, summary)
+	}
+	return report
+}
+
+// latencyStats summarizes the time from opening to the first review, from
+// opening to merge and from the first review to merge
+func latencyStats(latencies []PRLatency) (int, LatencyStats, LatencyStats, LatencyStats) {
+	var firstReview, merge, reviewToMerge []time.Duration
+	for _, latency := range latencies {
+		if !latency.FirstReview.IsZero() {
+			firstReview = append(firstReview, latency.FirstReview.Sub(latency.Created))
+		}
+		if !latency.Merged.IsZero() {
+			merge = append(merge, latency.Merged.Sub(latency.Created))
+			// Reviews after the merge don't hold it up
+			if !latency.FirstReview.IsZero() && latency.FirstReview.Before(latency.Merged) {
+				reviewToMerge = append(reviewToMerge, latency.Merged.Sub(latency.FirstReview))
+			}
+		}
+	}
+	return len(latencies), summarizeDurations(firstReview), summarizeDurations(merge), summarizeDurations(reviewToMerge)
+}
+
+// summarizeDurations returns the count, median, 90th percentile and mean of
+// the durations
+func summarizeDurations(durations []time.Duration) LatencyStats {
+	stats := LatencyStats{Count: len(durations)}
+	if len(durations) == 0 {
+		return stats
+	}
+
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	stats.Median = medianDuration(sorted)
+	// The nearest rank, so the 90th percentile of a few values is the largest
+	stats.P90 = sorted[(len(sorted)*9+9)/10-1]
+	stats.Mean = total / time.Duration(len(sorted))
+	return stats
+}
@@ -0,0 +1,116 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+)
+
+var (
+	// issueURLPattern matches a link to a GitHub issue
+	issueURLPattern = regexp.MustCompile(`github\.com/[\w.-]+/[\w.-]+/issues/\d+`)
+	// DefaultTicketPattern matches ticket keys of trackers such as Jira, like PAY-123
+	DefaultTicketPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9]+-\d+\b`)
+)
+
+// nonTicketPrefixes are names of standards that look like ticket keys, such as UTF-8
+var nonTicketPrefixes = map[string]bool{
+	"CVE": true, "HTTP": true, "ISO": true, "RFC": true, "SHA": true, "TLS": true, "UTF": true,
+}
+
+// LinkageStats counts the merged pull requests of an author, or of everyone,
+// and how many of them reference an issue or ticket
+type LinkageStats struct {
+	Author string
+	Merged int
+	Linked int
+}
+
+// Percent returns the percentage of merged pull requests that are linked
+func (s LinkageStats) Percent() float64 {
+	if s.Merged == 0 {
+		return 0
+	}
+	return float64(s.Linked) / float64(s.Merged) * 100
+}
+
+// LinkageReport summarizes how many merged pull requests reference an issue
+// or ticket, overall and by author
+type LinkageReport struct {
+	Total LinkageStats
+	// Authors are sorted by the number of merged pull requests, most first
+	Authors []LinkageStats
+	// Unlinked are the pull requests without a reference, newest first
+	Unlinked []*github.Issue
+}
+
+// SearchMergedPRs returns the pull requests merged in a repository since the
+// given time, along with the total GitHub counted, which is more than the
+// results when the search limit of 1000 is reached
+func SearchMergedPRs(ctx context.Context, client *github.Client, owner, repo string, since time.Time) ([]*github.Issue, int, error) {
+	query := fmt.Sprintf("repo:%s/%s is:pr is:merged merged:>=%s", owner, repo, since.Format(time.DateOnly))
+	issues, total, err := SearchAllIssues(ctx, client, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error searching merged pull requests in %s/%s: %w", owner, repo, err)
+	}
+	return issues, total, nil
+}
+
+// LinksIssue reports whether the title or body of a pull request references an
+// issue, as #123, owner/repo#123 or an issue link, or a ticket matching the pattern
+func LinksIssue(issue *github.Issue, ticketPattern *regexp.Regexp) bool {
+	text := issue.GetTitle() + "\n" + issue.GetBody()
+	if refPattern.MatchString(text) || issueURLPattern.MatchString(text) {
+		return true
+	}
+	if ticketPattern == nil {
+		return false
+	}
+	for _, ticket := range ticketPattern.FindAllString(text, -1) {
+		prefix, _, _ := strings.Cut(ticket, "-")
+		if !nonTicketPrefixes[strings.ToUpper(prefix)] {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildLinkageReport counts the pull requests that reference an issue or ticket
+func BuildLinkageReport(issues []*github.Issue, ticketPattern *regexp.Regexp) *LinkageReport {
+	report := &LinkageReport{}
+	byAuthor := make(map[string]*LinkageStats)
+	for _, issue := range issues {
+		author := getUserLogin(issue.User)
+		stats, ok := byAuthor[strings.ToLower(author)]
+		if !ok {
+			stats = &LinkageStats{Author: author}
+			byAuthor[strings.ToLower(author)] = stats
+		}
+
+		stats.Merged++
+		report.Total.Merged++
+		if LinksIssue(issue, ticketPattern) {
+			stats.Linked++
+			report.Total.Linked++
+		} else {
+			report.Unlinked = append(report.Unlinked, issue)
+		}
+	}
+
+	for _, stats := range byAuthor {
+		report.Authors = append(report.Authors, *stats)
+	}
+	sort.Slice(report.Authors, func(i, j int) bool {
+		a, b := report.Authors[i], report.Authors[j]
+		if a.Merged != b.Merged {
+			return a.Merged > b.Merged
+		}
+		return strings.ToLower(a.Author) < strings.ToLower(b.Author)
+	})
+	return report
+}
@@ -0,0 +1,156 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// Merge methods accepted by MergePullRequest
+const (
+	MergeMethodMerge  = "merge"
+	MergeMethodSquash = "squash"
+	MergeMethodRebase = "rebase"
+)
+
+// mergeableRetries is how many times to re-fetch a pull request while GitHub
+// is still computing whether it can be merged
+const mergeableRetries = 3
+
+// MergeCheck is the result of checking whether a pull request can be merged
+type MergeCheck struct {
+	PullRequest       *github.PullRequest
+	Approvals         int
+	RequiredApprovals int
+	ChangesRequested  []string
+	// Problems are the reasons the pull request can't be merged, empty when it can
+	Problems []string
+}
+
+// CheckMergeable fetches a pull request and checks its state, mergeability and
+// reviews before merging. The approvals required by branch protection are
+// only checked when the token is allowed to read the protection rules.
+func CheckMergeable(ctx context.Context, client *github.Client, owner, repo string, number int) (*MergeCheck, error) {
+	// GitHub computes mergeability in the background, so it may not be known yet
+	var pr *github.PullRequest
+	for attempt := 0; ; attempt++ {
+		var err error
+		pr, _, err = client.PullRequests.Get(ctx, owner, repo, number)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching pull request #%d: %w", number, err)
+		}
+		if pr.Mergeable != nil || attempt >= mergeableRetries {
+			break
+		}
+		logger.Debug("Mergeability of #%d not computed yet, retrying", number)
+		if err := sleepContext(ctx, 2*time.Second); err != nil {
+			return nil, err
+		}
+	}
+
+	check := &MergeCheck{PullRequest: pr}
+
+	reviews, err := ListAllReviews(ctx, client, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	check.Approvals, check.ChangesRequested = reviewVerdicts(reviews)
+
+	base := pr.GetBase().GetRef()
+	check.RequiredApprovals, err = RequiredApprovals(ctx, client, owner, repo, base)
+	if err != nil {
+		logger.Debug("Could not read required approvals: %v", err)
+	}
+
+	switch {
+	case pr.GetMerged():
+		check.Problems = append(check.Problems, "the pull request is already merged")
+	case pr.GetState() != "open":
+		check.Problems = append(check.Problems, "the pull request is closed")
+	}
+	if pr.GetDraft() {
+		check.Problems = append(check.Problems, "the pull request is a draft")
+	}
+	if (pr.Mergeable != nil && !pr.GetMergeable()) || pr.GetMergeableState() == "dirty" {
+		check.Problems = append(check.Problems, "the pull request has merge conflicts")
+	}
+	if check.Approvals < check.RequiredApprovals {
+		check.Problems = append(check.Problems,
+			fmt.Sprintf("%d of %d required approvals", check.Approvals, check.RequiredApprovals))
+	}
+	if len(check.ChangesRequested) > 0 {
+		check.Problems = append(check.Problems,
+			fmt.Sprintf("changes requested by %s", strings.Join(check.ChangesRequested, ", ")))
+	}
+	if len(check.Problems) == 0 && pr.GetMergeableState() == "blocked" {
+		check.Problems = append(check.Problems, "merging is blocked by branch protection, such as failing required checks")
+	}
+
+	return check, nil
+}
+
+// reviewVerdicts counts approvals and lists the reviewers requesting changes,
+// using each reviewer's latest approving or change-requesting review
+func reviewVerdicts(reviews []*github.PullRequestReview) (int, []string) {
+	latest, order := latestVerdicts(reviews)
+
+	approvals := 0
+	var changesRequested []string
+	for _, login := range order {
+		switch latest[login] {
+		case "APPROVED":
+			approvals++
+		case "CHANGES_REQUESTED":
+			changesRequested = append(changesRequested, login)
+		}
+	}
+	return approvals, changesRequested
+}
+
+// MergePullRequest merges a pull request with the given merge method. The
+// head must still be at sha, the commit that was checked before merging, so
+// commits pushed since then aren't merged unseen.
+func MergePullRequest(ctx context.Context, client *github.Client, owner, repo string, number int, method, sha string) (*github.PullRequestMergeResult, error) {
+	opts := &github.PullRequestOptions{MergeMethod: method, SHA: sha}
+	result, resp, err := client.PullRequests.Merge(ctx, owner, repo, number, "", opts)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusConflict {
+			return nil, fmt.Errorf("pull request #%d changed since it was checked, review the new commits and merge again: %w", number, err)
+		}
+		return nil, fmt.Errorf("error merging pull request #%d: %w", number, err)
+	}
+	return result, nil
+}
+
+// DeleteHeadBranch deletes the branch a pull request was opened from
+func DeleteHeadBranch(ctx context.Context, client *github.Client, pr *github.PullRequest) error {
+	head := pr.GetHead()
+	owner := head.GetRepo().GetOwner().GetLogin()
+	repo := head.GetRepo().GetName()
+	if owner == "" || repo == "" {
+		return fmt.Errorf("the head repository of #%d no longer exists", pr.GetNumber())
+	}
+
+	if _, err := client.Git.DeleteRef(ctx, owner, repo, "heads/"+head.GetRef()); err != nil {
+		return fmt.Errorf("error deleting branch %s: %w", head.GetRef(), err)
+	}
+	return nil
+}
+
+// sleepContext waits for d, returning the context's error early when it's
+// cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
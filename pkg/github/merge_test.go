@@ -0,0 +1,62 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// newTestClient returns a client that sends its requests to handler
+func newTestClient(t *testing.T, handler http.Handler) *github.Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	base, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL = base
+	return client
+}
+
+func TestMergePullRequestSendsHeadSHA(t *testing.T) {
+	var body map[string]interface{}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/repos/octo/repo/pulls/7/merge" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request body: %v", err)
+		}
+		w.Write([]byte(`{"sha": "merged", "merged": true}`))
+	}))
+
+	if _, err := MergePullRequest(context.Background(), client, "octo", "repo", 7, MergeMethodSquash, "abc123"); err != nil {
+		t.Fatal(err)
+	}
+	if body["sha"] != "abc123" {
+		t.Errorf("sha = %v, want abc123", body["sha"])
+	}
+	if body["merge_method"] != MergeMethodSquash {
+		t.Errorf("merge_method = %v, want %s", body["merge_method"], MergeMethodSquash)
+	}
+}
+
+func TestMergePullRequestStaleHead(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"message": "Head branch was modified. Review and try the merge again."}`))
+	}))
+
+	_, err := MergePullRequest(context.Background(), client, "octo", "repo", 7, MergeMethodMerge, "abc123")
+	if err == nil {
+		t.Fatal("expected an error when the head changed")
+	}
+}
@@ -0,0 +1,80 @@
+package github
+
+import (
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// MergeableState values reported by GitHub
+const (
+	MergeableClean    = "clean"
+	MergeableDirty    = "dirty"
+	MergeableBlocked  = "blocked"
+	MergeableBehind   = "behind"
+	MergeableUnstable = "unstable"
+	MergeableUnknown  = "unknown"
+)
+
+// MergeStatus describes the mergeable state of the PR for display, calling
+// out merge conflicts
+func (p *PullRequestData) MergeStatus() string {
+	if p.MergeableState == MergeableDirty {
+		return "conflicts"
+	}
+	return p.MergeableState
+}
+
+// HasConflicts reports whether the PR has merge conflicts
+func (p *PullRequestData) HasConflicts() bool {
+	return p.MergeableState == MergeableDirty
+}
+
+// EnrichWithMergeability re-fetches the open PRs whose mergeable state GitHub
+// was still computing when they were fetched. It must run after
+// EnrichWithPullRequests.
+func (c *PRCollection) EnrichWithMergeability() *PRCollection {
+	c.forEachItem("mergeability", func(i int, prData *PullRequestData) {
+		for attempt := 0; attempt < mergeableRetries; attempt++ {
+			if prData.PullRequest == nil || prData.PullRequest.GetState() != "open" ||
+				(prData.MergeableState != "" && prData.MergeableState != MergeableUnknown) {
+				return
+			}
+
+			// GitHub computes mergeability in the background after the first request
+			if sleepContext(c.Context, 2*time.Second) != nil {
+				return
+			}
+			pr, _, err := c.Client.PullRequests.Get(c.Context, prData.Owner, prData.Repo, prData.Number())
+			if err != nil {
+				logger.Debug("Error re-fetching %s#%d: %v", prData.RepoFullName(), prData.Number(), err)
+				return
+			}
+			prData.PullRequest = pr
+			prData.MergeableState = pr.GetMergeableState()
+		}
+
+		if c.Debug {
+			logger.Debug("PR %s#%d mergeable state: %s", prData.RepoFullName(), prData.Number(), prData.MergeableState)
+		}
+	})
+	return c
+}
+
+// FilterConflicts keeps only the PRs with merge conflicts. It must run after
+// EnrichWithMergeability.
+func (c *PRCollection) FilterConflicts() *PRCollection {
+	filtered := make([]*PullRequestData, 0, len(c.Items))
+	for _, prData := range c.Items {
+		if prData.HasConflicts() {
+			filtered = append(filtered, prData)
+		}
+	}
+
+	if c.Debug {
+		logger.Debug("Conflicts filter kept %d of %d PRs", len(filtered), len(c.Items))
+	}
+
+	c.Items = filtered
+	return c
+}
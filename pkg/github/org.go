@@ -0,0 +1,200 @@
+// Package github provides organization helpers for GitHub data.
+package github
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// RepoFilter selects repositories in an organization. Archived repositories
+// are always skipped.
+type RepoFilter struct {
+	// Include and Exclude are shell globs matched against the repository
+	// name, such as "api-*". When Include is set a repository must match one
+	// of its patterns.
+	Include []string
+	Exclude []string
+	// ActiveSince skips repositories that haven't been pushed to since then
+	ActiveSince time.Time
+	// Language is the primary language of the repository, ignoring case
+	Language string
+}
+
+// RepoSkips counts the repositories left out of a listing
+type RepoSkips struct {
+	Archived int
+	// Filtered counts repositories that didn't match the include, exclude or
+	// language filters. Repositories older than ActiveSince aren't counted.
+	Filtered int
+}
+
+// ListOrgRepos returns the names of the organization's repositories, skipping
+// archived ones and those filtered out by the include and exclude patterns.
+func ListOrgRepos(ctx context.Context, client *github.Client, org string, include, exclude []string) ([]string, RepoSkips, error) {
+	repos, skips, err := DiscoverOrgRepos(ctx, client, org, RepoFilter{Include: include, Exclude: exclude})
+	if err != nil {
+		return nil, skips, err
+	}
+
+	names := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		names = append(names, repo.GetName())
+	}
+	return names, skips, nil
+}
+
+// DiscoverOrgRepos returns the organization's repositories that match the
+// filter, most recently pushed first, and counts those it skipped.
+func DiscoverOrgRepos(ctx context.Context, client *github.Client, org string, filter RepoFilter) ([]*github.Repository, RepoSkips, error) {
+	opts := &github.RepositoryListByOrgOptions{
+		Sort:        "pushed",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var matched []*github.Repository
+	var skips RepoSkips
+	for {
+		repos, resp, err := client.Repositories.ListByOrg(ctx, org, opts)
+		if err != nil {
+			return nil, skips, fmt.Errorf("error listing repositories in %s: %w", org, err)
+		}
+
+		for _, repo := range repos {
+			name := repo.GetName()
+			// Repositories are sorted by push date, so the rest are older
+			if !filter.ActiveSince.IsZero() && repo.GetPushedAt().Before(filter.ActiveSince) {
+				logger.Debug("Found %d matching repositories in %s", len(matched), org)
+				return matched, skips, nil
+			}
+			if repo.GetArchived() {
+				logger.Debug("Skipping archived repository %s/%s", org, name)
+				skips.Archived++
+				continue
+			}
+			if (filter.Language != "" && !strings.EqualFold(repo.GetLanguage(), filter.Language)) ||
+				(len(filter.Include) > 0 && !matchesAny(filter.Include, name)) ||
+				matchesAny(filter.Exclude, name) {
+				skips.Filtered++
+				continue
+			}
+			matched = append(matched, repo)
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	logger.Debug("Found %d matching repositories in %s", len(matched), org)
+	return matched, skips, nil
+}
+
+// FilterArchived splits owner/repo names into active and archived
+// repositories, looking them up with up to workers concurrent requests.
+// Repositories that can't be looked up are kept, so scanning them reports the error.
+func FilterArchived(ctx context.Context, client *github.Client, repos []string, workers int) (active, archived []string) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	isArchived := make([]bool, len(repos))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i, fullName := range repos {
+		owner, name, ok := strings.Cut(fullName, "/")
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, owner, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			repo, _, err := client.Repositories.Get(ctx, owner, name)
+			if err != nil {
+				logger.Debug("Could not check whether %s/%s is archived: %v", owner, name, err)
+				return
+			}
+			isArchived[i] = repo.GetArchived()
+		}(i, owner, name)
+	}
+	wg.Wait()
+
+	for i, repo := range repos {
+		if isArchived[i] {
+			logger.Debug("Skipping archived repository %s", repo)
+			archived = append(archived, repo)
+		} else {
+			active = append(active, repo)
+		}
+	}
+	return active, archived
+}
+
+// matchesAny reports whether the name matches any of the glob patterns, ignoring case
+func matchesAny(patterns []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if matched, err := path.Match(strings.ToLower(pattern), name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// CoreQuota returns the remaining core API requests and when the quota resets
+func CoreQuota(ctx context.Context, client *github.Client) (int, time.Time, error) {
+	limits, _, err := client.RateLimit.Get(ctx)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("error fetching rate limits: %w", err)
+	}
+	if limits == nil || limits.Core == nil {
+		return 0, time.Time{}, fmt.Errorf("no core rate limit returned")
+	}
+	return limits.Core.Remaining, limits.Core.Reset.Time, nil
+}
+
+// ListOrgPullRequestIssues lists the pull requests in each of the owner's
+// repositories using up to workers concurrent requests. The results are in the
+// same order as repos. Listing uses the core API rather than search, since the
+// search quota is too small to cover a whole organization.
+func ListOrgPullRequestIssues(ctx context.Context, client *github.Client, owner string, repos []string, state string, authors []string, workers int) ([][]*github.Issue, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([][]*github.Issue, len(repos))
+	errs := make([]error, len(repos))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i, repo := range repos {
+		wg.Add(1)
+		go func(i int, repo string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i], errs[i] = ListPullRequestIssues(ctx, client, owner, repo, state, authors)
+		}(i, repo)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("error scanning %s/%s: %w", owner, repos[i], err)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,68 @@
+package github
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Progress reports how far an enrichment step has got
+type Progress struct {
+	// Stage is what is being fetched, such as "reviews"
+	Stage string
+	Done  int
+	Total int
+	// Current is the pull request enriched last, such as octocat/Hello-World#12
+	Current string
+	// Started is when the stage started, used for the ETA
+	Started time.Time
+}
+
+// ETA estimates the time left in the stage from its pace so far, or returns
+// zero before the first pull request is done
+func (p Progress) ETA() time.Duration {
+	if p.Done == 0 || p.Done >= p.Total {
+		return 0
+	}
+	perItem := time.Since(p.Started) / time.Duration(p.Done)
+	return perItem * time.Duration(p.Total-p.Done)
+}
+
+// WithProgress sets the channel the enrichment steps report their progress
+// on. Updates are dropped rather than waited for when the channel is full.
+func (c *PRCollection) WithProgress(progress chan<- Progress) *PRCollection {
+	c.Progress = progress
+	return c
+}
+
+// progressTracker counts the pull requests done in one enrichment step
+type progressTracker struct {
+	c       *PRCollection
+	stage   string
+	started time.Time
+	done    atomic.Int64
+}
+
+// newProgress starts tracking an enrichment step
+func (c *PRCollection) newProgress(stage string) *progressTracker {
+	return &progressTracker{c: c, stage: stage, started: time.Now()}
+}
+
+// itemDone records that a pull request was enriched and reports it
+func (t *progressTracker) itemDone(prData *PullRequestData) {
+	done := int(t.done.Add(1))
+	if t.c.Progress == nil {
+		return
+	}
+	progress := Progress{
+		Stage:   t.stage,
+		Done:    done,
+		Total:   len(t.c.Items),
+		Current: fmt.Sprintf("%s#%d", prData.RepoFullName(), prData.Number()),
+		Started: t.started,
+	}
+	select {
+	case t.c.Progress <- progress:
+	default:
+	}
+}
@@ -0,0 +1,35 @@
+package github
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// PromptStatus is the summary shown in shell prompts and status bars
+type PromptStatus struct {
+	// ReviewRequests is the number of open pull requests waiting for your review
+	ReviewRequests int `json:"review_requests"`
+	// FailingPRs is the number of your open pull requests whose checks fail
+	FailingPRs int       `json:"failing_prs"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// FetchPromptStatus counts the pull requests waiting for the authenticated
+// user's review and their own pull requests with failing checks. It makes two
+// search requests.
+func FetchPromptStatus(ctx context.Context, client *github.Client) (PromptStatus, error) {
+	var status PromptStatus
+	var err error
+	status.ReviewRequests, err = CountIssues(ctx, client, "is:pr is:open archived:false review-requested:@me")
+	if err != nil {
+		return status, err
+	}
+	status.FailingPRs, err = CountIssues(ctx, client, "is:pr is:open archived:false author:@me status:failure")
+	if err != nil {
+		return status, err
+	}
+	status.UpdatedAt = time.Now()
+	return status, nil
+}
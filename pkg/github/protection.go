@@ -0,0 +1,111 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// RequiredApprovals returns the number of approving reviews the protection
+// rules of a branch require, which is 0 when the branch isn't protected.
+// Reading protection rules needs admin access to the repository.
+func RequiredApprovals(ctx context.Context, client *github.Client, owner, repo, branch string) (int, error) {
+	protection, _, err := client.Repositories.GetBranchProtection(ctx, owner, repo, branch)
+	if errors.Is(err, github.ErrBranchNotProtected) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error reading branch protection for %s: %w", branch, err)
+	}
+	if reviews := protection.GetRequiredPullRequestReviews(); reviews != nil {
+		return reviews.RequiredApprovingReviewCount, nil
+	}
+	return 0, nil
+}
+
+// ApprovalStatus formats the approvals of a PR against the number its base
+// branch requires, such as "2/2 ✓" or "1/2". Only the approval count is shown
+// when the requirement isn't known.
+func (p *PullRequestData) ApprovalStatus() string {
+	if !p.RequirementKnown {
+		return fmt.Sprintf("%d", p.ApprovalCount)
+	}
+	status := fmt.Sprintf("%d/%d", p.ApprovalCount, p.RequiredApprovals)
+	if p.MeetsRequiredApprovals() {
+		status += " ✓"
+	}
+	return status
+}
+
+// MeetsRequiredApprovals reports whether the PR has the approvals its base
+// branch requires. It's false when the requirement isn't known.
+func (p *PullRequestData) MeetsRequiredApprovals() bool {
+	return p.RequirementKnown && p.ApprovalCount >= p.RequiredApprovals
+}
+
+// EnrichWithRequiredApprovals reads the approvals required by the protection
+// rules of each PR's base branch, reading each branch only once. It must run
+// after EnrichWithPullRequests.
+func (c *PRCollection) EnrichWithRequiredApprovals() *PRCollection {
+	type requirement struct {
+		count int
+		known bool
+	}
+
+	var mu sync.Mutex
+	cache := make(map[string]*requirement)
+
+	c.forEachItem("required approvals", func(i int, prData *PullRequestData) {
+		if prData.PullRequest == nil {
+			return
+		}
+		base := prData.PullRequest.GetBase().GetRef()
+		key := prData.RepoFullName() + ":" + base
+
+		mu.Lock()
+		req, ok := cache[key]
+		mu.Unlock()
+		if !ok {
+			req = &requirement{}
+			count, err := RequiredApprovals(c.Context, c.Client, prData.Owner, prData.Repo, base)
+			if err != nil {
+				logger.Debug("Could not read required approvals for %s: %v", key, err)
+			} else {
+				req.count, req.known = count, true
+			}
+
+			mu.Lock()
+			cache[key] = req
+			mu.Unlock()
+		}
+
+		prData.RequiredApprovals = req.count
+		prData.RequirementKnown = req.known
+		if c.Debug {
+			logger.Debug("PR %s#%d has %s approvals", prData.RepoFullName(), prData.Number(), prData.ApprovalStatus())
+		}
+	})
+	return c
+}
+
+// FilterReadyToMerge keeps only the PRs with the approvals their base branch
+// requires. It must run after EnrichWithReviews and EnrichWithRequiredApprovals.
+func (c *PRCollection) FilterReadyToMerge() *PRCollection {
+	filtered := make([]*PullRequestData, 0, len(c.Items))
+	for _, prData := range c.Items {
+		if prData.MeetsRequiredApprovals() {
+			filtered = append(filtered, prData)
+		}
+	}
+
+	if c.Debug {
+		logger.Debug("Ready to merge filter kept %d of %d PRs", len(filtered), len(c.Items))
+	}
+
+	c.Items = filtered
+	return c
+}
@@ -0,0 +1,124 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// Review decisions summarize the latest verdict of every reviewer on a PR
+const (
+	ReviewDecisionApproved         = "approved"
+	ReviewDecisionChangesRequested = "changes requested"
+	ReviewDecisionPending          = "pending"
+)
+
+// latestVerdicts returns each reviewer's latest approving, change-requesting
+// or dismissed review state, and the reviewers in the order they first
+// reviewed. Comments don't change a reviewer's verdict, and a dismissed
+// latest review leaves the reviewer without one.
+func latestVerdicts(reviews []*github.PullRequestReview) (map[string]string, []string) {
+	latest := make(map[string]string)
+	var order []string
+	for _, review := range reviews {
+		state := review.GetState()
+		if state != "APPROVED" && state != "CHANGES_REQUESTED" && state != "DISMISSED" {
+			continue
+		}
+		login := getReviewerLogin(review)
+		if _, seen := latest[login]; !seen {
+			order = append(order, login)
+		}
+		latest[login] = state
+	}
+	return latest, order
+}
+
+// reviewDecision returns changes requested when any reviewer's latest verdict
+// requests changes, approved when at least one approves, and pending otherwise
+func reviewDecision(latest map[string]string) string {
+	decision := ReviewDecisionPending
+	for _, state := range latest {
+		switch state {
+		case "CHANGES_REQUESTED":
+			return ReviewDecisionChangesRequested
+		case "APPROVED":
+			decision = ReviewDecisionApproved
+		}
+	}
+	return decision
+}
+
+// ListAllReviews returns every review on a pull request, following pagination
+func ListAllReviews(ctx context.Context, client *github.Client, owner, repo string, number int) ([]*github.PullRequestReview, error) {
+	opts := &github.ListOptions{PerPage: 100}
+
+	var all []*github.PullRequestReview
+	for {
+		reviews, resp, err := client.PullRequests.ListReviews(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error listing reviews for #%d: %w", number, err)
+		}
+		all = append(all, reviews...)
+
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return all, nil
+}
+
+// LatestReviewBy returns the most recently submitted review on a pull request
+// by the given user after since, or nil if they haven't submitted one since.
+func LatestReviewBy(ctx context.Context, client *github.Client, owner, repo string, number int, login string, since time.Time) (*github.PullRequestReview, error) {
+	reviews, err := ListAllReviews(ctx, client, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *github.PullRequestReview
+	for _, review := range reviews {
+		if !strings.EqualFold(getReviewerLogin(review), login) || review.SubmittedAt == nil {
+			continue
+		}
+		if !review.SubmittedAt.After(since) {
+			continue
+		}
+		if latest == nil || review.SubmittedAt.After(latest.SubmittedAt.Time) {
+			latest = review
+		}
+	}
+
+	return latest, nil
+}
+
+// Review events accepted by SubmitReview
+const (
+	ReviewApprove        = "APPROVE"
+	ReviewRequestChanges = "REQUEST_CHANGES"
+	ReviewComment        = "COMMENT"
+)
+
+// SubmitReview submits a review on a pull request with the given event and body.
+// Requesting changes and commenting require a body.
+func SubmitReview(ctx context.Context, client *github.Client, owner, repo string, number int, event, body string) (*github.PullRequestReview, error) {
+	if body == "" && event != ReviewApprove {
+		return nil, fmt.Errorf("a review body is required to %s", strings.ToLower(strings.ReplaceAll(event, "_", " ")))
+	}
+
+	request := &github.PullRequestReviewRequest{Event: github.Ptr(event)}
+	if body != "" {
+		request.Body = github.Ptr(body)
+	}
+
+	review, _, err := client.PullRequests.CreateReview(ctx, owner, repo, number, request)
+	if err != nil {
+		return nil, fmt.Errorf("error submitting review for #%d: %w", number, err)
+	}
+	return review, nil
+}
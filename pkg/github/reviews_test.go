@@ -0,0 +1,37 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLatestReviewBySince(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"id": 1, "user": {"login": "octocat"}, "state": "APPROVED", "submitted_at": "2024-05-01T10:00:00Z"},
+			{"id": 2, "user": {"login": "hubot"}, "state": "COMMENTED", "submitted_at": "2024-05-01T12:00:00Z"},
+			{"id": 3, "user": {"login": "octocat"}, "state": "COMMENTED", "submitted_at": "2024-05-01T11:00:00Z"}
+		]`))
+	}))
+	ctx := context.Background()
+
+	since := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	review, err := LatestReviewBy(ctx, client, "octo", "repo", 7, "OctoCat", since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if review.GetID() != 3 {
+		t.Errorf("review = %d, want 3", review.GetID())
+	}
+
+	since = time.Date(2024, 5, 1, 11, 30, 0, 0, time.UTC)
+	review, err = LatestReviewBy(ctx, client, "octo", "repo", 7, "octocat", since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if review != nil {
+		t.Errorf("review = %d, want none submitted since %s", review.GetID(), since)
+	}
+}
@@ -0,0 +1,188 @@
+// Package github provides search helpers for GitHub data.
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// maxFallbackPages limits how many pages of pull requests are listed when
+// falling back from the Search API to the core API.
+const maxFallbackPages = 10
+
+// maxSearchResults is the most results GitHub search returns for a query
+const maxSearchResults = 1000
+
+// CoreQuotaAvailable reports whether the core (non-search) API still has
+// requests remaining. GitHub tracks the search and core limits separately,
+// so an exhausted search quota does not mean the core API is unavailable.
+func CoreQuotaAvailable(ctx context.Context, client *github.Client) bool {
+	limits, _, err := client.RateLimit.Get(ctx)
+	if err != nil {
+		logger.Debug("Failed to fetch rate limits: %v", err)
+		return false
+	}
+	if limits == nil || limits.Core == nil {
+		return false
+	}
+
+	logger.Debug("Core rate limit: %d of %d remaining", limits.Core.Remaining, limits.Core.Limit)
+	return limits.Core.Remaining > 0
+}
+
+// SearchAllIssues returns every result of an issue search, newest first, up
+// to the search limit of 1000, along with the total GitHub counted
+func SearchAllIssues(ctx context.Context, client *github.Client, query string) ([]*github.Issue, int, error) {
+	return searchAllIssues(ctx, client, query, "created", "desc")
+}
+
+// SearchOldestIssues returns up to limit results of an issue search, oldest first
+func SearchOldestIssues(ctx context.Context, client *github.Client, query string, limit int) ([]*github.Issue, error) {
+	logger.Debug("Search query: %s, oldest %d", query, limit)
+
+	opts := &github.SearchOptions{Sort: "created", Order: "asc", ListOptions: github.ListOptions{PerPage: min(limit, 100)}}
+	result, _, err := client.Search.Issues(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	return result.Issues, nil
+}
+
+// searchAllIssues returns every result of an issue search in the given order,
+// up to the search limit of 1000, along with the total GitHub counted
+func searchAllIssues(ctx context.Context, client *github.Client, query, sort, order string) ([]*github.Issue, int, error) {
+	logger.Debug("Search query: %s, sorted by %s %s", query, sort, order)
+
+	opts := &github.SearchOptions{Sort: sort, Order: order, ListOptions: github.ListOptions{PerPage: 100}}
+	var issues []*github.Issue
+	total := 0
+	for {
+		result, resp, err := client.Search.Issues(ctx, query, opts)
+		if err != nil {
+			return nil, 0, err
+		}
+		issues = append(issues, result.Issues...)
+		total = result.GetTotal()
+
+		if resp == nil || resp.NextPage == 0 || len(issues) >= maxSearchResults {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	logger.Debug("Found %d of %d results", len(issues), total)
+	return issues, total, nil
+}
+
+// ListPullRequestIssues lists pull requests through the core API and filters
+// them client-side by state and author. The pull requests are converted to
+// issues so they can be fed into the same pipeline as search results.
+func ListPullRequestIssues(ctx context.Context, client *github.Client, owner, repo, state string, authors []string) ([]*github.Issue, error) {
+	listState := strings.ToLower(state)
+	if listState == "" {
+		listState = "all"
+	}
+
+	opts := &github.PullRequestListOptions{
+		State:       listState,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var issues []*github.Issue
+	for page := 0; page < maxFallbackPages; page++ {
+		prs, resp, err := client.PullRequests.List(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error listing pull requests: %w", err)
+		}
+
+		for _, pr := range prs {
+			if len(authors) > 0 && !contains(authors, strings.ToLower(pr.GetUser().GetLogin())) {
+				continue
+			}
+			issues = append(issues, issueFromPullRequest(pr))
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	logger.Debug("Listed %d pull requests for %s/%s", len(issues), owner, repo)
+	return issues, nil
+}
+
+// issueFromPullRequest converts a pull request to the issue shape returned by
+// the Search API.
+func issueFromPullRequest(pr *github.PullRequest) *github.Issue {
+	return &github.Issue{
+		Number:    pr.Number,
+		Title:     pr.Title,
+		Body:      pr.Body,
+		User:      pr.User,
+		State:     pr.State,
+		Labels:    pr.Labels,
+		Assignees: pr.Assignees,
+		CreatedAt: pr.CreatedAt,
+		UpdatedAt: pr.UpdatedAt,
+		ClosedAt:  pr.ClosedAt,
+		HTMLURL:   pr.HTMLURL,
+		URL:       pr.IssueURL,
+		PullRequestLinks: &github.PullRequestLinks{
+			URL:     pr.URL,
+			HTMLURL: pr.HTMLURL,
+		},
+	}
+}
+
+// AuthenticatedLogin returns the login of the user the client is authenticated as.
+func AuthenticatedLogin(ctx context.Context, client *github.Client) (string, error) {
+	user, _, err := client.Users.Get(ctx, "")
+	if err != nil {
+		return "", fmt.Errorf("error fetching authenticated user: %w", err)
+	}
+	return user.GetLogin(), nil
+}
+
+// UserTeamSlugs returns the slugs of the teams in the given organization that
+// the authenticated user belongs to. Listing teams requires the read:org scope.
+func UserTeamSlugs(ctx context.Context, client *github.Client, org string) ([]string, error) {
+	opts := &github.ListOptions{PerPage: 100}
+
+	var slugs []string
+	for {
+		teams, resp, err := client.Teams.ListUserTeams(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error listing user teams: %w", err)
+		}
+
+		for _, team := range teams {
+			if strings.EqualFold(team.GetOrganization().GetLogin(), org) {
+				slugs = append(slugs, strings.ToLower(team.GetSlug()))
+			}
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	logger.Debug("User belongs to %d teams in %s: %v", len(slugs), org, slugs)
+	return slugs, nil
+}
+
+// CountIssues returns the number of issues or pull requests matching a search
+// query, fetching a single result
+func CountIssues(ctx context.Context, client *github.Client, query string) (int, error) {
+	logger.Debug("Counting search results for: %s", query)
+	result, _, err := client.Search.Issues(ctx, query, &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 1}})
+	if err != nil {
+		return 0, fmt.Errorf("error searching %q: %w", query, err)
+	}
+	return result.GetTotal(), nil
+}
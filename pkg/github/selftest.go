@@ -0,0 +1,273 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// selftestComment is the body of the comment the write checks post. It's
+// deleted again once the checks have run.
+const selftestComment = "ghi selftest: checking that comments can be written. This comment is deleted when the test finishes."
+
+// SelftestOptions configures RunSelftest
+type SelftestOptions struct {
+	// Limit is the most pull requests the read checks search and enrich
+	Limit int
+	// Concurrency is the number of pull requests enriched at the same time
+	Concurrency int
+	// Write adds checks that comment on a pull request and delete the comment
+	Write bool
+	// Number is the pull request the write checks comment on, or 0 for the
+	// first open one found by search
+	Number int
+}
+
+// SelftestCheck is the outcome of one step of the self test
+type SelftestCheck struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Detail   string        `json:"detail"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// SelftestResult is the outcome of every step of the self test against a
+// repository
+type SelftestResult struct {
+	Repo string `json:"repo"`
+	// APIURL is the API the client talks to, which differs for GitHub Enterprise
+	APIURL string          `json:"api_url"`
+	Passed bool            `json:"passed"`
+	Checks []SelftestCheck `json:"checks"`
+}
+
+// Failed returns the names of the checks that didn't pass
+func (r *SelftestResult) Failed() []string {
+	var names []string
+	for _, check := range r.Checks {
+		if !check.Passed {
+			names = append(names, check.Name)
+		}
+	}
+	return names
+}
+
+// run times a check and records its outcome. The detail describes what was
+// found, or the error when the check failed.
+func (r *SelftestResult) run(name string, fn func() (string, error)) bool {
+	start := time.Now()
+	detail, err := fn()
+	check := SelftestCheck{Name: name, Passed: err == nil, Detail: detail, Duration: time.Since(start)}
+	if err != nil {
+		check.Detail = err.Error()
+		r.Passed = false
+	}
+	logger.Debug("Self test check %q passed: %v, %s", name, check.Passed, check.Detail)
+	r.Checks = append(r.Checks, check)
+	return check.Passed
+}
+
+// skip records a check that couldn't run because an earlier one failed
+func (r *SelftestResult) skip(name, reason string) {
+	r.Checks = append(r.Checks, SelftestCheck{Name: name, Detail: "skipped, " + reason})
+	r.Passed = false
+}
+
+// RunSelftest runs the pipeline the pr command uses against a repository:
+// searching its pull requests, enriching them and rendering them as JSON.
+// With Write set, it also comments on a pull request, edits the comment and
+// deletes it. It's meant for a sandbox repository, to check that ghi works
+// with a GitHub Enterprise server or a new token.
+func RunSelftest(ctx context.Context, client *github.Client, owner, repo string, opts SelftestOptions) *SelftestResult {
+	result := &SelftestResult{
+		Repo:   owner + "/" + repo,
+		APIURL: client.BaseURL.String(),
+		Passed: true,
+	}
+
+	readChecks := []string{"repository", "search", "pull requests", "reviews", "required approvals", "mergeability", "dependencies", "render json"}
+	if !result.run("authentication", func() (string, error) {
+		login, err := AuthenticatedLogin(ctx, client)
+		if err != nil {
+			return "", err
+		}
+		return "signed in as " + login, nil
+	}) {
+		for _, name := range readChecks {
+			result.skip(name, "not signed in")
+		}
+		return result
+	}
+
+	if !result.run("repository", func() (string, error) {
+		r, _, err := client.Repositories.Get(ctx, owner, repo)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("default branch %s, private: %v", r.GetDefaultBranch(), r.GetPrivate()), nil
+	}) {
+		for _, name := range readChecks[1:] {
+			result.skip(name, "the repository couldn't be read")
+		}
+		return result
+	}
+
+	var issues []*github.Issue
+	if !result.run("search", func() (string, error) {
+		var err error
+		issues, err = SearchOldestIssues(ctx, client, fmt.Sprintf("repo:%s/%s type:pr", owner, repo), opts.Limit)
+		if err != nil {
+			return "", err
+		}
+		if len(issues) == 0 {
+			return "", fmt.Errorf("no pull requests found, open one in the repository to test with")
+		}
+		return fmt.Sprintf("found %d pull requests", len(issues)), nil
+	}) {
+		for _, name := range readChecks[2:] {
+			result.skip(name, "no pull requests were found")
+		}
+		return result
+	}
+
+	collection := NewPRCollection(ctx, client, false).WithConcurrency(opts.Concurrency)
+	collection.FetchIssues(owner, repo, issues)
+	items := collection.Items
+
+	// The enrichment steps only log their errors, so each check counts the
+	// pull requests they left without data
+	result.run("pull requests", func() (string, error) {
+		collection.EnrichWithPullRequests()
+		return countEnriched(items, "fetched", func(p *PullRequestData) bool { return p.PullRequest != nil })
+	})
+	result.run("reviews", func() (string, error) {
+		collection.EnrichWithReviews(nil)
+		return countEnriched(items, "with reviews listed", func(p *PullRequestData) bool { return p.ReviewStates != nil })
+	})
+	// Unprotected branches and tokens without admin access leave the
+	// requirement unknown, which the pr command shows rather than failing
+	result.run("required approvals", func() (string, error) {
+		collection.EnrichWithRequiredApprovals()
+		known := 0
+		for _, item := range items {
+			if item.RequirementKnown {
+				known++
+			}
+		}
+		return fmt.Sprintf("known for %d of %d pull requests", known, len(items)), nil
+	})
+	result.run("mergeability", func() (string, error) {
+		collection.EnrichWithMergeability()
+		known := 0
+		for _, item := range items {
+			if item.MergeableState != "" && item.MergeableState != MergeableUnknown {
+				known++
+			}
+		}
+		return fmt.Sprintf("known for %d of %d pull requests", known, len(items)), nil
+	})
+	result.run("dependencies", func() (string, error) {
+		collection.EnrichWithDependencies()
+		found := 0
+		for _, item := range items {
+			found += len(item.Dependencies)
+		}
+		return fmt.Sprintf("%d dependencies found", found), nil
+	})
+	result.run("render json", func() (string, error) {
+		records := make([]PRRecord, 0, len(items))
+		for _, item := range items {
+			records = append(records, NewPRRecord(item.RepoFullName(), item.Issue))
+		}
+		data, err := json.Marshal(records)
+		if err != nil {
+			return "", err
+		}
+		var decoded []PRRecord
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return "", err
+		}
+		if len(decoded) != len(items) {
+			return "", fmt.Errorf("rendered %d of %d pull requests", len(decoded), len(items))
+		}
+		return fmt.Sprintf("rendered %d pull requests in %d bytes", len(decoded), len(data)), nil
+	})
+
+	if opts.Write {
+		runWriteChecks(ctx, client, owner, repo, selftestTarget(items, opts.Number), result)
+	}
+	return result
+}
+
+// countEnriched describes how many items an enrichment step filled in, and
+// fails when it missed any
+func countEnriched(items []*PullRequestData, what string, enriched func(*PullRequestData) bool) (string, error) {
+	count := 0
+	for _, item := range items {
+		if enriched(item) {
+			count++
+		}
+	}
+	if count < len(items) {
+		return "", fmt.Errorf("only %d of %d pull requests %s, run with --debug for the errors", count, len(items), what)
+	}
+	return fmt.Sprintf("%d pull requests %s", count, what), nil
+}
+
+// selftestTarget returns the pull request the write checks comment on: the
+// given number, or the first open pull request found
+func selftestTarget(items []*PullRequestData, number int) int {
+	if number > 0 {
+		return number
+	}
+	for _, item := range items {
+		if item.Issue.GetState() == "open" {
+			return item.Number()
+		}
+	}
+	return 0
+}
+
+// runWriteChecks comments on a pull request, edits the comment and deletes it
+func runWriteChecks(ctx context.Context, client *github.Client, owner, repo string, number int, result *SelftestResult) {
+	if number == 0 {
+		result.run("comment", func() (string, error) {
+			return "", fmt.Errorf("no open pull request to comment on, pass --number")
+		})
+		result.skip("edit comment", "no comment was added")
+		result.skip("delete comment", "no comment was added")
+		return
+	}
+
+	var comment *github.IssueComment
+	if !result.run("comment", func() (string, error) {
+		var err error
+		if comment, err = AddComment(ctx, client, owner, repo, number, selftestComment); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("commented on #%d", number), nil
+	}) {
+		result.skip("edit comment", "no comment was added")
+		result.skip("delete comment", "no comment was added")
+		return
+	}
+
+	result.run("edit comment", func() (string, error) {
+		edit := &github.IssueComment{Body: github.Ptr(selftestComment + " (edited)")}
+		if _, _, err := client.Issues.EditComment(ctx, owner, repo, comment.GetID(), edit); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("edited comment %d", comment.GetID()), nil
+	})
+	// The comment is deleted even when editing failed, so the test leaves nothing behind
+	result.run("delete comment", func() (string, error) {
+		if _, err := client.Issues.DeleteComment(ctx, owner, repo, comment.GetID()); err != nil {
+			return "", fmt.Errorf("%w, delete %s by hand", err, comment.GetHTMLURL())
+		}
+		return fmt.Sprintf("deleted comment %d", comment.GetID()), nil
+	})
+}
@@ -0,0 +1,83 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// sizeBuckets are the PR sizes from smallest to largest, with the most lines
+// added and deleted each allows. The last bucket has no limit.
+var sizeBuckets = []struct {
+	name     string
+	maxLines int
+}{
+	{"XS", 9},
+	{"S", 49},
+	{"M", 249},
+	{"L", 999},
+	{"XL", -1},
+}
+
+// LinesChanged returns the number of lines the PR adds and deletes
+func (p *PullRequestData) LinesChanged() int {
+	return p.PullRequest.GetAdditions() + p.PullRequest.GetDeletions()
+}
+
+// Size returns the size bucket of the PR by lines changed, from XS to XL,
+// or nothing when the pull request wasn't fetched
+func (p *PullRequestData) Size() string {
+	if p.PullRequest == nil {
+		return ""
+	}
+	return SizeOf(p.LinesChanged())
+}
+
+// sizeRank returns the index of the PR's size bucket
+func (p *PullRequestData) sizeRank() int {
+	return sizeRankOf(p.LinesChanged())
+}
+
+// SizeOf returns the size bucket of a change of the given number of lines
+func SizeOf(lines int) string {
+	return sizeBuckets[sizeRankOf(lines)].name
+}
+
+// sizeRankOf returns the index of the size bucket of a number of lines
+func sizeRankOf(lines int) int {
+	for i, bucket := range sizeBuckets {
+		if bucket.maxLines < 0 || lines <= bucket.maxLines {
+			return i
+		}
+	}
+	return len(sizeBuckets) - 1
+}
+
+// ParseSize returns the rank of a size bucket name, ignoring case
+func ParseSize(name string) (int, error) {
+	for i, bucket := range sizeBuckets {
+		if strings.EqualFold(bucket.name, strings.TrimSpace(name)) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid size %q. Use XS, S, M, L or XL", name)
+}
+
+// FilterMaxSize keeps only the PRs no larger than the size bucket with the
+// given rank. It must run after EnrichWithPullRequests.
+func (c *PRCollection) FilterMaxSize(maxRank int) *PRCollection {
+	filtered := make([]*PullRequestData, 0, len(c.Items))
+	for _, prData := range c.Items {
+		if prData.PullRequest != nil && prData.sizeRank() <= maxRank {
+			filtered = append(filtered, prData)
+		}
+	}
+
+	if c.Debug {
+		logger.Debug("Max size filter (%s) kept %d of %d PRs", sizeBuckets[maxRank].name, len(filtered), len(c.Items))
+	}
+
+	c.Items = filtered
+	return c
+}
@@ -0,0 +1,87 @@
+package github
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// SLA holds the ages that decide how a PR is highlighted. PRs older than
+// Stale have breached the review SLA and PRs younger than Fresh are new.
+type SLA struct {
+	Stale time.Duration
+	Fresh time.Duration
+}
+
+// DefaultSLA is used when no thresholds are configured
+var DefaultSLA = SLA{Stale: 30 * 24 * time.Hour, Fresh: 24 * time.Hour}
+
+// Age returns how long ago the PR was opened, or how long it had been open at
+// the time it's shown as of
+func (p *PullRequestData) Age() time.Duration {
+	if p.CreatedAt().IsZero() {
+		return 0
+	}
+	if !p.AsOf.IsZero() {
+		return p.AsOf.Sub(p.CreatedAt())
+	}
+	return time.Since(p.CreatedAt())
+}
+
+// IsStale reports whether the PR is older than the SLA allows
+func (s SLA) IsStale(p *PullRequestData) bool {
+	return s.Stale > 0 && p.Age() > s.Stale
+}
+
+// IsFresh reports whether the PR was opened within the SLA's fresh period
+func (s SLA) IsFresh(p *PullRequestData) bool {
+	return p.Age() <= s.Fresh
+}
+
+// FormatAge formats the age of a PR in days, showing "new" for PRs within the
+// SLA's fresh period and marking those past it with "!"
+func FormatAge(p *PullRequestData, sla SLA) string {
+	if sla.IsFresh(p) {
+		return "new"
+	}
+	age := fmt.Sprintf("%dd", int(p.Age().Hours()/24))
+	if sla.IsStale(p) {
+		age += " !"
+	}
+	return age
+}
+
+// FilterCreatedBefore keeps only the PRs opened before the given time
+func (c *PRCollection) FilterCreatedBefore(before time.Time) *PRCollection {
+	filtered := make([]*PullRequestData, 0, len(c.Items))
+	for _, prData := range c.Items {
+		if prData.CreatedAt().Before(before) {
+			filtered = append(filtered, prData)
+		}
+	}
+
+	if c.Debug {
+		logger.Debug("Created before %s filter kept %d of %d PRs", before.Format(time.DateOnly), len(filtered), len(c.Items))
+	}
+
+	c.Items = filtered
+	return c
+}
+
+// FilterUpdatedBefore keeps only the PRs last updated before the given time
+func (c *PRCollection) FilterUpdatedBefore(before time.Time) *PRCollection {
+	filtered := make([]*PullRequestData, 0, len(c.Items))
+	for _, prData := range c.Items {
+		if prData.UpdatedAt().Before(before) {
+			filtered = append(filtered, prData)
+		}
+	}
+
+	if c.Debug {
+		logger.Debug("Updated before %s filter kept %d of %d PRs", before.Format(time.DateOnly), len(filtered), len(c.Items))
+	}
+
+	c.Items = filtered
+	return c
+}
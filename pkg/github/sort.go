@@ -0,0 +1,61 @@
+package github
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// sortOrders compare two PRs for each --sort field, putting the newest, most
+// recently updated or largest first
+var sortOrders = map[string]func(a, b *PullRequestData) bool{
+	"created": func(a, b *PullRequestData) bool {
+		return a.CreatedAt().After(b.CreatedAt())
+	},
+	"updated": func(a, b *PullRequestData) bool {
+		return a.UpdatedAt().After(b.UpdatedAt())
+	},
+	"number": func(a, b *PullRequestData) bool {
+		return a.Number() > b.Number()
+	},
+	"size": func(a, b *PullRequestData) bool {
+		return a.LinesChanged() > b.LinesChanged()
+	},
+}
+
+// SortFields returns the fields PRs can be sorted by
+func SortFields() []string {
+	fields := make([]string, 0, len(sortOrders))
+	for field := range sortOrders {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// ValidateSort checks that PRs can be sorted by the field
+func ValidateSort(field string) error {
+	if _, ok := sortOrders[strings.ToLower(field)]; !ok {
+		return fmt.Errorf("invalid sort %q. Use %s", field, strings.Join(SortFields(), ", "))
+	}
+	return nil
+}
+
+// SortBy orders the PRs by a field from SortFields. Sorting by size must run
+// after EnrichWithPullRequests.
+func (c *PRCollection) SortBy(field string) *PRCollection {
+	less, ok := sortOrders[strings.ToLower(field)]
+	if !ok {
+		return c
+	}
+	sort.SliceStable(c.Items, func(i, j int) bool {
+		return less(c.Items[i], c.Items[j])
+	})
+
+	if c.Debug {
+		logger.Debug("Sorted %d PRs by %s", len(c.Items), field)
+	}
+	return c
+}
@@ -0,0 +1,136 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// StackEntry is a pull request in a stack. Level is 0 for the bottom of the
+// stack, which targets a branch that isn't another pull request's head, and
+// one more for each pull request below it.
+type StackEntry struct {
+	PullRequest *github.PullRequest
+	Level       int
+}
+
+// ListOpenPullRequests returns every open pull request in a repository
+func ListOpenPullRequests(ctx context.Context, client *github.Client, owner, repo string) ([]*github.PullRequest, error) {
+	opts := &github.PullRequestListOptions{
+		State:       "open",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var all []*github.PullRequest
+	for {
+		prs, resp, err := client.PullRequests.List(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error listing pull requests for %s/%s: %w", owner, repo, err)
+		}
+		all = append(all, prs...)
+
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+// isStackable reports whether a pull request's head branch is in the same
+// repository as its base, so other pull requests can target it
+func isStackable(pr *github.PullRequest) bool {
+	head := pr.GetHead().GetRepo().GetFullName()
+	return head != "" && head == pr.GetBase().GetRepo().GetFullName()
+}
+
+// StackOf returns the stack containing target among the open pull requests of
+// its repository, ordered from the bottom with each pull request followed by
+// those stacked on it. It returns nil when target isn't stacked.
+func StackOf(prs []*github.PullRequest, target *github.PullRequest) []StackEntry {
+	byHead := make(map[string]*github.PullRequest)
+	children := make(map[string][]*github.PullRequest)
+	found := false
+	for _, pr := range prs {
+		if pr.GetNumber() == target.GetNumber() {
+			found = true
+		}
+	}
+	if !found {
+		prs = append(prs, target)
+	}
+	for _, pr := range prs {
+		if isStackable(pr) {
+			byHead[pr.GetHead().GetRef()] = pr
+		}
+		children[pr.GetBase().GetRef()] = append(children[pr.GetBase().GetRef()], pr)
+	}
+
+	// Walk down to the bottom of the stack
+	bottom := target
+	seen := map[int]bool{bottom.GetNumber(): true}
+	for {
+		parent, ok := byHead[bottom.GetBase().GetRef()]
+		if !ok || seen[parent.GetNumber()] {
+			break
+		}
+		seen[parent.GetNumber()] = true
+		bottom = parent
+	}
+
+	// Then walk back up through every pull request stacked on it
+	var stack []StackEntry
+	visited := make(map[int]bool)
+	var walk func(pr *github.PullRequest, level int)
+	walk = func(pr *github.PullRequest, level int) {
+		if visited[pr.GetNumber()] {
+			return
+		}
+		visited[pr.GetNumber()] = true
+		stack = append(stack, StackEntry{PullRequest: pr, Level: level})
+
+		if !isStackable(pr) {
+			return
+		}
+		above := children[pr.GetHead().GetRef()]
+		sort.Slice(above, func(i, j int) bool { return above[i].GetNumber() < above[j].GetNumber() })
+		for _, child := range above {
+			walk(child, level+1)
+		}
+	}
+	walk(bottom, 0)
+
+	if len(stack) < 2 {
+		return nil
+	}
+	logger.Debug("PR #%d is in a stack of %d pull requests", target.GetNumber(), len(stack))
+	return stack
+}
+
+// EnrichWithStacks marks the PRs whose base branch is the head branch of
+// another PR in the collection. It must run after EnrichWithPullRequests.
+func (c *PRCollection) EnrichWithStacks() *PRCollection {
+	heads := make(map[string]*PullRequestData)
+	for _, prData := range c.Items {
+		if prData.PullRequest != nil && isStackable(prData.PullRequest) {
+			heads[prData.RepoFullName()+":"+prData.PullRequest.GetHead().GetRef()] = prData
+		}
+	}
+
+	for _, prData := range c.Items {
+		if prData.PullRequest == nil {
+			continue
+		}
+		parent, ok := heads[prData.RepoFullName()+":"+prData.PullRequest.GetBase().GetRef()]
+		if ok && parent != prData {
+			prData.StackedOn = parent.Number()
+			if c.Debug {
+				logger.Debug("PR %s#%d is stacked on #%d", prData.RepoFullName(), prData.Number(), prData.StackedOn)
+			}
+		}
+	}
+	return c
+}
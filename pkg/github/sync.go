@@ -0,0 +1,134 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// SearchPRsReviewedBy returns the pull requests the user has reviewed that were
+// updated since the given time, optionally limited to one owner/repo. Complete
+// is false when the search limit of 1000 cut the list short.
+func SearchPRsReviewedBy(ctx context.Context, client *github.Client, login, repo string, since time.Time) ([]*github.Issue, bool, error) {
+	query := fmt.Sprintf("is:pr reviewed-by:%s updated:>=%s", login, since.Format(time.DateOnly))
+	if repo != "" {
+		query += " repo:" + repo
+	}
+
+	issues, total, err := SearchAllIssues(ctx, client, query)
+	if err != nil {
+		return nil, false, fmt.Errorf("error searching pull requests reviewed by %s: %w", login, err)
+	}
+	return issues, len(issues) >= total, nil
+}
+
+// IssueRepo returns the owner and name of the repository of a search result
+func IssueRepo(issue *github.Issue) (string, string, error) {
+	// The repository URL ends in /repos/owner/repo
+	parts := strings.Split(strings.TrimSuffix(issue.GetRepositoryURL(), "/"), "/")
+	if len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return "", "", fmt.Errorf("unexpected repository URL %q for #%d", issue.GetRepositoryURL(), issue.GetNumber())
+	}
+	return parts[len(parts)-2], parts[len(parts)-1], nil
+}
+
+// SubmittedReviewsBy returns the reviews the user submitted. Pending reviews
+// and replies to review comments, which GitHub records as comment reviews
+// without a body, are left out.
+func SubmittedReviewsBy(reviews []*github.PullRequestReview, login string) []*github.PullRequestReview {
+	var submitted []*github.PullRequestReview
+	for _, review := range SubmittedReviews(reviews) {
+		if strings.EqualFold(getReviewerLogin(review), login) {
+			submitted = append(submitted, review)
+		}
+	}
+	return submitted
+}
+
+// SubmittedReviews returns the submitted reviews, leaving out pending reviews
+// and replies to review comments as SubmittedReviewsBy does
+func SubmittedReviews(reviews []*github.PullRequestReview) []*github.PullRequestReview {
+	var submitted []*github.PullRequestReview
+	for _, review := range reviews {
+		if review.SubmittedAt == nil {
+			continue
+		}
+		if review.GetState() == "PENDING" || (review.GetState() == "COMMENTED" && strings.TrimSpace(review.GetBody()) == "") {
+			continue
+		}
+		submitted = append(submitted, review)
+	}
+	return submitted
+}
+
+// SearchOrgPRsReviewedBy returns the pull requests the user reviewed in each
+// of the organization's repositories, by repository name, that were updated
+// since the given time. Reviews in other repositories of the organization are
+// left out. The organization is searched at once, or each repository with up
+// to workers concurrent searches when the search limit of 1000 would cut the
+// list short. Incomplete lists the repositories whose own search was cut short.
+func SearchOrgPRsReviewedBy(ctx context.Context, client *github.Client, login, org string, repos []string, since time.Time, workers int) (map[string][]*github.Issue, []string, error) {
+	wanted := make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		wanted[strings.ToLower(repo)] = true
+	}
+
+	query := fmt.Sprintf("is:pr reviewed-by:%s org:%s updated:>=%s", login, org, since.Format(time.DateOnly))
+	issues, total, err := SearchAllIssues(ctx, client, query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error searching pull requests reviewed by %s in %s: %w", login, org, err)
+	}
+	if len(issues) >= total {
+		byRepo := make(map[string][]*github.Issue)
+		for _, issue := range issues {
+			_, name, err := IssueRepo(issue)
+			if err != nil || !wanted[strings.ToLower(name)] {
+				continue
+			}
+			byRepo[name] = append(byRepo[name], issue)
+		}
+		return byRepo, nil, nil
+	}
+	logger.Debug("%d pull requests reviewed by %s in %s pass the search limit, searching each repository", total, login, org)
+
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([][]*github.Issue, len(repos))
+	complete := make([]bool, len(repos))
+	errs := make([]error, len(repos))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i, repo := range repos {
+		wg.Add(1)
+		go func(i int, repo string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i], complete[i], errs[i] = SearchPRsReviewedBy(ctx, client, login, org+"/"+repo, since)
+		}(i, repo)
+	}
+	wg.Wait()
+
+	byRepo := make(map[string][]*github.Issue)
+	var incomplete []string
+	for i, repo := range repos {
+		if errs[i] != nil {
+			return nil, nil, fmt.Errorf("error searching %s/%s: %w", org, repo, errs[i])
+		}
+		if len(results[i]) > 0 {
+			byRepo[repo] = results[i]
+		}
+		if !complete[i] {
+			incomplete = append(incomplete, repo)
+		}
+	}
+	return byRepo, incomplete, nil
+}
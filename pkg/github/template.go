@@ -0,0 +1,142 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// templatePaths are where GitHub looks for a repository's pull request
+// template, in the order they're tried
+var templatePaths = []string{
+	".github/pull_request_template.md",
+	".github/PULL_REQUEST_TEMPLATE.md",
+	"pull_request_template.md",
+	"PULL_REQUEST_TEMPLATE.md",
+	"docs/pull_request_template.md",
+	"docs/PULL_REQUEST_TEMPLATE.md",
+}
+
+var (
+	// headingPattern matches a markdown heading, capturing its level and text
+	headingPattern = regexp.MustCompile(`(?m)^(#{1,6})\s+(.+?)\s*#*\s*$`)
+	// commentPattern matches an HTML comment, which templates use for hints
+	commentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+	// summaryHeadingPattern matches the headings commit summaries are added under
+	summaryHeadingPattern = regexp.MustCompile(`(?i)summary|description|changes|what`)
+)
+
+// FetchPRTemplate returns the pull request template of a repository at the
+// given ref, or an empty string when it doesn't have one
+func FetchPRTemplate(ctx context.Context, client *github.Client, owner, repo, ref string) (string, error) {
+	opts := &github.RepositoryContentGetOptions{Ref: ref}
+	for _, path := range templatePaths {
+		file, _, resp, err := client.Repositories.GetContents(ctx, owner, repo, path, opts)
+		if resp != nil && resp.StatusCode == 404 {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("error reading %s: %w", path, err)
+		}
+		if file == nil {
+			continue
+		}
+
+		logger.Debug("Found pull request template %s in %s/%s", path, owner, repo)
+		return file.GetContent()
+	}
+	return "", nil
+}
+
+// CommitSummaries returns the first line of every commit on head that isn't
+// on base, oldest first
+func CommitSummaries(ctx context.Context, client *github.Client, owner, repo, base, head string) ([]string, error) {
+	comparison, _, err := client.Repositories.CompareCommits(ctx, owner, repo, base, head, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, fmt.Errorf("error comparing %s with %s: %w", head, base, err)
+	}
+
+	summaries := make([]string, 0, len(comparison.Commits))
+	for _, commit := range comparison.Commits {
+		summaries = append(summaries, firstLine(commit.GetCommit().GetMessage()))
+	}
+	return summaries, nil
+}
+
+// ScaffoldPRBody fills a pull request template with a list of commit summaries,
+// added below the first heading about the summary, description or changes of
+// the pull request, or at the top when there isn't one
+func ScaffoldPRBody(template string, summaries []string) string {
+	var list strings.Builder
+	for _, summary := range summaries {
+		fmt.Fprintf(&list, "- %s\n", summary)
+	}
+
+	if strings.TrimSpace(template) == "" {
+		return "## Changes\n\n" + list.String()
+	}
+
+	for _, match := range headingPattern.FindAllStringSubmatchIndex(template, -1) {
+		heading := template[match[4]:match[5]]
+		if summaryHeadingPattern.MatchString(heading) {
+			return template[:match[1]] + "\n\n" + list.String() + template[match[1]:]
+		}
+	}
+	return list.String() + "\n" + template
+}
+
+// MissingSections returns the headings of the template that are missing from
+// the body or left without content. Headings marked "(optional)" aren't
+// required, and HTML comments don't count as content.
+func MissingSections(template, body string) []string {
+	sections := sectionContents(body)
+
+	var missing []string
+	for _, match := range headingPattern.FindAllStringSubmatch(template, -1) {
+		heading := match[2]
+		if strings.Contains(strings.ToLower(heading), "(optional)") {
+			continue
+		}
+
+		content, ok := sections[strings.ToLower(heading)]
+		if !ok || strings.TrimSpace(commentPattern.ReplaceAllString(content, "")) == "" {
+			missing = append(missing, heading)
+		}
+	}
+	return missing
+}
+
+// sectionContents maps the lowercase headings of a markdown document to the
+// text below them, up to the next heading of the same or a higher level
+func sectionContents(markdown string) map[string]string {
+	matches := headingPattern.FindAllStringSubmatchIndex(markdown, -1)
+
+	sections := make(map[string]string)
+	for i, match := range matches {
+		level := match[3] - match[2]
+		end := len(markdown)
+		for _, next := range matches[i+1:] {
+			if next[3]-next[2] <= level {
+				end = next[0]
+				break
+			}
+		}
+
+		// Subsections count as content of their parent section
+		sections[strings.ToLower(markdown[match[4]:match[5]])] = markdown[match[1]:end]
+	}
+	return sections
+}
+
+// CreatePullRequest opens a pull request from head into base
+func CreatePullRequest(ctx context.Context, client *github.Client, owner, repo string, pr *github.NewPullRequest) (*github.PullRequest, error) {
+	created, _, err := client.PullRequests.Create(ctx, owner, repo, pr)
+	if err != nil {
+		return nil, fmt.Errorf("error creating pull request: %w", err)
+	}
+	return created, nil
+}
@@ -0,0 +1,94 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// Headers GitHub describes the token of a request with
+const (
+	scopesHeader     = "X-OAuth-Scopes"
+	expirationHeader = "GitHub-Authentication-Token-Expiration"
+)
+
+// expirationLayouts are the formats GitHub sends token expiration times in
+var expirationLayouts = []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"}
+
+// TokenDetails describes the token a client is authenticated with
+type TokenDetails struct {
+	Login string
+	// Scopes are the OAuth scopes of a classic token, only set when
+	// ScopesKnown. Fine-grained and GitHub App tokens don't report scopes.
+	Scopes      []string
+	ScopesKnown bool
+	// Expires is when the token expires, or the zero time when it doesn't
+	Expires time.Time
+}
+
+// HasScope reports whether the token has a scope, or a scope that includes it
+// such as repo for public_repo. Tokens that don't report scopes have them all.
+func (t *TokenDetails) HasScope(scope string) bool {
+	if !t.ScopesKnown {
+		return true
+	}
+	for _, s := range t.Scopes {
+		if s == scope || (scope == "public_repo" && s == "repo") ||
+			(scope == "read:org" && (s == "write:org" || s == "admin:org")) {
+			return true
+		}
+	}
+	return false
+}
+
+// InspectToken returns the login, scopes and expiration of the token the client
+// is authenticated with, which GitHub reports in the headers of any response
+func InspectToken(ctx context.Context, client *github.Client) (*TokenDetails, error) {
+	user, resp, err := client.Users.Get(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	details := &TokenDetails{Login: user.GetLogin()}
+	if resp != nil {
+		// The header is sent empty for a classic token without scopes
+		if scopes, ok := resp.Header[http.CanonicalHeaderKey(scopesHeader)]; ok {
+			details.ScopesKnown = true
+			for _, scope := range strings.Split(strings.Join(scopes, ","), ",") {
+				if scope = strings.TrimSpace(scope); scope != "" {
+					details.Scopes = append(details.Scopes, scope)
+				}
+			}
+		}
+		if expires := resp.Header.Get(expirationHeader); expires != "" {
+			for _, layout := range expirationLayouts {
+				if t, err := time.Parse(layout, expires); err == nil {
+					details.Expires = t
+					break
+				}
+			}
+			if details.Expires.IsZero() {
+				logger.Debug("Could not parse the token expiration %q", expires)
+			}
+		}
+	}
+	logger.Debug("Token of %s has scopes %v (known: %v), expires %v", details.Login, details.Scopes, details.ScopesKnown, details.Expires)
+	return details, nil
+}
+
+// RateLimits returns the core and search rate limits of the client
+func RateLimits(ctx context.Context, client *github.Client) (core, search *github.Rate, err error) {
+	limits, _, err := client.RateLimit.Get(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching rate limits: %w", err)
+	}
+	if limits == nil || limits.Core == nil || limits.Search == nil {
+		return nil, nil, fmt.Errorf("no rate limits returned")
+	}
+	return limits.Core, limits.Search, nil
+}
@@ -0,0 +1,193 @@
+// Package gitutil provides helpers for inspecting the local git repository,
+// such as inferring the GitHub owner and repository from a remote URL.
+package gitutil
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultRemote is the remote used to detect the repository
+const DefaultRemote = "origin"
+
+// Repo identifies a repository on a GitHub or GitHub Enterprise host
+type Repo struct {
+	Host  string
+	Owner string
+	Name  string
+}
+
+// FullName returns the repository in owner/repo form
+func (r Repo) FullName() string {
+	return r.Owner + "/" + r.Name
+}
+
+// DetectRepo infers the repository for the current directory from the URL
+// of the default remote.
+func DetectRepo() (Repo, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return Repo{}, fmt.Errorf("could not determine working directory: %w", err)
+	}
+
+	remoteURL, err := RemoteURL(dir, DefaultRemote)
+	if err != nil {
+		return Repo{}, err
+	}
+	return ParseRemoteURL(remoteURL)
+}
+
+// RemoteURL returns the URL of the named remote for the repository containing
+// dir. It asks git first and falls back to reading .git/config directly so it
+// works when git isn't installed.
+func RemoteURL(dir, remote string) (string, error) {
+	cmd := exec.Command("git", "remote", "get-url", remote)
+	cmd.Dir = dir
+	if out, err := cmd.Output(); err == nil {
+		if remoteURL := strings.TrimSpace(string(out)); remoteURL != "" {
+			return remoteURL, nil
+		}
+	}
+
+	configPath, err := findGitConfig(dir)
+	if err != nil {
+		return "", err
+	}
+	return readRemoteURL(configPath, remote)
+}
+
+// ParseRemoteURL extracts the host, owner and repository from a remote URL.
+// It supports HTTPS and SSH URLs as well as the scp-like git@host:owner/repo
+// syntax, for github.com and Enterprise hosts.
+func ParseRemoteURL(remoteURL string) (Repo, error) {
+	remoteURL = strings.TrimSpace(remoteURL)
+
+	var host, path string
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return Repo{}, fmt.Errorf("invalid remote URL %q: %w", remoteURL, err)
+		}
+		host, path = u.Hostname(), u.Path
+	} else if at := strings.Index(remoteURL, ":"); at > 0 {
+		// scp-like syntax: [user@]host:owner/repo.git
+		host, path = remoteURL[:at], remoteURL[at+1:]
+		if i := strings.LastIndex(host, "@"); i >= 0 {
+			host = host[i+1:]
+		}
+	} else {
+		return Repo{}, fmt.Errorf("unrecognized remote URL %q", remoteURL)
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return Repo{}, fmt.Errorf("remote URL %q does not contain owner/repo", remoteURL)
+	}
+
+	// Enterprise hosts may serve repositories under a path prefix, so use the last two segments
+	return Repo{
+		Host:  host,
+		Owner: parts[len(parts)-2],
+		Name:  parts[len(parts)-1],
+	}, nil
+}
+
+// findGitConfig walks up from dir to find the repository's .git/config file
+func findGitConfig(dir string) (string, error) {
+	for {
+		gitPath := filepath.Join(dir, ".git")
+		if info, err := os.Stat(gitPath); err == nil {
+			if info.IsDir() {
+				return filepath.Join(gitPath, "config"), nil
+			}
+			// Worktrees and submodules use a .git file pointing at the git directory
+			return gitDirConfig(gitPath)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("not inside a git repository")
+		}
+		dir = parent
+	}
+}
+
+// gitDirConfig resolves the config file for a .git file containing "gitdir: <path>"
+func gitDirConfig(gitFile string) (string, error) {
+	data, err := os.ReadFile(gitFile)
+	if err != nil {
+		return "", fmt.Errorf("could not read %s: %w", gitFile, err)
+	}
+
+	gitDir := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(data)), "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(gitFile), gitDir)
+	}
+
+	// Linked worktrees keep the shared config in the common directory
+	if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+		gitDir = commonDir
+	}
+
+	return filepath.Join(gitDir, "config"), nil
+}
+
+// readRemoteURL reads the url of a remote from a git config file
+func readRemoteURL(configPath, remote string) (string, error) {
+	f, err := os.Open(configPath)
+	if err != nil {
+		return "", fmt.Errorf("could not read git config: %w", err)
+	}
+	defer f.Close()
+
+	section := fmt.Sprintf(`[remote "%s"]`, remote)
+	inSection := false
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inSection = line == section
+			continue
+		}
+		if !inSection {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(key) == "url" {
+			return strings.TrimSpace(value), nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("could not read git config: %w", err)
+	}
+	return "", fmt.Errorf("remote %q not found in %s", remote, configPath)
+}
+
+// CurrentBranch returns the branch checked out in the repository containing dir
+func CurrentBranch(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("could not determine the current branch: %w", err)
+	}
+
+	branch := strings.TrimSpace(string(out))
+	if branch == "HEAD" {
+		return "", fmt.Errorf("no branch is checked out")
+	}
+	return branch, nil
+}
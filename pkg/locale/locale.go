@@ -0,0 +1,123 @@
+// Package locale formats dates, relative times and numbers for the user's
+// locale, set in the config file or detected from the environment.
+package locale
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// current is the locale output is formatted for. Undetermined keeps ghi's
+// original formats.
+var current = language.Und
+
+// Set sets the locale from a BCP 47 tag such as "de-DE" or a POSIX locale
+// such as "en_GB.UTF-8". An empty name detects the locale from LC_ALL,
+// LC_TIME and LANG, in that order.
+func Set(name string) error {
+	if name == "" {
+		name = detect()
+	}
+
+	tag, err := Parse(name)
+	current = tag
+	return err
+}
+
+// Parse returns the language tag of a BCP 47 tag or a POSIX locale. The C and
+// POSIX locales, and an empty name, are undetermined.
+func Parse(name string) (language.Tag, error) {
+	// POSIX locales carry an encoding and use _ between language and region
+	name, _, _ = strings.Cut(name, ".")
+	name, _, _ = strings.Cut(name, "@")
+	name = strings.ReplaceAll(name, "_", "-")
+	if name == "" || name == "C" || name == "POSIX" {
+		return language.Und, nil
+	}
+
+	tag, err := language.Parse(name)
+	if err != nil {
+		return language.Und, fmt.Errorf("invalid locale %q: %w", name, err)
+	}
+	return tag, nil
+}
+
+// detect returns the locale of the environment
+func detect() string {
+	for _, key := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Tag returns the current locale
+func Tag() language.Tag {
+	return current
+}
+
+// layouts are the date and date-time layouts of a locale
+type layouts struct {
+	date     string
+	dateTime string
+}
+
+// defaultLayouts are used when no locale is set or it isn't known
+var defaultLayouts = layouts{date: "2006-01-02", dateTime: time.RFC1123}
+
+// layoutsByLanguage are keyed by language, or language-REGION where the
+// regions of a language differ. Languages other than English use numeric
+// dates, so no month names need translating.
+var layoutsByLanguage = map[string]layouts{
+	"en-US": {date: "Jan 2, 2006", dateTime: "Mon, Jan 2, 2006 3:04 PM MST"},
+	"en":    {date: "2 Jan 2006", dateTime: "Mon, 2 Jan 2006 15:04 MST"},
+	"de":    {date: "02.01.2006", dateTime: "02.01.2006 15:04 MST"},
+	"fr":    {date: "02/01/2006", dateTime: "02/01/2006 15:04 MST"},
+	"es":    {date: "02/01/2006", dateTime: "02/01/2006 15:04 MST"},
+	"it":    {date: "02/01/2006", dateTime: "02/01/2006 15:04 MST"},
+	"pt":    {date: "02/01/2006", dateTime: "02/01/2006 15:04 MST"},
+	"nl":    {date: "02-01-2006", dateTime: "02-01-2006 15:04 MST"},
+	"ja":    {date: "2006/01/02", dateTime: "2006/01/02 15:04 MST"},
+	"zh":    {date: "2006/01/02", dateTime: "2006/01/02 15:04 MST"},
+	"ko":    {date: "2006. 01. 02.", dateTime: "2006. 01. 02. 15:04 MST"},
+}
+
+// currentLayouts returns the layouts of the current locale
+func currentLayouts() layouts {
+	if current == language.Und {
+		return defaultLayouts
+	}
+	base, _ := current.Base()
+	region, _ := current.Region()
+	if l, ok := layoutsByLanguage[base.String()+"-"+region.String()]; ok {
+		return l
+	}
+	if l, ok := layoutsByLanguage[base.String()]; ok {
+		return l
+	}
+	return defaultLayouts
+}
+
+// Date formats the date of a time
+func Date(t time.Time) string {
+	return t.Format(currentLayouts().date)
+}
+
+// DateTime formats a date and time
+func DateTime(t time.Time) string {
+	return t.Format(currentLayouts().dateTime)
+}
+
+// Number formats an integer with the locale's thousands separator
+func Number(n int) string {
+	if current == language.Und {
+		return fmt.Sprint(n)
+	}
+	return message.NewPrinter(current).Sprintf("%d", n)
+}
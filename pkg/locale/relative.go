@@ -0,0 +1,84 @@
+package locale
+
+import (
+	"fmt"
+	"time"
+)
+
+// words are the words of relative times in a language
+type words struct {
+	never   string
+	justNow string
+	today   string
+	// ago places an amount of time in the past, such as "%s ago"
+	ago string
+	// units are the singular and plural of minute, hour and day
+	minute, hour, day [2]string
+}
+
+// wordsByLanguage are keyed by language. Other languages use English.
+var wordsByLanguage = map[string]words{
+	"en": {never: "never", justNow: "just now", today: "today", ago: "%s ago",
+		minute: [2]string{"minute", "minutes"}, hour: [2]string{"hour", "hours"}, day: [2]string{"day", "days"}},
+	"de": {never: "nie", justNow: "gerade eben", today: "heute", ago: "vor %s",
+		minute: [2]string{"Minute", "Minuten"}, hour: [2]string{"Stunde", "Stunden"}, day: [2]string{"Tag", "Tagen"}},
+	"fr": {never: "jamais", justNow: "à l'instant", today: "aujourd'hui", ago: "il y a %s",
+		minute: [2]string{"minute", "minutes"}, hour: [2]string{"heure", "heures"}, day: [2]string{"jour", "jours"}},
+	"es": {never: "nunca", justNow: "ahora mismo", today: "hoy", ago: "hace %s",
+		minute: [2]string{"minuto", "minutos"}, hour: [2]string{"hora", "horas"}, day: [2]string{"día", "días"}},
+	"it": {never: "mai", justNow: "proprio ora", today: "oggi", ago: "%s fa",
+		minute: [2]string{"minuto", "minuti"}, hour: [2]string{"ora", "ore"}, day: [2]string{"giorno", "giorni"}},
+	"pt": {never: "nunca", justNow: "agora mesmo", today: "hoje", ago: "há %s",
+		minute: [2]string{"minuto", "minutos"}, hour: [2]string{"hora", "horas"}, day: [2]string{"dia", "dias"}},
+	"nl": {never: "nooit", justNow: "zojuist", today: "vandaag", ago: "%s geleden",
+		minute: [2]string{"minuut", "minuten"}, hour: [2]string{"uur", "uur"}, day: [2]string{"dag", "dagen"}},
+}
+
+// currentWords returns the words of the current locale's language
+func currentWords() words {
+	base, _ := current.Base()
+	if w, ok := wordsByLanguage[base.String()]; ok {
+		return w
+	}
+	return wordsByLanguage["en"]
+}
+
+// amount formats a count of a unit, such as "1 day" or "3 days"
+func amount(count int, unit [2]string) string {
+	if count == 1 {
+		return Number(count) + " " + unit[0]
+	}
+	return Number(count) + " " + unit[1]
+}
+
+// DaysAgo describes how many whole days ago a time was, such as "today" or
+// "3 days ago"
+func DaysAgo(t time.Time) string {
+	w := currentWords()
+	days := int(time.Since(t).Hours() / 24)
+	if days == 0 {
+		return w.today
+	}
+	return fmt.Sprintf(w.ago, amount(days, w.day))
+}
+
+// TimeAgo describes how long ago a time was in minutes, hours or days, such
+// as "just now" or "2 hours ago". The zero time was never.
+func TimeAgo(t time.Time) string {
+	w := currentWords()
+	if t.IsZero() {
+		return w.never
+	}
+
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return w.justNow
+	case age < time.Hour:
+		return fmt.Sprintf(w.ago, amount(int(age.Minutes()), w.minute))
+	case age < 24*time.Hour:
+		return fmt.Sprintf(w.ago, amount(int(age.Hours()), w.hour))
+	default:
+		return fmt.Sprintf(w.ago, amount(int(age.Hours()/24), w.day))
+	}
+}
@@ -1,112 +1,311 @@
 // Package logger provides logging functionality for the GHI application.
-// It supports debug logging to date-rotated files in the ~/.ghi/logs directory.
+// It's built on log/slog: messages have a level, can carry key/value fields,
+// and go to the console, from warnings up by default, and with --debug to
+// size-rotated files in the logs directory of ghi's state directory, from
+// debug up.
 package logger
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/jbrinkman/ghi/pkg/paths"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var (
-	// IsDebug indicates whether debug mode is enabled
-	IsDebug      bool
-	debugEnabled bool
-	debugLogger  *log.Logger
+// Options configures where log messages go
+type Options struct {
+	// Level is the lowest level written to the console
+	Level slog.Level
+	// Debug also writes messages of every level to a rotated file in Dir
+	Debug bool
+	// Dir is the directory of the log files, DefaultDir when empty
+	Dir string
+	// Format is the format of the log files: FormatText or FormatJSON
+	Format string
+	// Command is the command being run, recorded with every file entry
+	Command string
+}
+
+// Formats of the log files
+const (
+	FormatText = "text"
+	FormatJSON = "json"
 )
 
-// init initializes the logger
+// runID identifies the entries of one invocation in the log files
+var runID = newRunID()
+
+// current is the logger the package functions write to, with secrets masked.
+// Until Setup is called, warnings and errors go to stderr.
+var current atomic.Pointer[slog.Logger]
+
 func init() {
-	// Get the user's home directory
-	home, err := os.UserHomeDir()
-	if err != nil {
-		log.Printf("Warning: Could not get home directory: %v", err)
-		return
+	current.Store(slog.New(redactingHandler{next: newConsoleHandler(os.Stderr, slog.LevelWarn)}))
+}
+
+// Setup configures the console and file logging. The file is only opened
+// when debug logging is enabled.
+func Setup(opts Options) error {
+	if opts.Format != "" && opts.Format != FormatText && opts.Format != FormatJSON {
+		return fmt.Errorf("invalid log format %q, use %s or %s", opts.Format, FormatText, FormatJSON)
 	}
 
-	// Create the .ghi/logs directory if it doesn't exist
-	logDir := filepath.Join(home, ".ghi", "logs")
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		log.Printf("Warning: Could not create log directory: %v", err)
-		return
+	handlers := []slog.Handler{newConsoleHandler(os.Stderr, opts.Level)}
+	if opts.Debug {
+		dir := opts.Dir
+		if dir == "" {
+			dir = DefaultDir()
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+		file := newFileHandler(&lumberjack.Logger{
+			Filename:   filepath.Join(dir, fmt.Sprintf("ghi-%s.log", time.Now().Format("2006-01-02"))),
+			MaxSize:    10, // megabytes
+			MaxBackups: 5,
+			MaxAge:     30, // days
+			Compress:   true,
+		}, opts.Format)
+		attrs := []slog.Attr{slog.String("run_id", runID)}
+		if opts.Command != "" {
+			attrs = append(attrs, slog.String("command", opts.Command))
+		}
+		handlers = append(handlers, file.WithAttrs(attrs))
+	}
+	// Secrets are masked before any handler writes a message
+	current.Store(slog.New(redactingHandler{next: fanoutHandler(handlers)}))
+	return nil
+}
+
+// DefaultDir returns the directory log files are written to
+func DefaultDir() string {
+	return paths.LogDir()
+}
+
+// RunID returns the ID recorded with every log file entry of this run
+func RunID() string {
+	return runID
+}
+
+// newRunID returns a random ID for a run
+func newRunID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// ParseLevel parses a level name: debug, info, warn or error
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q, use debug, info, warn or error", name)
 	}
+	return level, nil
+}
+
+// Logger returns the configured logger, for messages with key/value fields:
+//
+//	logger.Logger().Info("fetched pull requests", "repo", repo, "count", n)
+func Logger() *slog.Logger {
+	return current.Load()
+}
+
+// With returns the configured logger with fields added to every message
+func With(args ...any) *slog.Logger {
+	return current.Load().With(args...)
+}
+
+// Enabled reports whether messages of a level are written anywhere, to skip
+// building costly messages
+func Enabled(level slog.Level) bool {
+	return current.Load().Enabled(context.Background(), level)
+}
+
+// Debug logs a message for troubleshooting, written to the log file with --debug
+func Debug(format string, v ...interface{}) {
+	logf(slog.LevelDebug, format, v...)
+}
 
-	// Create or open the log file with today's date
-	logFile := filepath.Join(logDir, fmt.Sprintf("ghi-%s.log", time.Now().Format("2006-01-02")))
-	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		log.Printf("Warning: Could not open log file: %v", err)
+// Info logs a message about normal progress
+func Info(format string, v ...interface{}) {
+	logf(slog.LevelInfo, format, v...)
+}
+
+// Warn logs a problem the command works around
+func Warn(format string, v ...interface{}) {
+	logf(slog.LevelWarn, format, v...)
+}
+
+// Error logs a problem that stops part of the command from working
+func Error(format string, v ...interface{}) {
+	logf(slog.LevelError, format, v...)
+}
+
+// logf formats and logs a message, recording the caller of the package
+// function that called it as the source
+func logf(level slog.Level, format string, v ...interface{}) {
+	l := current.Load()
+	ctx := context.Background()
+	if !l.Enabled(ctx, level) {
 		return
 	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // skip Callers, logf and the level function
+	record := slog.NewRecord(time.Now(), level, fmt.Sprintf(format, v...), pcs[0])
+	_ = l.Handler().Handle(ctx, record)
+}
 
-	debugLogger = log.New(f, "", log.Ldate|log.Ltime|log.Lmicroseconds)
+// fanoutHandler passes each record to every handler that accepts its level
+type fanoutHandler []slog.Handler
+
+func (h fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
 }
 
-// SetupLogging configures logging based on whether debug mode is enabled.
-// If debug is true, logs will be written to date-rotated files in ~/.ghi/logs.
-// Otherwise, logs will continue to go to stderr.
-func SetupLogging(debug bool) {
-	IsDebug = debug
-	if !debug {
-		return // Keep default stderr logging
+func (h fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range h {
+		if handler.Enabled(ctx, record.Level) {
+			if err := handler.Handle(ctx, record.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
 	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
 
-	// Create logs directory if it doesn't exist
-	logsDir := filepath.Join(getUserHomeDir(), ".ghi", "logs")
-	if err := os.MkdirAll(logsDir, 0755); err != nil {
-		log.Printf("Warning: Failed to create logs directory: %v", err)
-		return
+func (h fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithAttrs(attrs)
 	}
+	return handlers
+}
 
-	// Set up the log file with rotation
-	logFileName := filepath.Join(logsDir, fmt.Sprintf("ghi-%s.log", time.Now().Format("2006-01-02")))
+func (h fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return handlers
+}
 
-	// Configure lumberjack for log rotation
-	logRotator := &lumberjack.Logger{
-		Filename:   logFileName,
-		MaxSize:    10, // megabytes
-		MaxBackups: 5,
-		MaxAge:     30, // days
-		Compress:   true,
+// newFileHandler writes every level as text, or JSON objects for log tooling,
+// with the time and the source file and line of the call
+func newFileHandler(w *lumberjack.Logger, format string) slog.Handler {
+	opts := &slog.HandlerOptions{
+		AddSource: true,
+		Level:     slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			// The file name and line are enough to find the call
+			if source, ok := a.Value.Any().(*slog.Source); ok && len(groups) == 0 {
+				a.Value = slog.StringValue(fmt.Sprintf("%s:%d", filepath.Base(source.File), source.Line))
+			}
+			return a
+		},
+	}
+	if format == FormatJSON {
+		return slog.NewJSONHandler(w, opts)
 	}
+	return slog.NewTextHandler(w, opts)
+}
 
-	// Set the log output to the rotated file
-	log.SetOutput(logRotator)
-	log.SetFlags(log.LstdFlags | log.Lshortfile | log.Lmicroseconds)
+// consoleHandler writes messages for people reading the terminal, such as
+// "Warning: rate limit low remaining=12", without times or sources
+type consoleHandler struct {
+	w     *os.File
+	level slog.Level
+	// attrs are the fields added with WithAttrs, already formatted
+	attrs  string
+	prefix string
+}
 
-	log.Println("Debug logging enabled")
+func newConsoleHandler(w *os.File, level slog.Level) *consoleHandler {
+	return &consoleHandler{w: w, level: level}
 }
 
-// SetDebug enables or disables debug logging
-func SetDebug(enabled bool) {
-	debugEnabled = enabled
-	if enabled {
-		Debug("Debug logging enabled")
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *consoleHandler) Handle(_ context.Context, record slog.Record) error {
+	var b strings.Builder
+	switch {
+	case record.Level >= slog.LevelError:
+		b.WriteString("Error: ")
+	case record.Level >= slog.LevelWarn:
+		b.WriteString("Warning: ")
+	case record.Level < slog.LevelInfo:
+		b.WriteString("Debug: ")
 	}
+	b.WriteString(record.Message)
+	b.WriteString(h.attrs)
+	record.Attrs(func(a slog.Attr) bool {
+		h.writeAttr(&b, h.prefix, a)
+		return true
+	})
+	b.WriteString("\n")
+	_, err := h.w.WriteString(b.String())
+	return err
 }
 
-// Debug logs a message if debug mode is enabled
-func Debug(format string, v ...interface{}) {
-	if IsDebug {
-		log.Printf(format, v...)
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	for _, a := range attrs {
+		h.writeAttr(&b, h.prefix, a)
 	}
-	if debugEnabled && debugLogger != nil {
-		// Get the source file and line number
-		_, file := filepath.Split(os.Args[0])
-		debugLogger.Printf("%s:%d: %s", file, 58, fmt.Sprintf(format, v...))
+	clone := *h
+	clone.attrs += b.String()
+	return &clone
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
 	}
+	clone := *h
+	clone.prefix += name + "."
+	return &clone
 }
 
-// getUserHomeDir returns the user's home directory
-func getUserHomeDir() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		log.Printf("Warning: Failed to determine user home directory: %v", err)
-		return "."
+// writeAttr writes a field as " key=value", flattening groups into dotted keys
+func (h *consoleHandler) writeAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, attr := range a.Value.Group() {
+			h.writeAttr(b, prefix, attr)
+		}
+		return
+	}
+	value := a.Value.String()
+	if strings.ContainsAny(value, " \t\n\"=") {
+		value = fmt.Sprintf("%q", value)
 	}
-	return home
+	fmt.Fprintf(b, " %s%s=%s", prefix, a.Key, value)
 }
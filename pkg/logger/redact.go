@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// redacted replaces secrets in log messages
+const redacted = "[REDACTED]"
+
+// minSecretLength keeps short values, which would mask ordinary words, from
+// being treated as secrets
+const minSecretLength = 8
+
+// secretEnv are the environment variables holding secrets. Their values are
+// read when a message is logged, since the env file is loaded after startup.
+var secretEnv = []string{"GHI_GITHUB_TOKEN", "GHI_AUTH_TOKEN", "GHI_PASSPHRASE", "GHI_SLACK_WEBHOOK", "GHI_APP_PRIVATE_KEY"}
+
+// secretPatterns match secrets by their shape, with the part to keep in the
+// first group
+var secretPatterns = []*regexp.Regexp{
+	// GitHub tokens: personal, OAuth, user-to-server, server-to-server and refresh
+	regexp.MustCompile(`()\b(?:gh[opusr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,})`),
+	// JWTs, such as libsql database tokens
+	regexp.MustCompile(`()\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+	// Secrets in query parameters, such as the authToken of a libsql connection string
+	regexp.MustCompile(`(?i)(\b(?:authToken|access_token|token|client_secret|password|secret)=)[^&\s"']+`),
+	// Authorization headers
+	regexp.MustCompile(`(?i)(\bBearer\s+)[^\s"']+`),
+	// Slack webhook URLs, which let anyone post to the channel
+	regexp.MustCompile(`(hooks\.slack\.com/services/)[^\s"']+`),
+	// Private keys, such as that of a GitHub App
+	regexp.MustCompile(`(-----BEGIN [A-Z ]*PRIVATE KEY-----)[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	// Settings from the env file
+	regexp.MustCompile(`(\bGHI_[A-Z_]*(?:TOKEN|PASSPHRASE|WEBHOOK|PRIVATE_KEY)=)\S+`),
+}
+
+var (
+	secretsMu sync.RWMutex
+	// secrets are values registered with AddSecret
+	secrets []string
+)
+
+// AddSecret registers a value that's masked wherever it appears in the logs,
+// such as a token printed by a token command
+func AddSecret(secret string) {
+	if len(secret) < minSecretLength {
+		return
+	}
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	secrets = append(secrets, secret)
+}
+
+// Redact masks the secrets in s
+func Redact(s string) string {
+	secretsMu.RLock()
+	known := append([]string{}, secrets...)
+	secretsMu.RUnlock()
+	for _, name := range secretEnv {
+		known = append(known, os.Getenv(name))
+	}
+	for _, secret := range known {
+		if len(secret) >= minSecretLength {
+			s = strings.ReplaceAll(s, secret, redacted)
+		}
+	}
+
+	for _, pattern := range secretPatterns {
+		s = pattern.ReplaceAllString(s, "${1}"+redacted)
+	}
+	return s
+}
+
+// redactingHandler masks secrets in messages and fields before passing them
+// on, so nothing written to the console or the log files holds a secret
+type redactingHandler struct {
+	next slog.Handler
+}
+
+func (h redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	clean := slog.NewRecord(record.Time, record.Level, Redact(record.Message), record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		clean.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, clean)
+}
+
+func (h redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clean := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		clean[i] = redactAttr(a)
+	}
+	return redactingHandler{next: h.next.WithAttrs(clean)}
+}
+
+func (h redactingHandler) WithGroup(name string) slog.Handler {
+	return redactingHandler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr masks the secrets in a field. Values other than numbers, bools,
+// times and durations are logged as their redacted text.
+func redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		group := a.Value.Group()
+		clean := make([]any, len(group))
+		for i, attr := range group {
+			clean[i] = redactAttr(attr)
+		}
+		return slog.Group(a.Key, clean...)
+	case slog.KindString, slog.KindAny:
+		return slog.String(a.Key, Redact(a.Value.String()))
+	}
+	return a
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+)
+
+// DesktopNotifier shows messages as desktop notifications using notify-send
+// on Linux and osascript on macOS
+type DesktopNotifier struct{}
+
+// Name returns "desktop"
+func (n *DesktopNotifier) Name() string {
+	return TypeDesktop
+}
+
+// Notify shows the message title and body
+func (n *DesktopNotifier) Notify(ctx context.Context, msg Message) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd":
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name=ghi", msg.Title, msg.Body)
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s",
+			strconv.Quote(msg.Body), strconv.Quote(msg.Title))
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	default:
+		return fmt.Errorf("desktop notifications aren't supported on %s", runtime.GOOS)
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", cmd.Path, err, output)
+	}
+	return nil
+}
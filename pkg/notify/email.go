@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EmailNotifier sends messages by email through an SMTP server
+type EmailNotifier struct {
+	To       []string
+	From     string
+	Addr     string
+	Username string
+	Password string
+}
+
+// newEmailNotifier validates an email config
+func newEmailNotifier(config Config) (*EmailNotifier, error) {
+	if len(config.To) == 0 || config.From == "" || config.SMTPHost == "" {
+		return nil, fmt.Errorf("email notifier requires to, from and smtp_host")
+	}
+
+	port := config.SMTPPort
+	if port == 0 {
+		port = 587
+	}
+
+	notifier := &EmailNotifier{
+		To:       config.To,
+		From:     config.From,
+		Addr:     net.JoinHostPort(config.SMTPHost, strconv.Itoa(port)),
+		Username: config.Username,
+	}
+	if config.PasswordEnv != "" {
+		notifier.Password = os.Getenv(config.PasswordEnv)
+		if notifier.Password == "" {
+			return nil, fmt.Errorf("email notifier password variable %s is not set", config.PasswordEnv)
+		}
+	}
+	return notifier, nil
+}
+
+// Name returns "email"
+func (n *EmailNotifier) Name() string {
+	return TypeEmail
+}
+
+// Notify sends the message as a plain text email. The subject is encoded so
+// titles with non-ASCII characters arrive intact.
+func (n *EmailNotifier) Notify(ctx context.Context, msg Message) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(n.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Title))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(formatText(msg), "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	if err := n.send(ctx, []byte(b.String())); err != nil {
+		// A cancelled send fails on the closed connection, report why it was closed
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// send delivers the email like smtp.SendMail, upgrading to TLS when the
+// server offers it, but over a connection that cancelling ctx closes
+func (n *EmailNotifier) send(ctx context.Context, email []byte) error {
+	host, _, err := net.SplitHostPort(n.Addr)
+	if err != nil {
+		return err
+	}
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", n.Addr)
+	if err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if n.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("%s doesn't support authentication", host)
+		}
+		if err := c.Auth(smtp.PlainAuth("", n.Username, n.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(n.From); err != nil {
+		return err
+	}
+	for _, to := range n.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(email); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,173 @@
+// Package notify delivers notifications to the destinations configured by the
+// user, such as Slack, webhooks, the desktop or email.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Supported notifier types
+const (
+	TypeSlack   = "slack"
+	TypeWebhook = "webhook"
+	TypeDesktop = "desktop"
+	TypeEmail   = "email"
+	TypeStdout  = "stdout"
+)
+
+// Message is a single notification
+type Message struct {
+	Title string    `json:"title"`
+	Body  string    `json:"body"`
+	URL   string    `json:"url,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// Notifier delivers messages to one destination
+type Notifier interface {
+	// Name describes the destination for logs and errors
+	Name() string
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Config configures one notifier. Which fields are used depends on the type.
+type Config struct {
+	Type string `mapstructure:"type"`
+	// URL is the Slack or generic webhook URL
+	URL string `mapstructure:"url"`
+	// Email settings. The SMTP password is read from the environment variable
+	// named by PasswordEnv so it isn't stored in the config file.
+	To          []string `mapstructure:"to"`
+	From        string   `mapstructure:"from"`
+	SMTPHost    string   `mapstructure:"smtp_host"`
+	SMTPPort    int      `mapstructure:"smtp_port"`
+	Username    string   `mapstructure:"username"`
+	PasswordEnv string   `mapstructure:"password_env"`
+}
+
+// New creates the notifier for a config
+func New(config Config) (Notifier, error) {
+	switch config.Type {
+	case TypeSlack:
+		if config.URL == "" {
+			return nil, fmt.Errorf("slack notifier requires a url")
+		}
+		return &SlackNotifier{URL: config.URL}, nil
+	case TypeWebhook:
+		if config.URL == "" {
+			return nil, fmt.Errorf("webhook notifier requires a url")
+		}
+		return &WebhookNotifier{URL: config.URL}, nil
+	case TypeDesktop:
+		return &DesktopNotifier{}, nil
+	case TypeEmail:
+		return newEmailNotifier(config)
+	case TypeStdout:
+		return &WriterNotifier{Writer: os.Stdout}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type %q", config.Type)
+	}
+}
+
+// NewMulti creates a notifier that delivers to every configured destination
+func NewMulti(configs []Config) (*Multi, error) {
+	multi := &Multi{}
+	for i, config := range configs {
+		notifier, err := New(config)
+		if err != nil {
+			return nil, fmt.Errorf("notification %d: %w", i+1, err)
+		}
+		multi.Notifiers = append(multi.Notifiers, notifier)
+	}
+	return multi, nil
+}
+
+// Multi delivers messages to several notifiers
+type Multi struct {
+	Notifiers []Notifier
+}
+
+// Name lists the destinations
+func (m *Multi) Name() string {
+	return fmt.Sprintf("%d notifiers", len(m.Notifiers))
+}
+
+// Notify delivers the message to every notifier. Failing destinations don't
+// stop delivery to the others, their errors are returned together.
+func (m *Multi) Notify(ctx context.Context, msg Message) error {
+	if msg.Time.IsZero() {
+		msg.Time = time.Now()
+	}
+
+	var errs []error
+	for _, notifier := range m.Notifiers {
+		if err := notifier.Notify(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", notifier.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Empty reports whether no destinations are configured
+func (m *Multi) Empty() bool {
+	return len(m.Notifiers) == 0
+}
+
+// Only returns a notifier that delivers to the destinations of the given
+// types. It fails when a type has no destinations or isn't supported.
+func (m *Multi) Only(types []string) (*Multi, error) {
+	only := &Multi{}
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		found := false
+		for _, notifier := range m.Notifiers {
+			if notifier.Name() == t {
+				only.Notifiers = append(only.Notifiers, notifier)
+				found = true
+			}
+		}
+		if !found {
+			switch t {
+			case TypeSlack, TypeWebhook, TypeDesktop, TypeEmail, TypeStdout:
+				return nil, fmt.Errorf("no %s notifications are configured", t)
+			default:
+				return nil, fmt.Errorf("unknown notifier type %q", t)
+			}
+		}
+	}
+	return only, nil
+}
+
+// WriterNotifier writes messages as plain text, the stdout notifier
+type WriterNotifier struct {
+	Writer io.Writer
+}
+
+// Name returns "stdout"
+func (n *WriterNotifier) Name() string {
+	return TypeStdout
+}
+
+// Notify writes the message
+func (n *WriterNotifier) Notify(ctx context.Context, msg Message) error {
+	_, err := fmt.Fprintf(n.Writer, "%s\n%s\n", msg.Title, formatText(msg))
+	return err
+}
+
+// formatText renders the body and URL of a message as plain text
+func formatText(msg Message) string {
+	text := msg.Body
+	if msg.URL != "" {
+		if text != "" {
+			text += "\n"
+		}
+		text += msg.URL
+	}
+	return text
+}
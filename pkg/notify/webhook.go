@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// httpClient is shared by the webhook based notifiers
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// SlackNotifier posts messages to a Slack incoming webhook
+type SlackNotifier struct {
+	URL string
+}
+
+// Name returns "slack"
+func (n *SlackNotifier) Name() string {
+	return TypeSlack
+}
+
+// Notify posts the message as Slack mrkdwn text
+func (n *SlackNotifier) Notify(ctx context.Context, msg Message) error {
+	text := "*" + msg.Title + "*"
+	if body := formatText(msg); body != "" {
+		text += "\n" + body
+	}
+	return postJSON(ctx, n.URL, map[string]string{"text": text})
+}
+
+// WebhookNotifier posts messages as JSON to a generic webhook
+type WebhookNotifier struct {
+	URL string
+}
+
+// Name returns "webhook"
+func (n *WebhookNotifier) Name() string {
+	return TypeWebhook
+}
+
+// Notify posts the message as a JSON object with title, body, url and time fields
+func (n *WebhookNotifier) Notify(ctx context.Context, msg Message) error {
+	return postJSON(ctx, n.URL, msg)
+}
+
+// postJSON posts v as JSON and fails on non-2xx responses
+func postJSON(ctx context.Context, url string, v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
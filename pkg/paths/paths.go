@@ -0,0 +1,181 @@
+// Package paths resolves where ghi keeps its settings, logs and local state,
+// so every command finds them in the same place on every platform. Files
+// follow the XDG Base Directory layout: settings in $XDG_CONFIG_HOME/ghi, logs
+// and backups in $XDG_STATE_HOME/ghi and cached data in $XDG_CACHE_HOME/ghi.
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// appName names ghi's directory in each base directory
+const appName = "ghi"
+
+// legacyDirName is the directory in the home directory that held all of
+// ghi's files before the XDG layout
+const legacyDirName = ".ghi"
+
+// Home returns the user's home directory, $HOME on Unix and %USERPROFILE% on
+// Windows
+func Home() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine home directory: %w", err)
+	}
+	return home, nil
+}
+
+// ConfigHome returns the directory for ghi's settings: $XDG_CONFIG_HOME/ghi,
+// ~/.config/ghi by default and %AppData%\ghi on Windows
+func ConfigHome() string {
+	if dir, ok := xdgDir("XDG_CONFIG_HOME"); ok {
+		return dir
+	}
+	if runtime.GOOS == "windows" {
+		if dir, err := UserConfigDir(); err == nil {
+			return filepath.Join(dir, appName)
+		}
+	}
+	return homeDir(".config", appName)
+}
+
+// StateHome returns the directory for ghi's logs and backups:
+// $XDG_STATE_HOME/ghi, ~/.local/state/ghi by default and %LocalAppData%\ghi
+// on Windows
+func StateHome() string {
+	if dir, ok := xdgDir("XDG_STATE_HOME"); ok {
+		return dir
+	}
+	if dir, ok := localAppData(); ok {
+		return dir
+	}
+	return homeDir(".local", "state", appName)
+}
+
+// CacheHome returns the directory for data ghi can fetch again:
+// $XDG_CACHE_HOME/ghi, ~/.cache/ghi by default and %LocalAppData%\ghi\cache
+// on Windows
+func CacheHome() string {
+	if dir, ok := xdgDir("XDG_CACHE_HOME"); ok {
+		return dir
+	}
+	if dir, ok := localAppData(); ok {
+		return filepath.Join(dir, "cache")
+	}
+	return homeDir(".cache", appName)
+}
+
+// LegacyDir returns ~/.ghi, where versions before the XDG layout kept every
+// file, or an empty string when the home directory can't be determined
+func LegacyDir() string {
+	home, err := Home()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, legacyDirName)
+}
+
+// Legacy reports whether ghi's files are still in ~/.ghi because they haven't
+// been migrated to the XDG layout yet. They're used from there until they are.
+func Legacy() bool {
+	legacy := LegacyDir()
+	return legacy != "" && exists(legacy) && !exists(ConfigHome())
+}
+
+// ConfigDir returns the directory holding the config file and env files
+func ConfigDir() string {
+	if Legacy() {
+		return LegacyDir()
+	}
+	return ConfigHome()
+}
+
+// ConfigFile returns the config file used when --config isn't given
+func ConfigFile() string {
+	return filepath.Join(ConfigDir(), "config.yaml")
+}
+
+// EnvFile returns the env file of the default profile
+func EnvFile() string {
+	return filepath.Join(ConfigDir(), "env")
+}
+
+// ProfilesDir returns the directory holding the env file of each named profile
+func ProfilesDir() string {
+	return filepath.Join(ConfigDir(), "profiles")
+}
+
+// BackupsDir returns the directory settings are backed up to before a
+// migration. It's never in ~/.ghi, so backups aren't moved by the migration
+// to the XDG layout.
+func BackupsDir() string {
+	return filepath.Join(StateHome(), "backups")
+}
+
+// LogDir returns the directory log files are written to
+func LogDir() string {
+	if Legacy() {
+		return filepath.Join(LegacyDir(), "logs")
+	}
+	return filepath.Join(StateHome(), "logs")
+}
+
+// StorageDir returns the directory of ghi's local state, such as cached responses
+func StorageDir() string {
+	if Legacy() {
+		return filepath.Join(LegacyDir(), "storage")
+	}
+	return CacheHome()
+}
+
+// UserConfigDir returns the platform's directory for the settings of
+// applications: $XDG_CONFIG_HOME or ~/.config on Linux, ~/Library/Application
+// Support on macOS and %AppData% on Windows
+func UserConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine config directory: %w", err)
+	}
+	return dir, nil
+}
+
+// xdgDir returns ghi's directory in the base directory set by an XDG
+// variable. Relative paths are ignored, as the specification requires.
+func xdgDir(variable string) (string, bool) {
+	dir := os.Getenv(variable)
+	if dir == "" || !filepath.IsAbs(dir) {
+		return "", false
+	}
+	return filepath.Join(dir, appName), true
+}
+
+// localAppData returns ghi's directory in %LocalAppData% on Windows
+func localAppData() (string, bool) {
+	if runtime.GOOS != "windows" {
+		return "", false
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", false
+	}
+	return filepath.Join(dir, appName), true
+}
+
+// homeDir joins elem to the home directory. When the home directory can't be
+// determined the path is relative to the working directory.
+func homeDir(elem ...string) string {
+	home, err := Home()
+	if err != nil {
+		return filepath.Join(elem...)
+	}
+	return filepath.Join(append([]string{home}, elem...)...)
+}
+
+// exists reports whether a file or directory exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/locale"
+)
+
+// Formats a digest can be rendered in
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	// FormatSlack is Slack mrkdwn, the digest as posted to a Slack webhook
+	FormatSlack = "slack"
+)
+
+// Formats lists the formats a digest can be rendered in
+var Formats = []string{FormatMarkdown, FormatHTML, FormatSlack}
+
+// templates are the built-in digest templates, one per format
+//
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// templateFuncs are the functions digest templates can use besides the
+// template builtins:
+//
+//	date .Since          formats a date for the user's locale
+//	ago .CreatedAt       formats a time as how many days ago it was, such as "3 days ago"
+//	number .TotalMerged  formats a number for the user's locale
+//	truncate 60 .Title   shortens text to a length, ending it with "..."
+//	mrkdwn .Title        escapes text for Slack mrkdwn
+var templateFuncs = map[string]any{
+	"date":   locale.Date,
+	"ago":    locale.DaysAgo,
+	"number": locale.Number,
+	"truncate": func(length int, text string) string {
+		return gh.TruncateColumn(text, length)
+	},
+	"mrkdwn": slackEscaper.Replace,
+}
+
+// slackEscaper escapes the characters Slack uses for links and mentions
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Render writes the digest in the given format with the built-in template,
+// or with the template file at path when it isn't empty. HTML templates
+// escape the data they insert.
+func Render(w io.Writer, format, path string, digest *Digest) error {
+	if !slices.Contains(Formats, format) {
+		return fmt.Errorf("invalid report format %q, use one of %s", format, strings.Join(Formats, ", "))
+	}
+
+	var text []byte
+	var err error
+	name := "digest." + format + ".tmpl"
+	if path != "" {
+		name = filepath.Base(path)
+		text, err = os.ReadFile(path)
+	} else {
+		text, err = templates.ReadFile("templates/" + name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read report template: %w", err)
+	}
+
+	if format == FormatHTML {
+		tmpl, err := htmltemplate.New(name).Funcs(templateFuncs).Parse(string(text))
+		if err != nil {
+			return fmt.Errorf("invalid report template: %w", err)
+		}
+		return tmpl.Execute(w, digest)
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid report template: %w", err)
+	}
+	return tmpl.Execute(w, digest)
+}
@@ -0,0 +1,172 @@
+// Package report builds digests of what happened in a set of repositories,
+// combining pull requests from GitHub with the reviews logged in the
+// database, and renders them with Markdown, HTML or Slack templates.
+package report
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/jbrinkman/ghi/pkg/db"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// PR is a pull request in a digest
+type PR struct {
+	Repo      string
+	Number    int
+	Title     string
+	Author    string
+	URL       string
+	CreatedAt time.Time
+	MergedAt  time.Time
+}
+
+// RepoDigest is what happened in a repository during the period
+type RepoDigest struct {
+	Repo string
+	// Opened are the pull requests opened during the period, newest first
+	Opened []PR
+	// Merged are the pull requests merged during the period, most recently
+	// opened first
+	Merged []PR
+	// Waiting are the open pull requests, drafts aside, without any review
+	Waiting []PR
+	// Oldest are the longest open pull requests, drafts aside, oldest first
+	Oldest []PR
+	// Incomplete is true when GitHub search stopped at its limit of 1000
+	Incomplete bool
+}
+
+// Digest is what happened in a set of repositories during a period
+type Digest struct {
+	Since    time.Time
+	Until    time.Time
+	Username string
+	Repos    []RepoDigest
+	// Reviews are the reviews the user logged during the period, newest
+	// first. ReviewsIncluded is false when the database wasn't available.
+	Reviews         []db.Review
+	ReviewsIncluded bool
+}
+
+// Options choose what a digest covers
+type Options struct {
+	Repos []string
+	Since time.Time
+	Until time.Time
+	// Oldest is how many of the longest open pull requests to include, up to 100
+	Oldest int
+	// Username is whose logged reviews to include
+	Username string
+}
+
+// TotalOpened returns the number of pull requests opened in all repositories
+func (d *Digest) TotalOpened() int {
+	total := 0
+	for _, repo := range d.Repos {
+		total += len(repo.Opened)
+	}
+	return total
+}
+
+// TotalMerged returns the number of pull requests merged in all repositories
+func (d *Digest) TotalMerged() int {
+	total := 0
+	for _, repo := range d.Repos {
+		total += len(repo.Merged)
+	}
+	return total
+}
+
+// TotalWaiting returns the number of pull requests waiting for a first review
+// in all repositories
+func (d *Digest) TotalWaiting() int {
+	total := 0
+	for _, repo := range d.Repos {
+		total += len(repo.Waiting)
+	}
+	return total
+}
+
+// CollectRepo fetches what happened in a repository during the period from GitHub
+func CollectRepo(ctx context.Context, client *github.Client, repo string, opts Options) (RepoDigest, error) {
+	digest := RepoDigest{Repo: repo}
+	since := opts.Since.Format(time.DateOnly)
+
+	searches := []struct {
+		query  string
+		target *[]PR
+	}{
+		{fmt.Sprintf("repo:%s is:pr created:>=%s", repo, since), &digest.Opened},
+		{fmt.Sprintf("repo:%s is:pr is:merged merged:>=%s", repo, since), &digest.Merged},
+		{fmt.Sprintf("repo:%s is:pr is:open draft:false review:none", repo), &digest.Waiting},
+	}
+	for _, search := range searches {
+		issues, total, err := gh.SearchAllIssues(ctx, client, search.query)
+		if err != nil {
+			return digest, fmt.Errorf("error searching pull requests in %s: %w", repo, err)
+		}
+		if len(issues) < total {
+			digest.Incomplete = true
+		}
+		*search.target = digestPRs(repo, issues)
+	}
+
+	if opts.Oldest > 0 {
+		issues, err := gh.SearchOldestIssues(ctx, client, fmt.Sprintf("repo:%s is:pr is:open draft:false", repo), opts.Oldest)
+		if err != nil {
+			return digest, fmt.Errorf("error searching the oldest pull requests in %s: %w", repo, err)
+		}
+		digest.Oldest = digestPRs(repo, issues)
+	}
+
+	logger.Debug("%s: %d opened, %d merged, %d waiting since %s",
+		repo, len(digest.Opened), len(digest.Merged), len(digest.Waiting), since)
+	return digest, nil
+}
+
+// CollectReviews fetches the reviews the user logged in the repositories
+// during the period from the database
+func CollectReviews(ctx context.Context, dbClient *db.Client, opts Options) ([]db.Review, error) {
+	var reviews []db.Review
+	for _, repo := range opts.Repos {
+		logged, err := dbClient.GetReviewsByDateRange(ctx, repo, opts.Since, opts.Until)
+		if err != nil {
+			return nil, err
+		}
+		// The query covers whole days, so the exact start is checked here
+		for _, review := range logged {
+			if strings.EqualFold(review.Reviewer, opts.Username) && !review.Timestamp.Before(opts.Since) {
+				reviews = append(reviews, review)
+			}
+		}
+	}
+	sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].Timestamp.After(reviews[j].Timestamp) })
+	return reviews, nil
+}
+
+// digestPRs converts search results to the pull requests of a digest
+func digestPRs(repo string, issues []*github.Issue) []PR {
+	prs := make([]PR, 0, len(issues))
+	for _, issue := range issues {
+		pr := PR{
+			Repo:      repo,
+			Number:    issue.GetNumber(),
+			Title:     issue.GetTitle(),
+			Author:    issue.GetUser().GetLogin(),
+			URL:       issue.GetHTMLURL(),
+			CreatedAt: issue.GetCreatedAt().Time,
+		}
+		if issue.PullRequestLinks != nil {
+			pr.MergedAt = issue.PullRequestLinks.GetMergedAt().Time
+		}
+		prs = append(prs, pr)
+	}
+	return prs
+}
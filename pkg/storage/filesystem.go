@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// tempPattern names the temporary files values are written to before they
+// replace the stored value
+const tempPattern = "value-*.tmp"
+
+// FileStorage stores each value in its own file, in a directory per bucket
+type FileStorage struct {
+	dir string
+}
+
+// NewFileStorage creates a storage in the given directory, creating it if needed
+func NewFileStorage(dir string) (*FileStorage, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &FileStorage{dir: dir}, nil
+}
+
+// Get returns the value stored for key, if there is one
+func (s *FileStorage) Get(bucket, key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(s.path(bucket, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s value: %w", bucket, err)
+	}
+	return data, true, nil
+}
+
+// Put stores the value for key, replacing any existing value
+func (s *FileStorage) Put(bucket, key string, value []byte) error {
+	dir := filepath.Join(s.dir, bucket)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s bucket: %w", bucket, err)
+	}
+
+	// Write to a temporary file first so readers never see a partial value
+	tmp, err := os.CreateTemp(dir, tempPattern)
+	if err != nil {
+		return fmt.Errorf("failed to create %s value: %w", bucket, err)
+	}
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s value: %w", bucket, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s value: %w", bucket, err)
+	}
+
+	return os.Rename(tmp.Name(), s.path(bucket, key))
+}
+
+// Clear removes every value in the bucket and returns the number removed
+func (s *FileStorage) Clear(bucket string) (int, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, bucket))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s bucket: %w", bucket, err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, bucket, entry.Name())); err != nil {
+			return removed, fmt.Errorf("failed to remove %s value: %w", bucket, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Compact removes temporary files left behind by interrupted writes and
+// bucket directories that are empty
+func (s *FileStorage) Compact() (CompactResult, error) {
+	var result CompactResult
+	buckets, err := os.ReadDir(s.dir)
+	if err != nil {
+		return result, fmt.Errorf("failed to read storage directory: %w", err)
+	}
+
+	for _, bucket := range buckets {
+		if !bucket.IsDir() {
+			continue
+		}
+		dir := filepath.Join(s.dir, bucket.Name())
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return result, fmt.Errorf("failed to read %s bucket: %w", bucket.Name(), err)
+		}
+
+		remaining := len(entries)
+		for _, entry := range entries {
+			if entry.IsDir() || !isTempFile(entry.Name()) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return result, fmt.Errorf("failed to remove %s: %w", entry.Name(), err)
+			}
+			result.Removed++
+			result.Bytes += info.Size()
+			remaining--
+		}
+
+		if remaining == 0 {
+			if err := os.Remove(dir); err != nil {
+				return result, fmt.Errorf("failed to remove empty %s bucket: %w", bucket.Name(), err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// isTempFile reports whether a file name matches tempPattern
+func isTempFile(name string) bool {
+	prefix, suffix, _ := strings.Cut(tempPattern, "*")
+	return strings.HasPrefix(name, prefix) && strings.HasSuffix(name, suffix)
+}
+
+// path returns the file used to store the value for key. Keys are hashed so
+// any key is a valid file name.
+func (s *FileStorage) path(bucket, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, bucket, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,66 @@
+// Package storage keeps ghi's local state, such as cached GitHub responses, in
+// a single place managed by a backend selected in the config file.
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jbrinkman/ghi/pkg/paths"
+)
+
+// Storage backends
+const (
+	BackendFilesystem = "filesystem"
+)
+
+// Storage holds values by key in named buckets, one bucket per feature
+type Storage interface {
+	// Get returns the value stored for key, if there is one
+	Get(bucket, key string) ([]byte, bool, error)
+	// Put stores the value for key, replacing any existing value
+	Put(bucket, key string, value []byte) error
+	// Clear removes every value in the bucket and returns the number removed
+	Clear(bucket string) (int, error)
+	// Compact removes leftovers of interrupted writes and frees unused space
+	Compact() (CompactResult, error)
+}
+
+// CompactResult is what compacting the storage removed
+type CompactResult struct {
+	Removed int
+	Bytes   int64
+}
+
+// backend is the backend Open uses
+var backend = BackendFilesystem
+
+// SetBackend sets the backend used by Open. An empty name selects the default.
+func SetBackend(name string) {
+	if name == "" {
+		name = BackendFilesystem
+	}
+	backend = strings.ToLower(name)
+}
+
+// DefaultDir returns the default storage directory, the cache directory of ghi
+func DefaultDir() (string, error) {
+	if _, err := paths.Home(); err != nil {
+		return "", err
+	}
+	return paths.StorageDir(), nil
+}
+
+// Open opens the configured backend in the default storage directory
+func Open() (Storage, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+
+	switch backend {
+	case BackendFilesystem:
+		return NewFileStorage(dir)
+	}
+	return nil, fmt.Errorf("unknown storage backend %q, use %q", backend, BackendFilesystem)
+}
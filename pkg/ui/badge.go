@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+)
+
+// BadgeColors are the named badge colors, matching those of shields.io
+var BadgeColors = map[string]string{
+	"brightgreen": "#4c1",
+	"green":       "#97ca00",
+	"yellowgreen": "#a4a61d",
+	"yellow":      "#dfb317",
+	"orange":      "#fe7d37",
+	"red":         "#e05d44",
+	"blue":        "#007ec6",
+	"lightgrey":   "#9f9f9f",
+}
+
+// badgeHeight is the height of a badge in pixels
+const badgeHeight = 20
+
+// badgePadding is the space on each side of a badge's text in pixels
+const badgePadding = 6
+
+// Badge draws a flat shields-style SVG badge with the label on grey and the
+// message on the given color, either a name from BadgeColors or a hex color
+func Badge(label, message, color string) string {
+	if hex, ok := BadgeColors[color]; ok {
+		color = hex
+	}
+
+	labelWidth := textWidth(label) + 2*badgePadding
+	messageWidth := textWidth(message) + 2*badgePadding
+	width := labelWidth + messageWidth
+	title := html.EscapeString(label + ": " + message)
+	label, message = html.EscapeString(label), html.EscapeString(message)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" role="img" aria-label="%s">`, width, badgeHeight, title)
+	fmt.Fprintf(&b, `<title>%s</title>`, title)
+	b.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&b, `<clipPath id="r"><rect width="%d" height="%d" rx="3" fill="#fff"/></clipPath>`, width, badgeHeight)
+	b.WriteString(`<g clip-path="url(#r)">`)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#555"/>`, labelWidth, badgeHeight)
+	fmt.Fprintf(&b, `<rect x="%d" width="%d" height="%d" fill="%s"/>`, labelWidth, messageWidth, badgeHeight, html.EscapeString(color))
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="url(#s)"/>`, width, badgeHeight)
+	b.WriteString(`</g>`)
+	b.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	for _, text := range []struct {
+		x     float64
+		value string
+	}{
+		{float64(labelWidth) / 2, label},
+		{float64(labelWidth) + float64(messageWidth)/2, message},
+	} {
+		// The shadow sits a pixel below the text
+		fmt.Fprintf(&b, `<text x="%.1f" y="15" fill="#010101" fill-opacity=".3">%s</text>`, text.x, text.value)
+		fmt.Fprintf(&b, `<text x="%.1f" y="14">%s</text>`, text.x, text.value)
+	}
+	b.WriteString(`</g></svg>`)
+	return b.String()
+}
+
+// textWidth estimates the width in pixels of text in 11px Verdana, which is
+// close enough to size a badge without measuring the font
+func textWidth(text string) int {
+	var width float64
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune("iljtfrI.,:;!|' ", r):
+			width += 4
+		case strings.ContainsRune("mwMW%", r):
+			width += 10
+		case r >= 'A' && r <= 'Z':
+			width += 7.5
+		default:
+			width += 7
+		}
+	}
+	return int(math.Ceil(width))
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// OpenURL opens a URL in the default web browser. The BROWSER environment
+// variable chooses another browser command.
+func OpenURL(url string) error {
+	logger.Debug("Attempting to open URL: %s", url)
+
+	cmd, err := browserCommand(url)
+	if err != nil {
+		return err
+	}
+	return cmd.Start()
+}
+
+// browserCommand returns the command that opens a URL on this platform
+func browserCommand(url string) (*exec.Cmd, error) {
+	if browser := strings.Fields(os.Getenv("BROWSER")); len(browser) > 0 {
+		return exec.Command(browser[0], append(browser[1:], url)...), nil
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		// rundll32 hands the URL to the default browser without a shell, so
+		// characters such as & aren't interpreted by cmd.exe
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url), nil
+	case "darwin":
+		return exec.Command("open", url), nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		// wslview opens the Windows browser from the Windows Subsystem for
+		// Linux, where xdg-open often isn't installed
+		for _, opener := range []string{"xdg-open", "wslview"} {
+			if path, err := exec.LookPath(opener); err == nil {
+				return exec.Command(path, url), nil
+			}
+		}
+		return nil, fmt.Errorf("no browser found, install xdg-open or set BROWSER")
+	default:
+		return nil, fmt.Errorf("unsupported platform")
+	}
+}
@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// heatmapColors are the GitHub contribution graph colors, from no activity to the most
+var heatmapColors = []string{"#161b22", "#0e4429", "#006d32", "#26a641", "#39d353"}
+
+// heatmapDays labels the rows of the heatmap, only every other day is shown
+var heatmapDays = []string{"", "Mon", "", "Wed", "", "Fri", ""}
+
+const (
+	// svgCell is the size of a heatmap cell in the SVG output
+	svgCell = 10
+	// svgGap is the space between cells in the SVG output
+	svgGap = 3
+	// svgLeft and svgTop leave room for the day and month labels
+	svgLeft = 30
+	svgTop  = 20
+)
+
+// Heatmap is a year of daily activity counts laid out like the GitHub
+// contribution graph, one column per week starting on Sunday
+type Heatmap struct {
+	Year   int
+	Counts map[string]int
+	weeks  [][]time.Time
+	max    int
+}
+
+// NewHeatmap lays out the days of the year. Counts are keyed by YYYY-MM-DD.
+func NewHeatmap(year int, counts map[string]int) *Heatmap {
+	h := &Heatmap{Year: year, Counts: counts}
+
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// Start the first column on the Sunday on or before January 1st
+	day := start.AddDate(0, 0, -int(start.Weekday()))
+	for day.Before(end) {
+		week := make([]time.Time, 7)
+		for i := range week {
+			week[i] = day
+			day = day.AddDate(0, 0, 1)
+		}
+		h.weeks = append(h.weeks, week)
+	}
+
+	for _, count := range counts {
+		if count > h.max {
+			h.max = count
+		}
+	}
+	return h
+}
+
+// Total returns the number of reviews in the year
+func (h *Heatmap) Total() int {
+	total := 0
+	for day, count := range h.Counts {
+		if strings.HasPrefix(day, fmt.Sprintf("%d-", h.Year)) {
+			total += count
+		}
+	}
+	return total
+}
+
+// level buckets a day's count into one of the heatmap colors
+func (h *Heatmap) level(day time.Time) int {
+	count := h.Counts[day.Format("2006-01-02")]
+	if count == 0 || h.max == 0 {
+		return 0
+	}
+	level := (count*4 + h.max - 1) / h.max
+	if level > 4 {
+		level = 4
+	}
+	return level
+}
+
+// inYear reports whether the day belongs to the heatmap's year
+func (h *Heatmap) inYear(day time.Time) bool {
+	return day.Year() == h.Year
+}
+
+// monthLabels returns the month abbreviation to show above each week column
+func (h *Heatmap) monthLabels() []string {
+	labels := make([]string, len(h.weeks))
+	lastMonth := time.Month(0)
+	for i, week := range h.weeks {
+		for _, day := range week {
+			if h.inYear(day) && day.Day() == 1 && day.Month() != lastMonth {
+				labels[i] = day.Format("Jan")
+				lastMonth = day.Month()
+			}
+		}
+	}
+	return labels
+}
+
+// Render draws the heatmap for the terminal using colored blocks
+func (h *Heatmap) Render() string {
+	styles := make([]lipgloss.Style, len(heatmapColors))
+	for i, color := range heatmapColors {
+		styles[i] = lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+	}
+
+	var b strings.Builder
+
+	// Month labels, each week column is two characters wide
+	b.WriteString("    ")
+	labels := h.monthLabels()
+	for i := 0; i < len(labels); i++ {
+		if labels[i] != "" {
+			b.WriteString(labels[i])
+			// The three letter label spills into the next column
+			i++
+			b.WriteString(" ")
+			continue
+		}
+		b.WriteString("  ")
+	}
+	b.WriteString("\n")
+
+	for weekday := 0; weekday < 7; weekday++ {
+		b.WriteString(fmt.Sprintf("%-4s", heatmapDays[weekday]))
+		for _, week := range h.weeks {
+			day := week[weekday]
+			if !h.inYear(day) {
+				b.WriteString("  ")
+				continue
+			}
+			b.WriteString(styles[h.level(day)].Render("■") + " ")
+		}
+		b.WriteString("\n")
+	}
+
+	// Legend
+	b.WriteString("\n    Less ")
+	for _, style := range styles {
+		b.WriteString(style.Render("■") + " ")
+	}
+	b.WriteString("More\n")
+
+	return b.String()
+}
+
+// SVG renders the heatmap as a standalone SVG image
+func (h *Heatmap) SVG() string {
+	width := svgLeft + len(h.weeks)*(svgCell+svgGap)
+	height := svgTop + 7*(svgCell+svgGap)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="9">`+"\n", width, height)
+	fmt.Fprintf(&b, `  <rect width="%d" height="%d" fill="#0d1117"/>`+"\n", width, height)
+
+	for i, label := range h.monthLabels() {
+		if label != "" {
+			fmt.Fprintf(&b, `  <text x="%d" y="%d" fill="#8b949e">%s</text>`+"\n", svgLeft+i*(svgCell+svgGap), svgTop-6, label)
+		}
+	}
+	for weekday, label := range heatmapDays {
+		if label != "" {
+			fmt.Fprintf(&b, `  <text x="0" y="%d" fill="#8b949e">%s</text>`+"\n", svgTop+weekday*(svgCell+svgGap)+svgCell-1, label)
+		}
+	}
+
+	for i, week := range h.weeks {
+		for weekday, day := range week {
+			if !h.inYear(day) {
+				continue
+			}
+			key := day.Format("2006-01-02")
+			fmt.Fprintf(&b, `  <rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"><title>%s</title></rect>`+"\n",
+				svgLeft+i*(svgCell+svgGap), svgTop+weekday*(svgCell+svgGap), svgCell, svgCell,
+				heatmapColors[h.level(day)], html.EscapeString(fmt.Sprintf("%d reviews on %s", h.Counts[key], key)))
+		}
+	}
+
+	b.WriteString("</svg>\n")
+	return b.String()
+}
@@ -0,0 +1,18 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jbrinkman/ghi/pkg/colors"
+)
+
+// Hyperlink wraps text in an OSC 8 escape sequence so terminals that support it
+// render a clickable link. Other terminals ignore the sequence and show the text.
+// Like colors, the sequence is left out when stdout isn't a color terminal.
+func Hyperlink(url, text string) string {
+	if url == "" || !colors.Enabled(os.Stdout) {
+		return text
+	}
+	return fmt.Sprintf("\033]8;;%s\033\\%s\033]8;;\033\\", url, text)
+}
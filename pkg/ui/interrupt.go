@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ErrInterrupted is the cause of a context cancelled by Ctrl+C
+var ErrInterrupted = errors.New("interrupted")
+
+// interruptKey holds the function that cancels an interruptible context
+type interruptKey struct{}
+
+// WithInterrupt returns a context that is cancelled when the user presses
+// Ctrl+C, whether it arrives as a signal or as a key press in a spinner.
+// Once interrupted, a second Ctrl+C stops the program right away.
+func WithInterrupt(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	ctx = context.WithValue(ctx, interruptKey{}, cancel)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			cancel(ErrInterrupted)
+		case <-ctx.Done():
+		}
+		signal.Stop(signals)
+	}()
+
+	return ctx, func() { cancel(context.Canceled) }
+}
+
+// Interrupt cancels a context returned by WithInterrupt as Ctrl+C would
+func Interrupt(ctx context.Context) {
+	if cancel, ok := ctx.Value(interruptKey{}).(context.CancelCauseFunc); ok {
+		cancel(ErrInterrupted)
+	}
+}
+
+// Interrupted reports whether the user pressed Ctrl+C
+func Interrupted(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrInterrupted)
+}
+
+// stdin is shared by the prompts so input typed ahead isn't lost between them
+var stdin = bufio.NewReader(os.Stdin)
+
+// ReadLine reads a line typed at a prompt. Ctrl+C interrupts ctx while the
+// terminal is waiting for input, so it returns ErrInterrupted then instead
+// of leaving the prompt hanging.
+func ReadLine(ctx context.Context) (string, error) {
+	type result struct {
+		line string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		line, err := stdin.ReadString('\n')
+		done <- result{line: line, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.line, res.err
+	case <-ctx.Done():
+		return "", context.Cause(ctx)
+	}
+}
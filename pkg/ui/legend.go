@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// LegendEntry explains a marker, color or key of the pull request tables
+type LegendEntry struct {
+	Symbol  string
+	Meaning string
+}
+
+// LegendSection is a titled group of legend entries
+type LegendSection struct {
+	Title   string
+	Entries []LegendEntry
+}
+
+// Legend explains what the markers, colors and keys of the pull request
+// tables mean. It's shown with ? in the interactive table and by
+// 'ghi help symbols'.
+var Legend = []LegendSection{
+	{Title: "Markers", Entries: []LegendEntry{
+		{"[X]", "Draft column: the pull request is a draft"},
+		{"", "Reviewer column: one of the --reviewers has reviewed it"},
+		{"[ ]", "Draft column: the pull request is ready for review"},
+		{"", "Reviewer column: none of the --reviewers has reviewed it yet"},
+		{"new", "Age column: opened within the fresh period of the review SLA"},
+		{"12d !", "Age column: open longer than the review SLA"},
+		{"1/2 ✓", "Approvals column: approvals out of those the base branch requires, ✓ once met"},
+		{"✓", "Signed column: every commit has a verified signature"},
+		{"✗", "Signed column: some commits aren't verified"},
+		{"conflicts", "Merge column: the pull request has merge conflicts"},
+		{"*", "Number column with --watch: changed since the last refresh"},
+		{"STALE", "ghi review: new commits since your last review"},
+	}},
+	{Title: "Colors", Entries: []LegendEntry{
+		{"red #", "Past the review SLA"},
+		{"gray #", "A draft"},
+		{"green #", "Opened within the fresh period of the review SLA"},
+		{"labels", "Shown on their GitHub colors in the details and the plain table"},
+		{"", "Colors are left out with --no-color, NO_COLOR or when output isn't a terminal"},
+	}},
+	{Title: "Keys", Entries: []LegendEntry{
+		{"↑/↓", "Move between pull requests"},
+		{"enter", "Show the details of the selected pull request"},
+		{"esc", "Go back to the table"},
+		{"g", "Switch to a repository alias, group or view from the config file"},
+		{"r", "Refresh now with --watch"},
+		{"?", "Show or hide this legend"},
+		{"x", "Hide the tip about the legend for good"},
+		{"q", "Quit"},
+	}},
+}
+
+// LegendText renders the legend as aligned plain text
+func LegendText() string {
+	var b strings.Builder
+	for i, section := range Legend {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(section.Title + ":\n")
+		w := tabwriter.NewWriter(&b, 0, 0, 3, ' ', 0)
+		for _, entry := range section.Entries {
+			fmt.Fprintf(w, "  %s\t%s\n", entry.Symbol, entry.Meaning)
+		}
+		w.Flush()
+	}
+	return b.String()
+}
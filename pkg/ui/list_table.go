@@ -0,0 +1,371 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// ListColumn is a column of a ListTable
+type ListColumn struct {
+	Title string
+	Width int
+}
+
+// ListRow is a row of a ListTable. URL is opened in the browser with the o key.
+type ListRow struct {
+	Cells []string
+	URL   string
+	// SortKeys are compared instead of the cells when sorting, for cells such
+	// as localized dates that don't sort as text. A missing or empty key sorts
+	// by the cell.
+	SortKeys []string
+	// Item is passed to the actions run on the row
+	Item any
+}
+
+// ListAction is a key that runs an action on the selected row of a ListTable,
+// such as posting a comment. Run happens in the background and returns the
+// message to show when it's done.
+type ListAction struct {
+	Key  string
+	Help string
+	// Confirm is a question asked before running the action, such as
+	// "Post a comment on #12?". Actions without it run right away.
+	Confirm func(row ListRow) string
+	Run     func(row ListRow) (string, error)
+}
+
+// listActionDoneMsg carries the outcome of an action into the model
+type listActionDoneMsg struct {
+	status string
+	err    error
+}
+
+// ListTableModel is an interactive table of rows that can be sorted by any
+// column, filtered by text and opened in the browser
+type ListTableModel struct {
+	table   table.Model
+	columns []ListColumn
+	rows    []ListRow
+	// visible are the rows that match the filter, in the sort order
+	visible []ListRow
+
+	// sortColumn is the index of the column rows are sorted by, or -1 to
+	// keep the order they were given in
+	sortColumn int
+	sortDesc   bool
+
+	filter    textinput.Model
+	filtering bool
+
+	actions []ListAction
+	// pending is the action waiting for the user to confirm it on pendingRow
+	pending    *ListAction
+	pendingRow ListRow
+
+	// status is a message about the last action, such as a failure to open a URL
+	status string
+}
+
+// NewListTable creates an interactive table of the rows with the given columns
+func NewListTable(columns []ListColumn, rows []ListRow) *ListTableModel {
+	logger.Debug("Creating new list table with %d rows", len(rows))
+
+	t := table.New(
+		table.WithFocused(true),
+		table.WithHeight(20),
+	)
+
+	s := table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(true)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57")).
+		Bold(false)
+	t.SetStyles(s)
+
+	filter := textinput.New()
+	filter.Prompt = "/"
+	filter.Placeholder = "filter"
+
+	m := &ListTableModel{
+		table:      t,
+		columns:    columns,
+		rows:       rows,
+		sortColumn: -1,
+		filter:     filter,
+	}
+	m.updateRows()
+	return m
+}
+
+// WithActions adds keys that run actions on the selected row
+func (m *ListTableModel) WithActions(actions ...ListAction) *ListTableModel {
+	m.actions = append(m.actions, actions...)
+	return m
+}
+
+// updateRows applies the filter and sort order and refreshes the table
+func (m *ListTableModel) updateRows() {
+	query := strings.ToLower(strings.TrimSpace(m.filter.Value()))
+	m.visible = m.visible[:0]
+	for _, row := range m.rows {
+		if query == "" || strings.Contains(strings.ToLower(strings.Join(row.Cells, " ")), query) {
+			m.visible = append(m.visible, row)
+		}
+	}
+
+	if m.sortColumn >= 0 {
+		col := m.sortColumn
+		sort.SliceStable(m.visible, func(i, j int) bool {
+			a, b := sortKey(m.visible[i], col), sortKey(m.visible[j], col)
+			if m.sortDesc {
+				return lessCell(b, a)
+			}
+			return lessCell(a, b)
+		})
+	}
+
+	tableRows := make([]table.Row, 0, len(m.visible))
+	for _, row := range m.visible {
+		tableRows = append(tableRows, table.Row(row.Cells))
+	}
+
+	// Rows must be cleared before the columns change, the titles carry the sort marker
+	m.table.SetRows(nil)
+	m.table.SetColumns(m.tableColumns())
+	m.table.SetRows(tableRows)
+	if m.table.Cursor() >= len(tableRows) {
+		m.table.SetCursor(max(len(tableRows)-1, 0))
+	}
+}
+
+// tableColumns returns the columns with the sort marker on the sorted column
+func (m *ListTableModel) tableColumns() []table.Column {
+	columns := make([]table.Column, 0, len(m.columns))
+	for i, column := range m.columns {
+		title := column.Title
+		if i == m.sortColumn {
+			if m.sortDesc {
+				title += " ↓"
+			} else {
+				title += " ↑"
+			}
+		}
+		columns = append(columns, table.Column{Title: title, Width: column.Width})
+	}
+	return columns
+}
+
+// cell returns a cell of the row, or an empty string if the row is short
+func cell(row ListRow, i int) string {
+	if i < len(row.Cells) {
+		return row.Cells[i]
+	}
+	return ""
+}
+
+// sortKey returns the value a row is sorted by in a column
+func sortKey(row ListRow, i int) string {
+	if i < len(row.SortKeys) && row.SortKeys[i] != "" {
+		return row.SortKeys[i]
+	}
+	return cell(row, i)
+}
+
+// lessCell compares cells as numbers when both are, ignoring a leading #,
+// and as case-insensitive text otherwise
+func lessCell(a, b string) bool {
+	na, errA := strconv.ParseFloat(strings.TrimPrefix(a, "#"), 64)
+	nb, errB := strconv.ParseFloat(strings.TrimPrefix(b, "#"), 64)
+	if errA == nil && errB == nil {
+		return na < nb
+	}
+	return strings.ToLower(a) < strings.ToLower(b)
+}
+
+func (m *ListTableModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *ListTableModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if m.filtering {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch msg.String() {
+			case "enter":
+				m.filtering = false
+				m.filter.Blur()
+				m.table.Focus()
+				return m, nil
+			case "esc":
+				m.filtering = false
+				m.filter.Blur()
+				m.filter.SetValue("")
+				m.table.Focus()
+				m.updateRows()
+				return m, nil
+			case "ctrl+c":
+				return m, tea.Quit
+			}
+		}
+		m.filter, cmd = m.filter.Update(msg)
+		m.updateRows()
+		return m, cmd
+	}
+
+	if m.pending != nil {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			action, row := m.pending, m.pendingRow
+			m.pending = nil
+			if msg.String() == "y" || msg.String() == "Y" {
+				return m, m.runAction(action, row)
+			}
+			m.status = "Cancelled"
+			return m, nil
+		}
+	}
+
+	switch msg := msg.(type) {
+	case listActionDoneMsg:
+		m.status = msg.status
+		if msg.err != nil {
+			m.status = msg.err.Error()
+		}
+		return m, nil
+	case tea.WindowSizeMsg:
+		// Leave room for the header, the filter and the footer
+		m.table.SetHeight(max(msg.Height-6, 3))
+	case tea.KeyMsg:
+		m.status = ""
+		for i := range m.actions {
+			if msg.String() == m.actions[i].Key {
+				return m, m.startAction(&m.actions[i])
+			}
+		}
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "/":
+			m.filtering = true
+			m.table.Blur()
+			return m, m.filter.Focus()
+		case "esc":
+			if m.filter.Value() != "" {
+				m.filter.SetValue("")
+				m.updateRows()
+			}
+			return m, nil
+		case "s":
+			// Cycle through the columns, then back to the original order
+			m.sortColumn++
+			if m.sortColumn >= len(m.columns) {
+				m.sortColumn = -1
+			}
+			m.updateRows()
+			return m, nil
+		case "S":
+			m.sortDesc = !m.sortDesc
+			m.updateRows()
+			return m, nil
+		case "o":
+			m.openSelected()
+			return m, nil
+		}
+	}
+
+	m.table, cmd = m.table.Update(msg)
+	return m, cmd
+}
+
+// openSelected opens the URL of the selected row in the browser
+func (m *ListTableModel) openSelected() {
+	row, ok := m.selectedRow()
+	if !ok {
+		return
+	}
+	url := row.URL
+	if url == "" {
+		m.status = "The selected row has no link"
+		return
+	}
+	if err := OpenURL(url); err != nil {
+		logger.Debug("Failed to open %s: %v", url, err)
+		m.status = fmt.Sprintf("Failed to open browser: %v", err)
+		return
+	}
+	m.status = "Opened " + url
+}
+
+// selectedRow returns the selected row, or false when no row is visible
+func (m *ListTableModel) selectedRow() (ListRow, bool) {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.visible) {
+		return ListRow{}, false
+	}
+	return m.visible[cursor], true
+}
+
+// startAction runs an action on the selected row, or asks to confirm it first
+func (m *ListTableModel) startAction(action *ListAction) tea.Cmd {
+	row, ok := m.selectedRow()
+	if !ok {
+		return nil
+	}
+	if action.Confirm != nil {
+		m.pending, m.pendingRow = action, row
+		m.status = action.Confirm(row) + " (y/n)"
+		return nil
+	}
+	return m.runAction(action, row)
+}
+
+// runAction runs an action in the background so the table stays responsive
+func (m *ListTableModel) runAction(action *ListAction, row ListRow) tea.Cmd {
+	m.status = action.Help + "..."
+	run := action.Run
+	return func() tea.Msg {
+		status, err := run(row)
+		if err != nil {
+			logger.Debug("Action %s failed: %v", action.Key, err)
+		}
+		return listActionDoneMsg{status: status, err: err}
+	}
+}
+
+func (m *ListTableModel) View() string {
+	var b strings.Builder
+	b.WriteString("\n" + m.table.View() + "\n")
+
+	if m.filtering || m.filter.Value() != "" {
+		b.WriteString(m.filter.View())
+		b.WriteString(fmt.Sprintf("  (%d of %d)\n", len(m.visible), len(m.rows)))
+	}
+	if m.status != "" {
+		b.WriteString(m.status + "\n")
+	}
+
+	if m.filtering {
+		b.WriteString("enter: Apply • esc: Clear\n")
+	} else {
+		help := "↑/↓: Navigate • o: Open"
+		for _, action := range m.actions {
+			help += fmt.Sprintf(" • %s: %s", action.Key, action.Help)
+		}
+		b.WriteString(help + " • s: Sort • S: Reverse • /: Filter • q: Quit\n")
+	}
+	return b.String()
+}
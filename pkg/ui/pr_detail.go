@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jbrinkman/ghi/pkg/colors"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/locale"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// PRDetailLoader fetches the detail of a pull request when it is opened in the table
+type PRDetailLoader func(pr *gh.PullRequestData) (*gh.PRDetail, error)
+
+// prDetailLoadedMsg carries a fetched detail, or the error that prevented it,
+// into the table model
+type prDetailLoadedMsg struct {
+	key    string
+	detail *gh.PRDetail
+	err    error
+}
+
+var (
+	detailTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("229"))
+	detailLabelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	checkPassStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	checkFailStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	checkOtherStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+)
+
+// renderPRDetail renders the header fields and markdown body of a pull request.
+// The detail may be nil while it is still being fetched.
+func renderPRDetail(pr *gh.PullRequestData, detail *gh.PRDetail, width int, darkBackground bool) string {
+	var b strings.Builder
+
+	b.WriteString(detailTitleStyle.Render(fmt.Sprintf("%s #%d  %s", pr.RepoFullName(), pr.Number(), pr.Title())))
+	b.WriteString("\n\n")
+
+	created := pr.CreatedAt()
+	state := pr.State()
+	if pr.IsDraft {
+		state += " (draft)"
+	}
+	writeField(&b, "Author", fmt.Sprintf("%s (%s)", pr.Author(), gh.FormatAssociation(pr.AuthorAssociation())))
+	writeField(&b, "Opened", fmt.Sprintf("%s (%s)", locale.Date(created), locale.DaysAgo(created)))
+	writeField(&b, "State", state)
+
+	if len(pr.Labels) > 0 {
+		writeField(&b, "Labels", gh.FormatLabels(pr.Labels))
+	}
+	if pr.StackedOn != 0 {
+		writeField(&b, "Stacked", fmt.Sprintf("on #%d", pr.StackedOn))
+	}
+	if len(pr.Dependencies) > 0 {
+		var deps []string
+		for _, dep := range pr.Dependencies {
+			deps = append(deps, fmt.Sprintf("%s (%s)", dep.Ref, dep.State))
+		}
+		writeField(&b, "Depends", strings.Join(deps, ", "))
+	}
+
+	if detail == nil {
+		writeField(&b, "Reviews", "Loading...")
+		writeField(&b, "Checks", "Loading...")
+	} else {
+		pull := detail.PullRequest
+		writeField(&b, "Branch", fmt.Sprintf("%s → %s", pull.GetHead().GetLabel(), pull.GetBase().GetRef()))
+		writeField(&b, "Changes", fmt.Sprintf("+%s -%s in %s files (%s)",
+			locale.Number(pull.GetAdditions()), locale.Number(pull.GetDeletions()), locale.Number(pull.GetChangedFiles()), pr.Size()))
+		writeField(&b, "Reviews", reviewSummary(detail))
+		writeField(&b, "Checks", checkSummary(detail))
+	}
+
+	b.WriteString("\n")
+	b.WriteString(renderMarkdown(pr.Body(), width, darkBackground))
+	return b.String()
+}
+
+// writeField writes a labelled line of the detail header
+func writeField(b *strings.Builder, label, value string) {
+	b.WriteString(detailLabelStyle.Render(fmt.Sprintf("%-9s", label+":")))
+	b.WriteString(" " + value + "\n")
+}
+
+// reviewSummary describes the approvals and change requests on a pull request
+func reviewSummary(detail *gh.PRDetail) string {
+	if len(detail.Reviews) == 0 {
+		return "none"
+	}
+	summary := fmt.Sprintf("%d reviews, %d approvals", len(detail.Reviews), detail.Approvals)
+	if len(detail.ChangesRequested) > 0 {
+		summary += ", changes requested by " + strings.Join(detail.ChangesRequested, ", ")
+	}
+	return summary
+}
+
+// checkSummary lists the check runs on the pull request's head commit
+func checkSummary(detail *gh.PRDetail) string {
+	if detail.ChecksErr != nil {
+		return "unavailable"
+	}
+	if len(detail.Checks) == 0 {
+		return "none"
+	}
+
+	var checks []string
+	for _, check := range detail.Checks {
+		switch {
+		case check.GetStatus() != "completed":
+			checks = append(checks, checkOtherStyle.Render("… "+check.GetName()))
+		case check.GetConclusion() == "success" || check.GetConclusion() == "neutral" || check.GetConclusion() == "skipped":
+			checks = append(checks, checkPassStyle.Render("✓ "+check.GetName()))
+		default:
+			checks = append(checks, checkFailStyle.Render("✗ "+check.GetName()))
+		}
+	}
+	return strings.Join(checks, "  ")
+}
+
+// renderMarkdown renders a pull request body for the terminal, falling back
+// to the raw text if it can't be rendered
+func renderMarkdown(body string, width int, darkBackground bool) string {
+	if strings.TrimSpace(body) == "" {
+		return detailLabelStyle.Render("No description provided.") + "\n"
+	}
+
+	style := "light"
+	if darkBackground {
+		style = "dark"
+	}
+	if !colors.Enabled(os.Stdout) {
+		style = "notty"
+	}
+	renderer, err := glamour.NewTermRenderer(
+		glamour.WithStandardStyle(style),
+		glamour.WithWordWrap(width-4),
+	)
+	if err == nil {
+		var out string
+		if out, err = renderer.Render(body); err == nil {
+			return out
+		}
+	}
+	logger.Debug("Failed to render pull request body: %v", err)
+	return body + "\n"
+}
@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/logger"
+)
+
+// Kinds of PR sources
+const (
+	SourceRepo  = "repo"
+	SourceGroup = "group"
+	SourceView  = "view"
+)
+
+// PRSource is a configured set of pull requests the table can switch to:
+// a repository alias, a group of repositories or a saved view
+type PRSource struct {
+	Kind string
+	Name string
+	// Description is shown next to the name, such as the repositories or flags
+	Description string
+}
+
+// Label names the source in the footer
+func (s PRSource) Label() string {
+	return s.Kind + " " + s.Name
+}
+
+// PRSourceLoader fetches the pull requests of a source chosen in the table
+type PRSourceLoader func(source PRSource) ([]*gh.PullRequestData, error)
+
+// prSourceLoadedMsg carries the pull requests of a chosen source, or the
+// error that prevented loading them, into the table model
+type prSourceLoadedMsg struct {
+	source PRSource
+	items  []*gh.PullRequestData
+	err    error
+}
+
+// WithSourcePicker enables the source picker. Pressing g opens a list of the
+// sources and choosing one replaces the pull requests in the table with the
+// source's, loaded in the background.
+func (m *PRTableModel) WithSourcePicker(sources []PRSource, loader PRSourceLoader) *PRTableModel {
+	m.sources = sources
+	m.loadSource = loader
+
+	rows := make([]table.Row, 0, len(sources))
+	nameWidth := len("Name")
+	for _, source := range sources {
+		rows = append(rows, table.Row{source.Kind, source.Name, source.Description})
+		nameWidth = max(nameWidth, len(source.Name))
+	}
+
+	m.picker = table.New(
+		table.WithColumns([]table.Column{
+			{Title: "Type", Width: 5},
+			{Title: "Name", Width: nameWidth},
+			{Title: "Shows", Width: 60},
+		}),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithHeight(min(len(rows)+1, 15)),
+	)
+	s := table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(true)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57")).
+		Bold(false)
+	m.picker.SetStyles(s)
+	return m
+}
+
+// WithSource names the source of the pull requests shown at first
+func (m *PRTableModel) WithSource(label string) *PRTableModel {
+	m.source = label
+	return m
+}
+
+// updatePicker handles messages while the source picker is open
+func (m *PRTableModel) updatePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "esc", "g":
+		m.picking = false
+		return m, nil
+	case "enter":
+		m.picking = false
+		cursor := m.picker.Cursor()
+		if cursor < 0 || cursor >= len(m.sources) {
+			return m, nil
+		}
+		return m, m.switchSource(m.sources[cursor])
+	}
+
+	var cmd tea.Cmd
+	m.picker, cmd = m.picker.Update(msg)
+	return m, cmd
+}
+
+// switchSource loads the pull requests of a source in the background
+func (m *PRTableModel) switchSource(source PRSource) tea.Cmd {
+	logger.Debug("Switching to %s", source.Label())
+	m.switching = true
+	m.switchErr = nil
+	loader := m.loadSource
+	return func() tea.Msg {
+		items, err := loader(source)
+		return prSourceLoadedMsg{source: source, items: items, err: err}
+	}
+}
+
+// sourceLoaded replaces the pull requests shown with those of the chosen source
+func (m *PRTableModel) sourceLoaded(msg prSourceLoadedMsg) tea.Cmd {
+	m.switching = false
+	if msg.err != nil {
+		logger.Debug("Loading %s failed: %v", msg.source.Label(), msg.err)
+		m.switchErr = msg.err
+	} else {
+		logger.Debug("Loaded %d pull requests from %s", len(msg.items), msg.source.Label())
+		m.source = msg.source.Label()
+		// The rows aren't changes, they're a different set of pull requests
+		m.changed = nil
+		m.details = make(map[string]*gh.PRDetail)
+		m.UpdatePRs(msg.items)
+		m.table.SetCursor(0)
+		m.lastRefresh = time.Now()
+	}
+
+	// Refresh ticks are skipped while switching, so watch mode schedules the
+	// next one. It replaces a tick still pending from before the switch, which
+	// is then ignored, so each switch doesn't add a chain of refreshes.
+	if m.refresh != nil {
+		return m.tickCmd()
+	}
+	return nil
+}
+
+// sourceStatus describes the source shown and any switch in progress for the footer
+func (m *PRTableModel) sourceStatus() string {
+	switch {
+	case m.switching:
+		return "Loading..."
+	case m.switchErr != nil:
+		return fmt.Sprintf("Showing %s • Switching failed: %v", m.source, m.switchErr)
+	case m.source != "":
+		return "Showing " + m.source
+	}
+	return ""
+}
+
+// sourceHelp returns the footer hint for the source picker, if it is enabled
+func (m *PRTableModel) sourceHelp() string {
+	if m.loadSource == nil || len(m.sources) == 0 {
+		return ""
+	}
+	return "g: Switch • "
+}
@@ -6,46 +6,162 @@ import (
 	"time"
 
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jbrinkman/ghi/pkg/logger"
 	gh "github.com/jbrinkman/ghi/pkg/github"
+	"github.com/jbrinkman/ghi/pkg/locale"
 )
 
 type PRTableModel struct {
-	table  table.Model
+	table   table.Model
 	prData  []*gh.PullRequestData
 	loading bool
 	err     error
+
+	// Watch mode state, only used when a refresh function is set
+	refresh     func() ([]*gh.PullRequestData, error)
+	interval    time.Duration
+	refreshing  bool
+	refreshErr  error
+	lastRefresh time.Time
+	changed     map[string]bool
+	onChange    func(changed []*gh.PullRequestData)
+
+	// tickGeneration identifies the pending refresh tick
+	tickGeneration int
+
+	// Detail view state, only used when a detail loader is set
+	loadDetail     PRDetailLoader
+	details        map[string]*gh.PRDetail
+	detailPR       *gh.PullRequestData
+	detailErr      error
+	viewport       viewport.Model
+	width          int
+	height         int
+	darkBackground bool
+
+	// columns are the columns shown and columnOptions how they're formatted
+	columns       []gh.Column
+	columnOptions gh.ColumnOptions
+
+	// asOf is the past time the PRs are shown as of, the zero time for now
+	asOf time.Time
+
+	// Source picker state, only used when a source loader is set
+	sources    []PRSource
+	loadSource PRSourceLoader
+	picker     table.Model
+	picking    bool
+	source     string
+	switching  bool
+	switchErr  error
+
+	// showLegend is set while the legend of markers, colors and keys is shown
+	showLegend bool
+	// hints shows a tip about the legend until it's dismissed with x, which
+	// calls onDismissHints so it stays hidden
+	hints          bool
+	onDismissHints func()
+}
+
+// refreshTickMsg is sent when it's time to refresh the PR data in watch mode.
+// It carries the generation of the tick that scheduled it, so ticks replaced
+// by a newer one are ignored.
+type refreshTickMsg struct {
+	generation int
+}
+
+// PRsRefreshedMsg carries refreshed PR data, or the error that prevented
+// the refresh, into the table model
+type PRsRefreshedMsg struct {
+	Items []*gh.PullRequestData
+	Err   error
+}
+
+// cellPadding is the space the table puts around each cell
+const cellPadding = 2
+
+// minFitWidth is the narrowest a column that fits its values is made
+const minFitWidth = 20
+
+// hintStyle dims the tips shown to new users
+var hintStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+// changedMarker prefixes the number of PRs that changed since the last refresh
+const changedMarker = "*"
+
+// prKey identifies a PR across repositories
+func prKey(pr *gh.PullRequestData) string {
+	return fmt.Sprintf("%s#%d", pr.RepoFullName(), pr.Number())
 }
 
-// createTableRows converts PR data to table rows
-func createTableRows(prData []*gh.PullRequestData) []table.Row {
+// createTableRows converts PR data to table rows with the given columns,
+// marking the numbers of the PRs in changed. Cells can't hold colors, they
+// would break the column widths, so labels are colored in the detail view.
+func createTableRows(prData []*gh.PullRequestData, changed map[string]bool, columns []gh.Column, opts gh.ColumnOptions) []table.Row {
+	opts.Color = false
+
 	var rows []table.Row
 	for _, pr := range prData {
-		if pr == nil || pr.Issue == nil || pr.Issue.Number == nil {
+		if !pr.Valid() {
 			continue
 		}
 
-		author := "unknown"
-		if pr.Issue.User != nil && pr.Issue.User.Login != nil {
-			author = *pr.Issue.User.Login
+		row := make(table.Row, 0, len(columns))
+		for _, column := range columns {
+			value := column.Value(pr, opts)
+			if column.Name == "number" && changed[prKey(pr)] {
+				value = changedMarker + value
+			}
+			row = append(row, value)
 		}
+		rows = append(rows, row)
+	}
+	return rows
+}
 
-		reviewStatus := ""
-		if pr.ReviewerStatus != "" {
-			reviewStatus = pr.ReviewerStatus
+// tableColumns converts column definitions to the interactive table's
+// columns. Columns with a width of 0 are widened to fit their widest value,
+// as far as the terminal width leaves room for them.
+func tableColumns(columns []gh.Column, rows []table.Row, termWidth int) []table.Column {
+	converted := make([]table.Column, 0, len(columns))
+	fixed, fitting := 0, 0
+	for _, column := range columns {
+		if column.Width > 0 {
+			fixed += column.Width + cellPadding
+		} else {
+			fitting++
 		}
+	}
 
-		rows = append(rows, table.Row{
-			fmt.Sprintf("#%d", *pr.Issue.Number),
-			truncateString(*pr.Issue.Title, 35),
-			truncateString(author, 12),
-			*pr.Issue.State,
-			reviewStatus,
-		})
+	for i, column := range columns {
+		width := column.Width
+		if width == 0 {
+			width = lipgloss.Width(column.Title)
+			for _, row := range rows {
+				if i < len(row) {
+					width = max(width, lipgloss.Width(row[i]))
+				}
+			}
+			// Share the room left by the other columns, keeping a usable width
+			if room := (termWidth-fixed)/fitting - cellPadding; width > room {
+				width = max(room, minFitWidth)
+			}
+		}
+		converted = append(converted, table.Column{Title: column.Title, Width: width})
 	}
-	return rows
+	return converted
+}
+
+// setRows replaces the rows of the table, resizing the columns that fit
+// their values. Rows must be cleared before the columns change, or rows with
+// more cells than columns would be rendered.
+func (m *PRTableModel) setRows(rows []table.Row) {
+	m.table.SetRows(nil)
+	m.table.SetColumns(tableColumns(m.columns, rows, m.width))
+	m.table.SetRows(rows)
 }
 
 // NewPRTable creates a new Bubble Tea model for displaying PRs in a table
@@ -53,10 +169,10 @@ func NewPRTable(prData []*gh.PullRequestData) *PRTableModel {
 	// Debug logging
 	logger.Debug("Creating new PR table with %d items", len(prData))
 	for i, pr := range prData {
-		if pr == nil || pr.Issue == nil || pr.Issue.Number == nil {
+		if !pr.Valid() {
 			logger.Debug("  PR <nil or invalid>")
 		} else {
-			logger.Debug("  PR #%d: %s", *pr.Issue.Number, *pr.Issue.Title)
+			logger.Debug("  PR #%d: %s", pr.Number(), pr.Title())
 		}
 		if i >= 4 { // Only show first 5 items to avoid log spam
 			logger.Debug("  ... and %d more items", len(prData)-5)
@@ -64,17 +180,13 @@ func NewPRTable(prData []*gh.PullRequestData) *PRTableModel {
 		}
 	}
 
-	columns := []table.Column{
-		{Title: "#", Width: 5},
-		{Title: "Title", Width: 40},
-		{Title: "Author", Width: 15},
-		{Title: "State", Width: 8},
-		{Title: "Reviews", Width: 12},
-	}
+	// Every column but draft is shown until WithColumns chooses them
+	columns, _ := gh.LookupColumns(gh.DefaultColumnNames(false, true, true, true, true))
+	opts := gh.ColumnOptions{SLA: gh.DefaultSLA}
 
 	t := table.New(
-		table.WithColumns(columns),
-		table.WithRows(createTableRows(prData)),
+		table.WithColumns(tableColumns(columns, nil, 80)),
+		table.WithRows(createTableRows(prData, nil, columns, opts)),
 		table.WithFocused(true),
 		table.WithHeight(20),
 	)
@@ -95,7 +207,105 @@ func NewPRTable(prData []*gh.PullRequestData) *PRTableModel {
 		table:   t,
 		prData:  prData,
 		loading: false,
+		details: make(map[string]*gh.PRDetail),
+		width:   80,
+		height:  24,
+		// Detect the background before the program takes over the terminal
+		darkBackground: lipgloss.HasDarkBackground(),
+		columns:        columns,
+		columnOptions:  opts,
+	}
+}
+
+// WithColumns sets the columns shown and how their values are formatted
+func (m *PRTableModel) WithColumns(columns []gh.Column, opts gh.ColumnOptions) *PRTableModel {
+	m.columns = columns
+	m.columnOptions = opts
+
+	m.setRows(createTableRows(m.prData, m.changed, m.columns, m.columnOptions))
+	return m
+}
+
+// WithDetailLoader enables the detail view. Pressing enter on a row opens the
+// pull request's detail, which is fetched in the background the first time.
+func (m *PRTableModel) WithDetailLoader(loader PRDetailLoader) *PRTableModel {
+	m.loadDetail = loader
+	return m
+}
+
+// WithHints shows a tip about the legend below the table for new users.
+// Dismissing it with x calls onDismiss, which can record it in the config.
+func (m *PRTableModel) WithHints(onDismiss func()) *PRTableModel {
+	m.hints = true
+	m.onDismissHints = onDismiss
+	return m
+}
+
+// WithAsOf notes in the footer that the PRs are shown as of a past time
+func (m *PRTableModel) WithAsOf(asOf time.Time) *PRTableModel {
+	m.asOf = asOf
+	return m
+}
+
+// selectedPR returns the PR of the selected row, or nil if there isn't one
+func (m *PRTableModel) selectedPR() *gh.PullRequestData {
+	cursor := m.table.Cursor()
+	row := 0
+	for _, pr := range m.prData {
+		if !pr.Valid() {
+			continue
+		}
+		if row == cursor {
+			return pr
+		}
+		row++
+	}
+	return nil
+}
+
+// openDetail shows the detail view for a PR, fetching its detail if it
+// hasn't been fetched yet
+func (m *PRTableModel) openDetail(pr *gh.PullRequestData) tea.Cmd {
+	m.detailPR = pr
+	m.detailErr = nil
+	m.viewport = viewport.New(m.width, m.height-2)
+	m.renderDetail()
+
+	key := prKey(pr)
+	if _, ok := m.details[key]; ok {
+		return nil
 	}
+
+	logger.Debug("Fetching detail for %s", key)
+	loader := m.loadDetail
+	return func() tea.Msg {
+		detail, err := loader(pr)
+		return prDetailLoadedMsg{key: key, detail: detail, err: err}
+	}
+}
+
+// renderDetail renders the open PR into the detail viewport
+func (m *PRTableModel) renderDetail() {
+	content := renderPRDetail(m.detailPR, m.details[prKey(m.detailPR)], m.width, m.darkBackground)
+	if m.detailErr != nil {
+		content += fmt.Sprintf("\nFailed to load details: %v\n", m.detailErr)
+	}
+	m.viewport.SetContent(content)
+}
+
+// updateDetail handles messages while the detail view is open
+func (m *PRTableModel) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "esc", "backspace":
+		m.detailPR = nil
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
 }
 
 // Init initializes the table model
@@ -106,21 +316,204 @@ func (m *PRTableModel) Init() tea.Cmd {
 	} else {
 		logger.Debug("Table has no rows")
 	}
+	if m.refresh != nil {
+		return m.tickCmd()
+	}
 	return nil
 }
 
+// WithRefresh enables watch mode. The fetch function is called in the
+// background every interval and the table is updated in place, marking the
+// rows that changed since the previous refresh.
+func (m *PRTableModel) WithRefresh(interval time.Duration, fetch func() ([]*gh.PullRequestData, error)) *PRTableModel {
+	m.refresh = fetch
+	m.interval = interval
+	m.lastRefresh = time.Now()
+	return m
+}
+
+// WithChangeHandler sets a function that is called in the background with the
+// PRs that changed after each refresh in watch mode, for example to send notifications
+func (m *PRTableModel) WithChangeHandler(handler func(changed []*gh.PullRequestData)) *PRTableModel {
+	m.onChange = handler
+	return m
+}
+
+// changeCmd runs the change handler for the changed PRs in the background
+func (m *PRTableModel) changeCmd(items []*gh.PullRequestData) tea.Cmd {
+	var changed []*gh.PullRequestData
+	for _, pr := range items {
+		if pr != nil && pr.Issue != nil && m.changed[prKey(pr)] {
+			changed = append(changed, pr)
+		}
+	}
+	if m.onChange == nil || len(changed) == 0 {
+		return nil
+	}
+
+	handler := m.onChange
+	return func() tea.Msg {
+		handler(changed)
+		return nil
+	}
+}
+
+// tickCmd schedules the next refresh, replacing any tick still pending, so
+// refreshing early with r doesn't start a second chain of refreshes
+func (m *PRTableModel) tickCmd() tea.Cmd {
+	m.tickGeneration++
+	generation := m.tickGeneration
+	return tea.Tick(m.interval, func(time.Time) tea.Msg {
+		return refreshTickMsg{generation: generation}
+	})
+}
+
+// fetchCmd runs the refresh function in the background
+func (m *PRTableModel) fetchCmd() tea.Cmd {
+	fetch := m.refresh
+	return func() tea.Msg {
+		items, err := fetch()
+		return PRsRefreshedMsg{Items: items, Err: err}
+	}
+}
+
+// changedPRs returns the keys of the PRs in updated that are new or
+// differ from the PRs currently shown
+func (m *PRTableModel) changedPRs(updated []*gh.PullRequestData) map[string]bool {
+	previous := make(map[string]string)
+	for _, pr := range m.prData {
+		if pr.Valid() {
+			previous[prKey(pr)] = rowSignature(pr)
+		}
+	}
+
+	changed := make(map[string]bool)
+	for _, pr := range updated {
+		if !pr.Valid() {
+			continue
+		}
+		if sig, ok := previous[prKey(pr)]; !ok || sig != rowSignature(pr) {
+			changed[prKey(pr)] = true
+		}
+	}
+	return changed
+}
+
+// rowSignature summarizes the parts of a PR that matter for change detection
+func rowSignature(pr *gh.PullRequestData) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%d|%s|%v|%v|%s",
+		pr.Title(), pr.State(), pr.ReviewDecision, pr.MergeableState, pr.ReviewerStatus,
+		len(pr.Reviews), pr.ApprovalStatus(), pr.IsDraft, pr.Blocked, pr.UpdatedAt())
+}
+
 func (m *PRTableModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.table.SetWidth(msg.Width)
+		m.width, m.height = msg.Width, msg.Height
+		m.setRows(m.table.Rows())
+		if m.detailPR != nil {
+			m.viewport.Width, m.viewport.Height = msg.Width, msg.Height-2
+			m.renderDetail()
+		}
+		return m, nil
+
+	case prDetailLoadedMsg:
+		if msg.err != nil {
+			logger.Debug("Fetching detail for %s failed: %v", msg.key, msg.err)
+		} else {
+			m.details[msg.key] = msg.detail
+		}
+		if m.detailPR != nil && prKey(m.detailPR) == msg.key {
+			m.detailErr = msg.err
+			m.renderDetail()
+		}
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.detailPR != nil {
+			return m.updateDetail(msg)
+		}
+		if m.picking {
+			return m.updatePicker(msg)
+		}
+		if m.showLegend {
+			switch msg.String() {
+			case "q", "ctrl+c":
+				return m, tea.Quit
+			case "esc", "?":
+				m.showLegend = false
+			}
+			return m, nil
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
+		case "?":
+			m.showLegend = true
+			return m, nil
+		case "x":
+			if m.hints {
+				m.hints = false
+				if m.onDismissHints != nil {
+					m.onDismissHints()
+				}
+				return m, nil
+			}
+		case "enter":
+			if m.loadDetail != nil {
+				if pr := m.selectedPR(); pr != nil {
+					return m, m.openDetail(pr)
+				}
+			}
+		case "r":
+			// Refresh immediately in watch mode
+			if m.refresh != nil && !m.refreshing && !m.switching {
+				m.refreshing = true
+				return m, m.fetchCmd()
+			}
+		case "g":
+			// The refresh would fetch the pull requests of the old source
+			if m.sourceHelp() != "" && !m.refreshing && !m.switching {
+				m.picking = true
+				return m, nil
+			}
+		}
+
+	case prSourceLoadedMsg:
+		return m, m.sourceLoaded(msg)
+
+	case refreshTickMsg:
+		if msg.generation != m.tickGeneration {
+			logger.Debug("Ignoring replaced refresh tick %d", msg.generation)
+			return m, nil
+		}
+		if m.refreshing || m.switching {
+			return m, nil
+		}
+		m.refreshing = true
+		return m, m.fetchCmd()
+
+	case PRsRefreshedMsg:
+		m.refreshing = false
+		var changeCmd tea.Cmd
+		if msg.Err != nil {
+			logger.Debug("Refreshing pull requests failed: %v", msg.Err)
+			m.refreshErr = msg.Err
+		} else {
+			m.refreshErr = nil
+			m.changed = m.changedPRs(msg.Items)
+			logger.Debug("Refreshed %d pull requests, %d changed", len(msg.Items), len(m.changed))
+			// Details of changed PRs are fetched again when next opened
+			for key := range m.changed {
+				delete(m.details, key)
+			}
+			m.UpdatePRs(msg.Items)
+			changeCmd = m.changeCmd(msg.Items)
 		}
+		m.lastRefresh = time.Now()
+		return m, tea.Batch(m.tickCmd(), changeCmd)
 	}
 
 	m.table, cmd = m.table.Update(msg)
@@ -135,43 +528,76 @@ func (m *PRTableModel) View() string {
 	if m.err != nil {
 		return fmt.Sprintf("Error: %v", m.err)
 	}
+	if m.detailPR != nil {
+		return m.viewport.View() + "\n↑/↓: Scroll • esc: Back • q: Quit\n"
+	}
+	if m.picking {
+		return "\nSwitch to:\n\n" + m.picker.View() + "\n↑/↓: Navigate • enter: Switch • esc: Back • q: Quit\n"
+	}
+	if m.showLegend {
+		return "\n" + LegendText() + "\nesc: Back • q: Quit\n"
+	}
 	logger.Debug("Rendering table with %d rows", len(m.table.Rows()))
+	status := m.sourceStatus()
+	if status != "" {
+		status += "\n"
+	}
 	if len(m.table.Rows()) == 0 {
+		if m.refresh != nil || m.sourceHelp() != "" {
+			footer := m.sourceHelp() + "q: Quit"
+			if m.refresh != nil {
+				footer = m.watchStatus() + "\n" + footer
+			}
+			return "No pull requests found\n" + status + footer + "\n"
+		}
 		return "No pull requests found"
 	}
 	var b strings.Builder
 	b.WriteString("\n" + m.table.View() + "\n")
-	b.WriteString("↑/↓: Navigate • q: Quit\n")
+	if !m.asOf.IsZero() {
+		b.WriteString(fmt.Sprintf("As of %s\n", locale.DateTime(m.asOf)))
+	}
+	b.WriteString(status)
+	if m.refresh != nil {
+		b.WriteString(m.watchStatus() + "\n")
+		b.WriteString("↑/↓: Navigate • " + m.detailHelp() + m.sourceHelp() + "r: Refresh • ?: Legend • q: Quit\n")
+	} else {
+		b.WriteString("↑/↓: Navigate • " + m.detailHelp() + m.sourceHelp() + "?: Legend • q: Quit\n")
+	}
+	if m.hints {
+		b.WriteString(hintStyle.Render("Tip: press ? to see what the markers, colors and keys mean, or x to hide this tip") + "\n")
+	}
 	return b.String()
 }
 
+// detailHelp returns the footer hint for the detail view, if it is enabled
+func (m *PRTableModel) detailHelp() string {
+	if m.loadDetail == nil {
+		return ""
+	}
+	return "enter: Details • "
+}
+
+// watchStatus describes the state of watch mode for the footer
+func (m *PRTableModel) watchStatus() string {
+	if m.refreshing {
+		return "Refreshing..."
+	}
+	status := fmt.Sprintf("Last refreshed %s, every %v", m.lastRefresh.Format("15:04:05"), m.interval)
+	if len(m.changed) > 0 {
+		status += fmt.Sprintf(" • %s %d changed since last refresh", changedMarker, len(m.changed))
+	}
+	if m.refreshErr != nil {
+		status += fmt.Sprintf(" • Refresh failed: %v", m.refreshErr)
+	}
+	return status
+}
+
 // UpdatePRs updates the table with new PR data
 func (m *PRTableModel) UpdatePRs(prData []*gh.PullRequestData) {
 	m.prData = prData
 	m.loading = false
 
 	// Update the table with new data
-	m.table.SetRows(createTableRows(prData))
-}
-
-// truncateString shortens a string to the specified length and adds "..." if truncated
-func truncateString(str string, maxLen int) string {
-	if len(str) <= maxLen {
-		return str
-	}
-	return str[:maxLen-3] + "..."
-}
-
-// formatDaysAgo formats a time.Time as "X days ago"
-func formatDaysAgo(t *time.Time) string {
-	if t == nil {
-		return "N/A"
-	}
-	days := int(time.Since(*t).Hours() / 24)
-	if days == 0 {
-		return "today"
-	} else if days == 1 {
-		return "1 day ago"
-	}
-	return fmt.Sprintf("%d days ago", days)
+	m.setRows(createTableRows(prData, m.changed, m.columns, m.columnOptions))
 }
@@ -0,0 +1,27 @@
+package ui
+
+import "strings"
+
+// sparkBlocks are the bars of a sparkline, from the lowest to the highest
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws the values as a row of bars scaled from zero to the largest
+// value, one character per value
+func Sparkline(values []float64) string {
+	var highest float64
+	for _, v := range values {
+		if v > highest {
+			highest = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if highest > 0 && v > 0 {
+			i = int(v / highest * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[i])
+	}
+	return b.String()
+}
@@ -3,17 +3,32 @@ package ui
 import (
 	"context"
 	"fmt"
+	"os"
+	"time"
+
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	gh "github.com/jbrinkman/ghi/pkg/github"
 )
 
 type LoaderModel struct {
 	spinner  spinner.Model
 	message  string
 	complete bool
+	// ctx is cancelled by the first Ctrl+C, a second one abandons the work
+	ctx       context.Context
+	stopping  bool
+	abandoned bool
+	// updates carries the progress of the work, progress is the latest
+	updates  <-chan gh.Progress
+	progress *gh.Progress
 }
 
+// progressMsg carries a progress update to the spinner
+type progressMsg gh.Progress
+
 func NewLoader(message string) *LoaderModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
@@ -29,16 +44,44 @@ func NewLoader(message string) *LoaderModel {
 func (m LoaderModel) Init() tea.Cmd {
 	return tea.Batch(
 		m.spinner.Tick,
+		waitForProgress(m.updates),
 	)
 }
 
+// waitForProgress waits for the next progress update, or does nothing when
+// the work doesn't report progress
+func waitForProgress(updates <-chan gh.Progress) tea.Cmd {
+	if updates == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		progress, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return progressMsg(progress)
+	}
+}
+
 func (m LoaderModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "q", "ctrl+c":
+			// Give the work a chance to stop and return what it has so far
+			if !m.stopping && m.ctx != nil {
+				m.stopping = true
+				m.message = "Stopping"
+				Interrupt(m.ctx)
+				return m, nil
+			}
+			m.abandoned = true
 			return m, tea.Quit
 		}
+	case progressMsg:
+		progress := gh.Progress(msg)
+		m.progress = &progress
+		return m, waitForProgress(m.updates)
 	}
 
 	var cmd tea.Cmd
@@ -50,14 +93,52 @@ func (m LoaderModel) View() string {
 	if m.complete {
 		return ""
 	}
-	return fmt.Sprintf("%s %s...", m.spinner.View(), m.message)
+	if m.progress == nil || m.stopping {
+		return fmt.Sprintf("%s %s...", m.spinner.View(), m.message)
+	}
+	return fmt.Sprintf("%s %s: %s", m.spinner.View(), m.message, formatProgress(*m.progress))
+}
+
+// formatProgress describes a progress update, such as
+// "reviews 42/120 (octocat/Hello-World#12, about 30s left)"
+func formatProgress(p gh.Progress) string {
+	text := fmt.Sprintf("%s %d/%d (%s", p.Stage, p.Done, p.Total, p.Current)
+	if eta := p.ETA(); eta > 0 {
+		text += fmt.Sprintf(", about %s left", eta.Round(time.Second))
+	}
+	return text + ")"
 }
 
 // WithSpinner runs the provided function while showing a loading spinner.
-// The function can return a value of any type and an error.
+// The function can return a value of any type and an error. The spinner is
+// drawn on stderr so output piped from stdout isn't affected, and left out
+// when stderr isn't a terminal, such as in CI jobs.
+//
+// Pressing Ctrl+C interrupts ctx, when it comes from WithInterrupt, and waits
+// for the function to return what it fetched so far. Pressing it again stops
+// waiting and returns ErrInterrupted.
 func WithSpinner[T any](ctx context.Context, message string, fn func() (T, error)) (T, error) {
+	return runSpinner(ctx, message, nil, fn)
+}
+
+// WithProgress runs fn like WithSpinner, showing the progress it reports on
+// updates next to the message: the step, how many pull requests are done,
+// the one done last and an estimate of the time left in the step.
+func WithProgress[T any](ctx context.Context, message string, updates <-chan gh.Progress, fn func() (T, error)) (T, error) {
+	return runSpinner(ctx, message, updates, fn)
+}
+
+// runSpinner runs fn while showing the spinner and any progress on updates
+func runSpinner[T any](ctx context.Context, message string, updates <-chan gh.Progress, fn func() (T, error)) (T, error) {
+	if !term.IsTerminal(os.Stderr.Fd()) {
+		value, err := fn()
+		return interruptedResult(ctx, value, err)
+	}
+
 	loader := NewLoader(message)
-	p := tea.NewProgram(loader)
+	loader.ctx = ctx
+	loader.updates = updates
+	p := tea.NewProgram(loader, tea.WithOutput(os.Stderr))
 
 	// Start the spinner in a goroutine
 	type result struct {
@@ -73,17 +154,27 @@ func WithSpinner[T any](ctx context.Context, message string, fn func() (T, error
 	}()
 
 	// Run the TUI
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	if err != nil {
 		var zero T
 		return zero, fmt.Errorf("failed to run spinner: %w", err)
 	}
+	if m, ok := final.(LoaderModel); ok && m.abandoned {
+		var zero T
+		return zero, ErrInterrupted
+	}
 
 	// Return the result from the function
-	select {
-	case res := <-done:
-		return res.value, res.err
-	case <-ctx.Done():
-		var zero T
-		return zero, ctx.Err()
+	res := <-done
+	return interruptedResult(ctx, res.value, res.err)
+}
+
+// interruptedResult passes a result through, except that errors caused by
+// Ctrl+C become ErrInterrupted. A function that stops early without an error
+// returns what it fetched so far.
+func interruptedResult[T any](ctx context.Context, value T, err error) (T, error) {
+	if err != nil && Interrupted(ctx) {
+		return value, ErrInterrupted
 	}
+	return value, err
 }